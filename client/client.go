@@ -0,0 +1,324 @@
+// Package client is a Go client for the Snippetbox JSON API.
+//
+//	c := client.New("https://snippetbox.example.com", token)
+//
+//	id, err := c.CreateSnippet(ctx, client.NewSnippet{
+//		Title:   "Haiku",
+//		Content: "Over the wintry forest",
+//		Expires: 7,
+//	})
+//
+// Every method takes a context, which bounds the request and any retries.
+// Errors the API answers with are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the API version the client speaks.
+const Version = "v1"
+
+const (
+	// DefaultMaxRetries is how often New lets a request be retried.
+	DefaultMaxRetries = 3
+
+	// maxRetryAfter is the longest Retry-After the client waits for; a
+	// server asking for more, e.g. during maintenance, gets an error.
+	maxRetryAfter = time.Minute
+
+	// baseBackoff is the delay before the first retry when the server does
+	// not say how long to wait; it doubles with every retry.
+	baseBackoff = 500 * time.Millisecond
+)
+
+// Client calls the API of one Snippetbox instance with a personal access
+// token. Its fields must not change while it is in use.
+type Client struct {
+	// BaseURL is where the instance is served, including any base path,
+	// e.g. https://example.com/snippetbox.
+	BaseURL string
+	// Token is the personal access token sent with every request.
+	Token string
+	// HTTPClient sends the requests; nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxRetries is how often a request is retried after a rate limit, a
+	// temporary outage or, for requests that are safe to repeat, a network
+	// error. Zero disables retries.
+	MaxRetries int
+	// UserAgent is sent with every request.
+	UserAgent string
+}
+
+// New returns a client for the instance at baseURL using token, which
+// retries failed requests up to DefaultMaxRetries times.
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		MaxRetries: DefaultMaxRetries,
+		UserAgent:  "snippetbox-go-client",
+	}
+}
+
+// Error is an error answered by the API.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Message is the API's description of the error.
+	Message string
+	// Fields maps invalid fields to what is wrong with them, on a 422.
+	Fields map[string]string
+	// Items holds the invalid fields of each snippet of a batch, at the
+	// same index, when a batch is refused.
+	Items []map[string]string
+	// RetryAfter is how long the server asked the client to wait, if it did.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("snippetbox: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is the API answering 404.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// do sends a request for the API path with body, if not nil, encoded as
+// JSON, and decodes the response into dst, if not nil. It retries as
+// MaxRetries allows.
+func (c *Client) do(ctx context.Context, method, path string, body, dst any) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("snippetbox: encoding request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, payload)
+
+		retry, wait := c.shouldRetry(method, attempt, resp, err)
+		if !retry {
+			if err != nil {
+				return err
+			}
+
+			return decodeResponse(resp, dst)
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("snippetbox: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/api/"+Version+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("snippetbox: creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("snippetbox: %s %s: %w", method, path, err)
+	}
+
+	return resp, nil
+}
+
+// shouldRetry decides whether the outcome of an attempt is worth another,
+// and after how long. Rate limits and 503s are answered before a request
+// is handled, so they are retried whatever the method; network errors and
+// gateway errors may come after it was, so only requests that are safe to
+// repeat are retried then.
+func (c *Client) shouldRetry(method string, attempt int, resp *http.Response, err error) (bool, time.Duration) {
+	if attempt >= c.MaxRetries {
+		return false, 0
+	}
+
+	idempotent := method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+
+	if err != nil {
+		return idempotent && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded), backoff(attempt)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		if !idempotent {
+			return false, 0
+		}
+	default:
+		return false, 0
+	}
+
+	if wait, ok := retryAfter(resp); ok {
+		return wait <= maxRetryAfter, wait
+	}
+
+	return true, backoff(attempt)
+}
+
+// backoff returns the delay before retry number attempt+1: baseBackoff
+// doubled for every earlier retry, less up to half of it at random so
+// clients that failed together do not retry together.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << attempt
+
+	return d - rand.N(d/2) //nolint:gosec // jitter needs no cryptographic randomness
+}
+
+// retryAfter parses the Retry-After header of resp, which the API sends in
+// seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+
+	return time.Duration(secs) * time.Second, true
+}
+
+// decodeResponse decodes a successful response into dst, or turns an error
+// response into an *Error.
+func decodeResponse(resp *http.Response, dst any) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	if dst == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("snippetbox: decoding response: %w", err)
+	}
+
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	var body struct {
+		Error   string            `json:"error"`
+		Fields  map[string]string `json:"fields"`
+		Results []struct {
+			Fields map[string]string `json:"fields"`
+		} `json:"results"`
+	}
+
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil || body.Error == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	} else {
+		apiErr.Message = body.Error
+		apiErr.Fields = body.Fields
+	}
+
+	for _, result := range body.Results {
+		apiErr.Items = append(apiErr.Items, result.Fields)
+	}
+
+	apiErr.RetryAfter, _ = retryAfter(resp)
+
+	return apiErr
+}
+
+// Status describes the running build of the instance, see Client.Status.
+type Status struct {
+	Version       string         `json:"version"`
+	Commit        string         `json:"commit"`
+	BuildDate     string         `json:"build_date"`
+	GoVersion     string         `json:"go_version"`
+	Uptime        string         `json:"uptime"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Database      DatabaseStatus `json:"database"`
+}
+
+// DatabaseStatus is how the instance's database answered a ping.
+type DatabaseStatus struct {
+	// Status is "ok" or "unreachable".
+	Status string `json:"status"`
+	// LatencyMS is the round trip in milliseconds, or nil when the
+	// database is unreachable.
+	LatencyMS *float64 `json:"latency_ms"`
+}
+
+// Status returns which build the instance runs, its uptime and how quickly
+// it reaches its database. It needs no token.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var status Status
+
+	err := c.do(ctx, http.MethodGet, "/status", nil, &status)
+
+	return status, err
+}
+
+// Account describes the owner of the client's token.
+type Account struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Token is the name of the token.
+	Token string `json:"token"`
+}
+
+// Whoami returns the owner of the client's token.
+func (c *Client) Whoami(ctx context.Context) (Account, error) {
+	var account Account
+
+	err := c.do(ctx, http.MethodGet, "/whoami", nil, &account)
+
+	return account, err
+}
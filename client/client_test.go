@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestGetSnippet(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer sb_test":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid token"}`)) //nolint:errcheck // test server
+		case r.URL.Path == "/base/api/v1/snippets/1":
+			w.Write([]byte(`{"id":1,"title":"An old silent pond","content":"A frog jumps in"}`)) //nolint:errcheck // test server
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"snippet not found"}`)) //nolint:errcheck // test server
+		}
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL+"/base/", "sb_test")
+
+	snippet, err := c.GetSnippet(t.Context(), 1)
+	assert.NilError(t, err)
+	assert.Equal(t, snippet.ID, 1)
+	assert.Equal(t, snippet.Title, "An old silent pond")
+
+	_, err = c.GetSnippet(t.Context(), 2)
+	assert.Equal(t, IsNotFound(err), true)
+	assert.Equal(t, err.Error(), "snippetbox: snippet not found (status 404)")
+
+	c.Token = "wrong"
+
+	_, err = c.GetSnippet(t.Context(), 1)
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v; want *Error", err)
+	}
+
+	assert.Equal(t, apiErr.StatusCode, http.StatusUnauthorized)
+}
+
+func TestValidationError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
+		if r.URL.Path == "/api/v1/snippets:batch" {
+			w.Write([]byte(`{"error":"invalid snippets","results":[{},{"fields":{"title":"must not be blank"}}]}`)) //nolint:errcheck // test server
+		} else {
+			w.Write([]byte(`{"error":"invalid snippet","fields":{"expires":"must be 1, 7 or 365"}}`)) //nolint:errcheck // test server
+		}
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, "sb_test")
+
+	var apiErr *Error
+
+	_, err := c.CreateSnippet(t.Context(), NewSnippet{Title: "Haiku", Content: "x", Expires: 2})
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v; want *Error", err)
+	}
+
+	assert.Equal(t, apiErr.Fields["expires"], "must be 1, 7 or 365")
+
+	_, err = c.CreateSnippets(t.Context(), []NewSnippet{{Title: "Haiku"}, {}})
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v; want *Error", err)
+	}
+
+	assert.Equal(t, len(apiErr.Items), 2)
+	assert.Equal(t, apiErr.Items[1]["title"], "must not be blank")
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int
+		retryAfter   string
+		wantAttempts int32
+	}{
+		{"Rate limited", http.MethodPost, http.StatusTooManyRequests, "0", 3},
+		{"Unavailable", http.MethodGet, http.StatusServiceUnavailable, "", 3},
+		{"Bad gateway", http.MethodDelete, http.StatusBadGateway, "0", 3},
+		{"Bad gateway not idempotent", http.MethodPost, http.StatusBadGateway, "", 1},
+		{"Too long to wait", http.MethodGet, http.StatusServiceUnavailable, "300", 1},
+		{"Not found", http.MethodGet, http.StatusNotFound, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			// Every request fails until the third.
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) < 3 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(tt.status)
+
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			c := New(ts.URL, "sb_test")
+
+			err := c.do(t.Context(), tt.method, "/snippets/1", nil, nil)
+
+			assert.Equal(t, attempts.Load(), tt.wantAttempts)
+			assert.Equal(t, err == nil, tt.wantAttempts == 3)
+		})
+	}
+}
+
+// failingTransport fails every request with a network error.
+type failingTransport struct {
+	attempts atomic.Int32
+}
+
+func (ft *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	ft.attempts.Add(1)
+
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestNetworkErrorRetries(t *testing.T) {
+	ft := &failingTransport{}
+
+	c := New("https://snippetbox.example.com", "sb_test")
+	c.HTTPClient = &http.Client{Transport: ft}
+	c.MaxRetries = 1
+
+	_, err := c.CreateSnippet(t.Context(), NewSnippet{Title: "Haiku"})
+	assert.StringContains(t, err.Error(), "connection reset by peer")
+	assert.Equal(t, ft.attempts.Load(), int32(1))
+
+	err = c.DeleteSnippet(t.Context(), 1)
+	assert.StringContains(t, err.Error(), "connection reset by peer")
+	assert.Equal(t, ft.attempts.Load(), int32(3))
+}
+
+func TestRetryStopsWithContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := New(ts.URL, "sb_test").ListSnippets(ctx, Page{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v; want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Snippet is a snippet as the API returns it.
+type Snippet struct {
+	ID      int       `json:"id"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewSnippet is what a snippet is created or replaced from.
+type NewSnippet struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// Expires is in how many days the snippet expires: 1, 7 or 365.
+	Expires int `json:"expires"`
+}
+
+// Page selects a page of a list. Zero values use the API's defaults.
+type Page struct {
+	Page  int
+	Limit int
+}
+
+func (p Page) values() url.Values {
+	v := url.Values{}
+
+	if p.Page > 0 {
+		v.Set("page", strconv.Itoa(p.Page))
+	}
+
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	return v
+}
+
+// withQuery appends v to path as its query string, if there is one.
+func withQuery(path string, v url.Values) string {
+	if len(v) == 0 {
+		return path
+	}
+
+	return path + "?" + v.Encode()
+}
+
+// ListSnippets returns a page of the latest snippets, newest first.
+func (c *Client) ListSnippets(ctx context.Context, page Page) ([]Snippet, error) {
+	var resp struct {
+		Snippets []Snippet `json:"snippets"`
+	}
+
+	err := c.do(ctx, http.MethodGet, withQuery("/snippets", page.values()), nil, &resp)
+
+	return resp.Snippets, err
+}
+
+// GetSnippet returns the snippet with the given ID.
+func (c *Client) GetSnippet(ctx context.Context, id int) (Snippet, error) {
+	var snippet Snippet
+
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/snippets/%d", id), nil, &snippet)
+
+	return snippet, err
+}
+
+// CreateSnippet creates a snippet owned by the token's user and returns
+// its ID.
+func (c *Client) CreateSnippet(ctx context.Context, s NewSnippet) (int, error) {
+	var resp struct {
+		ID int `json:"id"`
+	}
+
+	err := c.do(ctx, http.MethodPost, "/snippets", s, &resp)
+
+	return resp.ID, err
+}
+
+// CreateSnippets creates up to 100 snippets at once and returns their IDs
+// in order. If any is invalid none is created, and the *Error's Items tell
+// what is wrong with each.
+func (c *Client) CreateSnippets(ctx context.Context, snippets []NewSnippet) ([]int, error) {
+	var resp struct {
+		Results []struct {
+			ID int `json:"id"`
+		} `json:"results"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/snippets:batch", map[string]any{"snippets": snippets}, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(resp.Results))
+	for i, result := range resp.Results {
+		ids[i] = result.ID
+	}
+
+	return ids, nil
+}
+
+// UpdateSnippet replaces one of the token user's snippets and returns it
+// as it is now.
+func (c *Client) UpdateSnippet(ctx context.Context, id int, s NewSnippet) (Snippet, error) {
+	var snippet Snippet
+
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/snippets/%d", id), s, &snippet)
+
+	return snippet, err
+}
+
+// DeleteSnippet deletes one of the token user's snippets.
+func (c *Client) DeleteSnippet(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/snippets/%d", id), nil, nil)
+}
+
+// Search orders.
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+)
+
+// Search is a full-text search of the live snippets.
+type Search struct {
+	// Query takes words, quoted phrases, OR and -word.
+	Query string
+	// AuthorID, if not zero, keeps one user's snippets.
+	AuthorID int
+	// Sort is SortRelevance, the default, SortNewest or SortOldest.
+	Sort string
+	Page
+}
+
+// SearchResult is a snippet matching a search and how well it matches.
+type SearchResult struct {
+	Snippet
+
+	Score float64 `json:"score"`
+	// Headline is an excerpt of the content around the matches, as HTML
+	// with each matching word in a <mark> element.
+	Headline string `json:"headline"`
+}
+
+// SearchResults is a page of results and how many there are in all.
+type SearchResults struct {
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
+}
+
+// Search searches the titles and contents of the live snippets.
+func (c *Client) Search(ctx context.Context, s Search) (SearchResults, error) {
+	v := s.values()
+	v.Set("q", s.Query)
+
+	if s.AuthorID != 0 {
+		v.Set("author", strconv.Itoa(s.AuthorID))
+	}
+
+	if s.Sort != "" {
+		v.Set("sort", s.Sort)
+	}
+
+	var results SearchResults
+
+	err := c.do(ctx, http.MethodGet, withQuery("/search", v), nil, &results)
+
+	return results, err
+}
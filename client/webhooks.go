@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EventSnippetCreated is sent to webhooks when their owner creates a
+// snippet.
+const EventSnippetCreated = "snippet.created"
+
+// Webhook is a URL the instance POSTs the token user's events to.
+type Webhook struct {
+	ID     int      `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs the deliveries. It is only set by CreateWebhook and
+	// RotateWebhookSecret.
+	Secret  string    `json:"secret"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// WebhookTest is how a webhook answered a test delivery.
+type WebhookTest struct {
+	Delivered bool `json:"delivered"`
+	// Status is the HTTP status the webhook answered with, if it did.
+	Status int `json:"status"`
+	// Error says why the delivery failed.
+	Error string `json:"error"`
+}
+
+type webhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// ListWebhooks returns the token user's webhooks.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var resp struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+
+	err := c.do(ctx, http.MethodGet, "/webhooks", nil, &resp)
+
+	return resp.Webhooks, err
+}
+
+// GetWebhook returns one of the token user's webhooks.
+func (c *Client) GetWebhook(ctx context.Context, id int) (Webhook, error) {
+	var hook Webhook
+
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/webhooks/%d", id), nil, &hook)
+
+	return hook, err
+}
+
+// CreateWebhook registers a webhook for events at url, which must be on a
+// public host. The returned webhook holds its secret, which is not shown
+// again.
+func (c *Client) CreateWebhook(ctx context.Context, url string, events ...string) (Webhook, error) {
+	var hook Webhook
+
+	err := c.do(ctx, http.MethodPost, "/webhooks", webhookInput{URL: url, Events: events}, &hook)
+
+	return hook, err
+}
+
+// UpdateWebhook replaces the URL and events of one of the token user's
+// webhooks.
+func (c *Client) UpdateWebhook(ctx context.Context, id int, url string, events ...string) (Webhook, error) {
+	var hook Webhook
+
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/webhooks/%d", id), webhookInput{URL: url, Events: events}, &hook)
+
+	return hook, err
+}
+
+// DeleteWebhook deletes one of the token user's webhooks.
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/webhooks/%d", id), nil, nil)
+}
+
+// RotateWebhookSecret gives one of the token user's webhooks a new secret
+// and returns it; the old one stops working at once.
+func (c *Client) RotateWebhookSecret(ctx context.Context, id int) (Webhook, error) {
+	var hook Webhook
+
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/webhooks/%d/rotate-secret", id), nil, &hook)
+
+	return hook, err
+}
+
+// TestWebhook sends a ping event to one of the token user's webhooks and
+// returns how it answered.
+func (c *Client) TestWebhook(ctx context.Context, id int) (WebhookTest, error) {
+	var result WebhookTest
+
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/webhooks/%d/test", id), nil, &result)
+
+	return result, err
+}
@@ -0,0 +1,171 @@
+// Command snip posts text to a Snippetbox instance and prints the URL of the
+// new snippet:
+//
+//	make 2>&1 | snip -title "Build log"
+//	snip -expires 1 notes.txt
+//
+// The instance and the API token come from -url and -token, or from the
+// SNIPPETBOX_URL and SNIPPETBOX_TOKEN environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/FABLOUSFALCON/snippetbox/client"
+)
+
+const (
+	// maxTitle is the longest title the API accepts, in characters.
+	maxTitle = 100
+
+	// maxInput bounds what snip reads, well above what an instance accepts,
+	// so a runaway pipe fails fast instead of filling memory.
+	maxInput = 16 << 20
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv)
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "snip:", err)
+		os.Exit(1)
+	}
+}
+
+// run posts the file named in args, or stdin if there is none or it is "-",
+// and writes the snippet's URL to stdout.
+func run(
+	ctx context.Context,
+	args []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	getenv func(string) string,
+) error {
+	fs := flag.NewFlagSet("snip", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: snip [flags] [file]")
+		fmt.Fprintln(fs.Output(), "\nPosts file, or stdin, as a snippet and prints its URL.")
+		fs.PrintDefaults()
+	}
+
+	baseURL := fs.String("url", getenv("SNIPPETBOX_URL"), "URL of the Snippetbox instance (env SNIPPETBOX_URL)")
+	token := fs.String("token", getenv("SNIPPETBOX_TOKEN"), "API token (env SNIPPETBOX_TOKEN)")
+	title := fs.String("title", "", "Title of the snippet (default the file name, or the first line of stdin)")
+	expires := fs.Int("expires", 7, "Days until the snippet expires: 1, 7 or 365")
+
+	if err := fs.Parse(args); err != nil {
+		return err //nolint:wrapcheck // the flag package already printed why
+	}
+
+	switch {
+	case *baseURL == "":
+		return errors.New("no instance: set -url or SNIPPETBOX_URL")
+	case *token == "":
+		return errors.New("no token: set -token or SNIPPETBOX_TOKEN")
+	case fs.NArg() > 1:
+		return errors.New("only one file can be posted at a time")
+	}
+
+	name := fs.Arg(0)
+
+	content, err := readInput(name, stdin)
+	if err != nil {
+		return err
+	}
+
+	if *title == "" {
+		*title = defaultTitle(name, content)
+	}
+
+	c := client.New(*baseURL, *token)
+
+	id, err := c.CreateSnippet(ctx, client.NewSnippet{Title: *title, Content: content, Expires: *expires})
+	if err != nil {
+		return describe(err)
+	}
+
+	fmt.Fprintf(stdout, "%s/snippet/view/%d\n", c.BaseURL, id)
+
+	return nil
+}
+
+// readInput reads the file name, or r when name is empty or "-".
+func readInput(name string, r io.Reader) (string, error) {
+	if name != "" && name != "-" {
+		f, err := os.Open(name) //nolint:gosec // reading the file the user named is the point
+		if err != nil {
+			return "", err //nolint:wrapcheck // the error names the file
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, maxInput+1))
+	if err != nil {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+
+	if len(b) > maxInput {
+		return "", fmt.Errorf("input is larger than %d MiB", maxInput>>20)
+	}
+
+	if strings.TrimSpace(string(b)) == "" {
+		return "", errors.New("nothing to post: the input is empty")
+	}
+
+	return string(b), nil
+}
+
+// defaultTitle names a snippet after its file or, for stdin, its first line
+// that is not blank, shortened to what the API accepts.
+func defaultTitle(name, content string) string {
+	title := filepath.Base(name)
+
+	if name == "" || name == "-" {
+		for line := range strings.Lines(content) {
+			if title = strings.TrimSpace(line); title != "" {
+				break
+			}
+		}
+	}
+
+	if utf8.RuneCountInString(title) > maxTitle {
+		title = string([]rune(title)[:maxTitle-1]) + "…"
+	}
+
+	return title
+}
+
+// describe adds the invalid fields of a refused snippet to err.
+func describe(err error) error {
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || len(apiErr.Fields) == 0 {
+		return err
+	}
+
+	fields := make([]string, 0, len(apiErr.Fields))
+	for field, problem := range apiErr.Fields {
+		fields = append(fields, field+": "+problem)
+	}
+
+	slices.Sort(fields)
+
+	return fmt.Errorf("%w\n  %s", err, strings.Join(fields, "\n  "))
+}
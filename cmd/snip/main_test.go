@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestRun(t *testing.T) {
+	var got struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+		Expires int    `json:"expires"`
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/snippets" || r.Header.Get("Authorization") != "Bearer sb_test" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+
+		if got.Expires != 1 && got.Expires != 7 && got.Expires != 365 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error":"invalid snippet","fields":{"expires":"This field must be equal 1, 7, or 365."}}`)) //nolint:errcheck // test server
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":42}`)) //nolint:errcheck // test server
+	}))
+	defer ts.Close()
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("from a file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"SNIPPETBOX_URL": ts.URL + "/", "SNIPPETBOX_TOKEN": "sb_test"}
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		env         map[string]string
+		wantTitle   string
+		wantContent string
+		wantExpires int
+		wantErr     string
+	}{
+		{
+			name:        "Stdin",
+			stdin:       "\n  panic: runtime error\ngoroutine 1 [running]:\n",
+			env:         env,
+			wantTitle:   "panic: runtime error",
+			wantContent: "\n  panic: runtime error\ngoroutine 1 [running]:\n",
+			wantExpires: 7,
+		},
+		{
+			name:        "File",
+			args:        []string{"-expires", "365", "-title", "Notes", file},
+			env:         env,
+			wantTitle:   "Notes",
+			wantContent: "from a file",
+			wantExpires: 365,
+		},
+		{
+			name:        "File title",
+			args:        []string{"-url", ts.URL, "-token", "sb_test", file},
+			wantTitle:   "notes.txt",
+			wantContent: "from a file",
+			wantExpires: 7,
+		},
+		{name: "Invalid", args: []string{"-expires", "2"}, stdin: "x", env: env, wantErr: "expires: This field must be equal 1, 7, or 365."},
+		{name: "Empty", stdin: " \n", env: env, wantErr: "the input is empty"},
+		{name: "Missing file", args: []string{"missing.txt"}, env: env, wantErr: "missing.txt"},
+		{name: "No URL", stdin: "x", env: map[string]string{"SNIPPETBOX_TOKEN": "sb_test"}, wantErr: "set -url or SNIPPETBOX_URL"},
+		{name: "No token", stdin: "x", env: map[string]string{"SNIPPETBOX_URL": ts.URL}, wantErr: "set -token or SNIPPETBOX_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			getenv := func(key string) string { return tt.env[key] }
+
+			err := run(t.Context(), tt.args, strings.NewReader(tt.stdin), &stdout, &stderr, getenv)
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got no error; want %q", tt.wantErr)
+				}
+
+				assert.StringContains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, stdout.String(), ts.URL+"/snippet/view/42\n")
+			assert.Equal(t, got.Title, tt.wantTitle)
+			assert.Equal(t, got.Content, tt.wantContent)
+			assert.Equal(t, got.Expires, tt.wantExpires)
+		})
+	}
+}
+
+func TestDefaultTitle(t *testing.T) {
+	long := strings.Repeat("é", 150)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"File", "logs/build.log", "ok", "build.log"},
+		{"First line", "", "\n\n  make: *** [all] Error 2\nmore", "make: *** [all] Error 2"},
+		{"Dash", "-", "hello", "hello"},
+		{"Long", "", long, strings.Repeat("é", 99) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, defaultTitle(tt.file, tt.content), tt.want)
+		})
+	}
+}
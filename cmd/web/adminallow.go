@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// adminPrefixes are the paths, relative to the base path, of the admin and
+// debugging surfaces that -admin-allow restricts, along with /admin/ in
+// every version of the API.
+var adminPrefixes = []string{"/admin/", "/_debug/", "/debug/"}
+
+// restrictAdminAccess answers requests for the admin and debugging surfaces
+// from addresses outside app.adminAllow with a 404, so they stay out of reach
+// of anyone off the office network even with an admin's credentials. It runs
+// before the session and authentication middleware, so such clients learn
+// nothing about those pages, not even that they require a login.
+func (app *application) restrictAdminAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAdminPath(r.URL.Path) || app.adminAddr(app.clientIP(r)) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		app.logger.WarnContext(r.Context(), "blocked admin request from outside the allowlist",
+			slog.String("ip", app.clientIP(r)), slog.String("uri", r.URL.RequestURI()))
+
+		app.notFound(w, r)
+	})
+}
+
+func isAdminPath(path string) bool {
+	if rest, ok := strings.CutPrefix(path, "/api/"); ok {
+		_, rest, _ = strings.Cut(rest, "/")
+
+		return strings.HasPrefix(rest, "admin/")
+	}
+
+	for _, prefix := range adminPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adminAddr reports whether ip belongs to one of the ranges in
+// app.adminAllow.
+func (app *application) adminAddr(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range app.adminAllow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
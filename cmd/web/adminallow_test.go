@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestRestrictAdminAccess(t *testing.T) {
+	app := newTestApplication(t)
+
+	var err error
+
+	app.adminAllow, err = parseAddrRanges("10.8.0.0/16, 192.0.2.7", "admin allowlist")
+	assert.NilError(t, err)
+
+	app.trustedProxies, err = parseAddrRanges("10.0.0.1", "trusted proxy")
+	assert.NilError(t, err)
+
+	handler := app.routes()
+
+	tests := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		forwarded  string
+		wantCode   int
+	}{
+		{"Allowed range", "/admin/audit", "10.8.3.4:5000", "", http.StatusSeeOther},
+		{"Allowed address", "/admin/audit", "192.0.2.7:5000", "", http.StatusSeeOther},
+		{"Outside", "/admin/audit", "203.0.113.5:5000", "", http.StatusNotFound},
+		{"Outside API", "/api/v1/admin/settings", "203.0.113.5:5000", "", http.StatusNotFound},
+		{"Allowed API", "/api/v1/admin/settings", "10.8.3.4:5000", "", http.StatusUnauthorized},
+		{"Outside behind proxy", "/admin/audit", "10.0.0.1:5000", "203.0.113.5", http.StatusNotFound},
+		{"Allowed behind proxy", "/admin/audit", "10.0.0.1:5000", "10.8.3.4", http.StatusSeeOther},
+		{"Spoofed header", "/admin/audit", "203.0.113.5:5000", "10.8.3.4", http.StatusNotFound},
+		{"Public page", "/about", "203.0.113.5:5000", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, r)
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+		})
+	}
+}
+
+func TestIsAdminPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/admin/users/1", true},
+		{"/api/v1/admin/settings", true},
+		{"/api/v2/admin/settings", true},
+		{"/_debug/requests", true},
+		{"/debug/pprof/", true},
+		{"/administrator", false},
+		{"/api/v1/whoami", false},
+		{"/api/docs", false},
+		{"/snippet/view/1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, isAdminPath(tt.path), tt.want)
+		})
+	}
+}
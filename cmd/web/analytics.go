@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// directReferrer labels views that arrived without a usable Referer header.
+const directReferrer = "(direct)"
+
+// referrerHost reduces the Referer header to a bare host name so rollups
+// group by site rather than by individual page. Same-site navigation, and
+// headers naming no valid host, are reported as direct traffic.
+func referrerHost(r *http.Request) string {
+	ref := r.Referer()
+	if ref == "" {
+		return directReferrer
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return directReferrer
+	}
+
+	host, ok := normalizeHost(u.Hostname())
+	if !ok {
+		return directReferrer
+	}
+
+	if self, _ := normalizeHost(hostWithoutPort(r.Host)); host == self {
+		return directReferrer
+	}
+
+	return strings.TrimPrefix(host, "www.")
+}
+
+// hostWithoutPort returns the host of a Host header, without its port or,
+// for IPv6 addresses, its brackets.
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// normalizeHost lower-cases a DNS name, or formats an IP address in its
+// canonical form, reporting false if host is neither. The Referer header is
+// client-supplied, so only names made of valid labels are stored.
+func normalizeHost(host string) (string, bool) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.WithZone("").String(), true
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || len(host) > 253 {
+		return "", false
+	}
+
+	for label := range strings.SplitSeq(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", false
+		}
+
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+				return "", false
+			}
+		}
+	}
+
+	return host, true
+}
+
+// uaFamilies is checked in order, so more specific tokens (Edge, Opera) must
+// come before the engines they embed (Chrome, Safari).
+var uaFamilies = []struct {
+	token  string
+	family string
+}{
+	{"bot", "Bot"},
+	{"spider", "Bot"},
+	{"crawl", "Bot"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"go-http-client", "Go"},
+	{"python", "Python"},
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"firefox/", "Firefox"},
+	{"chrome/", "Chrome"},
+	{"chromium/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+// uaFamily maps a User-Agent header to a coarse browser or client family.
+func uaFamily(ua string) string {
+	ua = strings.ToLower(ua)
+	if ua == "" {
+		return "Unknown"
+	}
+
+	for _, f := range uaFamilies {
+		if strings.Contains(ua, f.token) {
+			return f.family
+		}
+	}
+
+	return "Other"
+}
+
+// recordSnippetView adds the request to the snippet's view rollups. Only the
+// referrer host and user-agent family are stored, never the client IP.
+func (app *application) recordSnippetView(r *http.Request, snippetID int) {
+	err := app.analytics.RecordView(r.Context(), snippetID, referrerHost(r), uaFamily(r.UserAgent()))
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
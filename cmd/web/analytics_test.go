@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestUAFamily(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want string
+	}{
+		{
+			name: "Empty",
+			ua:   "",
+			want: "Unknown",
+		},
+		{
+			name: "Firefox",
+			ua:   "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0",
+			want: "Firefox",
+		},
+		{
+			name: "Chrome",
+			ua:   "Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36",
+			want: "Chrome",
+		},
+		{
+			name: "Edge",
+			ua:   "Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36 Edg/126.0",
+			want: "Edge",
+		},
+		{
+			name: "Safari",
+			ua:   "Mozilla/5.0 (Macintosh) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15",
+			want: "Safari",
+		},
+		{
+			name: "Bot",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: "Bot",
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.5.0",
+			want: "curl",
+		},
+		{
+			name: "Other",
+			ua:   "SomethingElse/1.0",
+			want: "Other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, uaFamily(tt.ua), tt.want)
+		})
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		referer string
+		want    string
+	}{
+		{
+			name:    "Missing",
+			referer: "",
+			want:    directReferrer,
+		},
+		{
+			name:    "External",
+			referer: "https://www.Example.com/some/page?q=1",
+			want:    "example.com",
+		},
+		{
+			name:    "Same site",
+			referer: "https://snippetbox.test/snippet/view/1",
+			want:    directReferrer,
+		},
+		{
+			name:    "Garbage",
+			referer: "::not a url",
+			want:    directReferrer,
+		},
+		{
+			name:    "Markup in host",
+			referer: "https://a<img src=x onerror=alert(1)>.example/",
+			want:    directReferrer,
+		},
+		{
+			name:    "IPv6",
+			referer: "http://[2001:DB8::1]:8080/",
+			want:    "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequestWithContext(
+				t.Context(),
+				http.MethodGet,
+				"https://snippetbox.test:4001/snippet/view/1",
+				nil,
+			)
+			if tt.referer != "" {
+				r.Header.Set("Referer", tt.referer)
+			}
+
+			assert.Equal(t, referrerHost(r), tt.want)
+		})
+	}
+}
+
+func TestHostWithoutPort(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"snippetbox.test", "snippetbox.test"},
+		{"snippetbox.test:4001", "snippetbox.test"},
+		{"[::1]", "::1"},
+		{"[::1]:4001", "::1"},
+		{"127.0.0.1:4001", "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, hostWithoutPort(tt.host), tt.want)
+		})
+	}
+}
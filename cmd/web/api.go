@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+// writeJSON encodes v as the JSON response body with the given status.
+func (app *application) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if s, ok := v.(apiShaper); ok {
+		v = s.shapeFor(apiVersionOf(r))
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// apiError writes a JSON error envelope such as {"error": "not found"}, or
+// just msg on routes that answer in plain text.
+func (app *application) apiError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	if plainTextErrors(r) {
+		http.Error(w, msg, status)
+
+		return
+	}
+
+	app.writeJSON(w, r, status, map[string]string{"error": msg})
+}
+
+// apiValidationError answers 422 with msg and the field errors of v, e.g.
+// {"error": "invalid snippet", "fields": {"title": "..."}}.
+func (app *application) apiValidationError(w http.ResponseWriter, r *http.Request, msg string, v validator.Validator) {
+	if plainTextErrors(r) {
+		lines := []string{msg}
+		for _, field := range slices.Sorted(maps.Keys(v.FieldErrors)) {
+			lines = append(lines, field+": "+v.FieldErrors[field])
+		}
+
+		http.Error(w, strings.Join(lines, "\n"), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusUnprocessableEntity, map[string]any{
+		"error":  msg,
+		"fields": v.FieldErrors,
+	})
+}
+
+// decodeJSON decodes the request body, of at most maxBytes, into dst,
+// rejecting unknown fields. If the body is unacceptable it answers with an
+// API error and returns false.
+func (app *application) decodeJSON(w http.ResponseWriter, r *http.Request, dst any, maxBytes int64) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			app.apiError(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+		} else {
+			app.apiError(w, r, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// ipRange reduces the client address to its /24 (IPv4) or /48 (IPv6)
+// network, which is stable across the address churn of a single ISP.
+func ipRange(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "unknown"
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%s/24", v4.Mask(net.CIDRMask(24, 32)))
+	}
+
+	return fmt.Sprintf("%s/48", ip.Mask(net.CIDRMask(48, 128)))
+}
+
+func (app *application) apiToken(r *http.Request) (models.Token, bool) {
+	token, ok := r.Context().Value(apiTokenContextKey).(models.Token)
+
+	return token, ok
+}
+
+// apiUser returns the owner of the request's API token, as loaded by
+// authenticateAPIToken.
+func (app *application) apiUser(r *http.Request) (models.User, bool) {
+	user, ok := r.Context().Value(apiUserContextKey).(models.User)
+
+	return user, ok
+}
+
+// apiAuthRealm names the protection space in WWW-Authenticate challenges.
+const apiAuthRealm = "snippetbox"
+
+// apiAuthError is apiError for failed bearer authentication or
+// authorization. Following RFC 6750 it adds a WWW-Authenticate challenge,
+// carrying code (e.g. "invalid_token") unless the request had no token at
+// all.
+func (app *application) apiAuthError(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", apiAuthRealm)
+	if code != "" {
+		challenge += fmt.Sprintf(", error=%q, error_description=%q", code, msg)
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	app.apiError(w, r, status, msg)
+}
+
+// bearerToken returns the token of an Authorization header using the Bearer
+// scheme, whose name is case-insensitive.
+func bearerToken(r *http.Request) string {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// authenticateAPIToken requires a valid personal access token in the
+// Authorization header, enforces its owner's per-token quota and records the
+// token's usage. The token and its owner are added to the request context,
+// for apiToken and apiUser.
+func (app *application) authenticateAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plaintext := bearerToken(r)
+		if plaintext == "" {
+			app.apiAuthError(w, r, http.StatusUnauthorized, "", "missing bearer token")
+
+			return
+		}
+
+		token, err := app.tokens.Authenticate(r.Context(), plaintext)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCredentials) {
+				app.apiAuthError(w, r, http.StatusUnauthorized, "invalid_token", "invalid bearer token")
+			} else {
+				app.handleError(w, r, err)
+			}
+
+			return
+		}
+
+		// A token outliving its owner is as good as revoked.
+		user, err := app.users.Get(token.UserID)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				app.apiAuthError(w, r, http.StatusUnauthorized, "invalid_token", "invalid bearer token")
+			} else {
+				app.handleError(w, r, err)
+			}
+
+			return
+		}
+
+		if !app.applyQuota(w, r, app.tokenQuota(user).Take(strconv.Itoa(token.ID))) {
+			return
+		}
+
+		app.recordTokenUse(r, token)
+		setRequestLogUser(r, token.UserID)
+
+		ctx := context.WithValue(r.Context(), apiTokenContextKey, token)
+		ctx = context.WithValue(ctx, apiUserContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tokenNewIPNotice is the data of the token_new_ip notification email.
+type tokenNewIPNotice struct {
+	Name  string
+	Range string
+}
+
+// recordTokenUse updates the usage statistics of token and alerts its owner,
+// via the audit log shown on their tokens page and by email, when the token
+// is used from a network range it has not been seen from before.
+func (app *application) recordTokenUse(r *http.Request, token models.Token) {
+	network := ipRange(app.clientIP(r))
+
+	newRange, err := app.tokens.RecordUse(r.Context(), token.ID, network)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+
+		return
+	}
+
+	if newRange {
+		details := fmt.Sprintf("token=%d name=%s range=%s", token.ID, token.Name, network)
+		app.recordAudit(r, token.UserID, models.AuditTokenNewIP, details)
+		app.notifyInBackground(token.UserID, models.NotifyTokenNewIP, "",
+			tokenNewIPNotice{Name: token.Name, Range: network})
+	}
+}
+
+func (app *application) apiWhoami(w http.ResponseWriter, r *http.Request) {
+	token, _ := app.apiToken(r)
+	user, _ := app.apiUser(r)
+
+	app.writeJSON(w, r, http.StatusOK, map[string]any{
+		"id":    user.ID,
+		"name":  user.Name,
+		"email": user.Email,
+		"token": token.Name,
+	})
+}
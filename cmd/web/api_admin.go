@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+// maxSettingsBody limits the size of a settings document.
+const maxSettingsBody = 64 << 10
+
+// requireAPIAdmin only lets through API tokens owned by an administrator.
+// It must run after authenticateAPIToken.
+func (app *application) requireAPIAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := app.apiUser(r); !user.IsAdmin {
+			app.apiAuthError(w, r, http.StatusForbidden, "insufficient_scope", "admin privileges required")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *application) apiAdminSettings(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, r, http.StatusOK, app.currentSettings())
+}
+
+// apiAdminSettingsUpdate applies a settings document. Settings missing from
+// the document keep their current value, so tooling may send either the
+// full document or only the keys it manages.
+func (app *application) apiAdminSettingsUpdate(w http.ResponseWriter, r *http.Request) {
+	current := app.currentSettings()
+	settings := current
+
+	if !app.decodeJSON(w, r, &settings, maxSettingsBody) {
+		return
+	}
+
+	var v validator.Validator
+
+	v.CheckField(
+		validator.PermittedValue(settings.RegistrationMode, models.RegistrationOpen, models.RegistrationClosed),
+		"registration_mode",
+		"must be open or closed",
+	)
+	v.CheckField(
+		settings.MaxSnippetLength >= 1 && settings.MaxSnippetLength <= 1000000,
+		"max_snippet_length",
+		"must be between 1 and 1000000",
+	)
+	v.CheckField(validator.MaxChars(settings.MaintenanceMessage, 500), "maintenance_message", "must be at most 500 characters")
+	v.CheckField(validator.MaxChars(settings.Banner, 500), "banner", "must be at most 500 characters")
+
+	if !v.Valid() {
+		app.apiValidationError(w, r, "invalid settings", v)
+
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	if changes := current.Changes(settings); len(changes) > 0 {
+		// A settings change is only made along with its audit event.
+		err := app.tx.WithTx(r.Context(), func(ctx context.Context) error {
+			if err := app.settings.Update(ctx, settings, token.UserID); err != nil {
+				return err //nolint:wrapcheck // already says what failed
+			}
+
+			return app.audit.Insert(ctx, token.UserID, models.AuditSettingsUpdate, app.clientIP(r), strings.Join(changes, "; ")) //nolint:wrapcheck // as above
+		})
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		app.settingsCache.Store(&settings)
+	}
+
+	app.writeJSON(w, r, http.StatusOK, settings)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+func TestAPIAdminSettings(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.apiGet(t, "/api/v1/admin/settings", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusForbidden)
+	assert.StringContains(t, body, "admin privileges required")
+	assert.StringContains(t, header.Get("WWW-Authenticate"), `error="insufficient_scope"`)
+
+	code, _, body = ts.apiGet(t, "/api/v1/admin/settings", mocks.MockAdminTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `"registration_mode":"open"`)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Invalid mode",
+			body:     `{"registration_mode": "invite"}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: `"registration_mode":"must be open or closed"`,
+		},
+		{
+			name:     "Invalid limit",
+			body:     `{"max_snippet_length": 0}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: `"max_snippet_length"`,
+		},
+		{
+			name:     "Unknown setting",
+			body:     `{"colour": "blue"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "unknown field",
+		},
+		{
+			name:     "Partial update",
+			body:     `{"registration_mode": "closed", "banner": "Read-only on Friday"}`,
+			wantCode: http.StatusOK,
+			wantBody: `"max_snippet_length":100000`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.apiDo(t, http.MethodPut, "/api/v1/admin/settings", mocks.MockAdminTokenPlaintext, tt.body)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	// The new settings take effect immediately.
+	code, _, body = ts.get(t, "/user/signup")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Registration is currently closed.")
+	assert.StringContains(t, body, "<div class='banner'>Read-only on Friday\n")
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	code, _, _ := ts.apiDo(t, http.MethodPut, "/api/v1/admin/settings", mocks.MockAdminTokenPlaintext,
+		`{"maintenance_mode": true}`)
+	assert.Equal(t, code, http.StatusOK)
+
+	code, _, _ = ts.get(t, "/")
+	assert.Equal(t, code, http.StatusOK)
+
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, headers, body := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusServiceUnavailable)
+	assert.Equal(t, headers.Get("Retry-After"), "300")
+	assert.StringContains(t, body, "Down for Maintenance")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// apiSnippetFields are the fields of a snippet in the API, in the order
+// they are encoded.
+var apiSnippetFields = []string{"id", "title", "content", "created", "updated", "expires"}
+
+// fieldSet is the fields of each item a list request asks for, in the order
+// they are encoded. A nil fieldSet keeps every field.
+type fieldSet []string
+
+// parseFieldSet reads ?fields, a comma-separated list of the known fields
+// to return, and ?include_content, which drops the content when false, so
+// clients rendering a list need not download every snippet in full. If
+// either is invalid it answers 400 and returns false.
+func (app *application) parseFieldSet(w http.ResponseWriter, r *http.Request, known []string) (fieldSet, bool) {
+	query := r.URL.Query()
+	fields := known
+
+	if v := query.Get("fields"); v != "" {
+		fields = nil
+
+		for name := range strings.SplitSeq(v, ",") {
+			name = strings.TrimSpace(name)
+
+			if !slices.Contains(known, name) {
+				app.apiError(w, r, http.StatusBadRequest,
+					fmt.Sprintf("fields must be a comma-separated list of %s", strings.Join(known, ", ")))
+
+				return nil, false
+			}
+
+			if !slices.Contains(fields, name) {
+				fields = append(fields, name)
+			}
+		}
+	}
+
+	if v := query.Get("include_content"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			app.apiError(w, r, http.StatusBadRequest, "include_content must be true or false")
+
+			return nil, false
+		}
+
+		if !include {
+			fields = slices.DeleteFunc(slices.Clone(fields), func(name string) bool { return name == "content" })
+		}
+	}
+
+	if slices.Equal(fields, known) {
+		return nil, true
+	}
+
+	// Encode the fields in their usual order whatever order they were
+	// asked for in.
+	slices.SortFunc(fields, func(a, b string) int {
+		return slices.Index(known, a) - slices.Index(known, b)
+	})
+
+	return fields, true
+}
+
+// sparseObject encodes the fields of v's JSON object that fs names.
+type sparseObject struct {
+	fs fieldSet
+	v  any
+}
+
+func (o sparseObject) MarshalJSON() ([]byte, error) {
+	body, err := json.Marshal(o.v)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // encoding/json wraps errors from MarshalJSON in a MarshalerError
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, err //nolint:wrapcheck // see above
+	}
+
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+
+	for i, name := range o.fs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(name) //nolint:errchkjson // a string always encodes
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(all[name])
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// sparseList returns items with only the fields in fs, or items unchanged
+// if fs keeps them all.
+func sparseList[T any](fs fieldSet, items []T) any {
+	if fs == nil {
+		return items
+	}
+
+	sparse := make([]sparseObject, len(items))
+	for i, item := range items {
+		sparse[i] = sparseObject{fs: fs, v: item}
+	}
+
+	return sparse
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// apiSearchResult is a snippet found by a search, with its relevance score.
+type apiSearchResult struct {
+	apiSnippet
+	Score float64 `json:"score"`
+	// Headline is an excerpt of the content around the matches for result
+	// previews, as HTML with each matching word in a <mark> element.
+	Headline string `json:"headline"`
+}
+
+// apiSearchResultFields are the fields of a search result.
+var apiSearchResultFields = append(slices.Clone(apiSnippetFields), "score", "headline")
+
+var headlineMarks = strings.NewReplacer(models.HeadlineStart, "<mark>", models.HeadlineStop, "</mark>")
+
+// headlineHTML escapes a headline and marks its matching words.
+func headlineHTML(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+// apiSearch runs a full-text search of the live snippets for ?q, which takes
+// quoted phrases, OR and -word. ?author limits it to one user's snippets,
+// ?sort orders the results by relevance (the default), newest or oldest,
+// and ?page, ?limit, ?fields and ?include_content work as on the snippet
+// list.
+func (app *application) apiSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	search := models.SnippetSearch{
+		Query: strings.TrimSpace(query.Get("q")),
+		Order: models.SearchRelevance,
+	}
+
+	if search.Query == "" {
+		app.apiError(w, r, http.StatusBadRequest, "q is required")
+
+		return
+	}
+
+	// Snippets have neither a language nor tags. Ignoring these filters
+	// would answer with results the client did not ask for.
+	for _, name := range []string{"language", "tag"} {
+		if query.Has(name) {
+			app.apiError(w, r, http.StatusBadRequest, name+" is not supported")
+
+			return
+		}
+	}
+
+	if v := query.Get("author"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			app.apiError(w, r, http.StatusBadRequest, "author must be a user ID")
+
+			return
+		}
+
+		search.AuthorID = id
+	}
+
+	if v := query.Get("sort"); v != "" {
+		if !models.ValidSearchOrder(v) {
+			app.apiError(w, r, http.StatusBadRequest, fmt.Sprintf("sort must be one of %s, %s or %s",
+				models.SearchRelevance, models.SearchNewest, models.SearchOldest))
+
+			return
+		}
+
+		search.Order = v
+	}
+
+	p, ok := app.parsePagination(w, r, defaultAPISnippets, maxAPISnippets)
+	if !ok {
+		return
+	}
+
+	search.Limit, search.Offset = p.limit, p.offset()
+
+	fields, ok := app.parseFieldSet(w, r, apiSearchResultFields)
+	if !ok {
+		return
+	}
+
+	matches, total, err := app.snippets.Search(r.Context(), search)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	results := make([]apiSearchResult, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, apiSearchResult{
+			apiSnippet: newAPISnippet(m.Snippet),
+			Score:      m.Rank,
+			Headline:   headlineHTML(m.Headline),
+		})
+	}
+
+	app.setPageLinks(w, r, p, total)
+	app.writeJSON(w, r, http.StatusOK, map[string]any{"results": sparseList(fields, results), "total": total})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+func TestAPISearch(t *testing.T) {
+	app := newTestApplication(t)
+	app.tokenLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"Match", "/api/v1/search?q=pond", http.StatusOK, `"title":"An old silent pond"`},
+		{"Score", "/api/v1/search?q=POND&sort=newest", http.StatusOK, `"score":0.5,"headline":"\u003cmark\u003eAn old silent pond\u003c/mark\u003e..."}],"total":1}`},
+		{"No match", "/api/v1/search?q=frog", http.StatusOK, `{"results":[],"total":0}`},
+		{"Author", "/api/v1/search?q=pond&author=1", http.StatusOK, `"total":1`},
+		{"Other author", "/api/v1/search?q=pond&author=2", http.StatusOK, `"total":0`},
+		{"Past the end", "/api/v1/search?q=pond&page=2", http.StatusOK, `{"results":[],"total":1}`},
+		{"Missing query", "/api/v1/search?q=+", http.StatusBadRequest, `"error":"q is required"`},
+		{"Invalid author", "/api/v1/search?q=pond&author=alice", http.StatusBadRequest, `"error":"author must be a user ID"`},
+		{"Invalid sort", "/api/v1/search?q=pond&sort=best", http.StatusBadRequest, `"error":"sort must be one of relevance, newest or oldest"`},
+		{"Language", "/api/v1/search?q=pond&language=go", http.StatusBadRequest, `"error":"language is not supported"`},
+		{"Tag", "/api/v1/search?q=pond&tag=haiku", http.StatusBadRequest, `"error":"tag is not supported"`},
+		{"Invalid limit", "/api/v1/search?q=pond&limit=0", http.StatusBadRequest, `"error":"limit must be between 1 and 100"`},
+		{"Fields", "/api/v1/search?q=pond&fields=score,id", http.StatusOK, `{"results":[{"id":1,"score":0.5}],"total":1}`},
+		{"Invalid fields", "/api/v1/search?q=pond&fields=rank", http.StatusBadRequest, `"error":"fields must be a comma-separated list of id, title, content, created, updated, expires, score, headline"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.apiGet(t, tt.urlPath, mocks.MockTokenPlaintext)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	_, header, _ := ts.apiGet(t, "/api/v1/search?q=pond&sort=oldest", mocks.MockTokenPlaintext)
+	assert.StringContains(t, header.Get("Link"), `</api/v1/search?limit=10&page=1&q=pond&sort=oldest>; rel="first"`)
+}
+
+func TestHeadlineHTML(t *testing.T) {
+	headline := "<b>" + models.HeadlineStart + "pond" + models.HeadlineStop + "</b> & " + models.HeadlineStart + "frog" + models.HeadlineStop
+
+	assert.Equal(t, headlineHTML(headline), "&lt;b&gt;<mark>pond</mark>&lt;/b&gt; &amp; <mark>frog</mark>")
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+const (
+	// defaultAPISnippets and maxAPISnippets are the default and largest
+	// ?limit of the snippet list.
+	defaultAPISnippets = 10
+	maxAPISnippets     = 100
+
+	// maxAPISnippetBatch is the most snippets a batch request may create,
+	// and maxAPIBatchBytes the largest body it may have.
+	maxAPISnippetBatch = 100
+	maxAPIBatchBytes   = 32 << 20
+)
+
+// apiSnippet is the JSON representation of a snippet.
+type apiSnippet struct {
+	ID      int       `json:"id"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Expires time.Time `json:"expires"`
+}
+
+func newAPISnippet(s models.Snippet) apiSnippet {
+	return apiSnippet{
+		ID:      s.ID,
+		Title:   s.Title,
+		Content: s.Content,
+		Created: s.Created,
+		Updated: s.Updated,
+		Expires: s.Expires,
+	}
+}
+
+// newAPISnippets converts a list of snippets, which is never null in JSON.
+func newAPISnippets(snippets []models.Snippet) []apiSnippet {
+	resp := make([]apiSnippet, 0, len(snippets))
+	for _, s := range snippets {
+		resp = append(resp, newAPISnippet(s))
+	}
+
+	return resp
+}
+
+// apiSnippetInput is the body of a create or update request. Expires is the
+// number of days the snippet stays up, as on the create form.
+type apiSnippetInput struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Expires int    `json:"expires"`
+}
+
+// apiSnippetBatchInput is the body of a batch create request.
+type apiSnippetBatchInput struct {
+	Snippets []apiSnippetInput `json:"snippets"`
+}
+
+// apiBatchResult is the outcome for one snippet of a batch, at the same
+// index as in the request: the new snippet's ID, or why it is invalid.
+type apiBatchResult struct {
+	ID     int               `json:"id,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// requireAPISnippetWriter stops writes to snippets by suspended users and
+// while maintenance mode is on, as the web UI does. It must run after
+// authenticateAPIToken.
+func (app *application) requireAPISnippetWriter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.currentSettings().MaintenanceMode {
+			w.Header().Set("Retry-After", "300")
+			app.apiError(w, r, http.StatusServiceUnavailable, "read-only during maintenance")
+
+			return
+		}
+
+		if user, _ := app.apiUser(r); user.Suspended() {
+			app.apiError(w, r, http.StatusForbidden, "account suspended")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiSnippetList lists the live snippets, newest first, a page at a time.
+// The Link header points at the neighbouring pages, and ?fields and
+// ?include_content select what each snippet includes.
+func (app *application) apiSnippetList(w http.ResponseWriter, r *http.Request) {
+	p, ok := app.parsePagination(w, r, defaultAPISnippets, maxAPISnippets)
+	if !ok {
+		return
+	}
+
+	fields, ok := app.parseFieldSet(w, r, apiSnippetFields)
+	if !ok {
+		return
+	}
+
+	total, err := app.snippets.Count(r.Context())
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	snippets, err := app.snippets.LatestPage(r.Context(), p.limit, p.offset())
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.setPageLinks(w, r, p, total)
+	app.writeJSON(w, r, http.StatusOK, map[string]any{"snippets": sparseList(fields, newAPISnippets(snippets))})
+}
+
+// apiSnippetGet serves a snippet with its ETag, answering 304 Not Modified
+// to clients whose cached copy is current.
+func (app *application) apiSnippetGet(w http.ResponseWriter, r *http.Request) {
+	snippet, ok := app.apiSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeSnippetJSON(w, r, snippet)
+}
+
+// apiSnippetCreate creates a snippet owned by the token's user. The
+// response points at the new snippet with its Location header.
+func (app *application) apiSnippetCreate(w http.ResponseWriter, r *http.Request) {
+	input, ok := app.decodeAPISnippet(w, r)
+	if !ok {
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	id, err := app.snippets.Insert(r.Context(), token.UserID, input.Title, input.Content, input.Expires)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.snippetCreated(token.UserID, id, input.Title)
+
+	// mountBasePath adds the base path to root-relative locations.
+	w.Header().Set("Location", fmt.Sprintf("%s/snippets/%d", apiVersionOf(r).prefix(), id))
+	app.writeJSON(w, r, http.StatusCreated, map[string]int{"id": id})
+}
+
+// apiSnippetBatchCreate creates up to maxAPISnippetBatch snippets owned by
+// the token's user in one request. The batch is all or nothing: if any
+// snippet is invalid it answers 422 with the field errors of each, and
+// otherwise it inserts them all and answers 201 with their IDs.
+func (app *application) apiSnippetBatchCreate(w http.ResponseWriter, r *http.Request) {
+	var input apiSnippetBatchInput
+
+	if !app.decodeJSON(w, r, &input, maxAPIBatchBytes) {
+		return
+	}
+
+	if n := len(input.Snippets); n < 1 || n > maxAPISnippetBatch {
+		app.apiError(w, r, http.StatusBadRequest,
+			fmt.Sprintf("snippets must hold between 1 and %d snippets", maxAPISnippetBatch))
+
+		return
+	}
+
+	results := make([]apiBatchResult, len(input.Snippets))
+	snippets := make([]models.NewSnippet, len(input.Snippets))
+	valid := true
+
+	for i, s := range input.Snippets {
+		var v validator.Validator
+
+		app.checkSnippet(&v, s.Title, s.Content, s.Expires)
+
+		if !v.Valid() {
+			results[i].Fields = v.FieldErrors
+			valid = false
+		}
+
+		snippets[i] = models.NewSnippet{Title: s.Title, Content: s.Content, Expires: s.Expires}
+	}
+
+	if !valid {
+		app.writeJSON(w, r, http.StatusUnprocessableEntity, map[string]any{
+			"error":   "invalid snippets",
+			"results": results,
+		})
+
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	ids, err := app.snippets.InsertBatch(r.Context(), token.UserID, snippets)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	for i, id := range ids {
+		results[i].ID = id
+		app.snippetCreated(token.UserID, id, snippets[i].Title)
+	}
+
+	app.writeJSON(w, r, http.StatusCreated, map[string]any{"results": results})
+}
+
+// apiSnippetUpdate replaces the title, content and expiry of a snippet owned
+// by the token's user, provided it still matches the request's If-Match
+// header, and returns the updated snippet with its new ETag.
+func (app *application) apiSnippetUpdate(w http.ResponseWriter, r *http.Request) {
+	// The snippet is compared with If-Match and, after an update, returned,
+	// so it must not come from a replica that has not seen the last write.
+	r = r.WithContext(models.Primary(r.Context()))
+
+	snippet, ok := app.apiOwnedSnippet(w, r)
+	if !ok || !app.apiIfMatch(w, r, snippet) {
+		return
+	}
+
+	input, ok := app.decodeAPISnippet(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.snippets.Update(r.Context(), snippet.ID, ifMatchVersion(r, snippet), input.Title, input.Content, input.Expires)
+	if err != nil {
+		app.apiSnippetError(w, r, err)
+
+		return
+	}
+
+	updated, ok := app.apiSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", snippetJSONETag(updated))
+	app.writeJSON(w, r, http.StatusOK, newAPISnippet(updated))
+}
+
+// apiSnippetDelete deletes a snippet owned by the token's user, provided it
+// still matches the request's If-Match header.
+func (app *application) apiSnippetDelete(w http.ResponseWriter, r *http.Request) {
+	// As in apiSnippetUpdate, If-Match is checked against the primary.
+	r = r.WithContext(models.Primary(r.Context()))
+
+	snippet, ok := app.apiOwnedSnippet(w, r)
+	if !ok || !app.apiIfMatch(w, r, snippet) {
+		return
+	}
+
+	if err := app.snippets.Delete(r.Context(), snippet.ID, ifMatchVersion(r, snippet)); err != nil {
+		app.apiSnippetError(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apiIfMatch answers 412 Precondition Failed and returns false if the
+// request's If-Match header does not match the current snippet.
+func (app *application) apiIfMatch(w http.ResponseWriter, r *http.Request, snippet models.Snippet) bool {
+	if ifMatch(r, snippetJSONETag(snippet)) {
+		return true
+	}
+
+	w.Header().Set("ETag", snippetJSONETag(snippet))
+	app.apiError(w, r, http.StatusPreconditionFailed, "snippet has changed")
+
+	return false
+}
+
+// ifMatchVersion returns the version of snippet a change must still apply to
+// for the request's If-Match header to hold when the change is made, not
+// just when the snippet was read, or the zero time if any version will do.
+func ifMatchVersion(r *http.Request, snippet models.Snippet) time.Time {
+	if header := strings.TrimSpace(r.Header.Get("If-Match")); header == "" || header == "*" {
+		return time.Time{}
+	}
+
+	return snippet.Updated
+}
+
+// decodeAPISnippet decodes and validates the body of a create or update
+// request, answering with an API error if it is unacceptable.
+func (app *application) decodeAPISnippet(w http.ResponseWriter, r *http.Request) (apiSnippetInput, bool) {
+	var input apiSnippetInput
+
+	if !app.decodeJSON(w, r, &input, maxFormBytes) {
+		return input, false
+	}
+
+	var v validator.Validator
+
+	app.checkSnippet(&v, input.Title, input.Content, input.Expires)
+
+	if !v.Valid() {
+		app.apiValidationError(w, r, "invalid snippet", v)
+
+		return input, false
+	}
+
+	return input, true
+}
+
+// apiSnippet looks up the snippet named by the {id} path parameter.
+func (app *application) apiSnippet(w http.ResponseWriter, r *http.Request) (models.Snippet, bool) {
+	snippet, err := app.snippets.Get(r.Context(), pathInt(r, "id"))
+	if err != nil {
+		app.apiSnippetError(w, r, err)
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// apiOwnedSnippet is apiSnippet for changes, which only the snippet's owner
+// may make. Anonymous snippets cannot be changed at all.
+func (app *application) apiOwnedSnippet(w http.ResponseWriter, r *http.Request) (models.Snippet, bool) {
+	snippet, ok := app.apiSnippet(w, r)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	token, _ := app.apiToken(r)
+
+	if snippet.UserID == 0 || snippet.UserID != token.UserID {
+		app.apiError(w, r, http.StatusForbidden, "not the owner of this snippet")
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+func (app *application) apiSnippetError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, models.ErrNoRecord):
+		app.apiError(w, r, http.StatusNotFound, "snippet not found")
+
+		return
+	case errors.Is(err, models.ErrEditConflict):
+		app.apiError(w, r, http.StatusPreconditionFailed, "snippet has changed")
+
+		return
+	}
+
+	app.handleError(w, r, err)
+}
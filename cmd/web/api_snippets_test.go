@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+func TestAPISnippets(t *testing.T) {
+	app := newTestApplication(t)
+	// Every case is a request from the same two tokens.
+	app.tokenLimiter = ratelimit.New(1, 20)
+	app.adminLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	owner, other := mocks.MockTokenPlaintext, mocks.MockAdminTokenPlaintext
+	valid := `{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`
+
+	tests := []struct {
+		name     string
+		method   string
+		urlPath  string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"List", http.MethodGet, "/api/v1/snippets", owner, "", http.StatusOK, `{"snippets":[{"id":1,"title":"An old silent pond"`},
+		{"List limit", http.MethodGet, "/api/v1/snippets?limit=500", owner, "", http.StatusBadRequest, `"error":"limit must be between 1 and 100"`},
+		{"List page", http.MethodGet, "/api/v1/snippets?page=0", owner, "", http.StatusBadRequest, `"error":"page must be a positive integer"`},
+		{"List page overflow", http.MethodGet, "/api/v1/snippets?page=9223372036854775807&limit=10", owner, "", http.StatusBadRequest, `"error":"page is out of range"`},
+		{"List fields", http.MethodGet, "/api/v1/snippets?fields=title,id,title", owner, "", http.StatusOK, `{"snippets":[{"id":1,"title":"An old silent pond"}]}`},
+		{"List without content", http.MethodGet, "/api/v1/snippets?include_content=false", owner, "", http.StatusOK, `{"snippets":[{"id":1,"title":"An old silent pond","created":`},
+		{"List fields without content", http.MethodGet, "/api/v1/snippets?fields=id,content&include_content=0", owner, "", http.StatusOK, `{"snippets":[{"id":1}]}`},
+		{"List unknown field", http.MethodGet, "/api/v1/snippets?fields=id,tags", owner, "", http.StatusBadRequest, `"error":"fields must be a comma-separated list of id, title, content, created, updated, expires"`},
+		{"List include content", http.MethodGet, "/api/v1/snippets?include_content=maybe", owner, "", http.StatusBadRequest, `"error":"include_content must be true or false"`},
+		{"List past the end", http.MethodGet, "/api/v1/snippets?page=2", owner, "", http.StatusOK, `{"snippets":[]}`},
+		{"List without token", http.MethodGet, "/api/v1/snippets", "", "", http.StatusUnauthorized, `"error":"missing bearer token"`},
+		{"Get", http.MethodGet, "/api/v1/snippets/1", other, "", http.StatusOK, `"content":"An old silent pond..."`},
+		{"Get missing", http.MethodGet, "/api/v1/snippets/2", owner, "", http.StatusNotFound, `{"error":"snippet not found"}`},
+		{"Create", http.MethodPost, "/api/v1/snippets", owner, valid, http.StatusCreated, `{"id":2}`},
+		{"Create invalid", http.MethodPost, "/api/v1/snippets", owner, `{"title": "", "content": "x", "expires": 2}`, http.StatusUnprocessableEntity, `"expires":"This field must be equal 1, 7, or 365."`},
+		{"Create unknown field", http.MethodPost, "/api/v1/snippets", owner, `{"tags": []}`, http.StatusBadRequest, "unknown field"},
+		{"Update", http.MethodPut, "/api/v1/snippets/1", owner, valid, http.StatusOK, `"id":1`},
+		{"Update not owner", http.MethodPut, "/api/v1/snippets/1", other, valid, http.StatusForbidden, `"error":"not the owner of this snippet"`},
+		{"Update missing", http.MethodPut, "/api/v1/snippets/2", owner, valid, http.StatusNotFound, `"error":"snippet not found"`},
+		{"Delete not owner", http.MethodDelete, "/api/v1/snippets/1", other, "", http.StatusForbidden, `"error":"not the owner of this snippet"`},
+		{"Delete", http.MethodDelete, "/api/v1/snippets/1", owner, "", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.apiDo(t, tt.method, tt.urlPath, tt.token, tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if code == http.StatusCreated {
+				assert.Equal(t, header.Get("Location"), "/api/v1/snippets/2")
+			}
+		})
+	}
+}
+
+func TestAPISnippetsMaintenance(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, _ := ts.apiDo(t, http.MethodPut, "/api/v1/admin/settings", mocks.MockAdminTokenPlaintext,
+		`{"maintenance_mode": true}`)
+	assert.Equal(t, code, http.StatusOK)
+
+	code, _, _ = ts.apiGet(t, "/api/v1/snippets/1", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+
+	code, header, body := ts.apiDo(t, http.MethodDelete, "/api/v1/snippets/1", mocks.MockTokenPlaintext, "")
+	assert.Equal(t, code, http.StatusServiceUnavailable)
+	assert.Equal(t, header.Get("Retry-After"), "300")
+	assert.StringContains(t, body, `"error":"read-only during maintenance"`)
+}
+
+func TestAPISnippetConditional(t *testing.T) {
+	app := newTestApplication(t)
+	app.tokenLimiter = ratelimit.New(1, 20)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := mocks.MockTokenPlaintext
+	valid := `{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`
+
+	code, header, _ := ts.apiGet(t, "/api/v1/snippets/1", token)
+	assert.Equal(t, code, http.StatusOK)
+
+	etag := header.Get("ETag")
+	assert.StringContains(t, etag, `"`)
+
+	code, _, body := ts.apiDoWithHeaders(t, http.MethodGet, "/api/v1/snippets/1", token, "",
+		http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, code, http.StatusNotModified)
+	assert.Equal(t, body, "")
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		ifMatch  string
+		wantCode int
+	}{
+		{"Update stale", http.MethodPut, valid, `"stale"`, http.StatusPreconditionFailed},
+		{"Delete stale", http.MethodDelete, "", `"stale"`, http.StatusPreconditionFailed},
+		{"Delete weak", http.MethodDelete, "", "W/" + etag, http.StatusPreconditionFailed},
+		{"Update current", http.MethodPut, valid, etag, http.StatusOK},
+		{"Update any", http.MethodPut, valid, "*", http.StatusOK},
+		{"Delete current", http.MethodDelete, "", etag, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.apiDoWithHeaders(t, tt.method, "/api/v1/snippets/1", token, tt.body,
+				http.Header{"If-Match": {tt.ifMatch}})
+			assert.Equal(t, code, tt.wantCode)
+
+			switch code {
+			case http.StatusPreconditionFailed:
+				assert.StringContains(t, body, `"error":"snippet has changed"`)
+				assert.Equal(t, header.Get("ETag"), etag)
+			case http.StatusOK:
+				assert.Equal(t, header.Get("ETag"), etag)
+			}
+		})
+	}
+}
+
+// largeSnippetModel serves the mock snippet with content long enough to be
+// compressed.
+type largeSnippetModel struct {
+	models.SnippetModelInterface
+}
+
+func (m largeSnippetModel) Get(ctx context.Context, id int) (models.Snippet, error) {
+	s, err := m.SnippetModelInterface.Get(ctx, id)
+	s.Content = strings.Repeat(s.Content+"\n", 2*minCompressSize/len(s.Content))
+
+	return s, err
+}
+
+func TestAPISnippetConditionalCompressed(t *testing.T) {
+	app := newTestApplication(t)
+	app.snippets = largeSnippetModel{app.snippets}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := mocks.MockTokenPlaintext
+	gzipOK := http.Header{"Accept-Encoding": {"gzip"}}
+
+	code, header, _ := ts.apiDoWithHeaders(t, http.MethodGet, "/api/v1/snippets/1", token, "", gzipOK)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Encoding"), "gzip")
+
+	etag := header.Get("ETag")
+	assert.Equal(t, strings.HasPrefix(etag, `"`), true)
+
+	code, _, _ = ts.apiDoWithHeaders(t, http.MethodGet, "/api/v1/snippets/1", token, "",
+		http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {etag}})
+	assert.Equal(t, code, http.StatusNotModified)
+
+	code, _, _ = ts.apiDoWithHeaders(t, http.MethodPut, "/api/v1/snippets/1", token,
+		`{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`,
+		http.Header{"Accept-Encoding": {"gzip"}, "If-Match": {etag}})
+	assert.Equal(t, code, http.StatusOK)
+
+	code, _, _ = ts.apiDoWithHeaders(t, http.MethodDelete, "/api/v1/snippets/1", token, "",
+		http.Header{"Accept-Encoding": {"gzip"}, "If-Match": {etag}})
+	assert.Equal(t, code, http.StatusNoContent)
+}
+
+func TestAPISnippetBatchCreate(t *testing.T) {
+	app := newTestApplication(t)
+	app.tokenLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	valid := `{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`
+	tooMany := `{"snippets": [` + strings.TrimSuffix(strings.Repeat(valid+",", maxAPISnippetBatch+1), ",") + `]}`
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"Valid", `{"snippets": [` + valid + `,` + valid + `]}`, http.StatusCreated, `{"results":[{"id":2},{"id":3}]}`},
+		{"Invalid item", `{"snippets": [` + valid + `, {"title": "", "content": "x", "expires": 7}]}`,
+			http.StatusUnprocessableEntity, `"results":[{},{"fields":{"title":"This field cannot be blank."}}]`},
+		{"Empty", `{"snippets": []}`, http.StatusBadRequest, `"error":"snippets must hold between 1 and 100 snippets"`},
+		{"Too many", tooMany, http.StatusBadRequest, `"error":"snippets must hold between 1 and 100 snippets"`},
+		{"Unknown field", `{"snippets": [{"tags": []}]}`, http.StatusBadRequest, "unknown field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.apiDo(t, http.MethodPost, "/api/v1/snippets:batch", mocks.MockTokenPlaintext, tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
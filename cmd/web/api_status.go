@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// apiStatusReport is the JSON body of the API's status endpoint.
+type apiStatusReport struct {
+	buildInfo
+
+	Uptime        string            `json:"uptime"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Database      apiStatusDatabase `json:"database"`
+}
+
+// apiStatusDatabase is how the database answered a ping. LatencyMS is the
+// round trip in milliseconds, omitted when the database is unreachable.
+type apiStatusDatabase struct {
+	Status    string   `json:"status"`
+	LatencyMS *float64 `json:"latency_ms,omitempty"`
+}
+
+// apiStatus tells dashboards and deploy tools which build is running, for
+// how long, and how quickly it reaches its database. It needs no token and
+// answers 200 even when the database is down; /healthz is the endpoint
+// for deciding whether the instance should get traffic.
+func (app *application) apiStatus(w http.ResponseWriter, r *http.Request) {
+	uptime := time.Since(app.started).Truncate(time.Second)
+
+	report := apiStatusReport{
+		buildInfo:     readBuildInfo(),
+		Uptime:        uptime.String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Database:      apiStatusDatabase{Status: "ok"},
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthzTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	if err := app.pingDB(ctx); err != nil {
+		app.logger.WarnContext(r.Context(), "status ping failed", slog.String("err", err.Error()))
+
+		report.Database.Status = "unreachable"
+	} else {
+		latency := float64(time.Since(start).Microseconds()) / 1000
+		report.Database.LatencyMS = &latency
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	app.writeJSON(w, r, http.StatusOK, report)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestAPIStatus(t *testing.T) {
+	setBuildInfo(t, "v1.4.0", "0123456789abcdef", "2026-10-17T09:00:00Z")
+
+	tests := []struct {
+		name         string
+		pingErr      error
+		wantDatabase string
+		wantLatency  bool
+	}{
+		{"Healthy", nil, "ok", true},
+		{"Database down", errors.New("connection refused"), "unreachable", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.started = time.Now().Add(-90 * time.Minute)
+			app.pingDB = func(context.Context) error { return tt.pingErr }
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			// The status needs no token.
+			code, header, body := ts.get(t, "/api/v1/status")
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.Equal(t, header.Get("Cache-Control"), "no-store")
+
+			var report apiStatusReport
+			if err := json.Unmarshal([]byte(body), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, report.Version, "v1.4.0")
+			assert.Equal(t, report.Commit, "0123456789abcdef")
+			assert.Equal(t, report.BuildDate, "2026-10-17T09:00:00Z")
+			assert.Equal(t, report.Uptime, "1h30m0s")
+			assert.Equal(t, report.UptimeSeconds, int64(5400))
+			assert.Equal(t, report.Database.Status, tt.wantDatabase)
+			assert.Equal(t, report.Database.LatencyMS != nil, tt.wantLatency)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+// apiGet makes a GET request with the given bearer token (if any).
+func (ts *testServer) apiGet(t *testing.T, urlPath, token string) (int, http.Header, string) {
+	t.Helper()
+
+	return ts.apiDo(t, http.MethodGet, urlPath, token, "")
+}
+
+// apiDo makes an API request with the given bearer token (if any) and JSON
+// body (if any).
+func (ts *testServer) apiDo(t *testing.T, method, urlPath, token, body string) (int, http.Header, string) {
+	t.Helper()
+
+	return ts.apiDoWithHeaders(t, method, urlPath, token, body, nil)
+}
+
+// apiDoWithHeaders is like apiDo but sends the given request headers as well.
+func (ts *testServer) apiDoWithHeaders(
+	t *testing.T,
+	method, urlPath, token, body string,
+	header http.Header,
+) (int, http.Header, string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, ts.URL+urlPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+
+	respBody, err := io.ReadAll(rs.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rs.StatusCode, rs.Header, string(respBody)
+}
+
+func TestIPRange(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "203.0.113.57:4242", want: "203.0.113.0/24"},
+		{addr: "203.0.113.57", want: "203.0.113.0/24"},
+		{addr: "[2001:db8:1234:5678::1]:443", want: "2001:db8:1234::/48"},
+		{addr: "garbage", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, ipRange(tt.addr), tt.want)
+		})
+	}
+}
+
+func TestAPIWhoami(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantBody      string
+		wantChallenge string
+	}{
+		{
+			name:          "Valid token",
+			authorization: "Bearer " + mocks.MockTokenPlaintext,
+			wantCode:      http.StatusOK,
+			wantBody:      `"email":"alice@example.com"`,
+		},
+		{
+			name:          "Scheme is case-insensitive",
+			authorization: "bearer " + mocks.MockTokenPlaintext,
+			wantCode:      http.StatusOK,
+			wantBody:      `"token":`,
+		},
+		{
+			name:          "Missing token",
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `"error":"missing bearer token"`,
+			wantChallenge: `Bearer realm="snippetbox"`,
+		},
+		{
+			name:          "Other scheme",
+			authorization: "Basic YWxpY2U6cGFzcw==",
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `"error":"missing bearer token"`,
+			wantChallenge: `Bearer realm="snippetbox"`,
+		},
+		{
+			name:          "Invalid token",
+			authorization: "Bearer sbx_nope",
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `"error":"invalid bearer token"`,
+			wantChallenge: `Bearer realm="snippetbox", error="invalid_token", error_description="invalid bearer token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1/whoami", nil)
+			assert.NilError(t, err)
+
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rs, err := ts.Client().Do(req)
+			assert.NilError(t, err)
+			defer rs.Body.Close()
+
+			body, err := io.ReadAll(rs.Body)
+			assert.NilError(t, err)
+
+			assert.Equal(t, rs.StatusCode, tt.wantCode)
+			assert.Equal(t, rs.Header.Get("Content-Type"), "application/json")
+			assert.Equal(t, rs.Header.Get("WWW-Authenticate"), tt.wantChallenge)
+			assert.StringContains(t, string(body), tt.wantBody)
+		})
+	}
+}
+
+func TestAPITokenRateLimit(t *testing.T) {
+	app := newTestApplication(t)
+	app.adminLimiter = ratelimit.New(1, 10)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// The test application allows a burst of five requests per token.
+	for i := range 5 {
+		code, headers, _ := ts.apiGet(t, "/api/v1/whoami", mocks.MockTokenPlaintext)
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, headers.Get("X-RateLimit-Limit"), "5")
+		assert.Equal(t, headers.Get("X-RateLimit-Remaining"), strconv.Itoa(4-i))
+	}
+
+	code, headers, _ := ts.apiGet(t, "/api/v1/whoami", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, headers.Get("Retry-After"), "1")
+	assert.Equal(t, headers.Get("X-RateLimit-Remaining"), "0")
+	assert.Equal(t, headers.Get("X-RateLimit-Reset"), "5")
+
+	// Tokens of admins have their own quota.
+	code, headers, _ = ts.apiGet(t, "/api/v1/whoami", mocks.MockAdminTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, headers.Get("X-RateLimit-Limit"), "10")
+	assert.Equal(t, headers.Get("X-RateLimit-Remaining"), "9")
+}
+
+func TestAPIIPRateLimit(t *testing.T) {
+	app := newTestApplication(t)
+	app.apiIPLimiter = ratelimit.New(0.01, 3)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// The IP quota is the tighter one here, so the headers report it.
+	code, headers, _ := ts.apiGet(t, "/api/v1/whoami", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, headers.Get("X-RateLimit-Limit"), "3")
+	assert.Equal(t, headers.Get("X-RateLimit-Remaining"), "2")
+
+	// Requests with invalid tokens count against it too.
+	for range 2 {
+		code, _, _ = ts.apiGet(t, "/api/v1/whoami", "sbx_nope")
+		assert.Equal(t, code, http.StatusUnauthorized)
+	}
+
+	code, headers, body := ts.apiGet(t, "/api/v1/whoami", mocks.MockAdminTokenPlaintext)
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, headers.Get("Retry-After"), "100")
+	assert.Equal(t, headers.Get("X-RateLimit-Reset"), "300")
+	assert.StringContains(t, body, "rate limit exceeded")
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+const (
+	// maxWebhooks is how many webhooks a user may register.
+	maxWebhooks = 10
+
+	// maxWebhookBody limits the size of a webhook create or update request.
+	maxWebhookBody = 16 << 10
+)
+
+// apiWebhook is the JSON representation of a webhook. The secret is only
+// included when it is new, i.e. on creation and rotation.
+type apiWebhook struct {
+	ID      int       `json:"id"`
+	URL     string    `json:"url"`
+	Events  []string  `json:"events"`
+	Secret  string    `json:"secret,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+func newAPIWebhook(w models.Webhook, withSecret bool) apiWebhook {
+	hook := apiWebhook{
+		ID:      w.ID,
+		URL:     w.URL,
+		Events:  w.Events,
+		Created: w.Created,
+		Updated: w.Updated,
+	}
+
+	if withSecret {
+		hook.Secret = w.Secret
+	}
+
+	return hook
+}
+
+// apiWebhookInput is the body of a webhook create or update request.
+type apiWebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// apiWebhookTest is the outcome of a test delivery. Status is the HTTP
+// status the webhook answered with, if it answered at all.
+type apiWebhookTest struct {
+	Delivered bool   `json:"delivered"`
+	Status    int    `json:"status,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (app *application) apiWebhookList(w http.ResponseWriter, r *http.Request) {
+	token, _ := app.apiToken(r)
+
+	hooks, err := app.webhooks.List(r.Context(), token.UserID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	resp := make([]apiWebhook, 0, len(hooks))
+	for _, hook := range hooks {
+		resp = append(resp, newAPIWebhook(hook, false))
+	}
+
+	app.writeJSON(w, r, http.StatusOK, map[string]any{"webhooks": resp})
+}
+
+func (app *application) apiWebhookGet(w http.ResponseWriter, r *http.Request) {
+	hook, ok := app.apiWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIWebhook(hook, false))
+}
+
+// apiWebhookCreate registers a webhook for the token's user. The response is
+// the only one besides rotation that includes its secret.
+func (app *application) apiWebhookCreate(w http.ResponseWriter, r *http.Request) {
+	input, ok := app.decodeAPIWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	hooks, err := app.webhooks.List(r.Context(), token.UserID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if len(hooks) >= maxWebhooks {
+		app.apiError(w, r, http.StatusConflict, fmt.Sprintf("no more than %d webhooks allowed", maxWebhooks))
+
+		return
+	}
+
+	hook, err := app.webhooks.Insert(r.Context(), token.UserID, input.URL, input.Events)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/webhooks/%d", apiVersionOf(r).prefix(), hook.ID))
+	app.writeJSON(w, r, http.StatusCreated, newAPIWebhook(hook, true))
+}
+
+// apiWebhookUpdate replaces the URL and events of a webhook.
+func (app *application) apiWebhookUpdate(w http.ResponseWriter, r *http.Request) {
+	input, ok := app.decodeAPIWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	hook, err := app.webhooks.Update(r.Context(), token.UserID, pathInt(r, "id"), input.URL, input.Events)
+	if err != nil {
+		app.apiWebhookError(w, r, err)
+
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIWebhook(hook, false))
+}
+
+func (app *application) apiWebhookDelete(w http.ResponseWriter, r *http.Request) {
+	token, _ := app.apiToken(r)
+
+	if err := app.webhooks.Delete(r.Context(), token.UserID, pathInt(r, "id")); err != nil {
+		app.apiWebhookError(w, r, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apiWebhookRotateSecret replaces a webhook's secret and returns the new
+// one. The old secret stops working at once.
+func (app *application) apiWebhookRotateSecret(w http.ResponseWriter, r *http.Request) {
+	token, _ := app.apiToken(r)
+
+	hook, err := app.webhooks.RotateSecret(r.Context(), token.UserID, pathInt(r, "id"))
+	if err != nil {
+		app.apiWebhookError(w, r, err)
+
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIWebhook(hook, true))
+}
+
+// apiWebhookTest sends a ping event to a webhook and reports how it
+// answered, so integrations can check their endpoint and signature
+// verification without waiting for a real event.
+func (app *application) apiWebhookTest(w http.ResponseWriter, r *http.Request) {
+	hook, ok := app.apiWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := app.deliverWebhook(r.Context(), hook, webhookPing, map[string]int{"webhook_id": hook.ID})
+
+	result := apiWebhookTest{Delivered: err == nil, Status: status}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	app.writeJSON(w, r, http.StatusOK, result)
+}
+
+// decodeAPIWebhook decodes and validates the body of a webhook create or
+// update request, answering with an API error if it is unacceptable.
+func (app *application) decodeAPIWebhook(w http.ResponseWriter, r *http.Request) (apiWebhookInput, bool) {
+	var input apiWebhookInput
+
+	if !app.decodeJSON(w, r, &input, maxWebhookBody) {
+		return input, false
+	}
+
+	var v validator.Validator
+
+	v.CheckField(validWebhookURL(input.URL), "url", "must be an http or https URL of a public host")
+	v.CheckField(validator.MaxChars(input.URL, 2000), "url", "must be at most 2000 characters")
+	v.CheckField(len(input.Events) > 0, "events", "must name at least one event")
+
+	for _, event := range input.Events {
+		v.CheckField(slices.Contains(models.WebhookEvents, event), "events",
+			"must be some of "+strings.Join(models.WebhookEvents, ", "))
+	}
+
+	if !v.Valid() {
+		app.apiValidationError(w, r, "invalid webhook", v)
+
+		return input, false
+	}
+
+	slices.Sort(input.Events)
+	input.Events = slices.Compact(input.Events)
+
+	return input, true
+}
+
+// validWebhookURL reports whether s is an absolute http or https URL. Hosts
+// given as non-public IP addresses are refused right away; host names are
+// checked when delivering.
+func validWebhookURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return publicAddr(addr)
+	}
+
+	return u.Hostname() != "localhost"
+}
+
+// apiWebhook looks up the token user's webhook named by the {id} path
+// parameter. Other users' webhooks are not found.
+func (app *application) apiWebhook(w http.ResponseWriter, r *http.Request) (models.Webhook, bool) {
+	token, _ := app.apiToken(r)
+
+	hook, err := app.webhooks.Get(r.Context(), token.UserID, pathInt(r, "id"))
+	if err != nil {
+		app.apiWebhookError(w, r, err)
+
+		return models.Webhook{}, false
+	}
+
+	return hook, true
+}
+
+func (app *application) apiWebhookError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, models.ErrNoRecord) {
+		app.apiError(w, r, http.StatusNotFound, "webhook not found")
+
+		return
+	}
+
+	app.handleError(w, r, err)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+func TestAPIWebhooks(t *testing.T) {
+	app := newTestApplication(t)
+	// Every case is a request from the same two tokens.
+	app.tokenLimiter = ratelimit.New(1, 20)
+	app.adminLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	owner, other := mocks.MockTokenPlaintext, mocks.MockAdminTokenPlaintext
+	valid := `{"url": "https://example.com/hook", "events": ["snippet.created", "snippet.created"]}`
+
+	tests := []struct {
+		name     string
+		method   string
+		urlPath  string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"List", http.MethodGet, "/api/v1/webhooks", owner, "", http.StatusOK, `{"webhooks":[{"id":1,"url":"https://hooks.example.com/snippetbox","events":["snippet.created"],"created"`},
+		{"List other user", http.MethodGet, "/api/v1/webhooks", other, "", http.StatusOK, `{"webhooks":[]}`},
+		{"Get", http.MethodGet, "/api/v1/webhooks/1", owner, "", http.StatusOK, `"url":"https://hooks.example.com/snippetbox"`},
+		{"Get other user", http.MethodGet, "/api/v1/webhooks/1", other, "", http.StatusNotFound, `{"error":"webhook not found"}`},
+		{"Create", http.MethodPost, "/api/v1/webhooks", owner, valid, http.StatusCreated, `"events":["snippet.created"],"secret":"whsec_new"`},
+		{"Create loopback", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "http://127.0.0.1/", "events": ["snippet.created"]}`, http.StatusUnprocessableEntity, `"url":"must be an http or https URL of a public host"`},
+		{"Create private", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "http://[::ffff:10.0.0.1]/", "events": ["snippet.created"]}`, http.StatusUnprocessableEntity, `"url":"must be an http or https URL of a public host"`},
+		{"Create localhost", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "http://localhost:8080/", "events": ["snippet.created"]}`, http.StatusUnprocessableEntity, `"url":"must be an http or https URL of a public host"`},
+		{"Create ftp", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "ftp://example.com/", "events": ["snippet.created"]}`, http.StatusUnprocessableEntity, `"url":"must be an http or https URL of a public host"`},
+		{"Create no events", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "https://example.com/"}`, http.StatusUnprocessableEntity, `"events":"must name at least one event"`},
+		{"Create unknown event", http.MethodPost, "/api/v1/webhooks", owner, `{"url": "https://example.com/", "events": ["snippet.liked"]}`, http.StatusUnprocessableEntity, `"events":"must be some of snippet.created"`},
+		{"Update", http.MethodPut, "/api/v1/webhooks/1", owner, valid, http.StatusOK, `"url":"https://example.com/hook"`},
+		{"Update other user", http.MethodPut, "/api/v1/webhooks/1", other, valid, http.StatusNotFound, `{"error":"webhook not found"}`},
+		{"Rotate secret", http.MethodPost, "/api/v1/webhooks/1/rotate-secret", owner, "", http.StatusOK, `"secret":"whsec_rotated"`},
+		{"Rotate other user", http.MethodPost, "/api/v1/webhooks/1/rotate-secret", other, "", http.StatusNotFound, `{"error":"webhook not found"}`},
+		{"Delete other user", http.MethodDelete, "/api/v1/webhooks/1", other, "", http.StatusNotFound, `{"error":"webhook not found"}`},
+		{"Delete", http.MethodDelete, "/api/v1/webhooks/1", owner, "", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.apiDo(t, tt.method, tt.urlPath, tt.token, tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+
+			if code == http.StatusCreated {
+				assert.Equal(t, header.Get("Location"), "/api/v1/webhooks/2")
+			}
+		})
+	}
+
+	t.Run("Secret is not listed", func(t *testing.T) {
+		_, _, body := ts.apiGet(t, "/api/v1/webhooks/1", owner)
+
+		assert.Equal(t, strings.Contains(body, "secret"), false)
+	})
+}
+
+func TestAPIWebhookTest(t *testing.T) {
+	app := newTestApplication(t)
+	receiver := app.webhookClient.Transport.(*testWebhookReceiver)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.apiDo(t, http.MethodPost, "/api/v1/webhooks/1/test", mocks.MockTokenPlaintext, "")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, body, `{"delivered":true,"status":204}`+"\n")
+
+	requests, bodies := receiver.deliveries()
+	assert.Equal(t, len(requests), 1)
+	assert.Equal(t, requests[0].URL.String(), "https://hooks.example.com/snippetbox")
+	assert.Equal(t, requests[0].Header.Get("X-Snippetbox-Event"), "ping")
+	assert.Equal(t, requests[0].Header.Get("X-Snippetbox-Signature"), signWebhook(mocks.MockWebhookSecret, []byte(bodies[0])))
+	assert.StringContains(t, bodies[0], `"event":"ping"`)
+	assert.StringContains(t, bodies[0], `"data":{"webhook_id":1}`)
+
+	receiver.mu.Lock()
+	receiver.status = http.StatusInternalServerError
+	receiver.mu.Unlock()
+
+	code, _, body = ts.apiDo(t, http.MethodPost, "/api/v1/webhooks/1/test", mocks.MockTokenPlaintext, "")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `{"delivered":false,"status":500,"error":"delivering webhook: unexpected status 500"}`)
+}
+
+func TestSnippetCreatedWebhook(t *testing.T) {
+	app := newTestApplication(t)
+	receiver := app.webhookClient.Transport.(*testWebhookReceiver)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, _ := ts.apiDo(t, http.MethodPost, "/api/v1/snippets", mocks.MockTokenPlaintext,
+		`{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`)
+	assert.Equal(t, code, http.StatusCreated)
+
+	app.workers.Wait()
+
+	requests, bodies := receiver.deliveries()
+	assert.Equal(t, len(requests), 1)
+	assert.Equal(t, requests[0].Header.Get("X-Snippetbox-Event"), "snippet.created")
+	assert.StringContains(t, bodies[0], `"data":{"id":2,"title":"Haiku","url":"`)
+}
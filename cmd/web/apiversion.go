@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiVersion is a major version of the JSON API, served under /api/<name>.
+// Every version runs the same handlers, which build the responses of the
+// current version; where an older version's responses differ, the response
+// types implement apiShaper to adapt them.
+type apiVersion struct {
+	name string
+	// deprecated is when a newer version superseded this one, or zero for
+	// the current version.
+	deprecated time.Time
+}
+
+func (v apiVersion) prefix() string {
+	return "/api/" + v.name
+}
+
+// apiVersions are the versions of the JSON API, oldest first. The last one
+// is current; adding a version deprecates the one before it, which keeps
+// being served until it is retired after its -api-sunset date. Add one only
+// for a change that breaks clients, and give the responses it changes an
+// apiShaper.
+var apiVersions = []apiVersion{
+	{name: "v1"},
+}
+
+func currentAPIVersion() apiVersion {
+	return apiVersions[len(apiVersions)-1]
+}
+
+type apiVersionContextKey struct{}
+
+// apiVersionOf returns the API version a request was made to, which is the
+// current version for requests outside the API.
+func apiVersionOf(r *http.Request) apiVersion {
+	if v, ok := r.Context().Value(apiVersionContextKey{}).(apiVersion); ok {
+		return v
+	}
+
+	return currentAPIVersion()
+}
+
+// withAPIVersion returns middleware that records v as the version of the
+// requests under its prefix. Responses of a deprecated version carry the
+// Deprecation header of RFC 9745 and, once a removal date is set with
+// -api-sunset, the Sunset header of RFC 8594.
+func (app *application) withAPIVersion(v apiVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !v.deprecated.IsZero() {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(v.deprecated.Unix(), 10))
+			}
+
+			if sunset, ok := app.apiSunsets[v.name]; ok {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			ctx := context.WithValue(r.Context(), apiVersionContextKey{}, v)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiShaper is implemented by API responses whose JSON differs between
+// versions. writeJSON encodes what shapeFor returns for the request's
+// version in place of the response itself, so handlers only ever build the
+// current shape.
+type apiShaper interface {
+	shapeFor(v apiVersion) any
+}
+
+// parseAPISunsets parses -api-sunset, a comma-separated list of the dates
+// deprecated versions will be removed, e.g. "v1=2027-04-30".
+func parseAPISunsets(s string) (map[string]time.Time, error) {
+	sunsets := make(map[string]time.Time)
+
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, date, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("-api-sunset: %q is not version=date", entry)
+		}
+
+		i := slices.IndexFunc(apiVersions, func(v apiVersion) bool { return v.name == name })
+
+		switch {
+		case i < 0:
+			return nil, fmt.Errorf("-api-sunset: unknown API version %q", name)
+		case apiVersions[i].deprecated.IsZero():
+			return nil, fmt.Errorf("-api-sunset: API version %s is current and cannot be removed", name)
+		}
+
+		t, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, fmt.Errorf("-api-sunset: %q is not a date like 2027-04-30", date)
+		}
+
+		sunsets[name] = t
+	}
+
+	return sunsets, nil
+}
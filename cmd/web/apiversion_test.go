@@ -0,0 +1,159 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+// withTestAPIVersions replaces apiVersions for the duration of the test with
+// a deprecated v1 and a current v2, as they will be once v2 ships.
+func withTestAPIVersions(t *testing.T) {
+	t.Helper()
+
+	versions := apiVersions
+	t.Cleanup(func() { apiVersions = versions })
+
+	apiVersions = []apiVersion{
+		{name: "v1", deprecated: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "v2"},
+	}
+}
+
+func TestAPIVersionCurrent(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// Until a change breaks clients, v1 is current and not deprecated.
+	code, header, _ := ts.apiGet(t, "/api/v1/whoami", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Deprecation"), "")
+	assert.Equal(t, currentAPIVersion().name, "v1")
+}
+
+func TestAPIVersions(t *testing.T) {
+	withTestAPIVersions(t)
+
+	app := newTestApplication(t)
+	app.apiSunsets = map[string]time.Time{"v1": time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	valid := `{"title": "Haiku", "content": "Over the wintry forest", "expires": 7}`
+
+	tests := []struct {
+		version         string
+		wantDeprecation string
+		wantSunset      string
+	}{
+		{"v1", "@1792195200", "Fri, 30 Apr 2027 00:00:00 GMT"},
+		{"v2", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			code, header, _ := ts.apiGet(t, "/api/"+tt.version+"/whoami", mocks.MockTokenPlaintext)
+			assert.Equal(t, code, http.StatusOK)
+			assert.Equal(t, header.Get("Deprecation"), tt.wantDeprecation)
+			assert.Equal(t, header.Get("Sunset"), tt.wantSunset)
+
+			// Errors before authentication are announced too.
+			_, header, _ = ts.apiGet(t, "/api/"+tt.version+"/whoami", "")
+			assert.Equal(t, header.Get("Deprecation"), tt.wantDeprecation)
+
+			code, header, _ = ts.apiDo(t, http.MethodPost, "/api/"+tt.version+"/snippets", mocks.MockTokenPlaintext, valid)
+			assert.Equal(t, code, http.StatusCreated)
+			assert.Equal(t, header.Get("Location"), "/api/"+tt.version+"/snippets/2")
+		})
+	}
+}
+
+// shapedResponse is a response that version 1 renamed.
+type shapedResponse struct {
+	Name string `json:"name"`
+}
+
+func (s shapedResponse) shapeFor(v apiVersion) any {
+	if v.name == "v1" {
+		return map[string]string{"title": s.Name}
+	}
+
+	return s
+}
+
+func TestAPIShaper(t *testing.T) {
+	withTestAPIVersions(t)
+
+	app := newTestApplication(t)
+
+	tests := []struct {
+		version apiVersion
+		want    string
+	}{
+		{apiVersions[0], `{"title":"pond"}`},
+		{currentAPIVersion(), `{"name":"pond"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version.name, func(t *testing.T) {
+			handler := app.withAPIVersion(tt.version)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				app.writeJSON(w, r, http.StatusOK, shapedResponse{Name: "pond"})
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, rr.Body.String(), tt.want+"\n")
+		})
+	}
+}
+
+func TestParseAPISunsets(t *testing.T) {
+	withTestAPIVersions(t)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]time.Time
+		wantErr string
+	}{
+		{name: "Empty", want: map[string]time.Time{}},
+		{
+			name:  "Valid",
+			value: " v1=2027-04-30 ",
+			want:  map[string]time.Time{"v1": time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)},
+		},
+		{name: "Malformed", value: "v1", wantErr: `"v1" is not version=date`},
+		{name: "Unknown version", value: "v0=2027-04-30", wantErr: `unknown API version "v0"`},
+		{name: "Current version", value: "v2=2027-04-30", wantErr: "v2 is current"},
+		{name: "Invalid date", value: "v1=soon", wantErr: `"soon" is not a date`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAPISunsets(tt.value)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got no error; want %q", tt.wantErr)
+				}
+
+				assert.StringContains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, len(got), len(tt.want))
+
+			for name, date := range tt.want {
+				assert.Equal(t, got[name], date)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assets"
+	"github.com/FABLOUSFALCON/snippetbox/ui"
+)
+
+// assetDir is where the assets command writes the bundles, relative to
+// ui/static.
+const assetDir = "dist"
+
+// assetBundles are the bundles linked from the templates with the asset
+// function. Every stylesheet and script in ui/static goes into one of them.
+var assetBundles = []assets.Bundle{
+	{Name: "css/main.css", Patterns: []string{"css/*.css"}},
+	{Name: "js/main.js", Patterns: []string{"js/*.js"}},
+}
+
+// cmdAssets rebuilds the minified, fingerprinted bundles in ui/static/dist.
+// Run it, or go generate ./ui, after changing a stylesheet or script.
+func cmdAssets(args []string) error {
+	flags := flag.NewFlagSet("assets", flag.ContinueOnError)
+	dir := flags.String("ui", "ui", "Directory of the ui package, whose static files are bundled")
+
+	if err := flags.Parse(args); err != nil {
+		return err //nolint:wrapcheck // flag errors are already descriptive
+	}
+
+	static := filepath.Join(*dir, "static")
+
+	outputs, manifest, err := assets.Build(os.DirFS(static), assetDir, assetBundles)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped by the assets package
+	}
+
+	if err := assets.Write(static, assetDir, outputs, manifest); err != nil {
+		return err //nolint:wrapcheck // already wrapped by the assets package
+	}
+
+	for _, b := range assetBundles {
+		fmt.Printf("%s -> %s\n", b.Name, manifest[b.Name])
+	}
+
+	return nil
+}
+
+// assetManifest is the manifest of the embedded bundles. It is empty in
+// development builds, which serve the source files so edits show up without
+// rebuilding the bundles, and when the bundles were never built.
+var assetManifest = sync.OnceValue(func() assets.Manifest {
+	if !ui.Embedded {
+		return nil
+	}
+
+	static, err := fs.Sub(ui.Files, "static")
+	if err != nil {
+		return nil
+	}
+
+	manifest, err := assets.ReadManifest(static, assetDir)
+	if err != nil {
+		return nil
+	}
+
+	return manifest
+})
+
+// asset returns the path under /static to link for the file name, e.g.
+// {{$.BasePath}}/static/{{asset "css/main.css"}}. That is its bundle if one
+// was built, and the file itself otherwise.
+func asset(name string) string {
+	if p, ok := assetManifest()[name]; ok {
+		return p
+	}
+
+	return name
+}
+
+// staticFiles serves ui/static. Bundles have their content hash in their
+// name, so browsers may cache them for good. A missing bundle is not
+// cached, since during a rolling deploy it may only exist on newer servers.
+func staticFiles() http.Handler {
+	files := http.FileServerFS(ui.Files)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if strings.HasPrefix(name, "static/"+assetDir+"/") && path.Base(name) != assets.ManifestName {
+			if _, err := fs.Stat(ui.Files, name); err == nil {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			}
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/assets"
+	"github.com/FABLOUSFALCON/snippetbox/ui"
+)
+
+// TestAssetsUpToDate fails when a stylesheet or script was changed without
+// rebuilding the bundles with go generate ./ui.
+func TestAssetsUpToDate(t *testing.T) {
+	static, err := fs.Sub(ui.Files, "static")
+	assert.NilError(t, err)
+
+	outputs, manifest, err := assets.Build(static, assetDir, assetBundles)
+	assert.NilError(t, err)
+
+	committed, err := assets.ReadManifest(static, assetDir)
+	assert.NilError(t, err)
+
+	for name, path := range manifest {
+		if committed[name] != path {
+			t.Fatalf("bundle %s is out of date; run go generate ./ui", name)
+		}
+
+		b, err := fs.ReadFile(static, path)
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), string(outputs[path]))
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	static, err := fs.Sub(ui.Files, "static")
+	assert.NilError(t, err)
+
+	manifest, err := assets.ReadManifest(static, assetDir)
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		wantCode    int
+		wantCaching string
+	}{
+		{"Bundle", "/static/" + manifest["css/main.css"], http.StatusOK, "public, max-age=31536000, immutable"},
+		{"Source file", "/static/css/main.css", http.StatusOK, ""},
+		{"Manifest", "/static/dist/manifest.json", http.StatusOK, ""},
+		{"Missing bundle", "/static/dist/css/main.00000000.css", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, _ := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Cache-Control"), tt.wantCaching)
+		})
+	}
+
+	// Release builds link the bundles, development builds the sources.
+	_, _, body := ts.get(t, "/about")
+	if ui.Embedded {
+		assert.StringContains(t, body, "/static/"+manifest["js/main.js"])
+	} else {
+		assert.StringContains(t, body, "/static/js/main.js")
+	}
+}
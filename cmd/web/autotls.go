@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type autoTLSConfig struct {
+	domains  string
+	cacheDir string
+	email    string
+}
+
+// autoTLSFlags registers the flags for obtaining certificates from Let's
+// Encrypt. Setting -auto-tls-domain turns the feature on.
+func autoTLSFlags(cfg *autoTLSConfig) {
+	flag.StringVar(&cfg.domains, "auto-tls-domain", "", "Comma-separated domains to get Let's Encrypt certificates for (empty disables)")
+	flag.StringVar(&cfg.cacheDir, "auto-tls-cache", "./certs", "Directory where Let's Encrypt certificates and the account key are stored")
+	flag.StringVar(&cfg.email, "auto-tls-email", "", "Contact email for the Let's Encrypt account, used for expiry warnings")
+}
+
+func (cfg autoTLSConfig) enabled() bool {
+	return cfg.domains != ""
+}
+
+func newAutocertManager(cfg autoTLSConfig) *autocert.Manager {
+	var domains []string
+
+	for domain := range strings.SplitSeq(cfg.domains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cfg.cacheDir),
+		Email:      cfg.email,
+	}
+}
+
+// useAutoTLS makes srv fetch its certificates from m. TLS-ALPN-01 challenges
+// are answered on the HTTPS listener itself; HTTP-01 challenges need the
+// server returned by newRedirectServer.
+func useAutoTLS(srv *http.Server, m *autocert.Manager) {
+	srv.TLSConfig.GetCertificate = m.GetCertificate
+	srv.TLSConfig.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+}
+
+// newRedirectServer returns the plain HTTP server started next to the HTTPS
+// one. It permanently redirects every request to the same URL on httpsAddr,
+// except for ACME HTTP-01 challenges: those are answered by m when
+// certificates come from Let's Encrypt, or served from the webroot directory
+// used by an external client such as certbot.
+func newRedirectServer(addr, httpsAddr string, m *autocert.Manager, webroot string, logger *slog.Logger) *http.Server {
+	handler := redirectToHTTPS(httpsAddr)
+
+	if webroot != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /.well-known/acme-challenge/{token}", http.FileServer(http.Dir(webroot)))
+		mux.Handle("/", handler)
+		handler = mux
+	}
+
+	if m != nil {
+		handler = m.HTTPHandler(handler)
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		IdleTimeout:       time.Minute,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+}
+
+// redirectToHTTPS redirects to the request URL on the port of httpsAddr,
+// using 308 so that methods and bodies are kept.
+func redirectToHTTPS(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestAutocertManager(t *testing.T) {
+	m := newAutocertManager(autoTLSConfig{
+		domains:  "snippets.example.com, www.snippets.example.com,",
+		cacheDir: t.TempDir(),
+		email:    "admin@example.com",
+	})
+
+	assert.Equal(t, m.Email, "admin@example.com")
+	assert.NilError(t, m.HostPolicy(context.Background(), "snippets.example.com"))
+	assert.NilError(t, m.HostPolicy(context.Background(), "www.snippets.example.com"))
+	assert.Equal(t, m.HostPolicy(context.Background(), "evil.example.com") != nil, true)
+
+	srv := &http.Server{TLSConfig: newTLSConfig()}
+	useAutoTLS(srv, m)
+
+	assert.Equal(t, srv.TLSConfig.GetCertificate != nil, true)
+	assert.Equal(t, srv.TLSConfig.NextProtos[len(srv.TLSConfig.NextProtos)-1], "acme-tls/1")
+}
+
+func TestRedirectServer(t *testing.T) {
+	webroot := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(webroot, ".well-known", "acme-challenge"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(webroot, ".well-known", "acme-challenge", "abc123"), []byte("abc123.key"), 0o600))
+
+	m := newAutocertManager(autoTLSConfig{domains: "snippets.example.com", cacheDir: t.TempDir()})
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name         string
+		srv          *http.Server
+		method       string
+		url          string
+		wantCode     int
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:         "Default HTTPS port",
+			srv:          newRedirectServer(":80", ":443", nil, "", logger),
+			method:       http.MethodGet,
+			url:          "http://snippets.example.com/snippet/view/1?x=1",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "https://snippets.example.com/snippet/view/1?x=1",
+		},
+		{
+			name:         "Custom HTTPS port",
+			srv:          newRedirectServer(":8080", ":4001", nil, "", logger),
+			method:       http.MethodPost,
+			url:          "http://localhost:8080/user/login",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "https://localhost:4001/user/login",
+		},
+		{
+			name:     "Webroot challenge",
+			srv:      newRedirectServer(":80", ":443", nil, webroot, logger),
+			method:   http.MethodGet,
+			url:      "http://snippets.example.com/.well-known/acme-challenge/abc123",
+			wantCode: http.StatusOK,
+			wantBody: "abc123.key",
+		},
+		{
+			name:         "Webroot redirects other requests",
+			srv:          newRedirectServer(":80", ":443", nil, webroot, logger),
+			method:       http.MethodGet,
+			url:          "http://snippets.example.com/about",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "https://snippets.example.com/about",
+		},
+		{
+			name:         "Autocert redirects other requests",
+			srv:          newRedirectServer(":80", ":443", m, "", logger),
+			method:       http.MethodGet,
+			url:          "http://snippets.example.com/snippet/view/1",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "https://snippets.example.com/snippet/view/1",
+		},
+		{
+			name:     "Autocert unknown challenge token",
+			srv:      newRedirectServer(":80", ":443", m, "", logger),
+			method:   http.MethodGet,
+			url:      "http://snippets.example.com/.well-known/acme-challenge/unknown",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.srv.Handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+			assert.Equal(t, rr.Header().Get("Location"), tt.wantLocation)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// normalizeBasePath turns the -base-path flag into the form used internally:
+// a leading slash and no trailing one, or the empty string when the app is
+// served from the root.
+func normalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+
+	return "/" + p
+}
+
+// cookiePath is the path session and CSRF cookies are scoped to.
+func (app *application) cookiePath() string {
+	if app.basePath == "" {
+		return "/"
+	}
+
+	return app.basePath + "/"
+}
+
+// mountBasePath serves the application under app.basePath. Requests outside
+// the prefix get a 404, the prefix is stripped before routing so handlers
+// keep working with root-relative paths, and root-relative redirects are
+// rewritten to point back under the prefix.
+func (app *application) mountBasePath(next http.Handler) http.Handler {
+	strip := http.StripPrefix(app.basePath, next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == app.basePath {
+			http.Redirect(w, r, app.basePath+"/", http.StatusMovedPermanently)
+
+			return
+		}
+
+		if !strings.HasPrefix(r.URL.Path, app.basePath+"/") {
+			app.notFound(w, r)
+
+			return
+		}
+
+		strip.ServeHTTP(&basePathWriter{ResponseWriter: w, basePath: app.basePath}, r)
+	})
+}
+
+// basePathWriter prefixes root-relative Location headers with basePath.
+type basePathWriter struct {
+	http.ResponseWriter
+	basePath    string
+	wroteHeader bool
+}
+
+func (w *basePathWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		loc := w.Header().Get("Location")
+		if strings.HasPrefix(loc, "/") && !strings.HasPrefix(loc, "//") {
+			w.Header().Set("Location", w.basePath+loc)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *basePathWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *basePathWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+func TestNormalizeBasePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"snippetbox", "/snippetbox"},
+		{"/snippetbox/", "/snippetbox"},
+		{" /preview/snippetbox ", "/preview/snippetbox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, normalizeBasePath(tt.in), tt.want)
+		})
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	app := newTestApplication(t)
+	app.basePath = "/snippetbox"
+	app.sessionManager.Cookie.Path = app.cookiePath()
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, _ := ts.get(t, "/")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, headers, _ := ts.get(t, "/snippetbox")
+	assert.Equal(t, code, http.StatusMovedPermanently)
+	assert.Equal(t, headers.Get("Location"), "/snippetbox/")
+
+	code, _, body := ts.get(t, "/snippetbox/")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<link rel='stylesheet' href='/snippetbox/static/"+asset("css/main.css")+"'>")
+	assert.StringContains(t, body, "<a href='/snippetbox/user/login'>")
+
+	code, _, _ = ts.get(t, "/snippetbox/static/css/main.css")
+	assert.Equal(t, code, http.StatusOK)
+
+	code, headers, _ = ts.get(t, "/snippetbox/account/view")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/snippetbox/user/login")
+
+	_, headers, body = ts.get(t, "/snippetbox/user/login")
+	assert.StringContains(t, headers.Get("Set-Cookie"), "Path=/snippetbox/")
+
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ = ts.postForm(t, "/snippetbox/user/login", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/snippetbox/account/view")
+
+	code, headers, _ = ts.apiDo(t, http.MethodPost, "/snippetbox/api/v1/snippets", mocks.MockTokenPlaintext,
+		`{"title": "O snail", "content": "Climb Mount Fuji", "expires": 7}`)
+	assert.Equal(t, code, http.StatusCreated)
+	assert.Equal(t, headers.Get("Location"), "/snippetbox/api/v1/snippets/2")
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// bodyLogConfig configures the sampled logging of request and response
+// bodies, meant for debugging form handling in staging.
+type bodyLogConfig struct {
+	rate     float64
+	maxBytes int
+}
+
+// bodyLogFlags registers the body logging flags.
+func bodyLogFlags(cfg *bodyLogConfig) {
+	flag.Float64Var(&cfg.rate, "body-log-rate", 0, "Fraction of requests, from 0 to 1, whose request and response bodies are logged with secrets redacted (0 disables)")
+	flag.IntVar(&cfg.maxBytes, "body-log-max-bytes", 2048, "Number of bytes of each body kept by -body-log-rate")
+}
+
+func (c bodyLogConfig) enabled() bool {
+	return c.rate > 0
+}
+
+func (c bodyLogConfig) validate() error {
+	switch {
+	case c.rate < 0 || c.rate > 1:
+		return errors.New("-body-log-rate must be between 0 and 1")
+	case c.enabled() && c.maxBytes <= 0:
+		return errors.New("-body-log-max-bytes must be positive")
+	}
+
+	return nil
+}
+
+// redacted replaces the value of every field that looks like a secret.
+const redacted = "REDACTED"
+
+// secretFieldParts are the substrings that mark a form or JSON field name,
+// compared in lower case, as holding a secret.
+var secretFieldParts = []string{"password", "secret", "token", "csrf", "authorization", "credential", "captcha"}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, part := range secretFieldParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretInputRe matches the value of a hidden or password input in an HTML
+// page, such as the CSRF token every form carries.
+var secretInputRe = regexp.MustCompile(`(<input[^>]*type=['"]?(?:hidden|password)['"]?[^>]*value=['"])[^'"]*`)
+
+// credentialRoutes are the routes whose responses may show a newly created
+// credential, such as the API token the tokens page prints once after it
+// was created, in markup redactBody cannot tell from other text. Their
+// response bodies are never logged.
+var credentialRoutes = []string{
+	"GET /account/tokens",
+	"POST /account/tokens",
+}
+
+// withheldBody replaces the response bodies of credentialRoutes.
+const withheldBody = "[withheld: may contain a credential]"
+
+// bodyLogger decides which requests have their bodies logged.
+type bodyLogger struct {
+	logger   *slog.Logger
+	maxBytes int
+	sample   func() bool
+}
+
+func newBodyLogger(cfg bodyLogConfig, logger *slog.Logger) *bodyLogger {
+	return &bodyLogger{
+		logger:   logger,
+		maxBytes: cfg.maxBytes,
+		sample: func() bool {
+			return rand.Float64() < cfg.rate //nolint:gosec // sampling needs no cryptographic randomness
+		},
+	}
+}
+
+// logBodies logs the start of the request and response bodies of a sample
+// of requests, after the handler has run. It must wrap the ServeMux
+// directly, like trackLatency, to see the route pattern. The bodies are
+// copied as the handler reads and writes them, so neither is buffered in
+// full.
+func (app *application) logBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.bodyLog.sample() {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		req := &limitedBuffer{max: app.bodyLog.maxBytes}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, req), r.Body}
+		}
+
+		bw := &bodyLogWriter{ResponseWriter: w, body: limitedBuffer{max: app.bodyLog.maxBytes}}
+
+		next.ServeHTTP(bw, r)
+
+		// Streams have no end worth waiting for and carry public data only.
+		if r.Pattern == snippetEventsRoute || r.Pattern == liveRoute {
+			return
+		}
+
+		status := bw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		response := withheldBody
+		if !slices.Contains(credentialRoutes, r.Pattern) {
+			response = redactBody(w.Header().Get("Content-Type"), &bw.body)
+		}
+
+		app.bodyLog.logger.InfoContext(r.Context(), "sampled request body",
+			slog.String("method", r.Method),
+			slog.String("uri", redactURI(r.Pattern, r.URL)),
+			slog.String("pattern", r.Pattern),
+			slog.Int("status", status),
+			slog.String("request_body", redactBody(r.Header.Get("Content-Type"), req)),
+			slog.String("response_body", response),
+		)
+	})
+}
+
+// bodyLogWriter copies the start of the response body.
+type bodyLogWriter struct {
+	http.ResponseWriter
+	status int
+	body   limitedBuffer
+}
+
+func (w *bodyLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	_, _ = w.body.Write(b)
+
+	return w.ResponseWriter.Write(b) //nolint:wrapcheck // errors pass through unchanged
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *bodyLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitedBuffer keeps the first max bytes written to it and counts the
+// rest. Writes never fail.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := min(len(p), b.max-b.buf.Len())
+	b.buf.Write(p[:n])
+	b.truncated += len(p) - n
+
+	return len(p), nil
+}
+
+// redactURI returns the request URI with secret path parameters, such as
+// the {token} of pattern, and secret query parameters redacted.
+func redactURI(pattern string, u *url.URL) string {
+	path := redactPath(pattern, u.EscapedPath())
+	if u.RawQuery == "" {
+		return path
+	}
+
+	query := u.Query()
+	redactValues(query)
+
+	return path + "?" + query.Encode()
+}
+
+// redactPath redacts the segments of path matched by the wildcards of
+// pattern whose names look like secrets.
+func redactPath(pattern, path string) string {
+	if _, route, ok := strings.Cut(pattern, " "); ok {
+		pattern = route
+	}
+
+	segments := strings.Split(path, "/")
+
+	for i, wildcard := range strings.Split(pattern, "/") {
+		name, ok := strings.CutPrefix(wildcard, "{")
+		if !ok || i >= len(segments) {
+			continue
+		}
+
+		name, rest := strings.CutSuffix(strings.TrimSuffix(name, "}"), "...")
+		if !isSecretField(name) {
+			continue
+		}
+
+		if rest {
+			// A {name...} wildcard matches the remainder of the path.
+			segments = append(segments[:i], redacted)
+
+			break
+		}
+
+		segments[i] = redacted
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func redactValues(values url.Values) {
+	for key, vs := range values {
+		if isSecretField(key) {
+			for i := range vs {
+				vs[i] = redacted
+			}
+		}
+	}
+}
+
+// redactBody returns the buffered body as text with secrets redacted:
+// secret fields of forms and JSON objects and the values of hidden and
+// password inputs in HTML. Other binary bodies are only described.
+func redactBody(contentType string, b *limitedBuffer) string {
+	if b.buf.Len() == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	body := b.buf.String()
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		// A truncated form may end in half a value, which is still redacted
+		// if its name made it in.
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			return "[unparsable form]"
+		}
+
+		redactValues(values)
+		body = values.Encode()
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v any
+		if err := json.Unmarshal(b.buf.Bytes(), &v); err != nil {
+			// A JSON body cut short cannot be parsed, so it cannot be
+			// checked for secrets either.
+			return "[unparsable JSON]"
+		}
+
+		redactJSON(v)
+
+		out, _ := json.Marshal(v) //nolint:errchkjson // v came from json.Unmarshal
+		body = string(out)
+	case mediaType == "text/html":
+		body = secretInputRe.ReplaceAllString(body, "${1}"+redacted)
+	case strings.HasPrefix(mediaType, "text/"):
+		// Other text is logged as it is.
+	default:
+		return "[" + cmp.Or(mediaType, "untyped") + " body]"
+	}
+
+	if b.truncated > 0 {
+		body += "…"
+	}
+
+	return body
+}
+
+// redactJSON redacts secret fields of the objects in v, at any depth.
+func redactJSON(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSecretField(key) {
+				v[key] = redacted
+			} else {
+				redactJSON(value)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			redactJSON(value)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+)
+
+func TestLogBodies(t *testing.T) {
+	app := newTestApplication(t)
+
+	logs := logbuffer.New(10)
+	app.bodyLog = &bodyLogger{
+		logger:   slog.New(logs.Handler(slog.DiscardHandler)),
+		maxBytes: 1 << 16,
+		sample:   func() bool { return true },
+	}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, page := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, page)
+
+	form := url.Values{
+		"email":      {"alice@example.com"},
+		"password":   {"pa$$word"},
+		"csrf_token": {csrfToken},
+	}
+	code, _, _ := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	records := logs.Records(logbuffer.Filter{})
+	assert.Equal(t, len(records), 2)
+
+	// Records are newest first.
+	post, get := records[0], records[1]
+
+	assert.Equal(t, get.Attr("pattern"), "GET /user/login")
+	assert.StringContains(t, get.Attr("response_body"), "name='csrf_token' value='REDACTED'")
+	assert.Equal(t, strings.Contains(get.Attr("response_body"), csrfToken), false)
+
+	assert.Equal(t, post.Attr("pattern"), "POST /user/login")
+	assert.Equal(t, post.Attr("status"), "303")
+	assert.Equal(t, post.Attr("request_body"), "csrf_token=REDACTED&email=alice%40example.com&password=REDACTED")
+
+	// The tokens page shows a new token in plain text once.
+	_, _, _ = ts.get(t, "/account/tokens")
+
+	tokens := logs.Records(logbuffer.Filter{})[0]
+	assert.Equal(t, tokens.Attr("pattern"), "GET /account/tokens")
+	assert.Equal(t, tokens.Attr("response_body"), withheldBody)
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		max         int
+		want        string
+	}{
+		{"Empty", "text/plain", "", 10, ""},
+		{"Text", "text/plain; charset=utf-8", "hello", 10, "hello"},
+		{"Truncated text", "text/plain", "hello world", 5, "hello…"},
+		{"Form", "application/x-www-form-urlencoded", "title=Hi&newPassword=x", 100, "newPassword=REDACTED&title=Hi"},
+		{"JSON", "application/json", `{"name":"ci","token":"sbx_1","items":[{"apiSecret":"s"}]}`, 100, `{"items":[{"apiSecret":"REDACTED"}],"name":"ci","token":"REDACTED"}`},
+		{"Truncated JSON", "application/json", `{"token":"sbx_1"}`, 8, "[unparsable JSON]"},
+		{"HTML", "text/html", `<input type="password" name="pw" value="hunter2">`, 100, `<input type="password" name="pw" value="REDACTED">`},
+		{"Binary", "image/png", "\x89PNG", 100, "[image/png body]"},
+		{"Untyped", "", "\x00\x01", 100, "[untyped body]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &limitedBuffer{max: tt.max}
+			_, _ = b.Write([]byte(tt.body))
+
+			assert.Equal(t, redactBody(tt.contentType, b), tt.want)
+		})
+	}
+}
+
+func TestRedactURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		uri     string
+		want    string
+	}{
+		{"Query", "GET /api/v1/snippets", "/api/v1/snippets?page=2&access_token=abc", "/api/v1/snippets?access_token=REDACTED&page=2"},
+		{"Path", "GET /account/export-data/{token}", "/account/export-data/abc123", "/account/export-data/REDACTED"},
+		{"Public path", "GET /snippet/view/{id}", "/snippet/view/1", "/snippet/view/1"},
+		{"Remainder", "GET /files/{token...}", "/files/a/b?x=1", "/files/REDACTED?x=1"},
+		{"Unmatched", "", "/nowhere/abc", "/nowhere/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.uri)
+			assert.NilError(t, err)
+
+			assert.Equal(t, redactURI(tt.pattern, u), tt.want)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// These describe the build when set with the linker, e.g.
+//
+//	go build -ldflags "-X main.version=v1.4.0 -X main.commit=$(git rev-parse HEAD) \
+//		-X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/web
+//
+// Builds without them fall back to what the Go toolchain records.
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// buildInfo describes the running binary.
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// readBuildInfo returns the version, commit and build date set with the
+// linker, filling in any that were not from the module version and the VCS
+// revision and commit time the toolchain embeds. Without either, the
+// version is the revision, or "devel" if there is none.
+func readBuildInfo() buildInfo {
+	b := buildInfo{Version: version, Commit: commit, BuildDate: buildDate, GoVersion: runtime.Version()}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		if b.Version == "" {
+			b.Version = "unknown"
+		}
+
+		return b
+	}
+
+	var revision, modified, vcsTime string
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		}
+	}
+
+	if b.Commit == "" {
+		b.Commit = revision
+	}
+
+	if b.BuildDate == "" {
+		b.BuildDate = vcsTime
+	}
+
+	if b.Version == "" {
+		b.Version = moduleVersion(info.Main.Version, revision, modified == "true")
+	}
+
+	return b
+}
+
+// moduleVersion is the version of a build not given one with the linker:
+// the module version for released builds, otherwise the short VCS revision.
+func moduleVersion(module, revision string, modified bool) string {
+	if module != "" && module != "(devel)" {
+		return module
+	}
+
+	if revision == "" {
+		return "devel"
+	}
+
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+
+	if modified {
+		revision += "-dirty"
+	}
+
+	return revision
+}
+
+// buildVersion describes the running binary in one word, for health checks
+// and traces.
+func buildVersion() string {
+	return readBuildInfo().Version
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestModuleVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   string
+		revision string
+		modified bool
+		want     string
+	}{
+		{"Release", "v1.4.0", "0123456789abcdef", false, "v1.4.0"},
+		{"Revision", "(devel)", "0123456789abcdef", false, "0123456789ab"},
+		{"Modified", "", "0123456789abcdef", true, "0123456789ab-dirty"},
+		{"Nothing", "(devel)", "", false, "devel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, moduleVersion(tt.module, tt.revision, tt.modified), tt.want)
+		})
+	}
+}
+
+func TestReadBuildInfoLinkerFlags(t *testing.T) {
+	setBuildInfo(t, "v1.4.0", "0123456789abcdef", "2026-10-17T09:00:00Z")
+
+	assert.Equal(t, readBuildInfo(), buildInfo{
+		Version:   "v1.4.0",
+		Commit:    "0123456789abcdef",
+		BuildDate: "2026-10-17T09:00:00Z",
+		GoVersion: runtime.Version(),
+	})
+	assert.Equal(t, buildVersion(), "v1.4.0")
+}
+
+// setBuildInfo sets the variables the linker would for the duration of the
+// test.
+func setBuildInfo(t *testing.T, v, c, date string) {
+	t.Helper()
+
+	oldVersion, oldCommit, oldDate := version, commit, buildDate
+	version, commit, buildDate = v, c, date
+
+	t.Cleanup(func() {
+		version, commit, buildDate = oldVersion, oldCommit, oldDate
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/alexedwards/scs/v2"
+)
+
+type cacheConfig struct {
+	backend       string
+	size          int
+	ttl           time.Duration
+	redisAddr     string
+	redisPassword string
+	redisDB       int
+}
+
+// newCacheBackend returns the configured cache backend, or nil when caching
+// is disabled.
+func newCacheBackend(cfg cacheConfig) (cache.Backend, error) {
+	switch cfg.backend {
+	case "", "none":
+		return nil, nil //nolint:nilnil // a nil backend means caching is off
+	case "memory":
+		return cache.NewMemory(cfg.size), nil
+	case "redis":
+		return &cache.Redis{
+			Addr:     cfg.redisAddr,
+			Password: cfg.redisPassword,
+			DB:       cfg.redisDB,
+			Timeout:  time.Second,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.backend)
+	}
+}
+
+// useCache puts a read-through cache in front of snippet lookups, snippet
+// view counts and, with a shared backend, sessions. Cached entries live for
+// at most ttl, which bounds how stale view counts can get.
+func (app *application) useCache(backend cache.Backend, ttl time.Duration) {
+	snippets := cache.New(backend, "snippets")
+	counts := cache.New(backend, "counts")
+
+	app.caches = []*cache.Cache{snippets, counts}
+
+	app.snippets = &cachedSnippetModel{SnippetModelInterface: app.snippets, cache: snippets, ttl: ttl}
+	app.analytics = &cachedAnalyticsModel{AnalyticsModelInterface: app.analytics, cache: counts, ttl: ttl}
+
+	// An instance keeping sessions in its own memory would go on accepting
+	// a session that another instance logged out or renewed, so sessions
+	// are only cached where every instance sees the same entries.
+	if _, local := backend.(*cache.Memory); local {
+		return
+	}
+
+	sessions := cache.New(backend, "sessions")
+	app.caches = append(app.caches, sessions)
+
+	app.sessionManager.Store = &cachedSessionStore{
+		Store: app.sessionManager.Store,
+		codec: app.sessionManager.Codec,
+		cache: sessions,
+		ttl:   ttl,
+	}
+}
+
+// cachedSnippetModel caches snippets by ID. Updating or deleting a snippet
+// evicts it; otherwise expiry caps the cache lifetime. With the in-memory
+// backend and several instances, other instances can serve the old snippet
+// for up to ttl. The cache is filled from the primary, so a read replica
+// that lags behind an update cannot put the old snippet back.
+type cachedSnippetModel struct {
+	models.SnippetModelInterface
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+func (m *cachedSnippetModel) Get(ctx context.Context, id int) (models.Snippet, error) {
+	return cache.Fetch(ctx, m.cache, strconv.Itoa(id),
+		func(ctx context.Context) (models.Snippet, time.Duration, error) {
+			s, err := m.SnippetModelInterface.Get(models.Primary(ctx), id)
+			if err != nil {
+				return models.Snippet{}, 0, err
+			}
+
+			return s, min(m.ttl, time.Until(s.Expires)), nil
+		})
+}
+
+func (m *cachedSnippetModel) Update(ctx context.Context, id int, version time.Time, title, content string, expires int) error {
+	if err := m.SnippetModelInterface.Update(ctx, id, version, title, content, expires); err != nil {
+		return err //nolint:wrapcheck // the model's errors are already descriptive
+	}
+
+	// A failed eviction only leaves the old snippet cached until it expires,
+	// and the cache counts the error in its stats.
+	_ = m.cache.Del(ctx, strconv.Itoa(id))
+
+	return nil
+}
+
+func (m *cachedSnippetModel) Delete(ctx context.Context, id int, version time.Time) error {
+	if err := m.SnippetModelInterface.Delete(ctx, id, version); err != nil {
+		return err //nolint:wrapcheck // the model's errors are already descriptive
+	}
+
+	_ = m.cache.Del(ctx, strconv.Itoa(id))
+
+	return nil
+}
+
+// cachedAnalyticsModel caches snippet statistics, which are read far more
+// often than it is worth recomputing them.
+type cachedAnalyticsModel struct {
+	models.AnalyticsModelInterface
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+func (m *cachedAnalyticsModel) SnippetStats(ctx context.Context, snippetID int) (models.SnippetStats, error) {
+	return cache.Fetch(ctx, m.cache, "stats:"+strconv.Itoa(snippetID),
+		func(ctx context.Context) (models.SnippetStats, time.Duration, error) {
+			stats, err := m.AnalyticsModelInterface.SnippetStats(ctx, snippetID)
+
+			return stats, m.ttl, err
+		})
+}
+
+// cachedSessionStore keeps recently used sessions in a shared cache so that
+// most requests do not need to read their session from the database. Writes
+// go to both. Entries never outlive the session they hold.
+type cachedSessionStore struct {
+	scs.Store
+	// codec decodes sessions read from the store to learn their deadline.
+	codec scs.Codec
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+func (s *cachedSessionStore) Find(token string) ([]byte, bool, error) {
+	ctx := context.Background()
+
+	if b, ok := s.cache.Get(ctx, token); ok {
+		return b, true, nil
+	}
+
+	b, found, err := s.Store.Find(token)
+	if err != nil {
+		return nil, false, fmt.Errorf("finding session: %w", err)
+	}
+
+	if !found {
+		return nil, false, nil
+	}
+
+	if deadline, _, err := s.codec.Decode(b); err == nil {
+		_ = s.cache.Set(ctx, token, b, min(s.ttl, time.Until(deadline)))
+	}
+
+	return b, true, nil
+}
+
+func (s *cachedSessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	if err := s.Store.Commit(token, b, expiry); err != nil {
+		return fmt.Errorf("committing session: %w", err)
+	}
+
+	_ = s.cache.Set(context.Background(), token, b, min(s.ttl, time.Until(expiry)))
+
+	return nil
+}
+
+func (s *cachedSessionStore) Delete(token string) error {
+	if err := s.cache.Del(context.Background(), token); err != nil {
+		return err
+	}
+
+	if err := s.Store.Delete(token); err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+
+	return nil
+}
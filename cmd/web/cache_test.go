@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+// sharedBackend stands in for a backend shared between instances, such as
+// Redis.
+type sharedBackend struct {
+	cache.Backend
+}
+
+func TestUseCache(t *testing.T) {
+	app := newTestApplication(t)
+	app.useCache(sharedBackend{cache.NewMemory(100)}, time.Minute)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	for range 2 {
+		code, _, _ := ts.get(t, "/snippet/stats/1")
+		assert.Equal(t, code, http.StatusOK)
+	}
+
+	stats := make(map[string]cache.Stats)
+	for _, c := range app.caches {
+		stats[c.Stats().Namespace] = c.Stats()
+	}
+
+	// The mock snippet has already expired, so it is never cached.
+	assert.Equal(t, stats["snippets"].Hits, uint64(0))
+	assert.Equal(t, stats["counts"].Hits, uint64(1))
+	assert.Equal(t, stats["counts"].Misses, uint64(1))
+	assert.Equal(t, stats["sessions"].Hits > 0, true)
+}
+
+func TestAdminCache(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/admin/cache")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Caching is disabled")
+
+	app.useCache(cache.NewMemory(100), time.Minute)
+
+	// Sessions are not cached in the memory of one instance.
+	_, _, body = ts.get(t, "/admin/cache")
+	assert.StringContains(t, body, "<td>snippets</td>")
+	assert.Equal(t, strings.Contains(body, "<td>sessions</td>"), false)
+}
+
+// countingSnippetModel serves a live snippet 1 and counts the lookups that
+// reach it.
+type countingSnippetModel struct {
+	mocks.SnippetModel
+	gets int
+}
+
+func (m *countingSnippetModel) Get(_ context.Context, id int) (models.Snippet, error) {
+	m.gets++
+
+	return models.Snippet{ID: id, Title: "Live", Expires: time.Now().Add(time.Hour)}, nil
+}
+
+func TestCachedSnippetModelEviction(t *testing.T) {
+	ctx := t.Context()
+	backend := &countingSnippetModel{}
+	m := &cachedSnippetModel{SnippetModelInterface: backend, cache: cache.New(cache.NewMemory(10), "snippets"), ttl: time.Minute}
+
+	for range 2 {
+		_, err := m.Get(ctx, 1)
+		assert.NilError(t, err)
+	}
+
+	assert.Equal(t, backend.gets, 1)
+
+	assert.NilError(t, m.Update(ctx, 1, time.Time{}, "Edited", "content", 7))
+	_, err := m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, backend.gets, 2)
+
+	assert.NilError(t, m.Delete(ctx, 1, time.Time{}))
+	_, err = m.Get(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, backend.gets, 3)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// canonicalExempt lists path prefixes, relative to the base path, whose URLs
+// are served as requested. API clients do not expect redirects, and the file
+// server redirects directories to a trailing slash itself.
+var canonicalExempt = []string{"/api/", "/static/"}
+
+// canonicalURL permanently redirects requests for a URL that has a trailing
+// slash or an upper-case host to the canonical one, e.g.
+// /snippet/view/1/ to /snippet/view/1, so each page has a single URL and a
+// stray slash does not end in a 404. The 308 keeps the method and body, and
+// the location is scheme-relative, so it also works behind a proxy that
+// terminates TLS.
+func (app *application) canonicalURL(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Paths outside the base path are left for mountBasePath to reject.
+		rel, ok := strings.CutPrefix(r.URL.Path, app.basePath)
+		if !ok || !strings.HasPrefix(rel, "/") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		for _, prefix := range canonicalExempt {
+			if strings.HasPrefix(rel, prefix) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+		}
+
+		u := *r.URL
+		host := strings.ToLower(r.Host)
+
+		if len(rel) > 1 && strings.HasSuffix(rel, "/") {
+			u.Path = app.basePath + "/" + strings.Trim(rel, "/")
+			u.RawPath = ""
+		}
+
+		if u.Path == r.URL.Path && host == r.Host {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		location := u.RequestURI()
+		if host != r.Host {
+			location = "//" + host + location
+		}
+
+		http.Redirect(w, r, location, http.StatusPermanentRedirect)
+	})
+}
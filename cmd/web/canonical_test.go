@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		basePath string
+		host     string
+		target   string
+		wantCode int
+		wantLoc  string
+	}{
+		{name: "Canonical", target: "/snippet/view/1", wantCode: http.StatusOK},
+		{name: "Root", target: "/", wantCode: http.StatusOK},
+		{name: "Trailing slash", target: "/snippet/view/1/", wantCode: http.StatusPermanentRedirect, wantLoc: "/snippet/view/1"},
+		{name: "Repeated slashes", target: "/about//", wantCode: http.StatusPermanentRedirect, wantLoc: "/about"},
+		{name: "Query kept", target: "/snippet/view/1/?page=2", wantCode: http.StatusPermanentRedirect, wantLoc: "/snippet/view/1?page=2"},
+		{name: "Upper-case host", host: "Snippets.Example.COM", target: "/about", wantCode: http.StatusPermanentRedirect, wantLoc: "//snippets.example.com/about"},
+		{name: "Both", host: "Snippets.Example.COM", target: "/about/", wantCode: http.StatusPermanentRedirect, wantLoc: "//snippets.example.com/about"},
+		{name: "API exempt", host: "Snippets.Example.COM", target: "/api/v1/whoami/", wantCode: http.StatusOK},
+		{name: "Static exempt", target: "/static/css/", wantCode: http.StatusOK},
+		{name: "Base path", basePath: "/app", target: "/app/about/", wantCode: http.StatusPermanentRedirect, wantLoc: "/app/about"},
+		{name: "Base path root", basePath: "/app", target: "/app/", wantCode: http.StatusOK},
+		{name: "Outside base path", basePath: "/app", target: "/other/", wantCode: http.StatusOK},
+		{name: "Base path API exempt", basePath: "/app", target: "/app/api/v1/whoami/", wantCode: http.StatusOK},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{basePath: tt.basePath}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.Host = "snippets.example.com"
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+
+			rr := httptest.NewRecorder()
+			app.canonicalURL(next).ServeHTTP(rr, r)
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+			assert.Equal(t, rr.Header().Get("Location"), tt.wantLoc)
+		})
+	}
+}
+
+func TestCanonicalURLRedirectServed(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, _ := ts.get(t, "/snippet/view/1/")
+	assert.Equal(t, code, http.StatusPermanentRedirect)
+	assert.Equal(t, header.Get("Location"), "/snippet/view/1")
+
+	code, _, _ = ts.get(t, header.Get("Location"))
+	assert.Equal(t, code, http.StatusOK)
+}
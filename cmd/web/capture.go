@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxCaptures bounds the number of request snapshots kept in memory.
+const maxCaptures = 100
+
+// capturedHeadersDenied lists the request headers that never make it into a
+// snapshot because they carry credentials.
+var capturedHeadersDenied = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"X-Csrf-Token",
+}
+
+// requestCapture is a sanitized snapshot of a request that caused a server
+// error. It keeps the names of query and form fields but not their values,
+// which may hold passwords or tokens.
+type requestCapture struct {
+	ID        string
+	RequestID string
+	Time      time.Time
+	Error     string
+	Method    string
+	Path      string
+	QueryKeys []string
+	FormKeys  []string
+	Headers   http.Header
+	UserID    int
+}
+
+// captureStore keeps request snapshots for a limited time so admins can
+// look them up by ID when reproducing a bug.
+type captureStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	captures []requestCapture
+	now      func() time.Time
+}
+
+func newCaptureStore(ttl time.Duration) *captureStore {
+	return &captureStore{ttl: ttl, now: time.Now}
+}
+
+// snapshot builds the sanitized capture of r. Captures get an ID of their
+// own, since a request ID may come from a proxy and need not be unique; the
+// request ID is kept to match the capture with the request's log records.
+func snapshot(r *http.Request, userID int, err error) requestCapture {
+	c := requestCapture{
+		ID:        newRequestID(),
+		RequestID: requestIDFromContext(r.Context()),
+		Error:     err.Error(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Headers:   r.Header.Clone(),
+		UserID:    userID,
+	}
+
+	for _, h := range capturedHeadersDenied {
+		c.Headers.Del(h)
+	}
+
+	for key := range r.URL.Query() {
+		c.QueryKeys = append(c.QueryKeys, key)
+	}
+
+	// The body is only looked at if a handler already parsed it.
+	for key := range r.PostForm {
+		c.FormKeys = append(c.FormKeys, key)
+	}
+
+	slices.Sort(c.QueryKeys)
+	slices.Sort(c.FormKeys)
+
+	return c
+}
+
+func (s *captureStore) add(c requestCapture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.Time = s.now()
+
+	s.expire(c.Time)
+	s.captures = append(s.captures, c)
+
+	if len(s.captures) > maxCaptures {
+		s.captures = slices.Delete(s.captures, 0, len(s.captures)-maxCaptures)
+	}
+}
+
+// expire drops captures older than the TTL. It must be called with mu held.
+func (s *captureStore) expire(now time.Time) {
+	s.captures = slices.DeleteFunc(s.captures, func(c requestCapture) bool {
+		return now.Sub(c.Time) > s.ttl
+	})
+}
+
+// list returns the captures that have not expired, newest first.
+func (s *captureStore) list() []requestCapture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+
+	out := slices.Clone(s.captures)
+	slices.Reverse(out)
+
+	return out
+}
+
+func (s *captureStore) get(id string) (requestCapture, bool) {
+	for _, c := range s.list() {
+		if c.ID == id {
+			return c, true
+		}
+	}
+
+	return requestCapture{}, false
+}
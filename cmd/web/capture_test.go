@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	r := httptest.NewRequest("POST", "/snippet/create?draft=1&token=secret", nil)
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("User-Agent", "Firefox")
+	r.PostForm = url.Values{"title": {"hello"}, "password": {"secret"}}
+
+	c := snapshot(r, 7, errors.New("boom"))
+
+	assert.Equal(t, len(c.ID), 16)
+	assert.Equal(t, c.Error, "boom")
+	assert.Equal(t, c.Path, "/snippet/create")
+	assert.Equal(t, c.UserID, 7)
+	assert.Equal(t, c.Headers.Get("Cookie"), "")
+	assert.Equal(t, c.Headers.Get("Authorization"), "")
+	assert.Equal(t, c.Headers.Get("User-Agent"), "Firefox")
+	assert.Equal(t, len(c.QueryKeys), 2)
+	assert.Equal(t, c.QueryKeys[1], "token")
+	assert.Equal(t, len(c.FormKeys), 2)
+	assert.Equal(t, c.FormKeys[0], "password")
+}
+
+func TestCaptureStore(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	s := newCaptureStore(time.Hour)
+	s.now = func() time.Time { return now }
+
+	s.add(requestCapture{ID: "old"})
+
+	now = now.Add(30 * time.Minute)
+	s.add(requestCapture{ID: "new"})
+
+	list := s.list()
+	assert.Equal(t, len(list), 2)
+	assert.Equal(t, list[0].ID, "new")
+
+	now = now.Add(45 * time.Minute)
+
+	_, ok := s.get("old")
+	assert.Equal(t, ok, false)
+
+	c, ok := s.get("new")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, c.ID, "new")
+
+	for range maxCaptures + 10 {
+		s.add(requestCapture{ID: newRequestID()})
+	}
+
+	assert.Equal(t, len(s.list()), maxCaptures)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/client"
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+// TestClient checks that the client package agrees with the API it wraps.
+func TestClient(t *testing.T) {
+	app := newTestApplication(t)
+	app.tokenLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	c := client.New(ts.URL, mocks.MockTokenPlaintext)
+	c.HTTPClient = ts.Client()
+	ctx := t.Context()
+
+	account, err := c.Whoami(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, account.ID, 1)
+
+	status, err := c.Status(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Database.Status, "ok")
+
+	snippets, err := c.ListSnippets(ctx, client.Page{Limit: 5})
+	assert.NilError(t, err)
+	assert.Equal(t, len(snippets), 1)
+	assert.Equal(t, snippets[0].Title, "An old silent pond")
+
+	_, err = c.GetSnippet(ctx, 2)
+	assert.Equal(t, client.IsNotFound(err), true)
+
+	id, err := c.CreateSnippet(ctx, client.NewSnippet{Title: "Haiku", Content: "Over the wintry forest", Expires: 7})
+	assert.NilError(t, err)
+	assert.Equal(t, id, 2)
+
+	ids, err := c.CreateSnippets(ctx, []client.NewSnippet{
+		{Title: "One", Content: "1", Expires: 1},
+		{Title: "Two", Content: "2", Expires: 365},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(ids), 2)
+
+	updated, err := c.UpdateSnippet(ctx, 1, client.NewSnippet{Title: "Haiku", Content: "Over the wintry forest", Expires: 7})
+	assert.NilError(t, err)
+	assert.Equal(t, updated.ID, 1)
+
+	results, err := c.Search(ctx, client.Search{Query: "pond", Sort: client.SortNewest})
+	assert.NilError(t, err)
+	assert.Equal(t, results.Total, 1)
+	assert.Equal(t, results.Results[0].Score, 0.5)
+
+	hook, err := c.CreateWebhook(ctx, "https://example.com/hook", client.EventSnippetCreated)
+	assert.NilError(t, err)
+	assert.Equal(t, hook.Secret, "whsec_new")
+
+	result, err := c.TestWebhook(ctx, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, result.Delivered, true)
+
+	assert.NilError(t, c.DeleteSnippet(ctx, 1))
+}
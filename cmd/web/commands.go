@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// command is a subcommand of the binary. Every command that uses the
+// database accepts the same flags, config file and environment variables as
+// serve, so they all talk to the same database.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "Run the web server (the default)", cmdServe},
+	{"migrate", "Apply or revert database migrations: migrate up|down|status", cmdMigrate},
+	{"createadmin", "Create an admin user, or make an existing user one", cmdCreateAdmin},
+	{"seed", "Insert sample snippets for development", cmdSeed},
+	{"assets", "Rebuild the minified CSS and JavaScript bundles in ui/static/dist", cmdAssets},
+}
+
+// run dispatches to the subcommand named by the first argument. Without
+// one, or when the first argument is a flag, the server is started, so
+// existing `web -addr=...` invocations keep working.
+func run(args []string) error {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args)
+		}
+	}
+
+	if name == "help" {
+		printCommands(os.Stdout)
+
+		return nil
+	}
+
+	printCommands(os.Stderr)
+
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", os.Args[0])
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.usage)
+	}
+
+	fmt.Fprintf(w, "\nRun '%s serve -help' for the flags shared by all commands.\n", os.Args[0])
+}
+
+// withDB parses the shared configuration from args and calls fn with a
+// connection pool to the configured database.
+func withDB(args []string, fn func(ctx context.Context, db *pgxpool.Pool) error) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	// Commands often run in an init container next to a database that is
+	// still starting, so they wait for it like the server does.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	db, err := connectDB(logger, cfg.dsn, nil, nil, cfg.dbTimeout)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(context.Background(), db)
+}
+
+// migrateUp applies pending migrations when the server starts, so a new
+// release brings its own schema changes along.
+func migrateUp(ctx context.Context, logger *slog.Logger, db *pgxpool.Pool) error {
+	applied, err := models.MigrateUp(ctx, db)
+
+	for _, m := range applied {
+		logger.Info("applied database migration", slog.String("migration", m.String()))
+	}
+
+	return err //nolint:wrapcheck // already wrapped by the models package
+}
+
+func cmdMigrate(args []string) error {
+	direction := ""
+	if len(args) > 0 {
+		direction, args = args[0], args[1:]
+	}
+
+	switch direction {
+	case "up":
+		return withDB(args, func(ctx context.Context, db *pgxpool.Pool) error {
+			applied, err := models.MigrateUp(ctx, db)
+			printMigrations("Applied", applied)
+
+			return err //nolint:wrapcheck // already wrapped by the models package
+		})
+	case "down":
+		steps := flag.Int("steps", 1, "Number of migrations to revert")
+
+		return withDB(args, func(ctx context.Context, db *pgxpool.Pool) error {
+			reverted, err := models.MigrateDown(ctx, db, *steps)
+			printMigrations("Reverted", reverted)
+
+			return err //nolint:wrapcheck // already wrapped by the models package
+		})
+	case "status":
+		return withDB(args, migrationStatus)
+	default:
+		return errors.New("usage: migrate up|down|status [flags]")
+	}
+}
+
+func printMigrations(verb string, migrations []models.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("Nothing to do.")
+
+		return
+	}
+
+	for _, m := range migrations {
+		fmt.Printf("%s %s\n", verb, m)
+	}
+}
+
+func migrationStatus(ctx context.Context, db *pgxpool.Pool) error {
+	migrations, err := models.Migrations()
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped by the models package
+	}
+
+	version, err := models.SchemaVersion(ctx, db)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped by the models package
+	}
+
+	for _, m := range migrations {
+		state := "pending"
+		if m.Version <= version {
+			state = "applied"
+		}
+
+		fmt.Printf("%-8s %s\n", state, m)
+	}
+
+	if version > len(migrations) {
+		fmt.Printf("The database is at version %d, newer than this binary.\n", version)
+	}
+
+	return nil
+}
+
+func cmdSeed(args []string) error {
+	return withDB(args, func(ctx context.Context, db *pgxpool.Pool) error {
+		if err := models.Seed(ctx, db); err != nil {
+			return err //nolint:wrapcheck // already wrapped by the models package
+		}
+
+		fmt.Println("Sample snippets inserted.")
+
+		return nil
+	})
+}
+
+type createAdminForm struct {
+	Name     string
+	Email    string
+	Password string
+	validator.Validator
+}
+
+func cmdCreateAdmin(args []string) error {
+	var form createAdminForm
+
+	flag.StringVar(&form.Name, "name", "Admin", "Name of the admin user")
+	flag.StringVar(&form.Email, "email", "", "Email address of the admin user")
+	flag.StringVar(&form.Password, "password", "", "Password of a new admin user (read from stdin if empty)")
+
+	return withDB(args, func(_ context.Context, db *pgxpool.Pool) error {
+		users := &models.UserModel{DB: db}
+
+		return createAdmin(users, &form, os.Stdin, os.Stdout)
+	})
+}
+
+// createAdmin makes the user with form.Email an admin, creating the account
+// first if there is none. The password is only needed for a new account and
+// is read from in when not given as a flag, to keep it out of shell history.
+func createAdmin(users models.UserModelInterface, form *createAdminForm, in io.Reader, out io.Writer) error {
+	form.CheckField(validator.NotBlank(form.Email), "email", "-email is required")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "-email must be a valid email address")
+
+	if !form.Valid() {
+		return errors.New(form.FieldErrors["email"])
+	}
+
+	id, err := users.SetAdmin(form.Email, true)
+	if err == nil {
+		fmt.Fprintf(out, "User %d (%s) is now an admin.\n", id, form.Email)
+
+		return nil
+	}
+
+	if !errors.Is(err, models.ErrNoRecord) {
+		return err //nolint:wrapcheck // already wrapped by the models package
+	}
+
+	if form.Password == "" {
+		fmt.Fprint(out, "Password: ")
+
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading password: %w", err)
+		}
+
+		form.Password = strings.TrimRight(line, "\r\n")
+	}
+
+	if !validator.MinChars(form.Password, 8) {
+		return errors.New("the password must be at least 8 characters long")
+	}
+
+	// Admins created here have not seen the terms of service, so they are
+	// asked to accept them on their first login.
+	if _, err := users.Insert(form.Name, form.Email, form.Password, 0); err != nil {
+		return err //nolint:wrapcheck // already wrapped by the models package
+	}
+
+	id, err = users.SetAdmin(form.Email, true)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped by the models package
+	}
+
+	fmt.Fprintf(out, "Created admin user %d (%s).\n", id, form.Email)
+
+	return nil
+}
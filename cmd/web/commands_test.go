@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+func TestRunUnknownCommand(t *testing.T) {
+	err := run([]string{"frobnicate"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	assert.StringContains(t, err.Error(), `unknown command "frobnicate"`)
+}
+
+func TestCreateAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    createAdminForm
+		stdin   string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "Existing user",
+			form:    createAdminForm{Email: "alice@example.com"},
+			wantOut: "User 1 (alice@example.com) is now an admin.",
+		},
+		{
+			name:    "Missing email",
+			wantErr: "-email is required",
+		},
+		{
+			name:    "Invalid email",
+			form:    createAdminForm{Email: "alice"},
+			wantErr: "-email must be a valid email address",
+		},
+		{
+			name:    "Short password from stdin",
+			form:    createAdminForm{Email: "new@example.com"},
+			stdin:   "short\n",
+			wantOut: "Password: ",
+			wantErr: "at least 8 characters",
+		},
+		{
+			name:    "Insert fails",
+			form:    createAdminForm{Email: "dupe@example.com", Password: "validPa$$word"},
+			wantErr: "duplicate email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := createAdmin(&mocks.UserModel{}, &tt.form, strings.NewReader(tt.stdin), &out)
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			case tt.wantErr != "":
+				assert.StringContains(t, err.Error(), tt.wantErr)
+			}
+
+			assert.StringContains(t, out.String(), tt.wantOut)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressSize is the smallest response worth compressing; anything
+// shorter fits in a single packet anyway.
+const minCompressSize = 1024
+
+// compressibleTypes are the media types compressed by compress. Everything
+// else, such as images and fonts, is either already compressed or rare.
+var compressibleTypes = map[string]bool{
+	"text/html":              true,
+	"text/plain":             true,
+	"text/css":               true,
+	"text/javascript":        true,
+	"text/xml":               true,
+	"application/javascript": true,
+	"application/json":       true,
+	"application/xml":        true,
+	"image/svg+xml":          true,
+}
+
+// encoder is what compress needs from gzip.Writer and zlib.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	// HTTP's "deflate" is the zlib format, not raw DEFLATE.
+	"deflate": {New: func() any {
+		return zlib.NewWriter(io.Discard)
+	}},
+}
+
+// encodedETag returns the entity tag compress gives the representation
+// tagged etag once it is compressed with encoding. The tag stays strong, so
+// clients can send it back in If-Match; ifMatch and etagMatch accept it for
+// etag.
+func encodedETag(etag, encoding string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip, or returns "" if the client accepts neither.
+func negotiateEncoding(accept string) string {
+	accepted := make(map[string]bool)
+
+	for part := range strings.SplitSeq(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		accepted[strings.ToLower(strings.TrimSpace(coding))] = q > 0
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	case accepted["*"]:
+		if _, refused := accepted["gzip"]; !refused {
+			return "gzip"
+		}
+	}
+
+	return ""
+}
+
+// compress compresses responses for clients that accept gzip or deflate.
+// Range requests are passed through untouched, since byte ranges refer to
+// the uncompressed content.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// An upgraded connection is hijacked, so there is no response body
+		// to compress.
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" ||
+			r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+
+		next.ServeHTTP(cw, r)
+
+		// Not deferred: after a panic recoverPanic should get to write a
+		// clean error response if nothing has been sent yet.
+		cw.close()
+	})
+}
+
+// compressWriter holds back the start of the response until it knows
+// whether compressing it is worthwhile, then writes it either through an
+// encoder or straight to the underlying writer.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	decided  bool
+	enc      encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.decided || cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	cw.status = status
+
+	// Informational and bodyless responses are never compressed.
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide()
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.decided {
+		return cw.write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) < minCompressSize {
+		return len(b), nil
+	}
+
+	cw.decide()
+
+	return len(b), nil
+}
+
+func (cw *compressWriter) write(b []byte) (int, error) {
+	if cw.enc != nil {
+		return cw.enc.Write(b) //nolint:wrapcheck // pass the encoder's error through
+	}
+
+	return cw.ResponseWriter.Write(b) //nolint:wrapcheck // pass the underlying writer's error through
+}
+
+// decide chooses whether to compress from what has been buffered so far,
+// writes the header and flushes the buffer.
+func (cw *compressWriter) decide() {
+	cw.decided = true
+
+	h := cw.Header()
+
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+
+	if len(cw.buf) >= minCompressSize &&
+		cw.status != http.StatusPartialContent &&
+		h.Get("Content-Encoding") == "" &&
+		compressibleTypes[mediaType] {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+
+		// The compressed bytes differ from the identity representation, so
+		// they get a strong validator of their own.
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", encodedETag(etag, cw.encoding))
+		}
+
+		cw.enc = encoderPools[cw.encoding].Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) > 0 {
+		_, _ = cw.write(cw.buf)
+	}
+
+	cw.buf = nil
+}
+
+// close writes out whatever is still buffered and returns the encoder to
+// its pool.
+func (cw *compressWriter) close() {
+	if !cw.decided && (cw.status != 0 || len(cw.buf) > 0) {
+		cw.decide()
+	}
+
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		encoderPools[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
+
+// Flush sends what has been written so far, compressed or not, to the
+// client.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide()
+	}
+
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
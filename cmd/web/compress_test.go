@@ -0,0 +1,245 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"GZIP", "gzip"},
+		{"br", ""},
+		{"*", "gzip"},
+		{"gzip;q=0, *", ""},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, negotiateEncoding(tt.accept), tt.want)
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := "<!doctype html><p>" + strings.Repeat("snippet ", 500)
+
+	tests := []struct {
+		name         string
+		method       string
+		accept       string
+		rangeHeader  string
+		contentType  string
+		encoding     string
+		status       int
+		body         string
+		wantEncoding string
+	}{
+		{
+			name:         "Gzip",
+			accept:       "gzip, deflate",
+			contentType:  "text/html; charset=utf-8",
+			body:         large,
+			wantEncoding: "gzip",
+		},
+		{
+			name:         "Deflate",
+			accept:       "deflate",
+			contentType:  "text/plain; charset=utf-8",
+			body:         large,
+			wantEncoding: "deflate",
+		},
+		{
+			name:         "Sniffed content type",
+			accept:       "gzip",
+			body:         large,
+			wantEncoding: "gzip",
+		},
+		{
+			name:        "Not accepted",
+			contentType: "text/html",
+			body:        large,
+		},
+		{
+			name:        "Small body",
+			accept:      "gzip",
+			contentType: "text/html",
+			body:        "<p>Hello</p>",
+		},
+		{
+			name:        "Already compressed type",
+			accept:      "gzip",
+			contentType: "image/png",
+			body:        large,
+		},
+		{
+			name:        "Already encoded",
+			accept:      "gzip",
+			contentType: "text/html",
+			encoding:    "br",
+			body:        large,
+		},
+		{
+			name:        "Range request",
+			accept:      "gzip",
+			rangeHeader: "bytes=0-99",
+			contentType: "text/html",
+			body:        large,
+		},
+		{
+			name:        "Head",
+			method:      http.MethodHead,
+			accept:      "gzip",
+			contentType: "text/html",
+		},
+		{
+			name:   "Not modified",
+			accept: "gzip",
+			status: http.StatusNotModified,
+		},
+		{
+			name:         "Error status",
+			accept:       "gzip",
+			contentType:  "text/html",
+			status:       http.StatusNotFound,
+			body:         large,
+			wantEncoding: "gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, method, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.Header.Set("Accept-Encoding", tt.accept)
+			r.Header.Set("Range", tt.rangeHeader)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Header().Set("Content-Length", "12345")
+				w.Header().Set("ETag", `"v1"`)
+
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+
+				// Write in small pieces to exercise the buffering.
+				for chunk := range slices.Chunk([]byte(tt.body), 100) {
+					_, _ = w.Write(chunk)
+				}
+			})
+
+			rr := httptest.NewRecorder()
+			compress(next).ServeHTTP(rr, r)
+
+			rs := rr.Result()
+			defer rs.Body.Close()
+
+			wantStatus := tt.status
+			if wantStatus == 0 {
+				wantStatus = http.StatusOK
+			}
+
+			assert.Equal(t, rs.StatusCode, wantStatus)
+			assert.Equal(t, rs.Header.Get("Vary"), "Accept-Encoding")
+
+			if tt.wantEncoding == "" {
+				if tt.encoding == "" {
+					assert.Equal(t, rs.Header.Get("Content-Encoding"), "")
+				}
+
+				assert.Equal(t, rr.Body.String(), tt.body)
+
+				return
+			}
+
+			assert.Equal(t, rs.Header.Get("Content-Encoding"), tt.wantEncoding)
+			assert.Equal(t, rs.Header.Get("Content-Length"), "")
+			assert.Equal(t, rs.Header.Get("ETag"), `"v1-`+tt.wantEncoding+`"`)
+
+			var zr io.Reader
+
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err = gzip.NewReader(rr.Body)
+			case "deflate":
+				zr, err = zlib.NewReader(rr.Body)
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, string(body), tt.body)
+			assert.Equal(t, rr.Body.Len() < len(tt.body)/4, true)
+		})
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "partial")
+
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	compress(next).ServeHTTP(rr, r)
+
+	// A flush before the threshold commits to an uncompressed response.
+	assert.Equal(t, rr.Flushed, true)
+	assert.Equal(t, rr.Header().Get("Content-Encoding"), "")
+	assert.Equal(t, rr.Body.String(), "partial")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// snippetETag returns a strong entity tag for the content of a snippet.
+func snippetETag(s models.Snippet) string {
+	return fmt.Sprintf(`"%s-%x"`, s.ContentHash(), s.Updated.UnixNano())
+}
+
+// snippetJSONETag is snippetETag for the JSON document of a snippet, which
+// is a different representation and so needs a different strong tag.
+func snippetJSONETag(s models.Snippet) string {
+	return fmt.Sprintf(`"%s-%x-json"`, s.ContentHash(), s.Updated.UnixNano())
+}
+
+// viewETag returns the entity tag of the snippet page as rendered for data.
+// Besides the snippet it covers everything about the viewer that changes the
+// page. The tag is weak because every rendering carries a freshly masked
+// CSRF token, so two renderings are equivalent but never byte-identical.
+func viewETag(s models.Snippet, data templateData) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%d|%s|%v|%v",
+		snippetETag(s), data.AuthenticatedUserID, data.Locale.Tag, data.Preferences, data.Settings))
+
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the ETag and, unless modified is zero, the Last-Modified
+// header, then evaluates the request's If-None-Match and If-Modified-Since
+// preconditions. If the client's copy is still current it writes a 304 Not
+// Modified response and returns true.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-Modified-Since is only considered when there is no If-None-Match,
+	// as required by RFC 9110.
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatch(inm, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || modified.IsZero() || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// ifMatch evaluates the If-Match header of a request that changes a resource
+// whose current entity tag is etag, reporting whether the change may go
+// ahead. Clients send the tag of the copy they edited, so a change based on
+// an outdated copy is refused rather than silently overwriting someone
+// else's. As RFC 9110 requires, the comparison is strong and a missing
+// header imposes no condition. The tags compress gives the compressed
+// representations count as the resource's own.
+func ifMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || representationTag(candidate, etag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagMatch reports whether the If-None-Match header lists etag, using the
+// weak comparison that RFC 9110 prescribes for GET requests.
+func etagMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || representationTag(strings.TrimPrefix(candidate, "W/"), etag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// representationTag reports whether tag is etag or the tag of one of its
+// compressed representations.
+func representationTag(tag, etag string) bool {
+	if tag == etag {
+		return true
+	}
+
+	for encoding := range encoderPools {
+		if tag == encodedETag(etag, encoding) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	etag := `"abc-1"`
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		modified time.Time
+		want     bool
+	}{
+		{
+			name:     "No preconditions",
+			modified: modified,
+		},
+		{
+			name:     "Matching ETag",
+			header:   http.Header{"If-None-Match": {etag}},
+			modified: modified,
+			want:     true,
+		},
+		{
+			name:     "Weak match",
+			header:   http.Header{"If-None-Match": {`W/"abc-1"`}},
+			modified: modified,
+			want:     true,
+		},
+		{
+			name:     "One of several",
+			header:   http.Header{"If-None-Match": {`"xyz", "abc-1"`}},
+			modified: modified,
+			want:     true,
+		},
+		{
+			name:     "Wildcard",
+			header:   http.Header{"If-None-Match": {"*"}},
+			modified: modified,
+			want:     true,
+		},
+		{
+			name:     "Stale ETag",
+			header:   http.Header{"If-None-Match": {`"abc-0"`}},
+			modified: modified,
+		},
+		{
+			name: "Stale ETag wins over If-Modified-Since",
+			header: http.Header{
+				"If-None-Match":     {`"abc-0"`},
+				"If-Modified-Since": {modified.Format(http.TimeFormat)},
+			},
+			modified: modified,
+		},
+		{
+			name:     "Not modified since",
+			header:   http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}},
+			modified: modified,
+			want:     true,
+		},
+		{
+			name:     "Modified since",
+			header:   http.Header{"If-Modified-Since": {modified.Add(-time.Hour).Format(http.TimeFormat)}},
+			modified: modified,
+		},
+		{
+			name:     "Invalid date",
+			header:   http.Header{"If-Modified-Since": {"yesterday"}},
+			modified: modified,
+		},
+		{
+			name:   "No modification time",
+			header: http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.Header = tt.header
+
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, notModified(rr, r, etag, tt.modified), tt.want)
+			assert.Equal(t, rr.Header().Get("ETag"), etag)
+
+			if tt.want {
+				assert.Equal(t, rr.Code, http.StatusNotModified)
+			}
+
+			if !tt.modified.IsZero() {
+				assert.Equal(t, rr.Header().Get("Last-Modified"), "Sun, 01 Mar 2026 12:00:00 GMT")
+			}
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	etag := `"abc-1"`
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "No header", want: true},
+		{name: "Matching", header: etag, want: true},
+		{name: "One of several", header: `"xyz", "abc-1"`, want: true},
+		{name: "Any", header: "*", want: true},
+		{name: "Stale", header: `"abc-0"`},
+		{name: "Weak tags never match", header: `W/"abc-1"`},
+		{name: "Compressed", header: `"abc-1-gzip"`, want: true},
+		{name: "Weak compressed", header: `W/"abc-1-gzip"`},
+		{name: "Unknown encoding", header: `"abc-1-br"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, http.MethodPut, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			assert.Equal(t, ifMatch(r, etag), tt.want)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the name of the environment variable of every flag, e.g.
+// SNIPPETBOX_IP_RATE for -ip-rate.
+const envPrefix = "SNIPPETBOX_"
+
+// envName returns the environment variable that sets the flag name.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyConfigLayers fills in the flags of fs that were not given on the
+// command line, first from the config file at path, if any, then from the
+// environment. Together with the defaults the resulting precedence is
+// defaults < config file < environment < command line. fs must already
+// have been parsed.
+func applyConfigLayers(fs *flag.FlagSet, path string, lookupEnv func(string) (string, bool)) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range values {
+			if fs.Lookup(name) == nil {
+				return fmt.Errorf("%s: unknown setting %q", path, name)
+			}
+
+			if explicit[name] {
+				continue
+			}
+
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("%s: invalid value for %s: %w", path, name, err)
+			}
+		}
+	}
+
+	var errs []error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if explicit[f.Name] {
+			return
+		}
+
+		value, ok := lookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for %s: %w", envName(f.Name), err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// readConfigFile reads a YAML or TOML config file, chosen by extension. Its
+// keys are flag names without the dash and its values must be scalars, e.g.
+//
+//	addr: ":8080"
+//	ip-rate: 5
+//	shutdown-timeout: 30s
+func readConfigFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]any
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &raw)
+	case ".toml":
+		err = toml.Unmarshal(b, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, use .yaml or .toml", path, ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+
+	for name, value := range raw {
+		switch value.(type) {
+		case nil:
+			values[name] = ""
+		case map[string]any, []any:
+			return nil, fmt.Errorf("%s: %s must be a single value", path, name)
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+
+	return values, nil
+}
+
+// validate rejects combinations of settings that cannot work together, so
+// mistakes are reported at startup rather than on first use.
+func (cfg config) validate() error {
+	switch {
+	case cfg.autoTLS.enabled() && (cfg.certFile != "" || cfg.keyFile != ""):
+		return errors.New("-auto-tls-domain cannot be combined with -tls or -tls-cert/-tls-key")
+	case cfg.useTLS && !cfg.autoTLS.enabled() && (cfg.certFile == "" || cfg.keyFile == ""):
+		return errors.New("-tls-cert and -tls-key must be set together")
+	case cfg.httpRedirectAddr != "" && !cfg.useTLS:
+		return errors.New("-http-redirect-addr needs TLS to be enabled")
+	case cfg.acmeWebroot != "" && cfg.httpRedirectAddr == "":
+		return errors.New("-acme-webroot needs -http-redirect-addr")
+	case cfg.logBufferSize < 0:
+		return errors.New("-log-buffer cannot be negative")
+	case cfg.tokenRate <= 0 || cfg.adminTokenRate <= 0:
+		return errors.New("-token-rate and -admin-token-rate must be positive")
+	case cfg.apiIPRate < 0:
+		return errors.New("-api-ip-rate cannot be negative")
+	case cfg.ipRate < 0:
+		return errors.New("-ip-rate cannot be negative")
+	case cfg.dbTimeout < 0:
+		return errors.New("-db-connect-timeout cannot be negative")
+	case cfg.grpcAddr != "" && cfg.grpcToken == "" && !isLoopbackAddr(cfg.grpcAddr):
+		// The service can create and delete any user's snippets.
+		return errors.New("-grpc-token must be set when -grpc-addr is reachable beyond this host")
+	}
+
+	if err := cfg.sessions.validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.jobs.validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.bodyLog.validate(); err != nil {
+		return err
+	}
+
+	return cfg.timeouts.validate()
+}
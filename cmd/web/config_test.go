@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, envName("addr"), "SNIPPETBOX_ADDR")
+	assert.Equal(t, envName("shutdown-timeout"), "SNIPPETBOX_SHUTDOWN_TIMEOUT")
+}
+
+func TestApplyConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile := func(name, content string) string {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	yamlFile := writeFile("config.yaml", "addr: \":8080\"\nip-rate: 2.5\ndebug: true\nshutdown-timeout: 30s\n")
+	tomlFile := writeFile("config.toml", "addr = \":9090\"\nip-rate = 4\n")
+
+	tests := []struct {
+		name         string
+		args         []string
+		file         string
+		env          map[string]string
+		wantAddr     string
+		wantRate     float64
+		wantDebug    bool
+		wantShutdown time.Duration
+	}{
+		{
+			name:         "Defaults",
+			wantAddr:     ":4001",
+			wantRate:     10,
+			wantShutdown: 20 * time.Second,
+		},
+		{
+			name:         "YAML file",
+			file:         yamlFile,
+			wantAddr:     ":8080",
+			wantRate:     2.5,
+			wantDebug:    true,
+			wantShutdown: 30 * time.Second,
+		},
+		{
+			name:         "TOML file",
+			file:         tomlFile,
+			wantAddr:     ":9090",
+			wantRate:     4,
+			wantShutdown: 20 * time.Second,
+		},
+		{
+			name:         "Environment overrides file",
+			file:         yamlFile,
+			env:          map[string]string{"SNIPPETBOX_ADDR": ":7070", "SNIPPETBOX_DEBUG": "false"},
+			wantAddr:     ":7070",
+			wantRate:     2.5,
+			wantShutdown: 30 * time.Second,
+		},
+		{
+			name:         "Flags override everything",
+			args:         []string{"-addr=:6060", "-ip-rate=1"},
+			file:         yamlFile,
+			env:          map[string]string{"SNIPPETBOX_ADDR": ":7070"},
+			wantAddr:     ":6060",
+			wantRate:     1,
+			wantDebug:    true,
+			wantShutdown: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			addr := fs.String("addr", ":4001", "")
+			rate := fs.Float64("ip-rate", 10, "")
+			debug := fs.Bool("debug", false, "")
+			shutdown := fs.Duration("shutdown-timeout", 20*time.Second, "")
+
+			assert.NilError(t, fs.Parse(tt.args))
+
+			lookupEnv := func(key string) (string, bool) {
+				v, ok := tt.env[key]
+
+				return v, ok
+			}
+
+			assert.NilError(t, applyConfigLayers(fs, tt.file, lookupEnv))
+
+			assert.Equal(t, *addr, tt.wantAddr)
+			assert.Equal(t, *rate, tt.wantRate)
+			assert.Equal(t, *debug, tt.wantDebug)
+			assert.Equal(t, *shutdown, tt.wantShutdown)
+		})
+	}
+}
+
+func TestDeprecatedFlags(t *testing.T) {
+	var out strings.Builder
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+
+	certFile := fs.String("tls-cert", "", "")
+	fs.StringVar(certFile, "cert", "", "")
+
+	assert.NilError(t, fs.Parse([]string{"-cert=old.pem"}))
+	warnDeprecatedFlags(fs)
+
+	assert.Equal(t, *certFile, "old.pem")
+	assert.Equal(t, out.String(), "warning: -cert is deprecated, use -tls-cert\n")
+}
+
+func TestApplyConfigLayersErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+	}{
+		{name: "Unknown setting", file: "config.yaml", content: "adr: \":8080\"\n"},
+		{name: "Invalid value", file: "config.yaml", content: "ip-rate: fast\n"},
+		{name: "Nested value", file: "config.yaml", content: "addr:\n  host: localhost\n"},
+		{name: "Broken file", file: "config.toml", content: "addr = \n"},
+		{name: "Unsupported format", file: "config.json", content: "{}"},
+		{name: "Invalid environment", env: map[string]string{"SNIPPETBOX_IP_RATE": "fast"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			fs.String("addr", ":4001", "")
+			fs.Float64("ip-rate", 10, "")
+
+			var path string
+
+			if tt.file != "" {
+				path = filepath.Join(dir, tt.file)
+				assert.NilError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			}
+
+			lookupEnv := func(key string) (string, bool) {
+				v, ok := tt.env[key]
+
+				return v, ok
+			}
+
+			assert.Equal(t, applyConfigLayers(fs, path, lookupEnv) != nil, true)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := config{
+		timeouts:       timeoutConfig{write: 10 * time.Second, handler: 8 * time.Second},
+		sessions:       sessionConfig{lifetime: 12 * time.Hour},
+		tokenRate:      1,
+		adminTokenRate: 5,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*config)
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(*config) {}},
+		{name: "Half a certificate", modify: func(c *config) { c.useTLS, c.certFile = true, "cert.pem" }, wantErr: true},
+		{name: "Redirect without TLS", modify: func(c *config) { c.httpRedirectAddr = ":80" }, wantErr: true},
+		{name: "Webroot without redirect", modify: func(c *config) { c.acmeWebroot = "/srv/acme" }, wantErr: true},
+		{name: "Negative rate", modify: func(c *config) { c.ipRate = -1 }, wantErr: true},
+		{name: "Zero token rate", modify: func(c *config) { c.adminTokenRate = 0 }, wantErr: true},
+		{name: "Handler timeout too long", modify: func(c *config) { c.timeouts.handler = time.Minute }, wantErr: true},
+		{name: "Idle timeout too long", modify: func(c *config) { c.sessions.idleTimeout = 24 * time.Hour }, wantErr: true},
+		{name: "Invalid job schedule", modify: func(c *config) { c.jobs.purgeSchedule = "nightly" }, wantErr: true},
+		{name: "Body log rate above one", modify: func(c *config) { c.bodyLog.rate = 2 }, wantErr: true},
+		{name: "Local gRPC without token", modify: func(c *config) { c.grpcAddr = "localhost:50051" }},
+		{name: "Public gRPC without token", modify: func(c *config) { c.grpcAddr = ":50051" }, wantErr: true},
+		{name: "Public gRPC with token", modify: func(c *config) { c.grpcAddr, c.grpcToken = ":50051", "s3cret" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			assert.Equal(t, cfg.validate() != nil, tt.wantErr)
+		})
+	}
+}
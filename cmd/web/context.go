@@ -2,4 +2,12 @@ package main
 
 type contextKey string
 
-const isAuthenticatedContextKey = contextKey("isAuthenticated")
+const (
+	isAuthenticatedContextKey = contextKey("isAuthenticated")
+	apiTokenContextKey        = contextKey("apiToken")
+	apiUserContextKey         = contextKey("apiUser")
+	pathParamsContextKey      = contextKey("pathParams")
+	uploadsContextKey         = contextKey("uploads")
+	requestLogContextKey      = contextKey("requestLog")
+	requestIDContextKey       = contextKey("requestID")
+)
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/securecookie"
+)
+
+// bannerCookie remembers which site banner a visitor dismissed.
+const bannerCookie = "banner_dismissed"
+
+// bannerDismissalAge is how long a dismissed banner stays hidden.
+const bannerDismissalAge = 30 * 24 * time.Hour
+
+// newCookieCodec returns the codec for cookies set with setSecureCookie,
+// deriving separate signing and encryption keys from secret. Without a
+// secret a random one is used, so such cookies stop being accepted when the
+// server restarts and differ between instances.
+func newCookieCodec(secret string) (*securecookie.Codec, error) {
+	key := []byte(secret)
+
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generating cookie secret: %w", err)
+		}
+	}
+
+	derive := func(purpose string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(purpose))
+
+		return h.Sum(nil)
+	}
+
+	codec, err := securecookie.New(derive("snippetbox cookie signing"), derive("snippetbox cookie encryption"))
+	if err != nil {
+		return nil, fmt.Errorf("creating cookie codec: %w", err)
+	}
+
+	return codec, nil
+}
+
+// setSecureCookie stores value in a signed and encrypted cookie for small
+// bits of state that do not justify a session, such as dismissed banners.
+func (app *application) setSecureCookie(w http.ResponseWriter, name string, value []byte, maxAge time.Duration) error {
+	encoded, err := app.cookies.Encode(name, value)
+	if err != nil {
+		return err //nolint:wrapcheck // already wrapped by the securecookie package
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    encoded,
+		Path:     app.cookiePath(),
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   app.sessionManager.Cookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// secureCookie returns the value of a cookie set by setSecureCookie. A
+// missing, altered or expired cookie is reported as absent.
+func (app *application) secureCookie(r *http.Request, name string, maxAge time.Duration) ([]byte, bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, false
+	}
+
+	value, err := app.cookies.Decode(name, cookie.Value, maxAge)
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// bannerID identifies the text of a banner, so that dismissing one banner
+// does not hide the next one the admins publish.
+func bannerID(banner string) string {
+	sum := sha256.Sum256([]byte(banner))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// bannerDismissed reports whether the visitor dismissed banner.
+func (app *application) bannerDismissed(r *http.Request, banner string) bool {
+	if banner == "" {
+		return false
+	}
+
+	value, ok := app.secureCookie(r, bannerCookie, bannerDismissalAge)
+
+	return ok && string(value) == bannerID(banner)
+}
+
+func (app *application) bannerDismissPost(w http.ResponseWriter, r *http.Request) {
+	banner := app.currentSettings().Banner
+	if banner != "" {
+		if err := app.setSecureCookie(w, bannerCookie, []byte(bannerID(banner)), bannerDismissalAge); err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+	}
+
+	path := app.sameOriginRefererPath(r)
+	if path == "" || path == r.URL.Path {
+		path = "/"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
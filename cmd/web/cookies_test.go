@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+func TestNewCookieCodec(t *testing.T) {
+	encode := func(secret string) string {
+		t.Helper()
+
+		c, err := newCookieCodec(secret)
+		assert.NilError(t, err)
+
+		encoded, err := c.Encode("name", []byte("value"))
+		assert.NilError(t, err)
+
+		return encoded
+	}
+
+	decodes := func(secret, encoded string) bool {
+		t.Helper()
+
+		c, err := newCookieCodec(secret)
+		assert.NilError(t, err)
+
+		_, err = c.Decode("name", encoded, 0)
+
+		return err == nil
+	}
+
+	// Instances sharing a secret accept each other's cookies; a random
+	// secret is never shared.
+	assert.Equal(t, decodes("shared", encode("shared")), true)
+	assert.Equal(t, decodes("other", encode("shared")), false)
+	assert.Equal(t, decodes("", encode("")), false)
+}
+
+func TestBannerDismiss(t *testing.T) {
+	app := newTestApplication(t)
+
+	setBanner := func(banner string) {
+		settings := models.DefaultSettings
+		settings.Banner = banner
+		app.settingsCache.Store(&settings)
+	}
+
+	setBanner("Read-only on Friday")
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/")
+	assert.StringContains(t, body, "<div class='banner'>Read-only on Friday")
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ := ts.postForm(t, "/banner/dismiss", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/")
+
+	_, _, body = ts.get(t, "/")
+	assert.Equal(t, strings.Contains(body, "<div class='banner'>"), false)
+
+	// A new banner is shown even to visitors who dismissed the old one.
+	setBanner("Back on Monday")
+
+	_, _, body = ts.get(t, "/")
+	assert.StringContains(t, body, "<div class='banner'>Back on Monday")
+}
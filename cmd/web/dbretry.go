@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// backoff spaces out retries exponentially from base up to max. Each delay
+// is jittered between half and all of its nominal length, so instances
+// started together do not retry in lockstep.
+type backoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+// dbBackoff paces connection attempts to the database at startup.
+var dbBackoff = backoff{base: 250 * time.Millisecond, max: 5 * time.Second}
+
+// delay returns how long to wait after the given failed attempt, counting
+// from 1.
+func (b backoff) delay(attempt int) time.Duration {
+	d := b.max
+	if attempt < 32 && b.base<<(attempt-1) < b.max {
+		d = b.base << (attempt - 1)
+	}
+
+	return d/2 + rand.N(d/2+1) //nolint:gosec // jitter needs no cryptographic randomness
+}
+
+// connectDB opens the database, retrying for up to timeout while it is not
+// reachable yet, as happens when the app and Postgres start together in a
+// container orchestrator.
+func connectDB(logger *slog.Logger, dsn string, tracer trace.Tracer, queries pgx.QueryTracer, timeout time.Duration) (*pgxpool.Pool, error) {
+	// A malformed DSN never gets better, so fail before the first attempt.
+	if _, err := pgxpool.ParseConfig(dsn); err != nil {
+		return nil, fmt.Errorf("parsing database DSN: %w", err)
+	}
+
+	var db *pgxpool.Pool
+
+	err := retryUntil(logger, timeout, dbBackoff, func() error {
+		var err error
+		db, err = openDB(dsn, tracer, queries)
+
+		return err
+	})
+
+	return db, err
+}
+
+// retryUntil calls fn until it succeeds, fails permanently or the next
+// attempt would start more than timeout after the first.
+func retryUntil(logger *slog.Logger, timeout time.Duration, b backoff, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || permanentDBError(err) {
+			return err
+		}
+
+		wait := b.delay(attempt)
+		if time.Now().Add(wait).After(deadline) {
+			if attempt == 1 {
+				return err
+			}
+
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("database not reachable, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", wait),
+			slog.String("err", err.Error()))
+
+		time.Sleep(wait)
+	}
+}
+
+// permanentDBError reports whether err means the server is up but refuses
+// the credentials, which retrying cannot fix.
+func permanentDBError(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, "28")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := backoff{base: 100 * time.Millisecond, max: time.Second}
+
+	tests := []struct {
+		attempt int
+		nominal time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			d := b.delay(tt.attempt)
+			if d < tt.nominal/2 || d > tt.nominal {
+				t.Fatalf("attempt %d: got %v; want between %v and %v", tt.attempt, d, tt.nominal/2, tt.nominal)
+			}
+		}
+	}
+}
+
+func TestRetryUntil(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	b := backoff{base: time.Millisecond, max: 2 * time.Millisecond}
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"Up at once", time.Second, 0, errDown, 1, false},
+		{"Up after retries", time.Second, 3, errDown, 4, false},
+		{"No timeout", 0, 3, errDown, 1, true},
+		{"Timeout", 20 * time.Millisecond, 1000, errDown, 0, true},
+		{"Bad password", time.Second, 3, &pgconn.PgError{Code: "28P01"}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+
+			err := retryUntil(logger, tt.timeout, b, func() error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			assert.Equal(t, err != nil, tt.wantErr)
+
+			if tt.wantErr && !errors.Is(err, tt.err) {
+				t.Errorf("got %v; want it to wrap %v", err, tt.err)
+			}
+
+			if tt.wantAttempts > 0 {
+				assert.Equal(t, attempts, tt.wantAttempts)
+			} else if attempts < 2 {
+				t.Errorf("got %d attempts; want retries before the timeout", attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/justinas/nosurf"
+)
+
+// notFound answers 404 with the not found page.
+func (app *application) notFound(w http.ResponseWriter, r *http.Request) {
+	app.errorPage(w, r, http.StatusNotFound, "not_found.tmpl", http.StatusText(http.StatusNotFound))
+}
+
+// errorPage renders page with status for browsers and answers API clients
+// that ask for JSON with {"error": ...}. Other clients, and every client when
+// the template cannot be rendered, get body as plain text, so an error page
+// never depends on a working template cache.
+func (app *application) errorPage(w http.ResponseWriter, r *http.Request, status int, page, body string) {
+	switch negotiate(w, r, mediaText, mediaJSON, mediaHTML) {
+	case mediaHTML:
+		buf, err := app.executePage(page, app.errorTemplateData(r))
+		if err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(status)
+
+			if _, err := buf.WriteTo(w); err != nil {
+				app.logger.ErrorContext(r.Context(), err.Error())
+			}
+
+			return
+		}
+
+		app.logger.ErrorContext(r.Context(), "rendering error page failed",
+			slog.String("page", page), slog.String("err", err.Error()))
+	case mediaJSON:
+		resp := map[string]string{"error": http.StatusText(status)}
+		if id := requestIDFromContext(r.Context()); id != "" {
+			resp["request_id"] = id
+		}
+
+		app.writeJSON(w, r, status, resp)
+
+		return
+	}
+
+	http.Error(w, body, status)
+}
+
+// errorTemplateData is newTemplateData for error pages. It reads nothing from
+// the session or the database, since either may be what failed or may not
+// be loaded for the route at all.
+func (app *application) errorTemplateData(r *http.Request) templateData {
+	locale, ok := i18n.Match(r.Header.Get("Accept-Language"))
+	if !ok {
+		locale = i18n.Default()
+	}
+
+	return templateData{
+		CurrentYear:     time.Now().Year(),
+		IsAuthenticated: app.isAuthenticated(r),
+		CSRFToken:       nosurf.Token(r),
+		Preferences:     models.DefaultPreferences,
+		Locale:          locale,
+		Locales:         i18n.Supported(),
+		Settings:        app.currentSettings(),
+		BasePath:        app.basePath,
+		BaseURL:         app.baseURL,
+		AbuseContact:    app.contacts.Abuse,
+		SecurityTxt:     app.contacts.servesSecurityTxt(),
+		RequestID:       requestIDFromContext(r.Context()),
+		Robots:          "noindex",
+	}
+}
+
+// notFoundWriter replaces the plain text 404 that http.ServeMux writes for
+// unknown paths with the not found page. Any other response passes through.
+type notFoundWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	notFound http.HandlerFunc
+	replaced bool
+}
+
+func (nw *notFoundWriter) WriteHeader(status int) {
+	if status != http.StatusNotFound {
+		nw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	nw.replaced = true
+	// http.NotFound has already set a plain text content type.
+	nw.Header().Del("Content-Type")
+	nw.notFound(nw.ResponseWriter, nw.r)
+}
+
+func (nw *notFoundWriter) Write(b []byte) (int, error) {
+	if nw.replaced {
+		return len(b), nil
+	}
+
+	return nw.ResponseWriter.Write(b) //nolint:wrapcheck // pass the underlying writer's error through
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (nw *notFoundWriter) Unwrap() http.ResponseWriter {
+	return nw.ResponseWriter
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"text/template"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+func TestNotFoundPage(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	browser := http.Header{"Accept": {"text/html,application/xhtml+xml,*/*;q=0.8"}}
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		header   http.Header
+		wantType string
+		wantBody string
+	}{
+		{"Unknown path", "/no/such/page", browser, "text/html; charset=utf-8", "<h2>Page Not Found</h2>"},
+		{"Malformed ID", "/snippet/view/abc", browser, "text/html; charset=utf-8", "<h2>Page Not Found</h2>"},
+		{"Missing snippet", "/snippet/view/2", browser, "text/html; charset=utf-8", "<h2>Page Not Found</h2>"},
+		{"Plain text client", "/no/such/page", nil, "text/plain; charset=utf-8", "Not Found"},
+		{"JSON client", "/snippet/view/2", http.Header{"Accept": {"application/json"}}, "application/json", `"error":"Not Found"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.getWithHeaders(t, tt.urlPath, tt.header)
+
+			assert.Equal(t, code, http.StatusNotFound)
+			assert.Equal(t, header.Get("Content-Type"), tt.wantType)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.Equal(t, slices.Contains(header.Values("Vary"), "Accept"), true)
+		})
+	}
+
+	t.Run("Wrong method", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodDelete, ts.URL+"/about", nil)
+		assert.NilError(t, err)
+		req.Header = browser
+
+		rs, err := ts.Client().Do(req)
+		assert.NilError(t, err)
+		defer rs.Body.Close()
+
+		assert.Equal(t, rs.StatusCode, http.StatusMethodNotAllowed)
+	})
+}
+
+func TestServerErrorPage(t *testing.T) {
+	app := newTestApplication(t)
+
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey, "req-8"))
+
+		rr := httptest.NewRecorder()
+		app.serverError(rr, r, errors.New("boom"))
+
+		return rr
+	}
+
+	rr := serve()
+	assert.Equal(t, rr.Code, http.StatusInternalServerError)
+	assert.StringContains(t, rr.Body.String(), "<h2>Something Went Wrong</h2>")
+	assert.StringContains(t, rr.Body.String(), "<code>req-8</code>")
+
+	// Without a usable template cache the page degrades to plain text.
+	app.templateCache.Store(&map[string]*template.Template{})
+
+	rr = serve()
+	assert.Equal(t, rr.Code, http.StatusInternalServerError)
+	assert.Equal(t, rr.Body.String(), "Internal Server Error\nRequest ID: req-8\n")
+}
+
+func TestHandleError(t *testing.T) {
+	app := newTestApplication(t)
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"Not found", fmt.Errorf("loading snippet: %w", models.ErrNoRecord), http.StatusNotFound},
+		{"Validation", errorx.Errorf(errorx.Validation, "bad title"), http.StatusUnprocessableEntity},
+		{"Internal", errors.New("database is down"), http.StatusInternalServerError},
+		{"Reclassified", errorx.Wrap(models.ErrNoRecord, errorx.Internal, "admin missing"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			rr := httptest.NewRecorder()
+
+			app.handleError(rr, r, tt.err)
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+		})
+	}
+}
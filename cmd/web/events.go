@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// snippetEventsRoute is the pattern of the event stream of new snippets.
+	snippetEventsRoute = "GET /events/snippets"
+
+	// eventsKeepalive is how often an idle event stream gets a comment, so
+	// proxies do not close it for inactivity.
+	eventsKeepalive = 25 * time.Second
+
+	// eventsWriteTimeout bounds each write to an event stream. Streams
+	// outlive the server's write timeout, so it is extended per write and a
+	// client that stops reading is dropped after this long.
+	eventsWriteTimeout = 10 * time.Second
+
+	// eventsBuffer is how many snippets may queue up for a slow subscriber
+	// before further ones are dropped for it.
+	eventsBuffer = 16
+)
+
+// snippetFeed fans new snippets out to the open event streams.
+type snippetFeed struct {
+	mu     sync.Mutex
+	subs   map[chan models.Snippet]struct{}
+	closed bool
+}
+
+func newSnippetFeed() *snippetFeed {
+	return &snippetFeed{subs: make(map[chan models.Snippet]struct{})}
+}
+
+// subscribe returns a channel receiving every snippet published from now on
+// and a function to stop receiving them. The channel is closed when the feed
+// closes; once it has, subscribe returns a nil channel.
+func (f *snippetFeed) subscribe() (<-chan models.Snippet, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, func() {}
+	}
+
+	ch := make(chan models.Snippet, eventsBuffer)
+	f.subs[ch] = struct{}{}
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if _, ok := f.subs[ch]; ok {
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends s to every subscriber without waiting for slow ones.
+func (f *snippetFeed) publish(s models.Snippet) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subs {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// close ends every subscription, which ends the open event streams. It is
+// registered to run on server shutdown, since http.Server.Shutdown would
+// otherwise wait for the streams until its timeout.
+func (f *snippetFeed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+
+	for ch := range f.subs {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
+
+// listenSnippets publishes snippets created by any instance to the feed
+// until ctx is cancelled, reconnecting with backoff when the listening
+// connection fails.
+func (app *application) listenSnippets(ctx context.Context, db *pgxpool.Pool) {
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+
+		err := models.ListenSnippetsCreated(ctx, db, func(id int) {
+			snippet, err := app.snippets.Get(ctx, id)
+			if err != nil {
+				app.logger.Error("loading new snippet", slog.Int("id", id), slog.String("err", err.Error()))
+
+				return
+			}
+
+			app.feed.publish(snippet)
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A connection that worked for a while starts the backoff afresh.
+		if time.Since(started) > dbBackoff.max {
+			attempt = 1
+		}
+
+		wait := dbBackoff.delay(attempt)
+		app.logger.Warn("snippet listener stopped, reconnecting",
+			slog.Duration("retry_in", wait), slog.String("err", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// snippetEvent is the data of a "snippet" event.
+type snippetEvent struct {
+	ID      int       `json:"id"`
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Created time.Time `json:"created"`
+	// Date is Created formatted for the client's language.
+	Date string `json:"date"`
+}
+
+// snippetEvents streams newly created snippets as server-sent events, so
+// open home pages can show them without polling.
+func (app *application) snippetEvents(w http.ResponseWriter, r *http.Request) {
+	snippets, unsubscribe := app.feed.subscribe()
+	defer unsubscribe()
+
+	if snippets == nil {
+		app.clientError(w, http.StatusServiceUnavailable)
+
+		return
+	}
+
+	locale, ok := i18n.Match(r.Header.Get("Accept-Language"))
+	if !ok {
+		locale = i18n.Default()
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	// Keep nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(format string, args ...any) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+
+		return rc.Flush() == nil
+	}
+
+	if !send("retry: 5000\n\n") {
+		return
+	}
+
+	keepalive := time.NewTicker(eventsKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if !send(": keepalive\n\n") {
+				return
+			}
+		case s, ok := <-snippets:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(snippetEvent{
+				ID:      s.ID,
+				Title:   s.Title,
+				URL:     app.basePath + "/snippet/view/" + strconv.Itoa(s.ID),
+				Created: s.Created,
+				Date:    locale.FormatDate(s.Created),
+			})
+			if err != nil {
+				app.logger.ErrorContext(r.Context(), err.Error())
+
+				return
+			}
+
+			if !send("event: snippet\nid: %d\ndata: %s\n\n", s.ID, data) {
+				return
+			}
+		}
+	}
+}
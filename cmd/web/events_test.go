@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+func TestSnippetFeed(t *testing.T) {
+	f := newSnippetFeed()
+
+	a, unsubscribeA := f.subscribe()
+	b, _ := f.subscribe()
+
+	f.publish(models.Snippet{ID: 1})
+	assert.Equal(t, (<-a).ID, 1)
+	assert.Equal(t, (<-b).ID, 1)
+
+	unsubscribeA()
+
+	_, ok := <-a
+	assert.Equal(t, ok, false)
+
+	// A subscriber that does not keep up misses snippets rather than
+	// holding up the others.
+	for i := range eventsBuffer + 5 {
+		f.publish(models.Snippet{ID: i})
+	}
+
+	assert.Equal(t, len(b), eventsBuffer)
+
+	f.close()
+
+	for range b { //nolint:revive // drain until the feed closes the channel
+	}
+
+	c, _ := f.subscribe()
+	assert.Equal(t, c == nil, true)
+}
+
+func TestSnippetEvents(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events/snippets", nil)
+	assert.NilError(t, err)
+	req.Header.Set("Accept-Language", "de")
+
+	rs, err := ts.Client().Do(req)
+	assert.NilError(t, err)
+	defer rs.Body.Close()
+
+	assert.Equal(t, rs.StatusCode, http.StatusOK)
+	assert.Equal(t, rs.Header.Get("Content-Type"), "text/event-stream")
+
+	lines := bufio.NewScanner(rs.Body)
+
+	// The retry hint is sent once the subscription is in place.
+	assert.Equal(t, lines.Scan(), true)
+	assert.Equal(t, lines.Text(), "retry: 5000")
+
+	app.feed.publish(models.Snippet{
+		ID:      7,
+		Title:   "Live <snippet>",
+		Created: time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC),
+	})
+
+	var event []string
+
+	for lines.Scan() {
+		if lines.Text() == "" && len(event) > 0 {
+			break
+		}
+
+		if lines.Text() != "" {
+			event = append(event, lines.Text())
+		}
+	}
+
+	assert.Equal(t, len(event), 3)
+	assert.Equal(t, event[0], "event: snippet")
+	assert.Equal(t, event[1], "id: 7")
+	assert.StringContains(t, event[2], `"title":"Live \u003csnippet\u003e"`)
+	assert.StringContains(t, event[2], `"url":"/snippet/view/7"`)
+	assert.StringContains(t, event[2], `"date":"17.03.2024`)
+
+	// Shutting down ends open streams.
+	app.feed.close()
+
+	_, err = io.ReadAll(rs.Body)
+	assert.NilError(t, err)
+
+	code, _, _ := ts.get(t, "/events/snippets")
+	assert.Equal(t, code, http.StatusServiceUnavailable)
+}
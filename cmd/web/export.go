@@ -0,0 +1,167 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// exportTimeout bounds the time spent generating a single data export.
+const exportTimeout = 5 * time.Minute
+
+type exportProfile struct {
+	ID                int                `json:"id"`
+	Name              string             `json:"name"`
+	Email             string             `json:"email"`
+	Created           time.Time          `json:"created"`
+	TOSVersion        int                `json:"tos_version"`
+	PasswordChangedAt time.Time          `json:"password_changed_at"`
+	Preferences       models.Preferences `json:"preferences"`
+}
+
+type exportSnippet struct {
+	ID      int       `json:"id"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+type exportAuditEvent struct {
+	Event   string    `json:"event"`
+	IP      string    `json:"ip"`
+	Details string    `json:"details"`
+	Created time.Time `json:"created"`
+}
+
+// buildExport collects everything stored about the user into a zip archive
+// of JSON documents. It also returns the number of snippets exported.
+func (app *application) buildExport(ctx context.Context, user models.User) ([]byte, int, error) {
+	prefs, err := app.prefs.Get(ctx, user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	snippets, err := app.snippets.ByUser(ctx, user.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing snippets: %w", err)
+	}
+
+	events, err := app.audit.List(ctx, models.AuditFilter{UserID: user.ID, Limit: 100000})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	files := map[string]any{
+		"profile.json": exportProfile{
+			ID:                user.ID,
+			Name:              user.Name,
+			Email:             user.Email,
+			Created:           user.Created,
+			TOSVersion:        user.TOSVersion,
+			PasswordChangedAt: user.PasswordChangedAt,
+			Preferences:       prefs,
+		},
+		"snippets.json":     exportSnippets(snippets),
+		"audit_events.json": exportAuditEvents(events),
+	}
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for _, name := range []string{"profile.json", "snippets.json", "audit_events.json"} {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, 0, fmt.Errorf("adding %s: %w", name, err)
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(files[name]); err != nil {
+			return nil, 0, fmt.Errorf("encoding %s: %w", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, 0, fmt.Errorf("closing archive: %w", err)
+	}
+
+	return buf.Bytes(), len(snippets), nil
+}
+
+func exportSnippets(snippets []models.Snippet) []exportSnippet {
+	out := make([]exportSnippet, 0, len(snippets))
+
+	for _, s := range snippets {
+		out = append(out, exportSnippet{
+			ID:      s.ID,
+			Title:   s.Title,
+			Content: s.Content,
+			Created: s.Created,
+			Expires: s.Expires,
+		})
+	}
+
+	return out
+}
+
+func exportAuditEvents(events []models.AuditEvent) []exportAuditEvent {
+	out := make([]exportAuditEvent, 0, len(events))
+
+	for _, e := range events {
+		out = append(out, exportAuditEvent{
+			Event:   e.Event,
+			IP:      e.IP,
+			Details: e.Details,
+			Created: e.Created,
+		})
+	}
+
+	return out
+}
+
+// generateExport builds and stores the archive of a pending export, then
+// emails the user a download link. The email text is English only, so its
+// dates and counts are formatted in English too. It runs in the background.
+func (app *application) generateExport(user models.User, export models.Export, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	archive, count, err := app.buildExport(ctx, user)
+	if err != nil {
+		app.logger.Error(err.Error())
+
+		return
+	}
+
+	if err := app.exports.Complete(ctx, export.ID, archive); err != nil {
+		app.logger.Error(err.Error())
+
+		return
+	}
+
+	en := i18n.Default()
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your Snippetbox data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour data export is ready and includes %s. "+
+				"Download it while signed in, before %s:\n\n%s/account/export-data/%s\n",
+			user.Name, en.Plural("snippets", int64(count)), en.FormatDate(export.Expires), app.baseURL, token,
+		),
+	}
+
+	if err := app.mailer.Send(ctx, msg); err != nil {
+		app.logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "net/http"
+
+// Flash levels, used as the suffix of the flash's CSS class.
+const (
+	flashSuccess = "success"
+	flashInfo    = "info"
+	flashWarning = "warning"
+	flashError   = "error"
+)
+
+// flashMessage is a one-off message shown on the next page the user sees.
+type flashMessage struct {
+	Level   string
+	Message string
+}
+
+// flash queues msg to be shown with the given level on the next rendered
+// page. The two parts are kept as plain strings in the session so no types
+// need registering with the session codec.
+func (app *application) flash(r *http.Request, level, msg string) {
+	app.sessionManager.Put(r.Context(), "flash", msg)
+	app.sessionManager.Put(r.Context(), "flash_level", level)
+}
+
+// popFlash removes the queued flash from the session and returns it, or nil
+// if there is none. Flashes queued without a level are shown as info.
+func (app *application) popFlash(r *http.Request) *flashMessage {
+	level := app.sessionManager.PopString(r.Context(), "flash_level")
+
+	msg := app.sessionManager.PopString(r.Context(), "flash")
+	if msg == "" {
+		return nil
+	}
+
+	if level == "" {
+		level = flashInfo
+	}
+
+	return &flashMessage{Level: level, Message: msg}
+}
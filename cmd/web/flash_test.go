@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestFlash(t *testing.T) {
+	app := newTestApplication(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	ctx, err := app.sessionManager.Load(r.Context(), "")
+	assert.NilError(t, err)
+
+	r = r.WithContext(ctx)
+
+	assert.Equal(t, app.popFlash(r) == nil, true)
+
+	app.flash(r, flashWarning, "Careful now.")
+
+	f := app.popFlash(r)
+	assert.Equal(t, f.Level, flashWarning)
+	assert.Equal(t, f.Message, "Careful now.")
+
+	// A flash is only shown once.
+	assert.Equal(t, app.popFlash(r) == nil, true)
+
+	// Flashes queued before levels existed are shown as info.
+	app.sessionManager.Put(r.Context(), "flash", "Old style.")
+	assert.Equal(t, app.popFlash(r).Level, flashInfo)
+}
+
+func TestFlashPartial(t *testing.T) {
+	cache, err := newTemplateCache("")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name  string
+		flash *flashMessage
+		want  string
+	}{
+		{
+			name:  "Success",
+			flash: &flashMessage{Level: flashSuccess, Message: "Saved."},
+			want:  `<div class='flash flash-success' role='status'>Saved.</div>`,
+		},
+		{
+			name:  "Error",
+			flash: &flashMessage{Level: flashError, Message: "Failed."},
+			want:  `<div class='flash flash-error' role='alert'>Failed.</div>`,
+		},
+		{
+			name: "None",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := cache["home.tmpl"].ExecuteTemplate(&buf, "flash", templateData{Flash: tt.flash})
+			assert.NilError(t, err)
+
+			if tt.want == "" {
+				assert.Equal(t, bytes.Contains(buf.Bytes(), []byte("<div")), false)
+			} else {
+				assert.StringContains(t, buf.String(), tt.want)
+			}
+		})
+	}
+}
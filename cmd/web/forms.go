@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/justinas/nosurf"
+)
+
+const (
+	// maxFormBytes caps the whole body of a form submission, uploads
+	// included.
+	maxFormBytes = 8 << 20
+	// maxFieldBytes caps a single non-file field. It leaves room for the
+	// longest snippet the settings allow.
+	maxFieldBytes = 4 << 20
+)
+
+var (
+	errFieldTooLarge = errors.New("form field too large")
+	errUploadCSRF    = errors.New("upload without a valid CSRF token")
+)
+
+// upload is a file part of a multipart form, streamed to a temporary file
+// that is removed once the request has been handled.
+type upload struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Path        string
+	Size        int64
+}
+
+// parseForms parses form submissions before anything else reads the body,
+// enforcing maxFormBytes and maxFieldBytes. It must run before noSurf, which
+// would otherwise parse multipart forms into memory to look for the CSRF
+// token. Oversized submissions get a 413 page instead of a bare 400.
+func (app *application) parseForms(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxFormBytes)
+
+		var (
+			uploads []upload
+			err     error
+		)
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "multipart/form-data" {
+			uploads, err = parseMultipartForm(r)
+		} else {
+			err = parseURLEncodedForm(r)
+		}
+
+		defer app.removeUploads(uploads)
+
+		if err != nil {
+			app.formError(w, r, err)
+
+			return
+		}
+
+		if len(uploads) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), uploadsContextKey, uploads))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func parseURLEncodedForm(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	for _, values := range r.PostForm {
+		for _, v := range values {
+			if len(v) > maxFieldBytes {
+				return errFieldTooLarge
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseMultipartForm reads a multipart body part by part. Fields end up in
+// r.PostForm as usual; file parts are streamed to temporary files rather
+// than held in memory. Since noSurf only runs afterwards, nothing is written
+// to disk until the request has shown a valid CSRF token, in its header or
+// in a field before the first file. The uploads saved so far are returned
+// even on error so the caller can remove them.
+func parseMultipartForm(r *http.Request) ([]upload, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("reading multipart form: %w", err)
+	}
+
+	var uploads []upload
+
+	r.PostForm = make(map[string][]string)
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return uploads, fmt.Errorf("reading multipart part: %w", err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFieldBytes+1))
+			if err != nil {
+				return uploads, fmt.Errorf("reading form field %s: %w", name, err)
+			}
+
+			if len(value) > maxFieldBytes {
+				return uploads, errFieldTooLarge
+			}
+
+			r.PostForm.Add(name, string(value))
+
+			continue
+		}
+
+		if len(uploads) == 0 && !validCSRFToken(r) {
+			return uploads, errUploadCSRF
+		}
+
+		u, err := saveUpload(part)
+		if err != nil {
+			return uploads, err
+		}
+
+		uploads = append(uploads, u)
+	}
+
+	// Fill r.Form from r.PostForm and the query string.
+	return uploads, r.ParseForm()
+}
+
+// validCSRFToken reports whether the X-CSRF-Token header or the csrf_token
+// field read so far matches the request's CSRF cookie.
+func validCSRFToken(r *http.Request) bool {
+	cookie, err := r.Cookie(nosurf.CookieName)
+	if err != nil {
+		return false
+	}
+
+	sent := r.Header.Get(nosurf.HeaderName)
+	if sent == "" {
+		sent = r.PostForm.Get(nosurf.FormFieldName)
+	}
+
+	return sent != "" && nosurf.VerifyToken(cookie.Value, sent)
+}
+
+func saveUpload(part *multipart.Part) (upload, error) {
+	u := upload{
+		Field:       part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+	}
+
+	f, err := os.CreateTemp("", "snippetbox-upload-*")
+	if err != nil {
+		return upload{}, fmt.Errorf("creating upload file: %w", err)
+	}
+
+	u.Path = f.Name()
+
+	u.Size, err = io.Copy(f, part)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(u.Path)
+
+		return upload{}, fmt.Errorf("saving upload %s: %w", u.Filename, err)
+	}
+
+	return u, nil
+}
+
+func (app *application) removeUploads(uploads []upload) {
+	for _, u := range uploads {
+		if err := os.Remove(u.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			app.logger.Error("removing upload", slog.String("path", u.Path), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// uploadedFiles returns the files submitted with a multipart form. They are
+// deleted when the handler returns; move them to keep them.
+func uploadedFiles(r *http.Request) []upload {
+	uploads, _ := r.Context().Value(uploadsContextKey).([]upload)
+
+	return uploads
+}
+
+// formError answers a form that could not be parsed: 413 with an
+// explanatory page when it was too large, 400 otherwise.
+func (app *application) formError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+
+	if !errors.As(err, &maxBytesErr) && !errors.Is(err, errFieldTooLarge) {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.MaxFormSize = bytesize(maxFormBytes)
+	data.MaxFieldSize = bytesize(maxFieldBytes)
+
+	// The rest of the body is not read, so the connection cannot be reused.
+	w.Header().Set("Connection", "close")
+
+	app.render(w, r, http.StatusRequestEntityTooLarge, "too_large.tmpl", data)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func multipartBody(t *testing.T, fields map[string]string, files map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	for name, value := range fields {
+		assert.NilError(t, mw.WriteField(name, value))
+	}
+
+	for name, content := range files {
+		fw, err := mw.CreateFormFile(name, name+".txt")
+		assert.NilError(t, err)
+
+		_, err = fw.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, mw.Close())
+
+	return &buf, mw.FormDataContentType()
+}
+
+func TestParseForms(t *testing.T) {
+	app := newTestApplication(t)
+
+	var (
+		gotTitle   string
+		gotUploads []upload
+		gotContent string
+	)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTitle = r.FormValue("title")
+		gotUploads = uploadedFiles(r)
+
+		if len(gotUploads) > 0 {
+			b, err := os.ReadFile(gotUploads[0].Path)
+			assert.NilError(t, err)
+			gotContent = string(b)
+		}
+
+		w.Write([]byte("OK"))
+	})
+
+	handler := app.sessionManager.LoadAndSave(app.parseForms(next))
+
+	t.Run("URL-encoded form", func(t *testing.T) {
+		form := url.Values{"title": {"O snail"}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusOK)
+		assert.Equal(t, gotTitle, "O snail")
+	})
+
+	t.Run("URL-encoded field too large", func(t *testing.T) {
+		form := url.Values{"title": {strings.Repeat("a", maxFieldBytes+1)}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusRequestEntityTooLarge)
+		assert.StringContains(t, rr.Body.String(), "Submission Too Large")
+	})
+
+	t.Run("Body too large", func(t *testing.T) {
+		form := url.Values{
+			"a": {strings.Repeat("a", maxFieldBytes)},
+			"b": {strings.Repeat("b", maxFieldBytes)},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	// The CSRF cookie and field of a browser that loaded the form.
+	csrfToken := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	csrfCookie := &http.Cookie{Name: "csrf_token", Value: csrfToken}
+
+	t.Run("Multipart upload", func(t *testing.T) {
+		body, ct := multipartBody(t, map[string]string{"title": "Haiku", "csrf_token": csrfToken}, map[string]string{"file": "An old silent pond"})
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", ct)
+		req.AddCookie(csrfCookie)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusOK)
+		assert.Equal(t, gotTitle, "Haiku")
+		assert.Equal(t, len(gotUploads), 1)
+		assert.Equal(t, gotUploads[0].Field, "file")
+		assert.Equal(t, gotUploads[0].Filename, "file.txt")
+		assert.Equal(t, gotUploads[0].Size, int64(len("An old silent pond")))
+		assert.Equal(t, gotContent, "An old silent pond")
+
+		// The temporary file is gone once the handler has returned.
+		_, err := os.Stat(gotUploads[0].Path)
+		assert.Equal(t, os.IsNotExist(err), true)
+	})
+
+	t.Run("Multipart field too large", func(t *testing.T) {
+		body, ct := multipartBody(t, map[string]string{"title": strings.Repeat("a", maxFieldBytes+1)}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", ct)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Upload without CSRF token", func(t *testing.T) {
+		gotUploads = nil
+
+		for _, tt := range []struct {
+			name  string
+			token string
+		}{
+			{"Missing", ""},
+			{"Wrong", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				body, ct := multipartBody(t, map[string]string{"csrf_token": tt.token}, map[string]string{"file": "An old silent pond"})
+				req := httptest.NewRequest(http.MethodPost, "/", body)
+				req.Header.Set("Content-Type", ct)
+				req.AddCookie(csrfCookie)
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				assert.Equal(t, rr.Code, http.StatusBadRequest)
+				assert.Equal(t, len(gotUploads), 0)
+			})
+		}
+	})
+
+	t.Run("Multipart upload too large", func(t *testing.T) {
+		body, ct := multipartBody(t, nil, map[string]string{"file": strings.Repeat("a", maxFormBytes)})
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", ct)
+		req.Header.Set("X-CSRF-Token", csrfToken)
+		req.AddCookie(csrfCookie)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusRequestEntityTooLarge)
+		assert.Equal(t, rr.Header().Get("Connection"), "close")
+	})
+
+	t.Run("Malformed multipart", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not multipart"))
+		req.Header.Set("Content-Type", "multipart/form-data")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, rr.Code, http.StatusBadRequest)
+	})
+}
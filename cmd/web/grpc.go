@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+	snippetboxv1 "github.com/FABLOUSFALCON/snippetbox/rpc/snippetbox/v1"
+)
+
+// newGRPCServer returns the server for the gRPC SnippetService on addr. It
+// runs as an auxiliary HTTP server speaking cleartext HTTP/2, so it starts
+// and shuts down with the others. Calls need token as a bearer token unless
+// it is empty, which config.validate only allows on loopback addresses.
+func (app *application) newGRPCServer(addr, token string) *http.Server {
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(app.logRPC, authenticateRPC(token)))
+	snippetboxv1.RegisterSnippetServiceServer(gs, &snippetService{app: app})
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           gs,
+		Protocols:         &protocols,
+		ErrorLog:          slog.NewLogLogger(app.logger.Handler(), slog.LevelError),
+		IdleTimeout:       time.Minute,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// logRPC logs every call like logRequest logs HTTP requests.
+func (app *application) logRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	app.logger.InfoContext(ctx, "served rpc",
+		slog.String("method", info.FullMethod),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)))
+
+	return resp, err
+}
+
+// authenticateRPC rejects calls whose authorization metadata does not carry
+// token as a bearer token. An empty token lets every call through.
+func authenticateRPC(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if token == "" {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		for _, v := range md.Get("authorization") {
+			scheme, got, _ := strings.Cut(v, " ")
+			if strings.EqualFold(scheme, "Bearer") && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+}
+
+// snippetService implements SnippetService with the models behind the JSON
+// API, applying the same rules.
+type snippetService struct {
+	snippetboxv1.UnimplementedSnippetServiceServer
+	app *application
+}
+
+func newRPCSnippet(s models.Snippet) *snippetboxv1.Snippet {
+	return &snippetboxv1.Snippet{
+		Id:      int64(s.ID),
+		Title:   s.Title,
+		Content: s.Content,
+		Created: timestamppb.New(s.Created),
+		Updated: timestamppb.New(s.Updated),
+		Expires: timestamppb.New(s.Expires),
+		UserId:  int64(s.UserID),
+	}
+}
+
+// rpcError turns a model error into a gRPC status. Unexpected errors are
+// logged and reported without details.
+func (s *snippetService) rpcError(ctx context.Context, err error) error {
+	if errors.Is(err, models.ErrNoRecord) {
+		return status.Error(codes.NotFound, "snippet not found")
+	}
+
+	s.app.logger.ErrorContext(ctx, err.Error())
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// checkWritable refuses changes while maintenance mode is on.
+func (s *snippetService) checkWritable() error {
+	if s.app.currentSettings().MaintenanceMode {
+		return status.Error(codes.Unavailable, "read-only during maintenance")
+	}
+
+	return nil
+}
+
+func (s *snippetService) CreateSnippet(ctx context.Context, req *snippetboxv1.CreateSnippetRequest) (*snippetboxv1.CreateSnippetResponse, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	var v validator.Validator
+
+	s.app.checkSnippet(&v, req.GetTitle(), req.GetContent(), int(req.GetExpiresDays()))
+
+	if !v.Valid() {
+		msgs := make([]string, 0, len(v.FieldErrors))
+		for _, field := range slices.Sorted(maps.Keys(v.FieldErrors)) {
+			msgs = append(msgs, field+": "+v.FieldErrors[field])
+		}
+
+		return nil, status.Error(codes.InvalidArgument, strings.Join(msgs, "; "))
+	}
+
+	userID := int(req.GetUserId())
+
+	// Suspended users may not create snippets, as on the web.
+	if userID != 0 {
+		user, err := s.app.users.Get(userID)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				return nil, status.Error(codes.InvalidArgument, "user_id: no such user")
+			}
+
+			return nil, s.rpcError(ctx, err)
+		}
+
+		if user.Suspended() {
+			return nil, status.Error(codes.FailedPrecondition, "account suspended")
+		}
+	}
+
+	id, err := s.app.snippets.Insert(ctx, userID, req.GetTitle(), req.GetContent(), int(req.GetExpiresDays()))
+	if err != nil {
+		return nil, s.rpcError(ctx, err)
+	}
+
+	s.app.snippetCreated(userID, id, req.GetTitle())
+
+	return &snippetboxv1.CreateSnippetResponse{Id: int64(id)}, nil
+}
+
+func (s *snippetService) GetSnippet(ctx context.Context, req *snippetboxv1.GetSnippetRequest) (*snippetboxv1.GetSnippetResponse, error) {
+	snippet, err := s.app.snippets.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, s.rpcError(ctx, err)
+	}
+
+	return &snippetboxv1.GetSnippetResponse{Snippet: newRPCSnippet(snippet)}, nil
+}
+
+func (s *snippetService) ListSnippets(ctx context.Context, req *snippetboxv1.ListSnippetsRequest) (*snippetboxv1.ListSnippetsResponse, error) {
+	p := pagination{page: max(1, int(req.GetPage())), limit: defaultAPISnippets}
+
+	if n := int(req.GetPageSize()); n != 0 {
+		if n < 1 || n > maxAPISnippets {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("page_size must be between 1 and %d", maxAPISnippets))
+		}
+
+		p.limit = n
+	}
+
+	total, err := s.app.snippets.Count(ctx)
+	if err != nil {
+		return nil, s.rpcError(ctx, err)
+	}
+
+	snippets, err := s.app.snippets.LatestPage(ctx, p.limit, p.offset())
+	if err != nil {
+		return nil, s.rpcError(ctx, err)
+	}
+
+	resp := &snippetboxv1.ListSnippetsResponse{Total: int32(total)} //nolint:gosec // the snippet count fits
+	for _, snippet := range snippets {
+		resp.Snippets = append(resp.Snippets, newRPCSnippet(snippet))
+	}
+
+	return resp, nil
+}
+
+func (s *snippetService) DeleteSnippet(ctx context.Context, req *snippetboxv1.DeleteSnippetRequest) (*snippetboxv1.DeleteSnippetResponse, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := s.app.snippets.Delete(ctx, int(req.GetId()), time.Time{}); err != nil {
+		return nil, s.rpcError(ctx, err)
+	}
+
+	return &snippetboxv1.DeleteSnippetResponse{}, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	snippetboxv1 "github.com/FABLOUSFALCON/snippetbox/rpc/snippetbox/v1"
+)
+
+// newTestGRPCClient serves app's gRPC service on a local port and returns a
+// client for it.
+func newTestGRPCClient(t *testing.T, app *application, token string) snippetboxv1.SnippetServiceClient {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	srv := app.newGRPCServer(ln.Addr().String(), token)
+
+	go srv.Serve(ln) //nolint:errcheck // Serve returns when the test closes the server
+
+	t.Cleanup(func() { srv.Close() })
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NilError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	return snippetboxv1.NewSnippetServiceClient(conn)
+}
+
+func TestGRPCSnippetService(t *testing.T) {
+	app := newTestApplication(t)
+	client := newTestGRPCClient(t, app, "s3cret")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.GetSnippet(ctx, &snippetboxv1.GetSnippetRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+
+	got, err := client.GetSnippet(ctx, &snippetboxv1.GetSnippetRequest{Id: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, got.GetSnippet().GetTitle(), "An old silent pond")
+	assert.Equal(t, got.GetSnippet().GetUserId(), int64(1))
+
+	_, err = client.GetSnippet(ctx, &snippetboxv1.GetSnippetRequest{Id: 2})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+
+	list, err := client.ListSnippets(ctx, &snippetboxv1.ListSnippetsRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(list.GetSnippets()), 1)
+	assert.Equal(t, list.GetTotal(), int32(1))
+
+	_, err = client.ListSnippets(ctx, &snippetboxv1.ListSnippetsRequest{PageSize: 500})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	created, err := client.CreateSnippet(ctx, &snippetboxv1.CreateSnippetRequest{
+		Title: "O snail", Content: "Climb Mount Fuji", ExpiresDays: 7, UserId: 1,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, created.GetId(), int64(2))
+
+	_, err = client.CreateSnippet(ctx, &snippetboxv1.CreateSnippetRequest{Content: "x", ExpiresDays: 2})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+	assert.StringContains(t, status.Convert(err).Message(), "expires: ")
+
+	_, err = client.CreateSnippet(ctx, &snippetboxv1.CreateSnippetRequest{
+		Title: "O snail", Content: "Climb Mount Fuji", ExpiresDays: 7, UserId: 3,
+	})
+	assert.Equal(t, status.Code(err), codes.FailedPrecondition)
+
+	_, err = client.DeleteSnippet(ctx, &snippetboxv1.DeleteSnippetRequest{Id: 2})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+
+	_, err = client.DeleteSnippet(ctx, &snippetboxv1.DeleteSnippetRequest{Id: 1})
+	assert.NilError(t, err)
+
+	settings := app.currentSettings()
+	settings.MaintenanceMode = true
+	app.settingsCache.Store(&settings)
+
+	_, err = client.DeleteSnippet(ctx, &snippetboxv1.DeleteSnippetRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unavailable)
+}
+
+func TestAuthenticateRPC(t *testing.T) {
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          codes.Code
+	}{
+		{name: "No token configured", want: codes.OK},
+		{name: "Valid", token: "s3cret", authorization: "Bearer s3cret", want: codes.OK},
+		{name: "Scheme is case-insensitive", token: "s3cret", authorization: "bearer s3cret", want: codes.OK},
+		{name: "Wrong token", token: "s3cret", authorization: "Bearer guess", want: codes.Unauthenticated},
+		{name: "Missing", token: "s3cret", want: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			_, err := authenticateRPC(tt.token)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			assert.Equal(t, status.Code(err), tt.want)
+		})
+	}
+}
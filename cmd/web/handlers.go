@@ -3,8 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/FABLOUSFALCON/snippetbox/internal/models"
 	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
@@ -21,6 +24,7 @@ type userSignupForm struct {
 	Name                string `form:"name"`
 	Email               string `form:"email"`
 	Password            string `form:"password"`
+	AcceptTOS           bool   `form:"accept_tos"`
 	validator.Validator `form:"-"`
 }
 
@@ -30,6 +34,16 @@ type userLoginForm struct {
 	validator.Validator `form:"-"`
 }
 
+type reauthenticateForm struct {
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+type termsAcceptForm struct {
+	AcceptTOS           bool `form:"accept_tos"`
+	validator.Validator `form:"-"`
+}
+
 type accountPasswordUpdateForm struct {
 	CurrentPassword         string `form:"currentPassword"`
 	NewPassword             string `form:"newPassword"`
@@ -44,9 +58,17 @@ func ping(w http.ResponseWriter, _ *http.Request) {
 }
 
 func (app *application) home(w http.ResponseWriter, r *http.Request) {
-	snippets, err := app.snippets.Latest(r.Context())
+	snippets, err := app.snippets.Latest(r.Context(), app.preferences(r).SnippetsPerPage)
 	if err != nil {
-		app.serverError(w, r, err)
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	// Scripts can ask for the latest snippets as JSON, in the shape of the
+	// API's snippet list.
+	if negotiate(w, r, mediaHTML, mediaJSON) == mediaJSON {
+		app.writeJSON(w, r, http.StatusOK, map[string]any{"snippets": newAPISnippets(snippets)})
 
 		return
 	}
@@ -58,34 +80,80 @@ func (app *application) home(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *application) snippetView(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.PathValue("id"))
-	if err != nil || id < 1 {
-		http.NotFound(w, r)
+	id := pathInt(r, "id")
+
+	snippet, err := app.snippets.Get(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordSnippetView(r, snippet.ID)
+
+	// API clients and scripts can ask for the snippet as JSON or plain text
+	// at the same URL.
+	switch negotiate(w, r, mediaHTML, mediaJSON, mediaText) {
+	case mediaJSON:
+		app.writeSnippetJSON(w, r, snippet)
+
+		return
+	case mediaText:
+		app.writeSnippetText(w, r, snippet)
 
 		return
 	}
 
+	data := app.newTemplateData(r)
+	data.Snippet = snippet
+
+	// A page carrying a flash message is a one-off and must not be
+	// revalidated later, so it gets no validator at all.
+	if data.Flash == nil {
+		w.Header().Set("Cache-Control", "private, no-cache")
+
+		if notModified(w, r, viewETag(snippet, data), time.Time{}) {
+			return
+		}
+	}
+
+	app.render(w, r, http.StatusOK, "view.tmpl", data)
+}
+
+func (app *application) snippetStats(w http.ResponseWriter, r *http.Request) {
+	id := pathInt(r, "id")
+
 	snippet, err := app.snippets.Get(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(w, r)
-		} else {
-			app.serverError(w, r, err)
-		}
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if snippet.UserID == 0 || snippet.UserID != app.authenticatedUserID(r) {
+		app.clientError(w, http.StatusForbidden)
+
+		return
+	}
+
+	stats, err := app.analytics.SnippetStats(r.Context(), snippet.ID)
+	if err != nil {
+		app.handleError(w, r, err)
 
 		return
 	}
 
 	data := app.newTemplateData(r)
 	data.Snippet = snippet
+	data.Stats = stats
 
-	app.render(w, r, http.StatusOK, "view.tmpl", data)
+	app.render(w, r, http.StatusOK, "stats.tmpl", data)
 }
 
 func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) {
 	data := app.newTemplateData(r)
 	data.Form = snippetCreateForm{
-		Expires: 365,
+		Expires: data.Preferences.DefaultExpiry,
 	}
 	app.render(w, r, http.StatusOK, "create.tmpl", data)
 }
@@ -106,18 +174,7 @@ func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank.")
-	form.CheckField(
-		validator.MaxChars(form.Title, 100),
-		"title",
-		"This field cannot be more then 100 characters long.",
-	)
-	form.CheckField(validator.NotBlank(form.Content), "content", "This field cannot be blank")
-	form.CheckField(
-		validator.PermittedValue(form.Expires, 1, 7, 365),
-		"expires",
-		"This field must be equal 1, 7, or 365.",
-	)
+	app.checkSnippet(&form.Validator, form.Title, form.Content, form.Expires)
 
 	if !form.Valid() {
 		data := app.newTemplateData(r)
@@ -127,25 +184,65 @@ func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	id, err := app.snippets.Insert(r.Context(), form.Title, form.Content, form.Expires)
+	userID := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+
+	id, err := app.snippets.Insert(r.Context(), userID, form.Title, form.Content, form.Expires)
 	if err != nil {
-		app.serverError(w, r, err)
+		app.handleError(w, r, err)
 
 		return
 	}
 
-	app.sessionManager.Put(r.Context(), "flash", "Snippet successfully created!")
+	app.snippetCreated(userID, id, form.Title)
+	app.flash(r, flashSuccess, "Snippet successfully created!")
 
 	http.Redirect(w, r, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
 }
 
+// checkSnippet validates the fields of a new or updated snippet, whether
+// they come from the create form or the API.
+func (app *application) checkSnippet(v *validator.Validator, title, content string, expires int) {
+	v.CheckField(validator.NotBlank(title), "title", "This field cannot be blank.")
+	v.CheckField(
+		validator.MaxChars(title, 100),
+		"title",
+		"This field cannot be more then 100 characters long.",
+	)
+	v.CheckField(validator.NotBlank(content), "content", "This field cannot be blank")
+
+	maxLength := app.currentSettings().MaxSnippetLength
+	v.CheckField(
+		validator.MaxChars(content, maxLength),
+		"content",
+		fmt.Sprintf("This field cannot be more than %d characters long", maxLength),
+	)
+	v.CheckField(
+		validator.PermittedValue(expires, 1, 7, 365),
+		"expires",
+		"This field must be equal 1, 7, or 365.",
+	)
+}
+
 func (app *application) userSignup(w http.ResponseWriter, r *http.Request) {
+	app.renderSignup(w, r, http.StatusOK, userSignupForm{})
+}
+
+// renderSignup renders the signup page, including the CAPTCHA widget when
+// one is configured.
+func (app *application) renderSignup(w http.ResponseWriter, r *http.Request, status int, form userSignupForm) {
 	data := app.newTemplateData(r)
-	data.Form = userSignupForm{}
-	app.render(w, r, http.StatusOK, "signup.tmpl", data)
+	data.Form = form
+	app.withCaptcha(w, &data)
+	app.render(w, r, status, "signup.tmpl", data)
 }
 
 func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
+	if app.currentSettings().RegistrationMode == models.RegistrationClosed {
+		app.renderSignup(w, r, http.StatusForbidden, userSignupForm{})
+
+		return
+	}
+
 	var form userSignupForm
 
 	err := app.decodePostForm(r, &form)
@@ -162,45 +259,78 @@ func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
 		"email",
 		"This field must be a valid email address",
 	)
+	if msg := app.signupDomains.check(form.Email); msg != "" {
+		form.AddFieldError("email", msg)
+	}
 	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
 	form.CheckField(
 		validator.MinChars(form.Password, 8),
 		"password",
 		"This field must be at least 8 characters long",
 	)
+	form.CheckField(form.AcceptTOS, "accept_tos", "You must accept the terms of service")
 
 	if !form.Valid() {
-		data := app.newTemplateData(r)
-		data.Form = form
-		app.render(w, r, http.StatusUnprocessableEntity, "signup.tmpl", data)
+		app.renderSignup(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	human, err := app.verifyCaptcha(r)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if !human {
+		form.AddNonFieldError("Please complete the CAPTCHA challenge")
+		app.renderSignup(w, r, http.StatusUnprocessableEntity, form)
 
 		return
 	}
 
-	err = app.users.Insert(form.Name, form.Email, form.Password)
+	id, err := app.users.Insert(form.Name, form.Email, form.Password, app.tosVersion)
 	if err != nil {
 		if errors.Is(err, models.ErrDuplicateEmail) {
 			form.AddFieldError("email", "Email address is already in use")
-
-			data := app.newTemplateData(r)
-			data.Form = form
-			app.render(w, r, http.StatusUnprocessableEntity, "signup.tmpl", data)
+			app.renderSignup(w, r, http.StatusUnprocessableEntity, form)
 		} else {
-			app.serverError(w, r, err)
+			app.handleError(w, r, err)
 		}
 
 		return
 	}
 
-	app.sessionManager.Put(r.Context(), "flash", "Your signup was succesfull. Please log in.")
+	app.recordAudit(r, id, models.AuditSignup, fmt.Sprintf("tos_version=%d", app.tosVersion))
+
+	app.flash(r, flashSuccess, "Your signup was succesfull. Please log in.")
 
 	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 }
 
 func (app *application) userLogin(w http.ResponseWriter, r *http.Request) {
+	app.renderLogin(w, r, http.StatusOK, userLoginForm{})
+}
+
+// renderLogin shows the login form, offering a passkey sign-in as well when
+// passkeys are enabled.
+func (app *application) renderLogin(w http.ResponseWriter, r *http.Request, status int, form userLoginForm) {
 	data := app.newTemplateData(r)
-	data.Form = userLoginForm{}
-	app.render(w, r, http.StatusOK, "login.tmpl", data)
+	data.Form = form
+
+	if app.webauthn != nil {
+		opts, err := app.newPasskeyChallenge(r)
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		data.Passkey = opts
+	}
+
+	app.render(w, r, status, "login.tmpl", data)
 }
 
 // userLoginPost is a handlerr that validates the user data and adds authenticatedUserID in sessionManager
@@ -222,9 +352,18 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
 	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
 
 	if !form.Valid() {
-		data := app.newTemplateData(r)
-		data.Form = form
-		app.render(w, r, http.StatusUnprocessableEntity, "login.tmpl", data)
+		app.renderLogin(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	if wait := app.loginThrottle.Wait(form.Email); wait > 0 {
+		app.recordAudit(r, 0, models.AuditLoginFailed, "email="+form.Email+" throttled")
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+
+		msg := fmt.Sprintf("Too many failed login attempts. Please try again in %s.", wait.Round(time.Second))
+		app.loginFailed(w, r, http.StatusTooManyRequests, form, msg)
 
 		return
 	}
@@ -232,48 +371,97 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
 	id, err := app.users.Authenticate(form.Email, form.Password)
 	if err != nil {
 		if errors.Is(err, models.ErrInvalidCredentials) {
-			form.AddNonFieldError("Email or password is incorrect")
-
-			data := app.newTemplateData(r)
-			data.Form = form
-			app.render(w, r, http.StatusUnprocessableEntity, "login.tmpl", data)
+			app.loginThrottle.Failure(form.Email)
+			app.recordAudit(r, 0, models.AuditLoginFailed, "email="+form.Email)
+			app.loginFailed(w, r, http.StatusUnprocessableEntity, form, "Email or password is incorrect")
 		} else {
-			app.serverError(w, r, err)
+			app.handleError(w, r, err)
 		}
 
 		return
 	}
 
-	err = app.sessionManager.RenewToken(r.Context())
+	app.loginThrottle.Success(form.Email)
+
+	user, err := app.users.Get(id)
 	if err != nil {
-		app.serverError(w, r, err)
+		app.handleError(w, r, err)
 
 		return
 	}
 
-	app.sessionManager.Put(r.Context(), "authenticatedUserID", id)
+	if user.Suspended() {
+		app.recordAudit(r, id, models.AuditLoginFailed, "suspended")
+		app.loginFailed(w, r, http.StatusForbidden, form, suspensionMessage(user))
 
-	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterLogin")
-	if path != "" {
-		http.Redirect(w, r, path, http.StatusSeeOther)
+		return
+	}
+
+	app.startSession(w, r, user)
+}
+
+// loginFailed re-renders the login form with a non-field error message.
+func (app *application) loginFailed(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	form userLoginForm,
+	msg string,
+) {
+	form.AddNonFieldError(msg)
+
+	app.renderLogin(w, r, status, form)
+}
+
+// startSession signs user in on the current session and redirects to the
+// page they originally asked for.
+func (app *application) startSession(w http.ResponseWriter, r *http.Request, user models.User) {
+	err := app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.handleError(w, r, err)
 
 		return
 	}
 
-	http.Redirect(w, r, "/snippet/create", http.StatusSeeOther)
+	app.sessionManager.Put(r.Context(), "authenticatedUserID", user.ID)
+	app.sessionManager.Put(r.Context(), "authenticatedAt", time.Now().Unix())
+	app.sessionManager.Put(r.Context(), "tosVersion", user.TOSVersion)
+	app.sessionManager.Put(r.Context(), "isAdmin", user.IsAdmin)
+	app.recordAudit(r, user.ID, models.AuditLogin, "")
+
+	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterLogin")
+	if path == "" {
+		path = "/snippet/create"
+	}
+
+	if user.PasswordExpired(app.passwordMaxAge) {
+		app.sessionManager.Put(r.Context(), "passwordExpired", true)
+		app.sessionManager.Put(r.Context(), "redirectPathAfterPasswordRotation", path)
+		path = "/account/password/expired"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
 }
 
+// userLogoutPost signs the user out. Everything the session held about them
+// goes, not just the login, so the next person to use the browser inherits
+// nothing: not the cached admin flag, pending redirects or a token that was
+// about to be shown.
 func (app *application) userLogoutPost(w http.ResponseWriter, r *http.Request) {
 	err := app.sessionManager.RenewToken(r.Context())
 	if err != nil {
-		app.serverError(w, r, err)
+		app.handleError(w, r, err)
 
 		return
 	}
 
-	app.sessionManager.Remove(r.Context(), "authenticatedUserID")
+	if err := app.sessionManager.Clear(r.Context()); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
 
-	app.sessionManager.Put(r.Context(), "flash", "You've been logged out successfully!")
+	app.flash(r, flashInfo, "You've been logged out successfully!")
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -282,6 +470,54 @@ func (app *application) about(w http.ResponseWriter, r *http.Request) {
 	app.render(w, r, http.StatusOK, "about.tmpl", app.newTemplateData(r))
 }
 
+func (app *application) terms(w http.ResponseWriter, r *http.Request) {
+	app.render(w, r, http.StatusOK, "terms.tmpl", app.newTemplateData(r))
+}
+
+func (app *application) termsAccept(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = termsAcceptForm{}
+
+	app.render(w, r, http.StatusOK, "terms_accept.tmpl", data)
+}
+
+func (app *application) termsAcceptPost(w http.ResponseWriter, r *http.Request) {
+	var form termsAcceptForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(form.AcceptTOS, "accept_tos", "You must accept the terms of service to continue")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "terms_accept.tmpl", data)
+
+		return
+	}
+
+	userID := app.authenticatedUserID(r)
+
+	if err := app.users.AcceptTOS(userID, app.tosVersion); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "tosVersion", app.tosVersion)
+
+	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterTerms")
+	if path == "" {
+		path = "/"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
 func (app *application) accountView(w http.ResponseWriter, r *http.Request) {
 	userID := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
 
@@ -290,7 +526,7 @@ func (app *application) accountView(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, models.ErrNoRecord) {
 			http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 		} else {
-			app.serverError(w, r, err)
+			app.handleError(w, r, err)
 		}
 
 		return
@@ -309,15 +545,9 @@ func (app *application) accountPasswordUpdate(w http.ResponseWriter, r *http.Req
 	app.render(w, r, http.StatusOK, "password.tmpl", data)
 }
 
-func (app *application) accountPasswordUpdatePost(w http.ResponseWriter, r *http.Request) {
-	var form accountPasswordUpdateForm
-
-	if err := app.decodePostForm(r, &form); err != nil {
-		app.clientError(w, http.StatusBadRequest)
-
-		return
-	}
-
+// validate records a field error for every problem with the submitted
+// passwords. It does not check the current password against the database.
+func (form *accountPasswordUpdateForm) validate() {
 	form.CheckField(
 		validator.NotBlank(form.CurrentPassword),
 		"currentPassword",
@@ -343,6 +573,18 @@ func (app *application) accountPasswordUpdatePost(w http.ResponseWriter, r *http
 		"newPasswordConfirmation",
 		"Passwords do not match",
 	)
+}
+
+func (app *application) accountPasswordUpdatePost(w http.ResponseWriter, r *http.Request) {
+	var form accountPasswordUpdateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate()
 
 	if !form.Valid() {
 		data := app.newTemplateData(r)
@@ -358,20 +600,287 @@ func (app *application) accountPasswordUpdatePost(w http.ResponseWriter, r *http
 	err := app.users.PasswordUpdate(userID, form.CurrentPassword, form.NewPassword)
 	if err != nil {
 		if errors.Is(err, models.ErrInvalidCredentials) {
-			form.AddFieldError("currentPassowrd", "Current password is incorrect")
+			form.AddFieldError("currentPassword", "Current password is incorrect")
 
 			data := app.newTemplateData(r)
 			data.Form = form
 
 			app.render(w, r, http.StatusUnprocessableEntity, "password.tmpl", data)
 		} else {
-			app.serverError(w, r, err)
+			app.handleError(w, r, err)
 		}
 
 		return
 	}
 
-	app.sessionManager.Put(r.Context(), "flash", "Your password has been updated!")
+	// A password change is a privilege change: issue a fresh session token so
+	// that a session ID captured before the change stops working.
+	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, userID, models.AuditPasswordChange, "")
+
+	app.flash(r, flashSuccess, "Your password has been updated!")
 
 	http.Redirect(w, r, "/account/view", http.StatusSeeOther)
 }
+
+// minContentHashPrefix is the shortest hash prefix accepted in pinned raw
+// URLs; anything shorter is too easy to collide with edited content.
+const minContentHashPrefix = 8
+
+func (app *application) snippetRaw(w http.ResponseWriter, r *http.Request) {
+	id := pathInt(r, "id")
+
+	snippet, err := app.snippets.Get(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.writeSnippetText(w, r, snippet)
+}
+
+// writeSnippetText answers with the snippet's content as plain text.
+func (app *application) writeSnippetText(w http.ResponseWriter, r *http.Request, snippet models.Snippet) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if notModified(w, r, snippetETag(snippet), snippet.Updated) {
+		return
+	}
+
+	if _, err := w.Write([]byte(snippet.Content)); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// writeSnippetJSON answers with the snippet as a JSON document.
+func (app *application) writeSnippetJSON(w http.ResponseWriter, r *http.Request, snippet models.Snippet) {
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if notModified(w, r, snippetJSONETag(snippet), snippet.Updated) {
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPISnippet(snippet))
+}
+
+// maxPinnedAge caps how long shared caches keep a pinned raw URL.
+const maxPinnedAge = 365 * 24 * time.Hour
+
+// snippetRawPinned serves the raw content only while it still matches the
+// hash prefix in the URL; once the content is edited the URL answers 410
+// Gone. The response never changes while it is served, so shared caches may
+// keep it until the snippet expires, but no longer: it is not immutable,
+// since it stops being served.
+func (app *application) snippetRawPinned(w http.ResponseWriter, r *http.Request) {
+	id := pathInt(r, "id")
+	prefix := pathString(r, "hash")
+
+	snippet, err := app.snippets.Get(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	hash := snippet.ContentHash()
+	if !strings.HasPrefix(hash, prefix) {
+		app.clientError(w, http.StatusGone)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	maxAge := min(max(time.Until(snippet.Expires), 0), maxPinnedAge)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+
+	if notModified(w, r, `"`+hash+`"`, snippet.Created) {
+		return
+	}
+
+	if _, err := w.Write([]byte(snippet.Content)); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func (app *application) reauthenticate(w http.ResponseWriter, r *http.Request) {
+	app.renderReauthenticate(w, r, http.StatusOK, reauthenticateForm{})
+}
+
+// renderReauthenticate shows the password form for reauthenticating,
+// offering a passkey as well when passkeys are enabled.
+func (app *application) renderReauthenticate(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	form reauthenticateForm,
+) {
+	data := app.newTemplateData(r)
+	data.Form = form
+
+	if app.webauthn != nil {
+		opts, err := app.newPasskeyChallenge(r)
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		data.Passkey = opts
+	}
+
+	app.render(w, r, status, "reauthenticate.tmpl", data)
+}
+
+func (app *application) reauthenticatePost(w http.ResponseWriter, r *http.Request) {
+	var form reauthenticateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	if !form.Valid() {
+		app.renderReauthenticate(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	user, err := app.users.Get(app.authenticatedUserID(r))
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if wait := app.loginThrottle.Wait(user.Email); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		form.AddNonFieldError(fmt.Sprintf(
+			"Too many failed attempts. Please try again in %s.", wait.Round(time.Second)))
+		app.renderReauthenticate(w, r, http.StatusTooManyRequests, form)
+
+		return
+	}
+
+	_, err = app.users.Authenticate(user.Email, form.Password)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			app.loginThrottle.Failure(user.Email)
+			app.recordAudit(r, user.ID, models.AuditReauthFailed, "")
+			form.AddFieldError("password", "Password is incorrect")
+			app.renderReauthenticate(w, r, http.StatusUnprocessableEntity, form)
+		} else {
+			app.handleError(w, r, err)
+		}
+
+		return
+	}
+
+	app.loginThrottle.Success(user.Email)
+
+	app.reauthenticated(w, r, user.ID, "")
+}
+
+// reauthenticated refreshes the time the user last proved who they are and
+// sends them back to the page that asked for it.
+func (app *application) reauthenticated(w http.ResponseWriter, r *http.Request, userID int, details string) {
+	// Reauthenticating unlocks sensitive actions, so it gets a fresh token
+	// like any other privilege change.
+	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "authenticatedAt", time.Now().Unix())
+	app.recordAudit(r, userID, models.AuditReauth, details)
+
+	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterReauth")
+	if path == "" {
+		path = "/account/view"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+func (app *application) accountPasswordExpired(w http.ResponseWriter, r *http.Request) {
+	if !app.sessionManager.GetBool(r.Context(), "passwordExpired") {
+		http.Redirect(w, r, "/account/password", http.StatusSeeOther)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = accountPasswordUpdateForm{}
+
+	app.render(w, r, http.StatusOK, "password_expired.tmpl", data)
+}
+
+func (app *application) accountPasswordExpiredPost(w http.ResponseWriter, r *http.Request) {
+	var form accountPasswordUpdateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate()
+	form.CheckField(
+		form.NewPassword != form.CurrentPassword,
+		"newPassword",
+		"Your new password must be different from the current one",
+	)
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "password_expired.tmpl", data)
+
+		return
+	}
+
+	userID := app.authenticatedUserID(r)
+
+	err := app.users.PasswordUpdate(userID, form.CurrentPassword, form.NewPassword)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("currentPassword", "Current password is incorrect")
+
+			data := app.newTemplateData(r)
+			data.Form = form
+			app.render(w, r, http.StatusUnprocessableEntity, "password_expired.tmpl", data)
+		} else {
+			app.handleError(w, r, err)
+		}
+
+		return
+	}
+
+	if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, userID, models.AuditPasswordChange, "rotation")
+
+	app.sessionManager.Remove(r.Context(), "passwordExpired")
+	app.flash(r, flashSuccess, "Your password has been updated!")
+
+	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterPasswordRotation")
+	if path == "" {
+		path = "/"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
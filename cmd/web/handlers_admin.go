@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+const auditDateLayout = "2006-01-02"
+
+type auditFilterForm struct {
+	User                string `form:"user"`
+	From                string `form:"from"`
+	To                  string `form:"to"`
+	validator.Validator `form:"-"`
+}
+
+type logFilterForm struct {
+	Level               string `form:"level"`
+	Route               string `form:"route"`
+	User                string `form:"user"`
+	RequestID           string `form:"request_id"`
+	validator.Validator `form:"-"`
+}
+
+// logLevels are the levels offered by the log viewer, lowest first.
+var logLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// logViewLimit caps the number of records shown by the log viewer.
+const logViewLimit = 200
+
+type userSuspendForm struct {
+	Days                int    `form:"days"`
+	Reason              string `form:"reason"`
+	validator.Validator `form:"-"`
+}
+
+// filter converts the submitted form into a models.AuditFilter, recording a
+// field error for every value that cannot be parsed.
+func (f *auditFilterForm) filter() models.AuditFilter {
+	var filter models.AuditFilter
+
+	if f.User != "" {
+		id, err := strconv.Atoi(f.User)
+		f.CheckField(err == nil && id > 0, "user", "This field must be a positive user ID")
+		filter.UserID = id
+	}
+
+	if f.From != "" {
+		from, err := time.Parse(auditDateLayout, f.From)
+		f.CheckField(err == nil, "from", "This field must be a date (YYYY-MM-DD)")
+		filter.From = from
+	}
+
+	if f.To != "" {
+		to, err := time.Parse(auditDateLayout, f.To)
+		f.CheckField(err == nil, "to", "This field must be a date (YYYY-MM-DD)")
+		// Make the upper bound inclusive of the whole day.
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter
+}
+
+func (app *application) adminAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	form := auditFilterForm{
+		User: query.Get("user"),
+		From: query.Get("from"),
+		To:   query.Get("to"),
+	}
+
+	filter := form.filter()
+
+	data := app.newTemplateData(r)
+	data.Form = form
+
+	if !form.Valid() {
+		app.render(w, r, http.StatusUnprocessableEntity, "audit.tmpl", data)
+
+		return
+	}
+
+	events, err := app.audit.List(r.Context(), filter)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data.AuditEvents = events
+
+	app.render(w, r, http.StatusOK, "audit.tmpl", data)
+}
+
+// filter converts the submitted form into a logbuffer.Filter, recording a
+// field error for every value that cannot be parsed.
+func (f *logFilterForm) filter() logbuffer.Filter {
+	filter := logbuffer.Filter{
+		Route:     f.Route,
+		RequestID: f.RequestID,
+		Limit:     logViewLimit,
+	}
+
+	if f.Level != "" {
+		err := filter.Level.UnmarshalText([]byte(f.Level))
+		f.CheckField(err == nil && validator.PermittedValue(f.Level, logLevels...), "level", "This field is invalid")
+	}
+
+	if f.User != "" {
+		id, err := strconv.Atoi(f.User)
+		f.CheckField(err == nil && id > 0, "user", "This field must be a positive user ID")
+		filter.UserID = f.User
+	}
+
+	return filter
+}
+
+func (app *application) adminLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	form := logFilterForm{
+		Level:     query.Get("level"),
+		Route:     query.Get("route"),
+		User:      query.Get("user"),
+		RequestID: query.Get("request_id"),
+	}
+
+	filter := form.filter()
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.LogLevels = logLevels
+
+	if !form.Valid() {
+		app.render(w, r, http.StatusUnprocessableEntity, "admin_logs.tmpl", data)
+
+		return
+	}
+
+	data.LogRecords = app.logs.Records(filter)
+
+	app.render(w, r, http.StatusOK, "admin_logs.tmpl", data)
+}
+
+func (app *application) adminErrors(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.CaptureEnabled = app.captures != nil
+
+	if app.captures != nil {
+		data.Captures = app.captures.list()
+	}
+
+	app.render(w, r, http.StatusOK, "admin_errors.tmpl", data)
+}
+
+func (app *application) adminErrorView(w http.ResponseWriter, r *http.Request) {
+	if app.captures == nil {
+		app.notFound(w, r)
+
+		return
+	}
+
+	c, ok := app.captures.get(r.PathValue("capture"))
+	if !ok {
+		app.notFound(w, r)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Capture = c
+
+	app.render(w, r, http.StatusOK, "admin_error.tmpl", data)
+}
+
+func (app *application) adminCache(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+
+	for _, c := range app.caches {
+		data.CacheStats = append(data.CacheStats, c.Stats())
+	}
+
+	app.render(w, r, http.StatusOK, "admin_cache.tmpl", data)
+}
+
+func (app *application) adminWorkers(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.WorkerStats = app.workers.Stats()
+	data.BackgroundTasks = app.workers.Tasks()
+
+	app.render(w, r, http.StatusOK, "admin_workers.tmpl", data)
+}
+
+// adminTargetUser loads the user named by the {id} path value, writing a 404
+// and returning false when there is no such user.
+func (app *application) adminTargetUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	id := pathInt(r, "id")
+
+	user, err := app.users.Get(id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+func (app *application) adminUserView(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.User = user
+	data.Form = userSuspendForm{Days: 7}
+
+	app.render(w, r, http.StatusOK, "admin_user.tmpl", data)
+}
+
+func (app *application) adminUserSuspendPost(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	var form userSuspendForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(
+		form.Days >= 1 && form.Days <= 3650,
+		"days",
+		"This field must be between 1 and 3650 days",
+	)
+	form.CheckField(validator.NotBlank(form.Reason), "reason", "This field cannot be blank")
+	form.CheckField(
+		validator.MaxChars(form.Reason, 500),
+		"reason",
+		"This field cannot be more than 500 characters long",
+	)
+	form.CheckField(
+		user.ID != app.authenticatedUserID(r),
+		"days",
+		"You cannot suspend your own account",
+	)
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.User = user
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "admin_user.tmpl", data)
+
+		return
+	}
+
+	until := time.Now().UTC().AddDate(0, 0, form.Days)
+
+	if err := app.users.Suspend(user.ID, until, form.Reason); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, user.ID, models.AuditSuspend,
+		fmt.Sprintf("by=%d days=%d reason=%s", app.authenticatedUserID(r), form.Days, form.Reason))
+
+	app.flash(r, flashSuccess, "User has been suspended.")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminUserUnsuspendPost(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.users.Unsuspend(user.ID); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, user.ID, models.AuditUnsuspend, fmt.Sprintf("by=%d", app.authenticatedUserID(r)))
+
+	app.flash(r, flashSuccess, "User has been unsuspended.")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d", user.ID), http.StatusSeeOther)
+}
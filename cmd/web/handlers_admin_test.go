@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestAdminAudit(t *testing.T) {
+	t.Run("Unauthenticated", func(t *testing.T) {
+		app := newTestApplication(t)
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, headers, _ := ts.get(t, "/admin/audit")
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/user/login")
+	})
+
+	t.Run("Non-admin", func(t *testing.T) {
+		app := newTestApplication(t)
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		ts.login(t, "alice@example.com", "pa$$word")
+
+		code, _, _ := ts.get(t, "/admin/audit")
+		assert.Equal(t, code, http.StatusForbidden)
+	})
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "No filter",
+			urlPath:  "/admin/audit",
+			wantCode: http.StatusOK,
+			wantBody: "<td>login</td>",
+		},
+		{
+			name:     "User filter",
+			urlPath:  "/admin/audit?user=1&from=2024-01-01&to=2024-12-31",
+			wantCode: http.StatusOK,
+			wantBody: "<td>login</td>",
+		},
+		{
+			name:     "Unmatched user",
+			urlPath:  "/admin/audit?user=7",
+			wantCode: http.StatusOK,
+			wantBody: "No audit events match this filter.",
+		},
+		{
+			name:     "Invalid user",
+			urlPath:  "/admin/audit?user=bob",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field must be a positive user ID",
+		},
+		{
+			name:     "Invalid date",
+			urlPath:  "/admin/audit?from=yesterday",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field must be a date (YYYY-MM-DD)",
+		},
+	}
+
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestAdminUserSuspend(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	_, _, body := ts.get(t, "/admin/users/1")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		days         string
+		reason       string
+		wantCode     int
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:         "Valid submission",
+			urlPath:      "/admin/users/1/suspend",
+			days:         "7",
+			reason:       "Spam",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/admin/users/1",
+		},
+		{
+			name:     "Blank reason",
+			urlPath:  "/admin/users/1/suspend",
+			days:     "7",
+			reason:   "",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field cannot be blank",
+		},
+		{
+			name:     "Too many days",
+			urlPath:  "/admin/users/1/suspend",
+			days:     "10000",
+			reason:   "Spam",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field must be between 1 and 3650 days",
+		},
+		{
+			name:     "Self suspension",
+			urlPath:  "/admin/users/2/suspend",
+			days:     "7",
+			reason:   "Oops",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "You cannot suspend your own account",
+		},
+		{
+			name:     "Unknown user",
+			urlPath:  "/admin/users/99/suspend",
+			days:     "7",
+			reason:   "Spam",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("days", tt.days)
+			form.Add("reason", tt.reason)
+			form.Add("csrf_token", validCSRFToken)
+
+			//nolint:govet // body is shadowed for readability
+			code, headers, body := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantLocation != "" {
+				assert.Equal(t, headers.Get("Location"), tt.wantLocation)
+			}
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("Unsuspend", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("csrf_token", validCSRFToken)
+
+		code, headers, _ := ts.postForm(t, "/admin/users/3/unsuspend", form)
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/admin/users/3")
+	})
+}
+
+func TestAdminRequiresRecentAuth(t *testing.T) {
+	app := newTestApplication(t)
+	// Treat every login as stale so the guard always triggers.
+	app.reauthWindow = -time.Second
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	_, _, body := ts.get(t, "/admin/users/3")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, headers, _ := ts.postFormFrom(t, "/admin/users/3", "/admin/users/3/unsuspend", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/reauthenticate")
+
+	form.Set("password", "wrong")
+
+	code, _, body = ts.postForm(t, "/account/reauthenticate", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "Password is incorrect")
+
+	form.Set("password", "pa$$word")
+
+	code, headers, _ = ts.postForm(t, "/account/reauthenticate", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	// The user is sent back to the page the form was on, since the POST
+	// cannot be replayed.
+	assert.Equal(t, headers.Get("Location"), "/admin/users/3")
+}
+
+func TestAdminLogs(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	app.logger.Error("database timeout", slog.String("uri", "/snippet/view/1"), slog.Int("user_id", 1),
+		slog.String("request_id", "req-42"))
+	app.logger.Warn("slow query", slog.String("uri", "/snippet/create"))
+	app.logger.Info("not found", slog.String("uri", "/<script>alert(1)</script>"))
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, _ := ts.get(t, "/admin/logs")
+	assert.Equal(t, code, http.StatusForbidden)
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{"No filter", "/admin/logs", http.StatusOK, "<td>slow query</td>"},
+		{"Level", "/admin/logs?level=ERROR", http.StatusOK, "<td>database timeout</td>"},
+		{"Route", "/admin/logs?route=/snippet/create", http.StatusOK, "<td>slow query</td>"},
+		{"User", "/admin/logs?user=1", http.StatusOK, "<td>database timeout</td>"},
+		{"Request ID", "/admin/logs?request_id=req-42", http.StatusOK, "<code>request_id=req-42</code>"},
+		{"Escaped", "/admin/logs?route=alert", http.StatusOK, "<code>uri=/&lt;script&gt;alert(1)&lt;/script&gt;</code>"},
+		{"No match", "/admin/logs?request_id=nope", http.StatusOK, "No log records match this filter."},
+		{"Invalid level", "/admin/logs?level=LOUD", http.StatusUnprocessableEntity, "This field is invalid"},
+		{"Invalid user", "/admin/logs?user=bob", http.StatusUnprocessableEntity, "This field must be a positive user ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	_, _, body := ts.get(t, "/admin/logs?level=ERROR")
+	assert.Equal(t, strings.Contains(body, "slow query"), false)
+}
+
+func TestAdminErrors(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	_, _, body := ts.get(t, "/admin/errors")
+	assert.StringContains(t, body, "Request capture is disabled")
+
+	app.captures = newCaptureStore(time.Hour)
+
+	r := httptest.NewRequest(http.MethodGet, "/snippet/view/1?tab=raw", nil)
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("User-Agent", "<script>alert(1)</script>")
+	r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey, "req-7"))
+	app.serverError(httptest.NewRecorder(), r, errors.New("database is down"))
+
+	captures := app.captures.list()
+	assert.Equal(t, len(captures), 1)
+
+	// Captures get their own ID rather than the client-supplied request ID.
+	id := captures[0].ID
+	assert.Equal(t, id != "req-7", true)
+	assert.Equal(t, captures[0].RequestID, "req-7")
+
+	code, _, body := ts.get(t, "/admin/errors")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<a href='/admin/errors/"+id+"'>")
+	assert.StringContains(t, body, "<td>database is down</td>")
+
+	code, _, body = ts.get(t, "/admin/errors/"+id)
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>GET /snippet/view/1</td>")
+	assert.StringContains(t, body, "<td>tab</td>")
+	assert.StringContains(t, body, "<code>&lt;script&gt;alert(1)&lt;/script&gt;</code>")
+	assert.Equal(t, strings.Contains(body, "session=secret"), false)
+
+	assert.StringContains(t, body, "<a href='/admin/logs?request_id=req-7'>")
+
+	// The capture is referenced from the log line.
+	_, _, body = ts.get(t, "/admin/logs?request_id=req-7")
+	assert.StringContains(t, body, "<td>database is down</td>")
+	assert.StringContains(t, body, "capture_id="+id)
+
+	code, _, _ = ts.get(t, "/admin/errors/unknown")
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestAdminWorkers(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	app.workers.Go("mailer", func(context.Context) { <-done })
+	app.workers.Go("snippet-listener", func(context.Context) { panic("connection lost") })
+
+	for len(app.workers.Stopped()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/admin/workers")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>mailer</td>\n<td>running</td>")
+	assert.StringContains(t, body, "<td>snippet-listener</td>\n<td>stopped</td>")
+	assert.StringContains(t, body, "<td>connection lost</td>")
+}
+
+func TestAdminJobs(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/admin/jobs")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "No jobs are scheduled.")
+
+	err := app.scheduleJobs(jobsConfig{purgeSchedule: "0 3 * * *", rollupSchedule: "@hourly"}, nil)
+	assert.NilError(t, err)
+
+	_, _, body = ts.get(t, "/admin/jobs")
+	assert.StringContains(t, body, "<td>purge-expired-snippets</td>\n<td><code>0 3 * * *</code></td>\n<td>never</td>")
+	assert.StringContains(t, body, "<td>rollup-daily-stats</td>\n<td><code>@hourly</code></td>")
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+// securityTxtValidity is how far in the future the Expires field of
+// security.txt lies unless the operator sets a date. The file is generated on
+// every request, so it never actually goes stale while the operator keeps the
+// contacts configured.
+const securityTxtValidity = 180 * 24 * time.Hour
+
+// contacts are the operator's security and abuse contacts.
+type contacts struct {
+	Security []string
+	Policy   string
+	Abuse    string
+	// Expires, if set, replaces the rolling expiry of security.txt.
+	Expires time.Time
+	// SecurityTxt, if set, is served verbatim instead of the generated
+	// security.txt, e.g. a file signed with the operator's PGP key.
+	SecurityTxt []byte
+}
+
+// newContacts parses the contact flags. Security contacts are a
+// comma-separated list of URIs; bare email addresses become mailto: URIs.
+func newContacts(security, policy, abuse string) contacts {
+	c := contacts{
+		Policy: strings.TrimSpace(policy),
+		Abuse:  strings.TrimSpace(abuse),
+	}
+
+	for contact := range strings.SplitSeq(security, ",") {
+		contact = strings.TrimSpace(contact)
+		if contact == "" {
+			continue
+		}
+
+		if !strings.Contains(contact, ":") && strings.Contains(contact, "@") {
+			contact = "mailto:" + contact
+		}
+
+		c.Security = append(c.Security, contact)
+	}
+
+	return c
+}
+
+// servesSecurityTxt reports whether /.well-known/security.txt is served
+// rather than answered with a 404.
+func (c contacts) servesSecurityTxt() bool {
+	return c.SecurityTxt != nil || len(c.Security) > 0
+}
+
+// loadSecurityTxt applies the -security-expires and -security-txt-file
+// flags. The expiry is a date such as 2026-12-31 and must lie in the future.
+func (c *contacts) loadSecurityTxt(expires, file string) error {
+	if expires != "" {
+		t, err := time.Parse(time.DateOnly, expires)
+		if err != nil {
+			return fmt.Errorf("parsing -security-expires: %w", err)
+		}
+
+		if !t.After(time.Now()) {
+			return errors.New("-security-expires must be in the future")
+		}
+
+		c.Expires = t
+	}
+
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("reading security.txt file: %w", err)
+		}
+
+		c.SecurityTxt = b
+	}
+
+	return nil
+}
+
+// securityTxt serves an RFC 9116 security.txt: the operator's own file if
+// one is configured, otherwise one built from the configured contacts, or a
+// 404 when there are none.
+func (app *application) securityTxt(w http.ResponseWriter, r *http.Request) {
+	if app.contacts.SecurityTxt != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if _, err := w.Write(app.contacts.SecurityTxt); err != nil {
+			app.logger.ErrorContext(r.Context(), err.Error())
+		}
+
+		return
+	}
+
+	if !app.contacts.servesSecurityTxt() {
+		app.notFound(w, r)
+
+		return
+	}
+
+	var b strings.Builder
+
+	for _, contact := range app.contacts.Security {
+		fmt.Fprintf(&b, "Contact: %s\n", contact)
+	}
+
+	expires := app.contacts.Expires
+	if expires.IsZero() {
+		expires = time.Now().UTC().Add(securityTxtValidity).Truncate(24 * time.Hour)
+	}
+
+	fmt.Fprintf(&b, "Expires: %s\n", expires.Format(time.RFC3339))
+
+	if app.contacts.Policy != "" {
+		fmt.Fprintf(&b, "Policy: %s\n", app.contacts.Policy)
+	}
+
+	fmt.Fprintf(&b, "Preferred-Languages: en\n")
+	fmt.Fprintf(&b, "Canonical: %s/.well-known/security.txt\n", app.baseURL)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+// maxOpenReports bounds the abuse reports listed for moderators at once.
+const maxOpenReports = 100
+
+type abuseReportForm struct {
+	Snippet             string `form:"snippet"`
+	Reason              string `form:"reason"`
+	Contact             string `form:"contact"`
+	validator.Validator `form:"-"`
+}
+
+// abuse tells outside users how to report abusive snippets.
+func (app *application) abuse(w http.ResponseWriter, r *http.Request) {
+	if app.contacts.Abuse == "" {
+		app.notFound(w, r)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = abuseReportForm{}
+
+	app.render(w, r, http.StatusOK, "abuse.tmpl", data)
+}
+
+// abusePost queues a report from the abuse form for the moderators, who
+// work through it on /admin/reports like any other report.
+func (app *application) abusePost(w http.ResponseWriter, r *http.Request) {
+	if app.contacts.Abuse == "" {
+		app.notFound(w, r)
+
+		return
+	}
+
+	var form abuseReportForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	snippetID, ok := reportedSnippetID(form.Snippet)
+	form.CheckField(ok, "snippet", "Enter the address or number of a snippet")
+
+	if ok {
+		_, err := app.snippets.Get(r.Context(), snippetID)
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			form.AddFieldError("snippet", "There is no snippet at this address")
+		case err != nil:
+			app.handleError(w, r, err)
+
+			return
+		}
+	}
+
+	form.CheckField(validator.NotBlank(form.Reason), "reason", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Reason, 2000), "reason", "This field cannot be more than 2000 characters long")
+	form.CheckField(validator.MaxChars(form.Contact, 255), "contact", "This field cannot be more than 255 characters long")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+
+		app.render(w, r, http.StatusUnprocessableEntity, "abuse.tmpl", data)
+
+		return
+	}
+
+	if _, err := app.reports.Insert(r.Context(), snippetID, form.Reason, strings.TrimSpace(form.Contact)); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "Thank you. Your report has been passed on to the moderators.")
+
+	http.Redirect(w, r, "/abuse", http.StatusSeeOther)
+}
+
+// reportedSnippetID reads the snippet a report is about from either its
+// address, such as https://example.com/snippet/view/123, or its number.
+func reportedSnippetID(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "/")
+
+	if i := strings.LastIndex(s, "/snippet/view/"); i >= 0 {
+		s = s[i+len("/snippet/view/"):]
+	}
+
+	id, err := strconv.Atoi(s)
+
+	return id, err == nil && id > 0
+}
+
+// adminReports lists the abuse reports waiting for a moderator.
+func (app *application) adminReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := app.reports.Open(r.Context(), maxOpenReports)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Reports = reports
+
+	app.render(w, r, http.StatusOK, "admin_reports.tmpl", data)
+}
+
+// adminReportResolvePost takes a report off the queue once a moderator has
+// dealt with it.
+func (app *application) adminReportResolvePost(w http.ResponseWriter, r *http.Request) {
+	if err := app.reports.Resolve(r.Context(), pathInt(r, "id")); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "The report has been resolved.")
+
+	http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+}
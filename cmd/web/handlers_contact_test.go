@@ -0,0 +1,251 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNewContacts(t *testing.T) {
+	c := newContacts(" security@example.com, https://example.com/report ,", "https://example.com/policy", "abuse@example.com")
+
+	assert.Equal(t, len(c.Security), 2)
+	assert.Equal(t, c.Security[0], "mailto:security@example.com")
+	assert.Equal(t, c.Security[1], "https://example.com/report")
+	assert.Equal(t, c.Policy, "https://example.com/policy")
+	assert.Equal(t, c.Abuse, "abuse@example.com")
+}
+
+func TestSecurityTxt(t *testing.T) {
+	t.Run("Not configured", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/.well-known/security.txt")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.baseURL = "https://snippetbox.example.com"
+		app.contacts = newContacts("security@example.com", "https://example.com/policy", "")
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, header, body := ts.get(t, "/.well-known/security.txt")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, header.Get("Content-Type"), "text/plain; charset=utf-8")
+		assert.StringContains(t, body, "Contact: mailto:security@example.com\n")
+		assert.StringContains(t, body, "Policy: https://example.com/policy\n")
+		assert.StringContains(t, body, "Canonical: https://snippetbox.example.com/.well-known/security.txt")
+
+		for line := range strings.SplitSeq(body, "\n") {
+			if value, ok := strings.CutPrefix(line, "Expires: "); ok {
+				expires, err := time.Parse(time.RFC3339, value)
+				assert.NilError(t, err)
+				assert.Equal(t, expires.After(time.Now().AddDate(0, 5, 0)), true)
+			}
+		}
+	})
+}
+
+func TestLoadSecurityTxt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "security.txt")
+	assert.NilError(t, os.WriteFile(file, []byte("Contact: mailto:a@example.com\n"), 0o600))
+
+	future := time.Now().AddDate(0, 3, 0).Format(time.DateOnly)
+
+	tests := []struct {
+		name    string
+		expires string
+		file    string
+		wantErr bool
+	}{
+		{name: "Nothing set"},
+		{name: "Future date", expires: future},
+		{name: "Past date", expires: "2020-01-01", wantErr: true},
+		{name: "Not a date", expires: "soon", wantErr: true},
+		{name: "File", file: file},
+		{name: "Missing file", file: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c contacts
+
+			err := c.loadSecurityTxt(tt.expires, tt.file)
+			assert.Equal(t, err != nil, tt.wantErr)
+		})
+	}
+}
+
+func TestSecurityTxtOverrides(t *testing.T) {
+	t.Run("Fixed expiry", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contacts = newContacts("security@example.com", "", "")
+		app.contacts.Expires = time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		_, _, body := ts.get(t, "/.well-known/security.txt")
+
+		assert.StringContains(t, body, "Expires: 2030-06-30T00:00:00Z\n")
+	})
+
+	t.Run("Operator file", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contacts.SecurityTxt = []byte("-----BEGIN PGP SIGNED MESSAGE-----\nContact: mailto:a@example.com\n")
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, body := ts.get(t, "/.well-known/security.txt")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body, "-----BEGIN PGP SIGNED MESSAGE-----\nContact: mailto:a@example.com")
+	})
+}
+
+func TestAbuse(t *testing.T) {
+	t.Run("Not configured", func(t *testing.T) {
+		app := newTestApplication(t)
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, _ := ts.get(t, "/abuse")
+
+		assert.Equal(t, code, http.StatusNotFound)
+
+		_, _, body := ts.get(t, "/")
+		assert.Equal(t, strings.Contains(body, "Report abuse"), false)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contacts = newContacts("", "", "abuse@example.com")
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		code, _, body := ts.get(t, "/abuse")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "href='mailto:abuse@example.com?subject=Abuse%20report'")
+		assert.Equal(t, strings.Contains(body, "security.txt"), false)
+
+		_, _, body = ts.get(t, "/")
+		assert.StringContains(t, body, "Report abuse")
+	})
+
+	t.Run("Security contact", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.contacts = newContacts("security@example.com", "", "abuse@example.com")
+
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		_, _, body := ts.get(t, "/abuse")
+		assert.StringContains(t, body, "<a href='/.well-known/security.txt'>")
+	})
+}
+
+func TestAbuseReport(t *testing.T) {
+	app := newTestApplication(t)
+	app.contacts = newContacts("", "", "abuse@example.com")
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/abuse")
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		snippet  string
+		reason   string
+		wantCode int
+		wantBody string
+	}{
+		{"Unknown snippet", "https://snippetbox.test/snippet/view/99", "Spam", http.StatusUnprocessableEntity, "There is no snippet at this address"},
+		{"Not a snippet", "https://example.com/", "Spam", http.StatusUnprocessableEntity, "Enter the address or number of a snippet"},
+		{"No reason", "1", "", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Escaped", "1", "<script>", http.StatusUnprocessableEntity, "&lt;script&gt;"},
+		{"Valid", "https://snippetbox.test/snippet/view/1", "Leaks my <b>address</b>", http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("snippet", tt.snippet)
+			form.Add("reason", tt.reason)
+			form.Add("csrf_token", csrfToken)
+
+			if tt.name == "Escaped" {
+				form.Set("snippet", "<script>")
+			}
+
+			code, _, body := ts.postForm(t, "/abuse", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	// The report reaches the moderators.
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	_, _, body = ts.get(t, "/admin/reports")
+	assert.StringContains(t, body, "<a href='/snippet/view/1'>#1</a>")
+	assert.StringContains(t, body, "Leaks my &lt;b&gt;address&lt;/b&gt;")
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ := ts.postForm(t, "/admin/reports/1/resolve", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/admin/reports")
+
+	_, _, body = ts.get(t, "/admin/reports")
+	assert.StringContains(t, body, "No abuse reports are waiting.")
+
+	code, _, _ = ts.postForm(t, "/admin/reports/1/resolve", form)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestReportedSnippetID(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"123", 123, true},
+		{" https://example.com/snippet/view/7/ ", 7, true},
+		{"/base/snippet/view/42", 42, true},
+		{"https://example.com/", 0, false},
+		{"0", 0, false},
+		{"-1", -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			id, ok := reportedSnippetID(tt.in)
+			assert.Equal(t, ok, tt.wantOK)
+
+			if ok {
+				assert.Equal(t, id, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// exportCooldown is the minimum time between two export requests.
+const exportCooldown = 24 * time.Hour
+
+func (app *application) accountExport(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+
+	export, err := app.exports.Latest(r.Context(), app.authenticatedUserID(r))
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data.Export = export
+
+	app.render(w, r, http.StatusOK, "export.tmpl", data)
+}
+
+func (app *application) accountExportPost(w http.ResponseWriter, r *http.Request) {
+	userID := app.authenticatedUserID(r)
+
+	latest, err := app.exports.Latest(r.Context(), userID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if err == nil && time.Since(latest.Created) < exportCooldown {
+		app.flash(r, flashWarning, "You can only request one data export per day.")
+		http.Redirect(w, r, "/account/export-data", http.StatusSeeOther)
+
+		return
+	}
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	export, token, err := app.exports.Create(r.Context(), userID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.workers.Background(func() {
+		app.generateExport(user, export, token)
+	})
+
+	app.recordAudit(r, userID, models.AuditDataExport, fmt.Sprintf("export=%d", export.ID))
+
+	app.flash(r, flashInfo, "Your export is being prepared. We'll email you a download link.")
+
+	http.Redirect(w, r, "/account/export-data", http.StatusSeeOther)
+}
+
+func (app *application) accountExportDownload(w http.ResponseWriter, r *http.Request) {
+	export, err := app.exports.Download(r.Context(), app.authenticatedUserID(r), r.PathValue("token"))
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	filename := fmt.Sprintf("snippetbox-export-%s.zip", export.Created.UTC().Format("2006-01-02"))
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Archive)))
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if _, err := w.Write(export.Archive); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
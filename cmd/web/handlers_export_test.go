@@ -0,0 +1,108 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+func TestAccountExport(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// The admin has no previous export.
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/account/export-data")
+	assert.Equal(t, code, http.StatusOK)
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ := ts.postForm(t, "/account/export-data", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/export-data")
+
+	app.workers.Wait()
+
+	sent := app.mailer.(*testMailer).messages()
+	assert.Equal(t, len(sent), 1)
+	assert.Equal(t, sent[0].To, "admin@example.com")
+	assert.StringContains(t, sent[0].Body, "includes 0 snippets")
+	assert.StringContains(t, sent[0].Body, "https://snippetbox.test/account/export-data/"+mocks.MockExportToken)
+}
+
+func TestAccountExportCooldown(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// Alice requested an export moments ago.
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	_, _, body := ts.get(t, "/account/export-data")
+	assert.StringContains(t, body, "Your last export was ready")
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, _, _ := ts.postForm(t, "/account/export-data", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	_, _, body = ts.get(t, "/account/export-data")
+	assert.StringContains(t, body, "You can only request one data export per day.")
+}
+
+func TestAccountExportDownload(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, headers, _ := ts.get(t, "/account/export-data/"+mocks.MockExportToken)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, headers.Get("Content-Type"), "application/zip")
+
+	code, _, _ = ts.get(t, "/account/export-data/wrong-token")
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestBuildExport(t *testing.T) {
+	app := newTestApplication(t)
+
+	user, err := app.users.Get(1)
+	assert.NilError(t, err)
+
+	archive, count, err := app.buildExport(t.Context(), user)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 1)
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NilError(t, err)
+
+	contents := map[string]string{}
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NilError(t, err)
+
+		b, err := io.ReadAll(rc)
+		assert.NilError(t, err)
+		rc.Close()
+
+		contents[f.Name] = string(b)
+	}
+
+	assert.StringContains(t, contents["profile.json"], `"email": "alice@example.com"`)
+	assert.StringContains(t, contents["profile.json"], `"theme": "dark"`)
+	assert.StringContains(t, contents["snippets.json"], "An old silent pond")
+	assert.StringContains(t, contents["audit_events.json"], `"event": "login"`)
+}
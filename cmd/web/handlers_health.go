@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// healthzTimeout bounds the database ping of a health probe, well below the
+// timeouts load balancers typically use for their checks.
+const healthzTimeout = 2 * time.Second
+
+// healthReport is the JSON body of /healthz.
+type healthReport struct {
+	Status        string `json:"status"`
+	Database      string `json:"database"`
+	Version       string `json:"version"`
+	Uptime        string `json:"uptime"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// healthz reports whether the application can reach its database, answering
+// 503 when it cannot so load balancers take the instance out of rotation.
+// Error details are logged rather than exposed to anonymous callers.
+func (app *application) healthz(w http.ResponseWriter, r *http.Request) {
+	uptime := time.Since(app.started).Truncate(time.Second)
+
+	report := healthReport{
+		Status:        "ok",
+		Database:      "ok",
+		Version:       buildVersion(),
+		Uptime:        uptime.String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+	}
+	status := http.StatusOK
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthzTimeout)
+	defer cancel()
+
+	if err := app.pingDB(ctx); err != nil {
+		app.logger.WarnContext(r.Context(), "health probe failed", slog.String("err", err.Error()))
+
+		report.Status = "unavailable"
+		report.Database = "unreachable"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	app.writeJSON(w, r, status, report)
+}
+
+// readinessReport is the JSON body of /readyz. Checks maps each check to
+// "ok" or "fail"; what failed is only logged, since the probe needs no
+// login.
+type readinessReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// livez answers as long as the process can serve HTTP at all. It checks no
+// dependencies, so a database outage never gets the process restarted.
+func (app *application) livez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	app.writeJSON(w, r, http.StatusOK, readinessReport{Status: "ok"})
+}
+
+// readyz reports whether this instance should receive traffic: the database
+// is reachable, its schema is complete and every background worker is still
+// running. It fails as soon as shutdown begins, so the instance is drained
+// before it stops.
+func (app *application) readyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if app.draining.Load() {
+		app.writeJSON(w, r, http.StatusServiceUnavailable, readinessReport{Status: "draining"})
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthzTimeout)
+	defer cancel()
+
+	checks := map[string]string{
+		"database": "ok",
+		"schema":   "ok",
+		"workers":  "ok",
+	}
+
+	fail := func(check, reason string) {
+		app.logger.WarnContext(r.Context(), "readiness probe failed",
+			slog.String("check", check), slog.String("err", reason))
+
+		checks[check] = "fail"
+	}
+
+	if err := app.pingDB(ctx); err != nil {
+		fail("database", err.Error())
+		fail("schema", "database unreachable")
+	} else {
+		missing, err := app.missingTables(ctx)
+
+		switch {
+		case err != nil:
+			fail("schema", err.Error())
+		case len(missing) > 0:
+			fail("schema", "missing tables: "+strings.Join(missing, ", "))
+		}
+	}
+
+	if stopped := app.workers.Stopped(); len(stopped) > 0 {
+		fail("workers", "stopped: "+strings.Join(stopped, ", "))
+	}
+
+	report := readinessReport{Status: "ok", Checks: checks}
+	status := http.StatusOK
+
+	for _, result := range checks {
+		if result != "ok" {
+			report.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	app.writeJSON(w, r, status, report)
+}
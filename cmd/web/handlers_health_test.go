@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestHealthz(t *testing.T) {
+	tests := []struct {
+		name         string
+		pingErr      error
+		wantCode     int
+		wantStatus   string
+		wantDatabase string
+	}{
+		{
+			name:         "Healthy",
+			wantCode:     http.StatusOK,
+			wantStatus:   "ok",
+			wantDatabase: "ok",
+		},
+		{
+			name:         "Database down",
+			pingErr:      errors.New("connection refused"),
+			wantCode:     http.StatusServiceUnavailable,
+			wantStatus:   "unavailable",
+			wantDatabase: "unreachable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.started = time.Now().Add(-90 * time.Minute)
+			app.pingDB = func(ctx context.Context) error {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Error("ping has no deadline")
+				}
+
+				return tt.pingErr
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, headers, body := ts.get(t, "/healthz")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, headers.Get("Content-Type"), "application/json")
+			assert.Equal(t, headers.Get("Cache-Control"), "no-store")
+
+			var report healthReport
+			if err := json.Unmarshal([]byte(body), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, report.Status, tt.wantStatus)
+			assert.Equal(t, report.Database, tt.wantDatabase)
+			assert.Equal(t, report.Uptime, "1h30m0s")
+			assert.Equal(t, report.UptimeSeconds, int64(5400))
+
+			if report.Version == "" {
+				t.Error("missing version")
+			}
+		})
+	}
+}
+
+func TestLivez(t *testing.T) {
+	app := newTestApplication(t)
+	app.pingDB = func(context.Context) error { return errors.New("connection refused") }
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/livez")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, body, `{"status":"ok"}`)
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		pingErr    error
+		missing    []string
+		schemaErr  error
+		stopWorker bool
+		draining   bool
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "Ready",
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{"database": "ok", "schema": "ok", "workers": "ok"},
+		},
+		{
+			name:       "Database down",
+			pingErr:    errors.New("connection refused"),
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "fail", "schema": "fail", "workers": "ok"},
+		},
+		{
+			name:       "Schema not applied",
+			missing:    []string{"passkeys", "sessions"},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "ok", "schema": "fail", "workers": "ok"},
+		},
+		{
+			name:       "Schema check failed",
+			schemaErr:  errors.New("permission denied"),
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "ok", "schema": "fail", "workers": "ok"},
+		},
+		{
+			name:       "Worker stopped",
+			stopWorker: true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "ok", "schema": "ok", "workers": "fail"},
+		},
+		{
+			name:       "Draining",
+			draining:   true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "draining",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.pingDB = func(context.Context) error { return tt.pingErr }
+			app.missingTables = func(context.Context) ([]string, error) { return tt.missing, tt.schemaErr }
+			app.draining.Store(tt.draining)
+
+			done := make(chan struct{})
+			t.Cleanup(func() { close(done) })
+
+			app.workers.Go("health-monitor", func(context.Context) { <-done })
+
+			if tt.stopWorker {
+				app.workers.Go("mailer", func(context.Context) { panic("boom") })
+			}
+
+			// Give a crashing worker the chance to be recorded as stopped.
+			for tt.stopWorker && len(app.workers.Stopped()) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			code, _, body := ts.get(t, "/readyz")
+
+			assert.Equal(t, code, tt.wantCode)
+
+			var report readinessReport
+			if err := json.Unmarshal([]byte(body), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, report.Status, tt.wantStatus)
+			assert.Equal(t, len(report.Checks), len(tt.wantChecks))
+
+			for name, want := range tt.wantChecks {
+				assert.Equal(t, report.Checks[name], want)
+			}
+
+			// What failed stays in the logs.
+			assert.Equal(t, strings.Contains(body, "passkeys"), false)
+			assert.Equal(t, strings.Contains(body, "mailer"), false)
+		})
+	}
+}
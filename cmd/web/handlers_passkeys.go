@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+	"github.com/FABLOUSFALCON/snippetbox/internal/webauthn"
+)
+
+type passkeyRegisterForm struct {
+	Name                string `form:"name"`
+	ClientData          string `form:"client_data"`
+	AttestationObject   string `form:"attestation_object"`
+	validator.Validator `form:"-"`
+}
+
+type passkeyLoginForm struct {
+	CredentialID      string `form:"credential_id"`
+	ClientData        string `form:"client_data"`
+	AuthenticatorData string `form:"authenticator_data"`
+	Signature         string `form:"signature"`
+	UserHandle        string `form:"user_handle"`
+}
+
+// passkeyOptions are rendered into the page for the script that runs the
+// WebAuthn ceremony in the browser. All binary values are base64url.
+type passkeyOptions struct {
+	Challenge       string
+	RPID            string
+	RPName          string
+	UserID          string
+	UserName        string
+	UserDisplayName string
+	Exclude         []string
+	Algorithms      []int
+}
+
+// errPasskeyLogin is returned when a passkey sign-in fails for a reason the
+// user can fix by trying again.
+var errPasskeyLogin = errors.New("passkey sign-in failed")
+
+// passkeyUserHandle is the WebAuthn user handle stored with resident
+// credentials. It identifies the account without revealing the email.
+func passkeyUserHandle(userID int) []byte {
+	return []byte(strconv.Itoa(userID))
+}
+
+// newPasskeyChallenge stores a fresh challenge in the session for the next
+// ceremony and returns the options for the page. It must only be called
+// when passkeys are enabled.
+func (app *application) newPasskeyChallenge(r *http.Request) (*passkeyOptions, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, fmt.Errorf("starting passkey ceremony: %w", err)
+	}
+
+	app.sessionManager.Put(r.Context(), "passkeyChallenge", webauthn.Encode(challenge))
+
+	return &passkeyOptions{
+		Challenge:  webauthn.Encode(challenge),
+		RPID:       app.webauthn.ID,
+		RPName:     app.webauthn.Name,
+		Algorithms: webauthn.Algorithms,
+	}, nil
+}
+
+// popPasskeyChallenge removes the pending challenge from the session, so
+// every challenge can be answered only once.
+func (app *application) popPasskeyChallenge(r *http.Request) []byte {
+	challenge, err := webauthn.Decode(app.sessionManager.PopString(r.Context(), "passkeyChallenge"))
+	if err != nil {
+		return nil
+	}
+
+	return challenge
+}
+
+func (app *application) accountPasskeys(w http.ResponseWriter, r *http.Request) {
+	app.renderPasskeys(w, r, http.StatusOK, passkeyRegisterForm{})
+}
+
+func (app *application) renderPasskeys(w http.ResponseWriter, r *http.Request, status int, form passkeyRegisterForm) {
+	userID := app.authenticatedUserID(r)
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	passkeys, err := app.passkeys.List(r.Context(), userID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	var opts *passkeyOptions
+
+	if app.webauthn != nil {
+		opts, err = app.newPasskeyChallenge(r)
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		opts.UserID = webauthn.Encode(passkeyUserHandle(userID))
+		opts.UserName = user.Email
+		opts.UserDisplayName = user.Name
+
+		for _, p := range passkeys {
+			opts.Exclude = append(opts.Exclude, webauthn.Encode(p.CredentialID))
+		}
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Passkeys = passkeys
+	data.Passkey = opts
+
+	app.render(w, r, status, "passkeys.tmpl", data)
+}
+
+func (app *application) accountPasskeyCreatePost(w http.ResponseWriter, r *http.Request) {
+	if app.webauthn == nil {
+		app.notFound(w, r)
+
+		return
+	}
+
+	var form passkeyRegisterForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(
+		validator.MaxChars(form.Name, 100),
+		"name",
+		"This field cannot be more than 100 characters long",
+	)
+
+	challenge := app.popPasskeyChallenge(r)
+
+	if !form.Valid() {
+		app.renderPasskeys(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	cred, err := app.verifyPasskeyRegistration(challenge, form)
+	if err != nil {
+		form.AddNonFieldError("Your passkey could not be registered. Please try again.")
+		app.renderPasskeys(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	userID := app.authenticatedUserID(r)
+
+	err = app.passkeys.Insert(r.Context(), userID, form.Name, cred.ID, cred.PublicKey, cred.SignCount)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicatePasskey) {
+			form.AddNonFieldError("This passkey is already registered.")
+			app.renderPasskeys(w, r, http.StatusUnprocessableEntity, form)
+		} else {
+			app.handleError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, userID, models.AuditPasskeyAdd, "name="+form.Name)
+
+	app.flash(r, flashSuccess, "Passkey added.")
+
+	http.Redirect(w, r, "/account/passkeys", http.StatusSeeOther)
+}
+
+func (app *application) verifyPasskeyRegistration(challenge []byte, form passkeyRegisterForm) (webauthn.Credential, error) {
+	clientData, err := webauthn.Decode(form.ClientData)
+	if err != nil {
+		return webauthn.Credential{}, fmt.Errorf("reading client data: %w", err)
+	}
+
+	attestation, err := webauthn.Decode(form.AttestationObject)
+	if err != nil {
+		return webauthn.Credential{}, fmt.Errorf("reading attestation: %w", err)
+	}
+
+	cred, err := app.webauthn.VerifyRegistration(challenge, clientData, attestation)
+	if err != nil {
+		return webauthn.Credential{}, fmt.Errorf("verifying passkey registration: %w", err)
+	}
+
+	return cred, nil
+}
+
+func (app *application) accountPasskeyDeletePost(w http.ResponseWriter, r *http.Request) {
+	id := pathInt(r, "id")
+
+	userID := app.authenticatedUserID(r)
+
+	err := app.passkeys.Delete(r.Context(), userID, id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, userID, models.AuditPasskeyRemove, fmt.Sprintf("passkey=%d", id))
+
+	app.flash(r, flashSuccess, "Passkey removed.")
+
+	http.Redirect(w, r, "/account/passkeys", http.StatusSeeOther)
+}
+
+func (app *application) userLoginPasskeyPost(w http.ResponseWriter, r *http.Request) {
+	if app.webauthn == nil {
+		app.notFound(w, r)
+
+		return
+	}
+
+	var form passkeyLoginForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	challenge := app.popPasskeyChallenge(r)
+
+	user, err := app.verifyPasskeyLogin(r.Context(), challenge, form)
+	if err != nil {
+		if errors.Is(err, errPasskeyLogin) {
+			app.recordAudit(r, user.ID, models.AuditLoginFailed, "passkey")
+			app.loginFailed(w, r, http.StatusUnprocessableEntity, userLoginForm{},
+				"Signing in with your passkey failed. Please try again or use your password.")
+		} else {
+			app.handleError(w, r, err)
+		}
+
+		return
+	}
+
+	if user.Suspended() {
+		app.recordAudit(r, user.ID, models.AuditLoginFailed, "suspended")
+		app.loginFailed(w, r, http.StatusForbidden, userLoginForm{}, suspensionMessage(user))
+
+		return
+	}
+
+	app.startSession(w, r, user)
+}
+
+// reauthenticatePasskeyPost lets users confirm who they are with a passkey
+// instead of their password, which passkey-only users do not have.
+func (app *application) reauthenticatePasskeyPost(w http.ResponseWriter, r *http.Request) {
+	if app.webauthn == nil {
+		app.notFound(w, r)
+
+		return
+	}
+
+	var form passkeyLoginForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	challenge := app.popPasskeyChallenge(r)
+	userID := app.authenticatedUserID(r)
+
+	user, err := app.verifyPasskeyLogin(r.Context(), challenge, form)
+	if err == nil && user.ID != userID {
+		err = fmt.Errorf("%w: passkey of another account", errPasskeyLogin)
+	}
+
+	if err != nil {
+		if errors.Is(err, errPasskeyLogin) {
+			app.recordAudit(r, userID, models.AuditReauthFailed, "passkey")
+
+			var failed reauthenticateForm
+			failed.AddNonFieldError("Confirming with your passkey failed. Please try again or use your password.")
+			app.renderReauthenticate(w, r, http.StatusUnprocessableEntity, failed)
+		} else {
+			app.handleError(w, r, err)
+		}
+
+		return
+	}
+
+	app.reauthenticated(w, r, userID, "passkey")
+}
+
+// verifyPasskeyLogin checks a sign-in assertion and returns the user the
+// passkey belongs to. Failures the user can retry wrap errPasskeyLogin; the
+// returned user is set when the passkey was found.
+func (app *application) verifyPasskeyLogin(
+	ctx context.Context,
+	challenge []byte,
+	form passkeyLoginForm,
+) (models.User, error) {
+	credentialID, a, err := form.decode()
+	if err != nil {
+		return models.User{}, fmt.Errorf("%w: %w", errPasskeyLogin, err)
+	}
+
+	passkey, err := app.passkeys.Get(ctx, credentialID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return models.User{}, fmt.Errorf("%w: unknown credential", errPasskeyLogin)
+		}
+
+		return models.User{}, fmt.Errorf("looking up passkey: %w", err)
+	}
+
+	user := models.User{ID: passkey.UserID}
+
+	// Discoverable credentials also return the user handle they were
+	// registered with, which must match the owner of the credential.
+	if form.UserHandle != "" {
+		handle, err := webauthn.Decode(form.UserHandle)
+		if err != nil || !bytes.Equal(handle, passkeyUserHandle(passkey.UserID)) {
+			return user, fmt.Errorf("%w: user handle mismatch", errPasskeyLogin)
+		}
+	}
+
+	cred := webauthn.Credential{ID: passkey.CredentialID, PublicKey: passkey.PublicKey, SignCount: passkey.SignCount}
+
+	signCount, err := app.webauthn.VerifyAssertion(cred, challenge, a)
+	if err != nil {
+		return user, fmt.Errorf("%w: %w", errPasskeyLogin, err)
+	}
+
+	if err := app.passkeys.RecordUse(ctx, passkey.ID, signCount); err != nil {
+		return user, fmt.Errorf("recording passkey use: %w", err)
+	}
+
+	user, err = app.users.Get(passkey.UserID)
+	if err != nil {
+		return user, fmt.Errorf("loading passkey owner: %w", err)
+	}
+
+	return user, nil
+}
+
+// decode converts the base64url fields posted by the sign-in script.
+func (form passkeyLoginForm) decode() ([]byte, webauthn.Assertion, error) {
+	var (
+		a   webauthn.Assertion
+		err error
+	)
+
+	credentialID, err := webauthn.Decode(form.CredentialID)
+	if err != nil {
+		return nil, a, fmt.Errorf("reading credential ID: %w", err)
+	}
+
+	if a.ClientDataJSON, err = webauthn.Decode(form.ClientData); err != nil {
+		return nil, a, fmt.Errorf("reading client data: %w", err)
+	}
+
+	if a.AuthenticatorData, err = webauthn.Decode(form.AuthenticatorData); err != nil {
+		return nil, a, fmt.Errorf("reading authenticator data: %w", err)
+	}
+
+	if a.Signature, err = webauthn.Decode(form.Signature); err != nil {
+		return nil, a, fmt.Errorf("reading signature: %w", err)
+	}
+
+	return credentialID, a, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/webauthn"
+)
+
+// testPasskey is an Ed25519 passkey that signs real assertions, registered
+// to a user through testPasskeyModel.
+type testPasskey struct {
+	passkey models.Passkey
+	key     ed25519.PrivateKey
+}
+
+func newTestPasskey(t *testing.T, id, userID int) testPasskey {
+	t.Helper()
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+
+	// The COSE key {kty: OKP, alg: EdDSA, crv: Ed25519, x: pub}.
+	cose := append([]byte{0xa4, 0x01, 0x01, 0x03, 0x27, 0x20, 0x06, 0x21, 0x58, 0x20}, pub...)
+
+	return testPasskey{
+		passkey: models.Passkey{
+			ID:           id,
+			UserID:       userID,
+			Name:         "Laptop",
+			CredentialID: fmt.Appendf(nil, "test-credential-%d", id),
+			PublicKey:    cose,
+			Created:      time.Now(),
+		},
+		key: key,
+	}
+}
+
+// assertion returns the form the sign-in script posts after the passkey
+// answered challenge on rp.
+func (p testPasskey) assertion(t *testing.T, rp *webauthn.RelyingParty, challenge string) url.Values {
+	t.Helper()
+
+	clientData, err := json.Marshal(map[string]string{
+		"type":      "webauthn.get",
+		"challenge": challenge,
+		"origin":    rp.Origin,
+	})
+	assert.NilError(t, err)
+
+	rpHash := sha256.Sum256([]byte(rp.ID))
+	authData := append(rpHash[:], 0x05) // user present and verified
+	authData = binary.BigEndian.AppendUint32(authData, 1)
+
+	clientHash := sha256.Sum256(clientData)
+	signature := ed25519.Sign(p.key, append(bytes.Clone(authData), clientHash[:]...))
+
+	return url.Values{
+		"credential_id":      {webauthn.Encode(p.passkey.CredentialID)},
+		"client_data":        {webauthn.Encode(clientData)},
+		"authenticator_data": {webauthn.Encode(authData)},
+		"signature":          {webauthn.Encode(signature)},
+	}
+}
+
+// testPasskeyModel adds test passkeys to the mock passkeys.
+type testPasskeyModel struct {
+	models.PasskeyModelInterface
+	passkeys []testPasskey
+}
+
+func (m testPasskeyModel) Get(ctx context.Context, credentialID []byte) (models.Passkey, error) {
+	for _, p := range m.passkeys {
+		if bytes.Equal(credentialID, p.passkey.CredentialID) {
+			return p.passkey, nil
+		}
+	}
+
+	return m.PasskeyModelInterface.Get(ctx, credentialID)
+}
+
+var passkeyChallengeRX = regexp.MustCompile(`data-challenge='([^']+)'`)
+
+func extractPasskeyChallenge(t *testing.T, body string) string {
+	t.Helper()
+
+	matches := passkeyChallengeRX.FindStringSubmatch(body)
+	if len(matches) < 2 {
+		t.Fatal("no passkey challenge found in body")
+	}
+
+	return matches[1]
+}
+
+func TestAccountPasskeys(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/account/passkeys")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>Phone</td>")
+	assert.StringContains(t, body, "data-rp-id='snippetbox.test'")
+	assert.StringContains(t, body, "data-exclude='"+webauthn.Encode([]byte("mock-credential"))+"'")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		passkey  string
+		data     string
+		wantCode int
+		wantBody string
+	}{
+		{"Blank name", "", "", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Bad response", "Laptop", "not base64!", http.StatusUnprocessableEntity, "could not be registered"},
+		{"Wrong challenge", "Laptop", webauthn.Encode([]byte(`{}`)), http.StatusUnprocessableEntity, "could not be registered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("name", tt.passkey)
+			form.Add("client_data", tt.data)
+			form.Add("attestation_object", tt.data)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/passkeys", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, headers, _ := ts.postForm(t, "/account/passkeys/1/delete", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/passkeys")
+
+	code, _, _ = ts.postForm(t, "/account/passkeys/2/delete", form)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestUserLoginPasskey(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+	assert.StringContains(t, body, `id="passkey-login"`)
+
+	tests := []struct {
+		name         string
+		credentialID string
+		wantCode     int
+	}{
+		{"Unknown credential", webauthn.Encode([]byte("unknown")), http.StatusUnprocessableEntity},
+		{"Invalid assertion", webauthn.Encode([]byte("mock-credential")), http.StatusUnprocessableEntity},
+		{"Malformed", "%%%", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("credential_id", tt.credentialID)
+			form.Add("client_data", webauthn.Encode([]byte(`{"type":"webauthn.get"}`)))
+			form.Add("authenticator_data", webauthn.Encode(make([]byte, 37)))
+			form.Add("signature", webauthn.Encode([]byte("signature")))
+			form.Add("csrf_token", extractCSRFToken(t, body))
+
+			code, _, page := ts.postForm(t, "/user/login/passkey", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, page, "Signing in with your passkey failed")
+		})
+	}
+}
+
+func TestReauthenticate(t *testing.T) {
+	alice := newTestPasskey(t, 7, 1)
+	admin := newTestPasskey(t, 8, 2)
+
+	tests := []struct {
+		name string
+		// submit posts the reauthentication for the page in body.
+		submit func(t *testing.T, app *application, ts *testServer, body string) (int, http.Header, string)
+	}{
+		{
+			name: "Password",
+			submit: func(t *testing.T, _ *application, ts *testServer, body string) (int, http.Header, string) {
+				form := url.Values{"password": {"wrong"}, "csrf_token": {extractCSRFToken(t, body)}}
+
+				code, _, page := ts.postForm(t, "/account/reauthenticate", form)
+				assert.Equal(t, code, http.StatusUnprocessableEntity)
+				assert.StringContains(t, page, "Password is incorrect")
+
+				form.Set("password", "pa$$word")
+
+				return ts.postForm(t, "/account/reauthenticate", form)
+			},
+		},
+		{
+			name: "Passkey",
+			submit: func(t *testing.T, app *application, ts *testServer, body string) (int, http.Header, string) {
+				// Another account's passkey does not confirm this one.
+				form := admin.assertion(t, app.webauthn, extractPasskeyChallenge(t, body))
+				form.Set("csrf_token", extractCSRFToken(t, body))
+
+				code, _, page := ts.postForm(t, "/account/reauthenticate/passkey", form)
+				assert.Equal(t, code, http.StatusUnprocessableEntity)
+				assert.StringContains(t, page, "Confirming with your passkey failed")
+
+				// Each challenge can be answered once.
+				form = alice.assertion(t, app.webauthn, extractPasskeyChallenge(t, body))
+				form.Set("csrf_token", extractCSRFToken(t, page))
+
+				code, _, page = ts.postForm(t, "/account/reauthenticate/passkey", form)
+				assert.Equal(t, code, http.StatusUnprocessableEntity)
+
+				form = alice.assertion(t, app.webauthn, extractPasskeyChallenge(t, page))
+				form.Set("csrf_token", extractCSRFToken(t, page))
+
+				return ts.postForm(t, "/account/reauthenticate/passkey", form)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.passkeys = testPasskeyModel{app.passkeys, []testPasskey{alice, admin}}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			ts.login(t, "alice@example.com", "pa$$word")
+
+			// Changing the password needs a recent login.
+			app.reauthWindow = -time.Second
+
+			code, headers, _ := ts.get(t, "/account/password")
+			assert.Equal(t, code, http.StatusSeeOther)
+			assert.Equal(t, headers.Get("Location"), "/account/reauthenticate")
+
+			_, _, body := ts.get(t, "/account/reauthenticate")
+			assert.StringContains(t, body, "id='passkey-reauth'")
+
+			code, headers, _ = tt.submit(t, app, ts, body)
+			assert.Equal(t, code, http.StatusSeeOther)
+			assert.Equal(t, headers.Get("Location"), "/account/password")
+
+			app.reauthWindow = time.Hour
+
+			code, _, _ = ts.get(t, "/account/password")
+			assert.Equal(t, code, http.StatusOK)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+type preferencesForm struct {
+	Theme               string `form:"theme"`
+	SnippetsPerPage     int    `form:"snippets_per_page"`
+	DefaultExpiry       int    `form:"default_expiry"`
+	Language            string `form:"language"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) accountPreferences(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = preferencesForm{
+		Theme:           data.Preferences.Theme,
+		SnippetsPerPage: data.Preferences.SnippetsPerPage,
+		DefaultExpiry:   data.Preferences.DefaultExpiry,
+		Language:        data.Preferences.Language,
+	}
+
+	app.render(w, r, http.StatusOK, "preferences.tmpl", data)
+}
+
+func (app *application) accountPreferencesPost(w http.ResponseWriter, r *http.Request) {
+	var form preferencesForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(
+		validator.PermittedValue(form.Theme, "light", "dark"),
+		"theme",
+		"This field must be light or dark",
+	)
+	form.CheckField(
+		validator.PermittedValue(form.SnippetsPerPage, 5, 10, 20, 50),
+		"snippets_per_page",
+		"This field must equal 5, 10, 20 or 50",
+	)
+	form.CheckField(
+		validator.PermittedValue(form.DefaultExpiry, 1, 7, 365),
+		"default_expiry",
+		"This field must equal 1, 7 or 365",
+	)
+	_, supported := i18n.Get(form.Language)
+	form.CheckField(form.Language == "" || supported, "language", "This field must be a supported language")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "preferences.tmpl", data)
+
+		return
+	}
+
+	prefs := models.Preferences{
+		Theme:           form.Theme,
+		SnippetsPerPage: form.SnippetsPerPage,
+		DefaultExpiry:   form.DefaultExpiry,
+		Language:        form.Language,
+	}
+
+	if err := app.prefs.Update(r.Context(), app.authenticatedUserID(r), prefs); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "Your preferences have been saved.")
+
+	http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+}
+
+// localePost stores the language picked in the footer for the rest of the
+// session and sends the user back to the page they came from.
+func (app *application) localePost(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	l, ok := i18n.Get(r.PostForm.Get("locale"))
+	if !ok {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "locale", l.Tag)
+
+	path := app.sameOriginRefererPath(r)
+	if path == "" || path == r.URL.Path {
+		path = "/"
+	}
+
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+type notificationsForm struct {
+	Enabled             []string `form:"enabled"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) accountNotifications(w http.ResponseWriter, r *http.Request) {
+	choices, err := app.notifications.Settings(r.Context(), app.authenticatedUserID(r))
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Notifications = notificationSettings(choices)
+
+	app.render(w, r, http.StatusOK, "notifications.tmpl", data)
+}
+
+// accountNotificationsPost saves a choice for every notification kind, so
+// that unchecked boxes are stored as opt-outs rather than falling back to
+// the default.
+func (app *application) accountNotificationsPost(w http.ResponseWriter, r *http.Request) {
+	var form notificationsForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	choices := make(map[string]bool, len(notificationKinds))
+	for _, k := range notificationKinds {
+		choices[k.Name] = false
+	}
+
+	for _, kind := range form.Enabled {
+		if _, ok := choices[kind]; !ok {
+			app.clientError(w, http.StatusBadRequest)
+
+			return
+		}
+
+		choices[kind] = true
+	}
+
+	if err := app.notifications.UpdateSettings(r.Context(), app.authenticatedUserID(r), choices); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "Your notification settings have been saved.")
+
+	http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+// uptimeCacheTTL is how long the status page reuses its uptime figures.
+// Each takes a count over up to 90 days of health checks, and the page
+// needs no login, so they are not recomputed for every visitor.
+const uptimeCacheTTL = time.Minute
+
+// uptimeWindow is one row of the uptime table on the status page.
+type uptimeWindow struct {
+	Label  string
+	Uptime models.Uptime
+}
+
+var uptimeWindows = []struct {
+	label  string
+	period time.Duration
+}{
+	{"Last 24 hours", 24 * time.Hour},
+	{"Last 7 days", 7 * 24 * time.Hour},
+	{"Last 30 days", 30 * 24 * time.Hour},
+	{"Last 90 days", healthRetention},
+}
+
+type incidentCreateForm struct {
+	Title               string `form:"title"`
+	Notes               string `form:"notes"`
+	validator.Validator `form:"-"`
+}
+
+type incidentUpdateForm struct {
+	Notes               string `form:"notes"`
+	Resolved            bool   `form:"resolved"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) statusPage(w http.ResponseWriter, r *http.Request) {
+	windows, err := cache.Fetch(r.Context(), app.uptimeCache, "windows",
+		func(ctx context.Context) ([]uptimeWindow, time.Duration, error) {
+			now := time.Now()
+
+			var windows []uptimeWindow
+
+			for _, uw := range uptimeWindows {
+				uptime, err := app.status.Uptime(ctx, now.Add(-uw.period))
+				if err != nil {
+					return nil, 0, err //nolint:wrapcheck // the model's errors are already descriptive
+				}
+
+				windows = append(windows, uptimeWindow{Label: uw.label, Uptime: uptime})
+			}
+
+			return windows, uptimeCacheTTL, nil
+		})
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	incidents, err := app.status.Incidents(r.Context(), 10)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Uptime = windows
+	data.Incidents = incidents
+
+	app.render(w, r, http.StatusOK, "status.tmpl", data)
+}
+
+func (app *application) adminIncidents(w http.ResponseWriter, r *http.Request) {
+	app.renderAdminIncidents(w, r, http.StatusOK, incidentCreateForm{})
+}
+
+func (app *application) renderAdminIncidents(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	form incidentCreateForm,
+) {
+	incidents, err := app.status.Incidents(r.Context(), 50)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Incidents = incidents
+	data.Form = form
+
+	app.render(w, r, status, "admin_incidents.tmpl", data)
+}
+
+func (app *application) adminIncidentCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form incidentCreateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
+	form.CheckField(
+		validator.MaxChars(form.Title, 100),
+		"title",
+		"This field cannot be more than 100 characters long",
+	)
+
+	if !form.Valid() {
+		app.renderAdminIncidents(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	id, err := app.status.InsertIncident(r.Context(), form.Title, form.Notes)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, app.authenticatedUserID(r), models.AuditIncidentCreate, fmt.Sprintf("incident=%d", id))
+
+	app.flash(r, flashSuccess, "Incident created.")
+
+	http.Redirect(w, r, "/admin/incidents", http.StatusSeeOther)
+}
+
+// adminTargetIncident loads the incident named by the {id} path value,
+// writing a 404 and returning false when there is no such incident.
+func (app *application) adminTargetIncident(w http.ResponseWriter, r *http.Request) (models.Incident, bool) {
+	id := pathInt(r, "id")
+
+	incident, err := app.status.GetIncident(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return models.Incident{}, false
+	}
+
+	return incident, true
+}
+
+func (app *application) adminIncidentView(w http.ResponseWriter, r *http.Request) {
+	incident, ok := app.adminTargetIncident(w, r)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Incident = incident
+	data.Form = incidentUpdateForm{
+		Notes:    incident.Notes,
+		Resolved: !incident.Ongoing(),
+	}
+
+	app.render(w, r, http.StatusOK, "admin_incident.tmpl", data)
+}
+
+func (app *application) adminIncidentUpdatePost(w http.ResponseWriter, r *http.Request) {
+	incident, ok := app.adminTargetIncident(w, r)
+	if !ok {
+		return
+	}
+
+	var form incidentUpdateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(
+		validator.MaxChars(form.Notes, 5000),
+		"notes",
+		"This field cannot be more than 5000 characters long",
+	)
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Incident = incident
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "admin_incident.tmpl", data)
+
+		return
+	}
+
+	if err := app.status.UpdateIncident(r.Context(), incident.ID, form.Notes, form.Resolved); err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.recordAudit(r, app.authenticatedUserID(r), models.AuditIncidentUpdate,
+		fmt.Sprintf("incident=%d resolved=%t", incident.ID, form.Resolved))
+
+	app.flash(r, flashSuccess, "Incident updated.")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/incidents/%d", incident.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestStatusPage(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/status")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>99.50%</td>")
+	assert.StringContains(t, body, "Database maintenance")
+	assert.StringContains(t, body, "Ongoing")
+
+	// The uptime figures are reused for the next visitor.
+	_, _, body = ts.get(t, "/status")
+	assert.StringContains(t, body, "<td>99.50%</td>")
+	assert.Equal(t, app.uptimeCache.Stats().Hits, uint64(1))
+}
+
+func TestAdminIncidents(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, _ := ts.get(t, "/admin/incidents")
+	assert.Equal(t, code, http.StatusForbidden)
+
+	ts = newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/admin/incidents")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<a href='/admin/incidents/1' dir='ltr'>Database maintenance</a>")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		form     url.Values
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Create",
+			urlPath:  "/admin/incidents",
+			form:     url.Values{"title": {"Slow pages"}, "notes": {"Investigating"}},
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Create without title",
+			urlPath:  "/admin/incidents",
+			form:     url.Values{"notes": {"Investigating"}},
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field cannot be blank",
+		},
+		{
+			name:     "Resolve",
+			urlPath:  "/admin/incidents/1",
+			form:     url.Values{"notes": {"Fixed"}, "resolved": {"true"}},
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Unknown incident",
+			urlPath:  "/admin/incidents/9",
+			form:     url.Values{"notes": {"Fixed"}},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, tt.urlPath, tt.form)
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
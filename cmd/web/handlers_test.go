@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/captcha"
 )
 
 func TestPing(t *testing.T) {
@@ -59,9 +67,11 @@ func TestSnippetView(t *testing.T) {
 			wantCode: http.StatusNotFound,
 		},
 		{
+			// The trailing slash is dropped first, leaving /snippet/view,
+			// which is not found.
 			name:     "Empty ID",
 			urlPath:  "/snippet/view/",
-			wantCode: http.StatusNotFound,
+			wantCode: http.StatusPermanentRedirect,
 		},
 	}
 
@@ -78,6 +88,71 @@ func TestSnippetView(t *testing.T) {
 	}
 }
 
+func TestSnippetViewNegotiation(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		accept   string
+		wantType string
+		wantBody string
+	}{
+		{"Browser", "text/html,application/xhtml+xml,*/*;q=0.8", "text/html; charset=utf-8", ">An old silent pond</strong>"},
+		{"No Accept header", "", "text/html; charset=utf-8", ">An old silent pond</strong>"},
+		{"JSON", "application/json", "application/json", `"title":"An old silent pond","content":"An old silent pond..."`},
+		{"Plain text", "text/plain", "text/plain; charset=utf-8", "An old silent pond..."},
+		{"JSON preferred", "text/html;q=0.5, application/json", "application/json", `"id":1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			if tt.accept != "" {
+				header = http.Header{"Accept": {tt.accept}}
+			}
+
+			code, headers, body := ts.getWithHeaders(t, "/snippet/view/1", header)
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.Equal(t, headers.Get("Content-Type"), tt.wantType)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.Equal(t, slices.Contains(headers.Values("Vary"), "Accept"), true)
+		})
+	}
+
+	t.Run("Tag depends on the representation", func(t *testing.T) {
+		json := http.Header{"Accept": {"application/json"}}
+
+		_, headers, _ := ts.get(t, "/snippet/view/1")
+		html := headers.Get("ETag")
+
+		_, headers, _ = ts.getWithHeaders(t, "/snippet/view/1", json)
+		assert.Equal(t, headers.Get("ETag") == html, false)
+
+		json.Set("If-None-Match", headers.Get("ETag"))
+		code, _, _ := ts.getWithHeaders(t, "/snippet/view/1", json)
+		assert.Equal(t, code, http.StatusNotModified)
+	})
+}
+
+func TestHomeNegotiation(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, headers, body := ts.getWithHeaders(t, "/", http.Header{"Accept": {"application/json"}})
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, headers.Get("Content-Type"), "application/json")
+	assert.StringContains(t, body, `{"snippets":[{"id":1,"title":"An old silent pond"`)
+	assert.Equal(t, slices.Contains(headers.Values("Vary"), "Accept"), true)
+
+	code, headers, _ = ts.get(t, "/")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, headers.Get("Content-Type"), "text/html; charset=utf-8")
+}
+
 func TestUserSignup(t *testing.T) {
 	app := newTestApplication(t)
 	ts := newTestServer(t, app.routes())
@@ -99,6 +174,7 @@ func TestUserSignup(t *testing.T) {
 		userEmail    string
 		userPassword string
 		csrfToken    string
+		rejectTOS    bool
 		wantCode     int
 		wantFormTag  string
 	}{
@@ -163,6 +239,16 @@ func TestUserSignup(t *testing.T) {
 			wantCode:     http.StatusUnprocessableEntity,
 			wantFormTag:  formTag,
 		},
+		{
+			desc:         "Terms not accepted",
+			userName:     validName,
+			userEmail:    validEmail,
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			rejectTOS:    true,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
 		{
 			desc:         "Duplicate email",
 			userName:     validName,
@@ -181,6 +267,10 @@ func TestUserSignup(t *testing.T) {
 			form.Add("password", tC.userPassword)
 			form.Add("csrf_token", tC.csrfToken)
 
+			if !tC.rejectTOS {
+				form.Add("accept_tos", "true")
+			}
+
 			//nolint:govet // body is shadowed for readability
 			code, _, body := ts.postForm(t, "/user/signup", form)
 
@@ -223,3 +313,628 @@ func Test_application_snippetCreate(t *testing.T) {
 		assert.StringContains(t, body, "<form action='/snippet/create' method='POST'>")
 	})
 }
+
+func TestSnippetStats(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		app := newTestApplication(t)
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		ts.login(t, "alice@example.com", "pa$$word")
+
+		code, _, body := ts.get(t, "/snippet/stats/1")
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "<p>3 views</p>")
+		assert.StringContains(t, body, "<td>news.ycombinator.com</td>")
+	})
+
+	t.Run("Not owner", func(t *testing.T) {
+		app := newTestApplication(t)
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		ts.login(t, "admin@example.com", "pa$$word")
+
+		code, _, _ := ts.get(t, "/snippet/stats/1")
+		assert.Equal(t, code, http.StatusForbidden)
+	})
+
+	t.Run("Missing snippet", func(t *testing.T) {
+		app := newTestApplication(t)
+		ts := newTestServer(t, app.routes())
+		defer ts.Close()
+
+		ts.login(t, "alice@example.com", "pa$$word")
+
+		code, _, _ := ts.get(t, "/snippet/stats/2")
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+}
+
+func TestUserLoginSuspended(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", "mallory@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, body := ts.postForm(t, "/user/login", form)
+
+	assert.Equal(t, code, http.StatusForbidden)
+	assert.StringContains(t, body, "Your account is suspended until")
+	assert.StringContains(t, body, "Reason: Spamming")
+}
+
+func TestSnippetRawPinned(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	sum := sha256.Sum256([]byte("An old silent pond..."))
+	hash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name      string
+		urlPath   string
+		wantCode  int
+		wantCache string
+	}{
+		{
+			name:      "Matching prefix",
+			urlPath:   "/raw/1/" + hash[:12],
+			wantCode:  http.StatusOK,
+			wantCache: "public, max-age=0", // the mock snippet has expired
+		},
+		{
+			name:      "Full hash",
+			urlPath:   "/raw/1/" + hash,
+			wantCode:  http.StatusOK,
+			wantCache: "public, max-age=0", // the mock snippet has expired
+		},
+		{
+			name:     "Edited content",
+			urlPath:  "/raw/1/0000000000",
+			wantCode: http.StatusGone,
+		},
+		{
+			name:     "Prefix too short",
+			urlPath:  "/raw/1/" + hash[:4],
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Not hex",
+			urlPath:  "/raw/1/zzzzzzzzzz",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Missing snippet",
+			urlPath:  "/raw/2/" + hash[:12],
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, headers, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, headers.Get("Cache-Control"), tt.wantCache)
+				assert.Equal(t, body, "An old silent pond...")
+			}
+		})
+	}
+}
+
+func TestSnippetConditionalGet(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for _, urlPath := range []string{"/snippet/view/1", "/snippet/raw/1"} {
+		t.Run(urlPath, func(t *testing.T) {
+			code, headers, _ := ts.get(t, urlPath)
+			assert.Equal(t, code, http.StatusOK)
+
+			etag := headers.Get("ETag")
+			if etag == "" {
+				t.Fatal("missing ETag header")
+			}
+
+			code, _, body := ts.getWithHeaders(t, urlPath, http.Header{"If-None-Match": {etag}})
+			assert.Equal(t, code, http.StatusNotModified)
+			assert.Equal(t, body, "")
+
+			code, _, _ = ts.getWithHeaders(t, urlPath, http.Header{"If-None-Match": {`"stale"`}})
+			assert.Equal(t, code, http.StatusOK)
+		})
+	}
+
+	t.Run("If-Modified-Since", func(t *testing.T) {
+		_, headers, _ := ts.get(t, "/snippet/raw/1")
+
+		code, _, _ := ts.getWithHeaders(t, "/snippet/raw/1",
+			http.Header{"If-Modified-Since": {headers.Get("Last-Modified")}})
+		assert.Equal(t, code, http.StatusNotModified)
+	})
+
+	t.Run("Tag depends on the viewer", func(t *testing.T) {
+		_, headers, _ := ts.get(t, "/snippet/view/1")
+		anonymous := headers.Get("ETag")
+
+		ts.login(t, "alice@example.com", "pa$$word")
+
+		code, _, _ := ts.getWithHeaders(t, "/snippet/view/1", http.Header{"If-None-Match": {anonymous}})
+		assert.Equal(t, code, http.StatusOK)
+	})
+}
+
+func TestTermsAcceptance(t *testing.T) {
+	app := newTestApplication(t)
+	// The mock users accepted version 1, so they must accept version 2.
+	app.tosVersion = 2
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, headers, _ := ts.get(t, "/account/view")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/terms/accept")
+
+	_, _, body := ts.get(t, "/terms/accept")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ = ts.postForm(t, "/terms/accept", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+
+	form.Add("accept_tos", "true")
+
+	code, headers, _ = ts.postForm(t, "/terms/accept", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/view")
+
+	code, _, _ = ts.get(t, "/account/view")
+	assert.Equal(t, code, http.StatusOK)
+}
+
+func TestUserLoginThrottle(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	attempt := func(password string) (int, http.Header, string) {
+		form := url.Values{}
+		form.Add("email", "alice@example.com")
+		form.Add("password", password)
+		form.Add("csrf_token", csrfToken)
+
+		return ts.postForm(t, "/user/login", form)
+	}
+
+	// The test application allows three free failures.
+	for range 3 {
+		code, _, _ := attempt("wrong")
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+	}
+
+	code, _, _ := attempt("wrong")
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+
+	// Even the correct password is rejected while the account is locked.
+	code, headers, body := attempt("pa$$word")
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, headers.Get("Retry-After"), "60")
+	assert.StringContains(t, body, "Too many failed login attempts")
+}
+
+type fakeCaptcha struct{}
+
+func (fakeCaptcha) Verify(_ context.Context, token, _ string) (bool, error) {
+	return token == "solved", nil
+}
+
+func (fakeCaptcha) Widget() captcha.Widget {
+	return captcha.Widget{
+		SiteKey:       "site-key",
+		ScriptURL:     "https://captcha.example.com/api.js",
+		Class:         "fake-captcha",
+		ResponseField: "fake-captcha-response",
+		Origins:       []string{"https://captcha.example.com"},
+	}
+}
+
+func TestUserSignupCaptcha(t *testing.T) {
+	app := newTestApplication(t)
+	app.captcha = fakeCaptcha{}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, headers, body := ts.get(t, "/user/signup")
+	assert.StringContains(t, body, "<div class='fake-captcha' data-sitekey='site-key'></div>")
+	assert.StringContains(t, headers.Get("Content-Security-Policy"), "frame-src https://captcha.example.com")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	for _, tt := range []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{name: "Unsolved", token: "", wantCode: http.StatusUnprocessableEntity},
+		{name: "Solved", token: "solved", wantCode: http.StatusSeeOther},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("name", "Bob")
+			form.Add("email", "bob@example.com")
+			form.Add("password", "validPa$$word")
+			form.Add("accept_tos", "true")
+			form.Add("fake-captcha-response", tt.token)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, _ := ts.postForm(t, "/user/signup", form)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestUserSignupEmailDomains(t *testing.T) {
+	app := newTestApplication(t)
+	app.signupDomains = emailDomainPolicy{allow: []string{"example.com"}, deny: []string{"temp.example.com"}}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/signup")
+	csrfToken := extractCSRFToken(t, body)
+
+	for _, tt := range []struct {
+		name     string
+		email    string
+		wantCode int
+		wantBody string
+	}{
+		{"Allowed", "bob@example.com", http.StatusSeeOther, ""},
+		{"Not allowed", "bob@gmail.com", http.StatusUnprocessableEntity, "Sign-ups are limited to addresses at example.com"},
+		{"Denied", "bob@temp.example.com", http.StatusUnprocessableEntity, "Addresses at this domain cannot be used"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("name", "Bob")
+			form.Add("email", tt.email)
+			form.Add("password", "validPa$$word")
+			form.Add("accept_tos", "true")
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/user/signup", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestAccountTokens(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/account/tokens")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>laptop</td>")
+	assert.StringContains(t, body, "192.0.2.0/24")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("name", "ci")
+	form.Add("csrf_token", csrfToken)
+
+	code, headers, _ := ts.postForm(t, "/account/tokens", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/tokens")
+
+	// The plaintext is shown exactly once.
+	_, _, body = ts.get(t, "/account/tokens")
+	assert.StringContains(t, body, "sbx_new-test-token")
+
+	_, _, body = ts.get(t, "/account/tokens")
+	if strings.Contains(body, "sbx_new-test-token") {
+		t.Error("token plaintext shown more than once")
+	}
+
+	code, _, _ = ts.postForm(t, "/account/tokens/1/delete", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, _ = ts.postForm(t, "/account/tokens/9/delete", form)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestAccountPreferences(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/")
+	assert.StringContains(t, body, "<body class='theme-light'>")
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/account/preferences")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<body class='theme-dark'>")
+	assert.StringContains(t, body, "value='20' checked")
+
+	// The create form starts with the user's default expiry selected.
+	_, _, body = ts.get(t, "/snippet/create")
+	assert.StringContains(t, body, "value='7' checked")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		theme    string
+		perPage  string
+		expiry   string
+		language string
+		wantCode int
+	}{
+		{"Valid", "light", "50", "1", "fr", http.StatusSeeOther},
+		{"Invalid theme", "neon", "10", "365", "en", http.StatusUnprocessableEntity},
+		{"Invalid page size", "light", "1000", "365", "en", http.StatusUnprocessableEntity},
+		{"Invalid expiry", "light", "10", "30", "en", http.StatusUnprocessableEntity},
+		{"Invalid language", "light", "10", "365", "xx", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("theme", tt.theme)
+			form.Add("snippets_per_page", tt.perPage)
+			form.Add("default_expiry", tt.expiry)
+			form.Add("language", tt.language)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, _ := ts.postForm(t, "/account/preferences", form)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestLocale(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	header := http.Header{"Accept-Language": {"es-MX, fr;q=0.9, en;q=0.5"}}
+
+	// With nothing else to go on the Accept-Language header decides.
+	_, _, body := ts.getWithHeaders(t, "/", header)
+	assert.StringContains(t, body, "<html lang='es'>")
+
+	form := url.Values{}
+	form.Add("locale", "de")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ := ts.postForm(t, "/locale", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/")
+
+	// The language chosen for the session beats the header.
+	_, _, body = ts.getWithHeaders(t, "/", header)
+	assert.StringContains(t, body, "<html lang='de'>")
+
+	// A saved preference beats both.
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	_, _, body = ts.getWithHeaders(t, "/", header)
+	assert.StringContains(t, body, "<html lang='en'>")
+
+	form.Set("locale", "xx")
+
+	code, _, _ = ts.postForm(t, "/locale", form)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
+
+func TestPasswordRotation(t *testing.T) {
+	app := newTestApplication(t)
+	// Alice's mock password is 100 days old.
+	app.passwordMaxAge = 90 * 24 * time.Hour
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, headers, _ := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/password/expired")
+
+	code, headers, _ = ts.get(t, "/account/view")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/password/expired")
+
+	code, _, body = ts.get(t, "/account/password/expired")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Your password has not been changed for a long time")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		current  string
+		newPass  string
+		wantCode int
+		wantBody string
+	}{
+		{"Same password", "pa$$word", "pa$$word", http.StatusUnprocessableEntity, "must be different"},
+		{"Wrong current", "wrong-password", "n3w-pa$$word", http.StatusUnprocessableEntity, "Current password is incorrect"},
+		{"Valid", "pa$$word", "n3w-pa$$word", http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("currentPassword", tt.current)
+			form.Add("newPassword", tt.newPass)
+			form.Add("newPasswordConfirmation", tt.newPass)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/password/expired", form)
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+
+	code, _, _ = ts.get(t, "/account/view")
+	assert.Equal(t, code, http.StatusOK)
+}
+
+func TestAccountPasswordUpdate(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, headers, _ := ts.get(t, "/account/password")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/user/login")
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	// Changing the password needs a recent login.
+	app.reauthWindow = -time.Second
+
+	code, headers, _ = ts.get(t, "/account/password")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/reauthenticate")
+
+	app.reauthWindow = time.Hour
+
+	code, _, body := ts.get(t, "/account/password")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<form action='/account/password' method='POST' novalidate>")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		current  string
+		newPass  string
+		confirm  string
+		wantCode int
+		wantBody string
+	}{
+		{"Blank current", "", "n3w-pa$$word", "n3w-pa$$word", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Short new", "pa$$word", "short", "short", http.StatusUnprocessableEntity, "at least 8 characters"},
+		{"Mismatch", "pa$$word", "n3w-pa$$word", "other-pa$$word", http.StatusUnprocessableEntity, "Passwords do not match"},
+		{"Wrong current", "wrong-password", "n3w-pa$$word", "n3w-pa$$word", http.StatusUnprocessableEntity, "Current password is incorrect"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("currentPassword", tt.current)
+			form.Add("newPassword", tt.newPass)
+			form.Add("newPasswordConfirmation", tt.confirm)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/password", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		serverURL, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		before := ts.Client().Jar.Cookies(serverURL)
+
+		form := url.Values{}
+		form.Add("currentPassword", "pa$$word")
+		form.Add("newPassword", "n3w-pa$$word")
+		form.Add("newPasswordConfirmation", "n3w-pa$$word")
+		form.Add("csrf_token", csrfToken)
+
+		code, headers, _ := ts.postForm(t, "/account/password", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/account/view")
+
+		after := ts.Client().Jar.Cookies(serverURL)
+		assert.Equal(t, sessionCookie(after) != "", true)
+		assert.Equal(t, sessionCookie(after) != sessionCookie(before), true)
+
+		_, _, body := ts.get(t, "/account/view")
+		assert.StringContains(t, body, "Your password has been updated!")
+	})
+}
+
+// sessionCookie returns the value of the session cookie among cookies.
+func sessionCookie(cookies []*http.Cookie) string {
+	for _, c := range cookies {
+		if c.Name == "session" {
+			return c.Value
+		}
+	}
+
+	return ""
+}
+
+func TestUserLogout(t *testing.T) {
+	app := newTestApplication(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/user/logout", nil)
+
+	ctx, err := app.sessionManager.Load(r.Context(), "")
+	assert.NilError(t, err)
+
+	r = r.WithContext(ctx)
+
+	for key, value := range map[string]any{
+		"authenticatedUserID":     2,
+		"authenticatedAt":         time.Now().Unix(),
+		"tosVersion":              1,
+		"isAdmin":                 true,
+		"passwordExpired":         true,
+		"redirectPathAfterReauth": "/admin/users",
+	} {
+		app.sessionManager.Put(ctx, key, value)
+	}
+
+	rr := httptest.NewRecorder()
+	app.userLogoutPost(rr, r)
+
+	assert.Equal(t, rr.Code, http.StatusSeeOther)
+	assert.Equal(t, rr.Header().Get("Location"), "/")
+
+	// Only the goodbye is left.
+	assert.Equal(t, app.popFlash(r).Message, "You've been logged out successfully!")
+	assert.Equal(t, len(app.sessionManager.Keys(ctx)), 0)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+type tokenCreateForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+// tokenAlertWindow is how far back new-network alerts are shown on the
+// tokens page.
+const tokenAlertWindow = 7 * 24 * time.Hour
+
+func (app *application) accountTokens(w http.ResponseWriter, r *http.Request) {
+	app.renderTokens(w, r, http.StatusOK, tokenCreateForm{})
+}
+
+// renderTokens shows the user's tokens with their usage, any recent
+// new-network alerts, and the plaintext of a token created just before.
+func (app *application) renderTokens(w http.ResponseWriter, r *http.Request, status int, form tokenCreateForm) {
+	userID := app.authenticatedUserID(r)
+
+	tokens, err := app.tokens.List(r.Context(), userID)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	for i := range tokens {
+		tokens[i].IPs, err = app.tokens.IPs(r.Context(), tokens[i].ID)
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+	}
+
+	alerts, err := app.audit.List(r.Context(), models.AuditFilter{
+		UserID: userID,
+		Event:  models.AuditTokenNewIP,
+		From:   time.Now().Add(-tokenAlertWindow),
+		Limit:  10,
+	})
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Tokens = tokens
+	data.AuditEvents = alerts
+	data.NewToken = app.sessionManager.PopString(r.Context(), "newToken")
+
+	app.render(w, r, status, "tokens.tmpl", data)
+}
+
+func (app *application) accountTokenCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form tokenCreateForm
+
+	if err := app.decodePostForm(r, &form); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(
+		validator.MaxChars(form.Name, 100),
+		"name",
+		"This field cannot be more than 100 characters long",
+	)
+
+	if !form.Valid() {
+		app.renderTokens(w, r, http.StatusUnprocessableEntity, form)
+
+		return
+	}
+
+	userID := app.authenticatedUserID(r)
+
+	var plaintext string
+
+	// A token is only created along with its audit event.
+	err := app.tx.WithTx(r.Context(), func(ctx context.Context) error {
+		var (
+			token models.Token
+			err   error
+		)
+
+		plaintext, token, err = app.tokens.Create(ctx, userID, form.Name)
+		if err != nil {
+			return err //nolint:wrapcheck // already says what failed
+		}
+
+		details := fmt.Sprintf("token=%d name=%s", token.ID, token.Name)
+
+		return app.audit.Insert(ctx, userID, models.AuditTokenCreate, app.clientIP(r), details) //nolint:wrapcheck // as above
+	})
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "newToken", plaintext)
+
+	http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+}
+
+func (app *application) accountTokenDeletePost(w http.ResponseWriter, r *http.Request) {
+	id := pathInt(r, "id")
+
+	userID := app.authenticatedUserID(r)
+
+	err := app.tx.WithTx(r.Context(), func(ctx context.Context) error {
+		if err := app.tokens.Delete(ctx, userID, id); err != nil {
+			return err //nolint:wrapcheck // already says what failed
+		}
+
+		return app.audit.Insert(ctx, userID, models.AuditTokenRevoke, app.clientIP(r), fmt.Sprintf("token=%d", id)) //nolint:wrapcheck // as above
+	})
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "Token revoked.")
+
+	http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+}
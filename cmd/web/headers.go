@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+)
+
+type headersConfig struct {
+	csp                   string
+	referrerPolicy        string
+	hstsMaxAge            time.Duration
+	hstsIncludeSubdomains bool
+}
+
+// defaultHeaders are the security headers sent unless the operator
+// overrides them. HSTS is off by default, since it cannot be taken back
+// once browsers have seen it.
+var defaultHeaders = headersConfig{
+	csp:            "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com",
+	referrerPolicy: "origin-when-cross-origin",
+}
+
+// headersFlags registers the flags for the security headers set by
+// commonHeaders.
+func headersFlags(cfg *headersConfig) {
+	flag.StringVar(&cfg.csp, "csp", defaultHeaders.csp, "Content-Security-Policy header value (empty omits the header)")
+	flag.StringVar(&cfg.referrerPolicy, "referrer-policy", defaultHeaders.referrerPolicy, "Referrer-Policy header value (empty omits the header)")
+	flag.DurationVar(&cfg.hstsMaxAge, "hsts-max-age", 0, "Send Strict-Transport-Security with this max-age, e.g. 8760h; only enable once the site is HTTPS-only (0 disables)")
+	flag.BoolVar(&cfg.hstsIncludeSubdomains, "hsts-include-subdomains", false, "Add includeSubDomains to Strict-Transport-Security")
+}
+
+// hsts returns the Strict-Transport-Security header value, or "" when HSTS
+// is disabled.
+func (cfg headersConfig) hsts() string {
+	if cfg.hstsMaxAge <= 0 {
+		return ""
+	}
+
+	value := "max-age=" + strconv.FormatInt(int64(cfg.hstsMaxAge/time.Second), 10)
+	if cfg.hstsIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return value
+}
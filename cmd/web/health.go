@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+const (
+	// healthRetention is how long health checks are kept. It bounds the
+	// longest uptime window the status page can show.
+	healthRetention = 90 * 24 * time.Hour
+
+	// maxPendingChecks caps the results buffered while the database is
+	// unreachable, roughly a day at the default interval.
+	maxPendingChecks = 1440
+)
+
+// healthMonitor periodically checks the database and records the result.
+// The database is also where results are stored, so checks that cannot be
+// written are buffered in memory and flushed once it is reachable again;
+// otherwise outages would never show up in the history.
+type healthMonitor struct {
+	check     func(ctx context.Context) error
+	status    models.StatusModelInterface
+	logger    *slog.Logger
+	pending   []models.HealthCheck
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func newHealthMonitor(
+	check func(ctx context.Context) error,
+	status models.StatusModelInterface,
+	logger *slog.Logger,
+) *healthMonitor {
+	return &healthMonitor{
+		check:  check,
+		status: status,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// run checks health every interval until ctx is cancelled.
+func (hm *healthMonitor) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		hm.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (hm *healthMonitor) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	start := hm.now()
+	err := hm.check(ctx)
+
+	hc := models.HealthCheck{
+		Checked: start,
+		OK:      err == nil,
+		Latency: hm.now().Sub(start),
+	}
+	if err != nil {
+		hc.Error = err.Error()
+		hm.logger.Warn("health check failed", slog.String("err", err.Error()))
+	}
+
+	hm.pending = append(hm.pending, hc)
+	if len(hm.pending) > maxPendingChecks {
+		hm.pending = hm.pending[len(hm.pending)-maxPendingChecks:]
+	}
+
+	for len(hm.pending) > 0 {
+		if err := hm.status.RecordCheck(ctx, hm.pending[0]); err != nil {
+			// Keep the backlog and retry on the next tick.
+			return
+		}
+
+		hm.pending = hm.pending[1:]
+	}
+
+	if start.Sub(hm.lastPrune) > 24*time.Hour {
+		if err := hm.status.PruneChecks(ctx, start.Add(-healthRetention)); err != nil {
+			hm.logger.Error(err.Error())
+
+			return
+		}
+
+		hm.lastPrune = start
+	}
+}
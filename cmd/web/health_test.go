@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// recordingStatus stores health checks in memory and can simulate an
+// unreachable database.
+type recordingStatus struct {
+	models.StatusModelInterface
+
+	down    bool
+	checks  []models.HealthCheck
+	pruneAt time.Time
+}
+
+func (s *recordingStatus) RecordCheck(ctx context.Context, hc models.HealthCheck) error {
+	if s.down {
+		return errors.New("database unreachable")
+	}
+
+	s.checks = append(s.checks, hc)
+
+	return nil
+}
+
+func (s *recordingStatus) PruneChecks(ctx context.Context, before time.Time) error {
+	s.pruneAt = before
+
+	return nil
+}
+
+func TestHealthMonitor(t *testing.T) {
+	status := &recordingStatus{}
+	checkErr := error(nil)
+
+	hm := newHealthMonitor(
+		func(context.Context) error { return checkErr },
+		status,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+
+	hm.runOnce(t.Context())
+	assert.Equal(t, len(status.checks), 1)
+	assert.Equal(t, status.checks[0].OK, true)
+	assert.Equal(t, status.pruneAt.IsZero(), false)
+
+	// While the database is down, failed checks are buffered...
+	status.down = true
+	checkErr = errors.New("connection refused")
+
+	hm.runOnce(t.Context())
+	hm.runOnce(t.Context())
+	assert.Equal(t, len(status.checks), 1)
+	assert.Equal(t, len(hm.pending), 2)
+
+	// ...and written once it is back.
+	status.down = false
+	checkErr = nil
+
+	hm.runOnce(t.Context())
+	assert.Equal(t, len(status.checks), 4)
+	assert.Equal(t, len(hm.pending), 0)
+	assert.Equal(t, status.checks[1].OK, false)
+	assert.Equal(t, status.checks[1].Error, "connection refused")
+	assert.Equal(t, status.checks[3].OK, true)
+}
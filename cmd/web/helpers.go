@@ -2,13 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
 	"github.com/go-playground/form/v4"
 	"github.com/justinas/nosurf"
 )
@@ -17,19 +23,62 @@ func (app *application) serverError(w http.ResponseWriter, r *http.Request, err
 	var (
 		method = r.Method
 		uri    = r.URL.RequestURI()
-		trace  = string(debug.Stack())
+		trace  = errorx.Stack(err)
 	)
 
-	app.logger.Error(err.Error(), slog.String("method", method), slog.String("uri", uri))
+	if trace == "" {
+		trace = string(debug.Stack())
+	}
+
+	attrs := []any{
+		slog.String("method", method),
+		slog.String("uri", uri),
+		slog.Int("user_id", app.authenticatedUserID(r)),
+	}
+
+	// Keep a sanitized copy of the request for admins, referenced from the
+	// log line.
+	if app.captures != nil {
+		c := snapshot(r, app.authenticatedUserID(r), err)
+		app.captures.add(c)
+		attrs = append(attrs, slog.String("capture_id", c.ID))
+	}
+
+	app.logger.ErrorContext(r.Context(), err.Error(), attrs...)
 
+	// The request ID lets users quote the failure when reporting it.
+	body := http.StatusText(http.StatusInternalServerError)
+	if id := requestIDFromContext(r.Context()); id != "" {
+		body += "\nRequest ID: " + id
+	}
+
+	// Developers get the error and stack trace as plain text instead of the
+	// friendly page.
 	if app.debug {
-		body := fmt.Sprintf("%s\n%s", err, trace)
-		http.Error(w, body, http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("%s\n\n%s\n%s", body, err, trace), http.StatusInternalServerError)
 
 		return
 	}
 
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	app.errorPage(w, r, http.StatusInternalServerError, "server_error.tmpl", body)
+}
+
+// handleError answers a request that failed with err according to the
+// error's kind: a not found page or a client error, logged at the kind's
+// level, or, for internal errors, the server error page.
+func (app *application) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	kind := errorx.KindOf(err)
+
+	switch kind {
+	case errorx.NotFound:
+		app.logger.Log(r.Context(), kind.Level(), err.Error(), slog.String("uri", r.URL.RequestURI()))
+		app.notFound(w, r)
+	case errorx.Validation:
+		app.logger.Log(r.Context(), kind.Level(), err.Error(), slog.String("uri", r.URL.RequestURI()))
+		app.clientError(w, kind.Status())
+	default:
+		app.serverError(w, r, err)
+	}
 }
 
 //nolint:unparam //unparam status is kept for future extensibility
@@ -44,21 +93,7 @@ func (app *application) render(
 	page string,
 	data templateData,
 ) {
-	ts, ok := app.templateCache[page]
-	if !ok {
-		err := fmt.Errorf("the template %s does not exist", page)
-		app.serverError(w, r, err)
-
-		return
-	}
-
-	// Iniitialize a new buffer.
-	buf := new(bytes.Buffer)
-
-	// Write the template to the buffer, instead of straight to the
-	// http.ResponseWriter. If there's an error, call our serverError() helper
-	// and then return.
-	err := ts.ExecuteTemplate(buf, "base", data)
+	buf, err := app.executePage(page, data)
 	if err != nil {
 		app.serverError(w, r, err)
 
@@ -70,19 +105,70 @@ func (app *application) render(
 	w.WriteHeader(status)
 
 	if _, err := buf.WriteTo(w); err != nil {
-		app.logger.Error(err.Error())
+		app.logger.ErrorContext(r.Context(), err.Error())
 
 		return
 	}
 }
 
+// executePage renders page into a buffer, so that a failing template never
+// leaves a half-written response behind.
+func (app *application) executePage(page string, data templateData) (*bytes.Buffer, error) {
+	ts, ok := (*app.templateCache.Load())[page]
+	if !ok {
+		return nil, fmt.Errorf("the template %s does not exist", page)
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := ts.ExecuteTemplate(buf, "base", data); err != nil {
+		return nil, fmt.Errorf("rendering template %s: %w", page, err)
+	}
+
+	return buf, nil
+}
+
 func (app *application) newTemplateData(r *http.Request) templateData {
+	prefs := app.preferences(r)
+	settings := app.currentSettings()
+
 	return templateData{
-		CurrentYear:     time.Now().Year(),
-		Flash:           app.sessionManager.PopString(r.Context(), "flash"),
-		IsAuthenticated: app.isAuthenticated(r),
-		CSRFToken:       nosurf.Token(r),
+		CurrentYear:         time.Now().Year(),
+		Flash:               app.popFlash(r),
+		IsAuthenticated:     app.isAuthenticated(r),
+		CSRFToken:           nosurf.Token(r),
+		AuthenticatedUserID: app.authenticatedUserID(r),
+		TOSVersion:          app.tosVersion,
+		Preferences:         prefs,
+		Locale:              app.locale(r, prefs),
+		Locales:             i18n.Supported(),
+		Settings:            settings,
+		BannerDismissed:     app.bannerDismissed(r, settings.Banner),
+		BasePath:            app.basePath,
+		BaseURL:             app.baseURL,
+		AbuseContact:        app.contacts.Abuse,
+		SecurityTxt:         app.contacts.servesSecurityTxt(),
+		RequestID:           requestIDFromContext(r.Context()),
+	}
+}
+
+// preferences returns the preferences of the authenticated user, or the
+// defaults for anonymous visitors. A failed lookup is logged rather than
+// failing the request, since preferences only affect presentation.
+func (app *application) preferences(r *http.Request) models.Preferences {
+	userID := app.authenticatedUserID(r)
+	if userID == 0 {
+		return models.DefaultPreferences
+	}
+
+	prefs, err := app.prefs.Get(r.Context(), userID)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+
+		return models.DefaultPreferences
 	}
+
+	return prefs
 }
 
 func (app *application) decodePostForm(r *http.Request, dst any) error {
@@ -105,6 +191,27 @@ func (app *application) decodePostForm(r *http.Request, dst any) error {
 	return nil
 }
 
+// suspensionMessage explains to a suspended user why they are locked out and
+// for how long.
+func suspensionMessage(user models.User) string {
+	msg := fmt.Sprintf("Your account is suspended until %s UTC.", humanDate(user.SuspendedUntil))
+	if user.SuspensionReason != "" {
+		msg += " Reason: " + user.SuspensionReason
+	}
+
+	return msg
+}
+
+// authenticatedUserID returns the ID of the signed-in user, or 0 when the
+// request is anonymous.
+func (app *application) authenticatedUserID(r *http.Request) int {
+	if !app.isAuthenticated(r) {
+		return 0
+	}
+
+	return app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+}
+
 func (app *application) isAuthenticated(r *http.Request) bool {
 	isAuthenticated, ok := r.Context().Value(isAuthenticatedContextKey).(bool)
 	if !ok {
@@ -113,3 +220,114 @@ func (app *application) isAuthenticated(r *http.Request) bool {
 
 	return isAuthenticated
 }
+
+// recordAudit appends an event to the audit log. Failures are logged rather
+// than surfaced so that a broken audit table never blocks a user action.
+func (app *application) recordAudit(r *http.Request, userID int, event, details string) {
+	err := app.audit.Insert(r.Context(), userID, event, app.clientIP(r), details)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error(), slog.String("event", event), slog.Int("userID", userID))
+	}
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameOriginRefererPath returns the path of the Referer header, relative to
+// the base path, when it points back at this site, and an empty string
+// otherwise.
+func (app *application) sameOriginRefererPath(r *http.Request) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || ref.Host != r.Host {
+		return ""
+	}
+
+	path, ok := strings.CutPrefix(ref.Path, app.basePath)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return ""
+	}
+
+	return path
+}
+
+// verifyCaptcha checks the CAPTCHA token submitted with r. It always succeeds
+// when no CAPTCHA provider is configured.
+func (app *application) verifyCaptcha(r *http.Request) (bool, error) {
+	if app.captcha == nil {
+		return true, nil
+	}
+
+	token := r.PostForm.Get(app.captcha.Widget().ResponseField)
+
+	ok, err := app.captcha.Verify(r.Context(), token, app.clientIP(r))
+	if err != nil {
+		return false, fmt.Errorf("verifying captcha: %w", err)
+	}
+
+	return ok, nil
+}
+
+// withCaptcha adds the CAPTCHA widget to data and relaxes the
+// Content-Security-Policy so the provider's script and iframe can load.
+func (app *application) withCaptcha(w http.ResponseWriter, data *templateData) {
+	if app.captcha == nil {
+		return
+	}
+
+	widget := app.captcha.Widget()
+	data.Captcha = &widget
+
+	origins := strings.Join(widget.Origins, " ")
+	csp := w.Header().Get("Content-Security-Policy")
+	csp += fmt.Sprintf("; script-src 'self' %[1]s; frame-src %[1]s; connect-src 'self' %[1]s", origins)
+	w.Header().Set("Content-Security-Policy", csp)
+}
+
+// locale picks the locale for a request: the user's saved language first,
+// then the language chosen for the session, then the browser's
+// Accept-Language header.
+func (app *application) locale(r *http.Request, prefs models.Preferences) *i18n.Locale {
+	if l, ok := i18n.Get(prefs.Language); ok {
+		return l
+	}
+
+	if l, ok := i18n.Get(app.sessionManager.GetString(r.Context(), "locale")); ok {
+		return l
+	}
+
+	if l, ok := i18n.Match(r.Header.Get("Accept-Language")); ok {
+		return l
+	}
+
+	return i18n.Default()
+}
+
+// loadSettings reads the instance settings from the database into the
+// in-memory copy used while serving requests.
+func (app *application) loadSettings(ctx context.Context) error {
+	settings, err := app.settings.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	app.settingsCache.Store(&settings)
+
+	return nil
+}
+
+// currentSettings returns the cached instance settings, or the defaults if
+// they have not been loaded.
+func (app *application) currentSettings() models.Settings {
+	if s := app.settingsCache.Load(); s != nil {
+		return *s
+	}
+
+	return models.DefaultSettings
+}
@@ -0,0 +1,23 @@
+//go:build !dev
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The request inspector at /_debug/requests only exists in development
+// builds (go build -tags dev); see inspector_dev.go. In other builds these
+// hooks do nothing.
+
+func (app *application) inspect(next http.Handler) http.Handler {
+	return next
+}
+
+func (app *application) inspectorRoutes(_ *router) {}
+
+func queryTracer() pgx.QueryTracer {
+	return nil
+}
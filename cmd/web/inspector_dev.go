@@ -0,0 +1,230 @@
+//go:build dev
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// inspectorSize is the number of requests the inspector keeps.
+const inspectorSize = 50
+
+// inspectorRedacted lists form fields whose values are never shown.
+var inspectorRedacted = []string{"password", "currentPassword", "newPassword", "newPasswordConfirmation", "csrf_token"}
+
+type inspectedQuery struct {
+	SQL      string
+	Args     int
+	Duration time.Duration
+	Err      string
+}
+
+// inspectedRequest is what the inspector knows about one request. Queries
+// may be added from other goroutines that share the request context, so
+// all access goes through mu.
+type inspectedRequest struct {
+	mu       sync.Mutex
+	Time     time.Time
+	Method   string
+	URI      string
+	Pattern  string
+	Status   int
+	Duration time.Duration
+	Session  map[string]string
+	Form     url.Values
+	Queries  []inspectedQuery
+}
+
+// requestInspector is a ring of the most recent requests, newest first. It
+// is package level because the query tracer is installed on the database
+// pool before the application exists.
+var requestInspector = &inspector{}
+
+type inspector struct {
+	mu       sync.Mutex
+	requests []*inspectedRequest
+}
+
+func (in *inspector) add(req *inspectedRequest) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.requests = slices.Insert(in.requests, 0, req)
+	if len(in.requests) > inspectorSize {
+		in.requests = in.requests[:inspectorSize]
+	}
+}
+
+// snapshot copies the recorded requests so they can be rendered without
+// holding any locks.
+func (in *inspector) snapshot() []inspectedRequest {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	out := make([]inspectedRequest, 0, len(in.requests))
+
+	for _, req := range in.requests {
+		req.mu.Lock()
+		out = append(out, inspectedRequest{
+			Time:     req.Time,
+			Method:   req.Method,
+			URI:      req.URI,
+			Pattern:  req.Pattern,
+			Status:   req.Status,
+			Duration: req.Duration,
+			Session:  req.Session,
+			Form:     req.Form,
+			Queries:  slices.Clone(req.Queries),
+		})
+		req.mu.Unlock()
+	}
+
+	return out
+}
+
+type inspectorContextKeyType struct{}
+
+var inspectorContextKey = inspectorContextKeyType{}
+
+// inspect records the request with its timing, the session data and form
+// values the handler left behind, and the queries run on its context. It
+// must run after the session has been loaded.
+func (app *application) inspect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &inspectedRequest{
+			Time:    time.Now(),
+			Method:  r.Method,
+			URI:     r.URL.RequestURI(),
+			Pattern: r.Pattern,
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), inspectorContextKey, req))
+
+		next.ServeHTTP(w, r)
+
+		// logRequest, further up the chain, has seen the status by now.
+		status := http.StatusOK
+		if rl, ok := r.Context().Value(requestLogContextKey).(*requestLog); ok {
+			status, _, _ = rl.result()
+		}
+
+		session := make(map[string]string)
+		for _, key := range app.sessionManager.Keys(r.Context()) {
+			session[key] = fmt.Sprintf("%v", app.sessionManager.Get(r.Context(), key))
+		}
+
+		form := make(url.Values, len(r.PostForm))
+		for name, values := range r.PostForm {
+			if slices.Contains(inspectorRedacted, name) {
+				values = []string{"[redacted]"}
+			}
+
+			form[name] = values
+		}
+
+		req.mu.Lock()
+		req.Duration = time.Since(req.Time)
+		req.Status = status
+		req.Session = session
+		req.Form = form
+		req.mu.Unlock()
+
+		requestInspector.add(req)
+	})
+}
+
+// queryTracer records the queries run on behalf of inspected requests.
+func queryTracer() pgx.QueryTracer {
+	return inspectorTracer{}
+}
+
+type inspectorTracer struct{}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql  string
+	args int
+	time time.Time
+}
+
+func (inspectorTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if _, ok := ctx.Value(inspectorContextKey).(*inspectedRequest); !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, args: len(data.Args), time: time.Now()})
+}
+
+func (inspectorTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	req, ok := ctx.Value(inspectorContextKey).(*inspectedRequest)
+	if !ok {
+		return
+	}
+
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	q := inspectedQuery{SQL: start.sql, Args: start.args, Duration: time.Since(start.time)}
+	if data.Err != nil {
+		q.Err = data.Err.Error()
+	}
+
+	req.mu.Lock()
+	req.Queries = append(req.Queries, q)
+	req.mu.Unlock()
+}
+
+func (app *application) inspectorRoutes(mux *router) {
+	mux.HandleFunc("GET /_debug/requests", app.inspectorPage)
+}
+
+var inspectorTemplate = template.Must(template.New("inspector").Parse(`<!doctype html>
+<html lang='en'>
+<head>
+<meta charset='utf-8'>
+<title>Request inspector - Snippetbox</title>
+<style>
+body { font-family: monospace; margin: 2em; }
+details { border-bottom: 1px solid #ddd; padding: 0.5em 0; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+td, th { border: 1px solid #ddd; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+pre { margin: 0; white-space: pre-wrap; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Last {{len .}} requests</h1>
+{{range .}}
+<details>
+<summary>{{.Time.Format "15:04:05.000"}} {{.Status}} {{.Method}} {{.URI}} ({{.Duration}}, {{len .Queries}} queries)</summary>
+<p>Route: {{with .Pattern}}{{.}}{{else}}none{{end}}</p>
+{{with .Session}}<table><tr><th colspan='2'>Session</th></tr>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>{{end}}
+{{with .Form}}<table><tr><th colspan='2'>Form</th></tr>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{range $v}}<pre>{{.}}</pre>{{end}}</td></tr>{{end}}</table>{{end}}
+{{with .Queries}}<table><tr><th>Query</th><th>Args</th><th>Time</th></tr>{{range .}}<tr><td><pre>{{.SQL}}</pre>{{with .Err}}<span class='error'>{{.}}</span>{{end}}</td><td>{{.Args}}</td><td>{{.Duration}}</td></tr>{{end}}</table>{{end}}
+</details>
+{{else}}
+<p>No requests recorded yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+func (app *application) inspectorPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := inspectorTemplate.Execute(w, requestInspector.snapshot()); err != nil {
+		app.handleError(w, r, err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build dev
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestInspector(t *testing.T) {
+	requestInspector = &inspector{}
+
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/_debug/requests")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "303 POST /user/login")
+	assert.StringContains(t, body, "Route: POST /user/login")
+	assert.StringContains(t, body, "alice@example.com")
+	assert.StringContains(t, body, "[redacted]")
+	assert.StringContains(t, body, "authenticatedUserID")
+	assert.Equal(t, strings.Contains(body, "pa$$word"), false)
+	assert.Equal(t, strings.Contains(body, "/_debug/requests"), false)
+}
+
+func TestInspectorTracer(t *testing.T) {
+	req := &inspectedRequest{}
+	ctx := context.WithValue(context.Background(), inspectorContextKey, req)
+
+	tracer := queryTracer()
+
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1", Args: []any{1, 2}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+
+	assert.Equal(t, len(req.Queries), 1)
+	assert.Equal(t, req.Queries[0].SQL, "SELECT 1")
+	assert.Equal(t, req.Queries[0].Args, 2)
+	assert.Equal(t, req.Queries[0].Err, "boom")
+
+	// Queries outside an inspected request are ignored.
+	other := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 2"})
+	tracer.TraceQueryEnd(other, nil, pgx.TraceQueryEndData{})
+
+	assert.Equal(t, len(req.Queries), 1)
+}
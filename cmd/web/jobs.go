@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/scheduler"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type jobsConfig struct {
+	purgeSchedule  string
+	rollupSchedule string
+}
+
+// jobFlags registers the flags that schedule the periodic jobs.
+func jobFlags(cfg *jobsConfig) {
+	flag.StringVar(&cfg.purgeSchedule, "purge-schedule", "0 3 * * *", "Cron schedule (UTC) for deleting expired snippets (empty disables)")
+	flag.StringVar(&cfg.rollupSchedule, "stats-rollup-schedule", "@hourly", "Cron schedule (UTC) for rolling up the daily site totals (empty disables)")
+}
+
+// validate rejects schedules that do not parse.
+func (cfg jobsConfig) validate() error {
+	for flagName, spec := range map[string]string{
+		"purge-schedule":        cfg.purgeSchedule,
+		"stats-rollup-schedule": cfg.rollupSchedule,
+	} {
+		if spec == "" {
+			continue
+		}
+
+		if _, err := scheduler.Parse(spec); err != nil {
+			return fmt.Errorf("-%s: %w", flagName, err)
+		}
+	}
+
+	return nil
+}
+
+// scheduleJobs registers the periodic maintenance jobs with app.scheduler.
+// Both are idempotent, so instances sharing a database may each run them.
+func (app *application) scheduleJobs(cfg jobsConfig, db *pgxpool.Pool) error {
+	if cfg.purgeSchedule != "" {
+		err := app.scheduler.Add("purge-expired-snippets", cfg.purgeSchedule, func(ctx context.Context) error {
+			n, err := models.PurgeExpiredSnippets(ctx, db)
+			if err != nil {
+				return err //nolint:wrapcheck // recorded as the job's error
+			}
+
+			app.logger.Info("purged expired snippets", slog.Int64("count", n))
+
+			return nil
+		})
+		if err != nil {
+			return err //nolint:wrapcheck // validated with the configuration
+		}
+	}
+
+	if cfg.rollupSchedule != "" {
+		// Yesterday is included so its last hour is counted after midnight.
+		err := app.scheduler.Add("rollup-daily-stats", cfg.rollupSchedule, func(ctx context.Context) error {
+			return models.RollupDailyStats(ctx, db, time.Now().UTC().AddDate(0, 0, -1)) //nolint:wrapcheck // recorded as the job's error
+		})
+		if err != nil {
+			return err //nolint:wrapcheck // validated with the configuration
+		}
+	}
+
+	return nil
+}
+
+func (app *application) adminJobs(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Jobs = app.scheduler.Status()
+
+	app.render(w, r, http.StatusOK, "admin_jobs.tmpl", data)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+const (
+	// jsonFeedVersion identifies the JSON Feed format the feed follows.
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+	// jsonFeedSize is how many of the latest snippets the feed lists.
+	jsonFeedSize = 20
+)
+
+// jsonFeed is a JSON Feed 1.1 document, see https://jsonfeed.org/version/1.1.
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string             `json:"id"`
+	URL           string             `json:"url"`
+	Title         string             `json:"title"`
+	ContentText   string             `json:"content_text"`
+	DatePublished time.Time          `json:"date_published"`
+	DateModified  *time.Time         `json:"date_modified,omitempty"`
+	Snippetbox    jsonFeedItemExtras `json:"_snippetbox"`
+}
+
+// jsonFeedItemExtras extends feed items with what JSON Feed has no field
+// for, under the _snippetbox key as the format requires.
+type jsonFeedItemExtras struct {
+	ID      int       `json:"id"`
+	Expires time.Time `json:"expires"`
+}
+
+// jsonFeed serves the latest snippets as a JSON Feed for feed readers and
+// scripts. Like the raw content it needs no session, so shared caches can
+// keep it; they revalidate it with its ETag.
+func (app *application) jsonFeed(w http.ResponseWriter, r *http.Request) {
+	snippets, err := app.snippets.Latest(r.Context(), jsonFeedSize)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// There is no Last-Modified: when a snippet expires and drops out of the
+	// feed, the newest remaining update can be older than one a client has
+	// already seen, so If-Modified-Since would wrongly match. The ETag
+	// covers the set of items and does not have this problem.
+	if notModified(w, r, jsonFeedETag(snippets), time.Time{}) {
+		return
+	}
+
+	feed := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       "Snippetbox",
+		HomePageURL: app.baseURL + "/",
+		FeedURL:     app.baseURL + "/feed.json",
+		Description: "The latest snippets",
+		Items:       make([]jsonFeedItem, 0, len(snippets)),
+	}
+
+	for _, s := range snippets {
+		feed.Items = append(feed.Items, app.jsonFeedItem(s))
+	}
+
+	body, err := json.Marshal(feed)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
+
+func (app *application) jsonFeedItem(s models.Snippet) jsonFeedItem {
+	url := fmt.Sprintf("%s/snippet/view/%d", app.baseURL, s.ID)
+
+	item := jsonFeedItem{
+		ID:            url,
+		URL:           url,
+		Title:         s.Title,
+		ContentText:   s.Content,
+		DatePublished: s.Created.UTC(),
+		Snippetbox:    jsonFeedItemExtras{ID: s.ID, Expires: s.Expires.UTC()},
+	}
+
+	if s.Updated.After(s.Created) {
+		updated := s.Updated.UTC()
+		item.DateModified = &updated
+	}
+
+	return item
+}
+
+// jsonFeedETag returns a strong entity tag for the feed of snippets, which
+// changes whenever one of them is added, edited or drops out of the feed.
+func jsonFeedETag(snippets []models.Snippet) string {
+	h := sha256.New()
+
+	for _, s := range snippets {
+		fmt.Fprintf(h, "%d:%s|", s.ID, snippetETag(s))
+	}
+
+	return `"feed-` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestJSONFeed(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/feed.json")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Type"), "application/feed+json")
+	assert.Equal(t, header.Get("Cache-Control"), "no-cache")
+	assert.Equal(t, header.Get("Last-Modified"), "")
+
+	var feed jsonFeed
+	if err := json.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, feed.Version, "https://jsonfeed.org/version/1.1")
+	assert.Equal(t, feed.FeedURL, "https://snippetbox.test/feed.json")
+	assert.Equal(t, len(feed.Items), 1)
+	assert.Equal(t, feed.Items[0].ID, "https://snippetbox.test/snippet/view/1")
+	assert.Equal(t, feed.Items[0].Title, "An old silent pond")
+	assert.Equal(t, feed.Items[0].ContentText, "An old silent pond...")
+	assert.Equal(t, feed.Items[0].Snippetbox.ID, 1)
+
+	code, _, _ = ts.getWithHeaders(t, "/feed.json", http.Header{"If-None-Match": {header.Get("ETag")}})
+	assert.Equal(t, code, http.StatusNotModified)
+
+	_, _, body = ts.get(t, "/")
+	assert.StringContains(t, body, `<link rel='alternate' type='application/feed+json' title='Snippetbox' href='/feed.json'>`)
+}
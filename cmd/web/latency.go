@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/latency"
+)
+
+// latencyCheckInterval is how often route latencies are compared against
+// their budgets.
+const latencyCheckInterval = 30 * time.Second
+
+// trackLatency records how long each request took under the pattern of the
+// route that served it. It must wrap the ServeMux directly, since the mux
+// sets r.Pattern on the request it is given.
+func (app *application) trackLatency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		next.ServeHTTP(w, r)
+
+		// An event stream or websocket lasts as long as the client stays,
+		// which says nothing about how fast the server answers.
+		if r.Pattern == snippetEventsRoute || r.Pattern == liveRoute {
+			return
+		}
+
+		app.latency.Observe(r.Pattern, time.Since(start))
+	})
+}
+
+// latencyMonitor periodically checks the tracked routes against their
+// budgets, logging a warning when one goes over budget and, if a webhook is
+// configured, posting an alert to it.
+type latencyMonitor struct {
+	tracker *latency.Tracker
+	logger  *slog.Logger
+	webhook string
+	client  *http.Client
+	window  time.Duration
+}
+
+// latencyAlert is the JSON body posted to the alert webhook.
+type latencyAlert struct {
+	Route    string `json:"route"`
+	Status   string `json:"status"`
+	P95MS    int64  `json:"p95_ms"`
+	BudgetMS int64  `json:"budget_ms"`
+	Samples  int    `json:"samples"`
+	Window   string `json:"window"`
+}
+
+// run checks budgets every interval until ctx is cancelled.
+func (lm *latencyMonitor) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lm.runOnce(ctx)
+		}
+	}
+}
+
+func (lm *latencyMonitor) runOnce(ctx context.Context) {
+	for _, rep := range lm.tracker.Check() {
+		attrs := []any{
+			slog.String("route", rep.Route),
+			slog.Duration("p95", rep.P95),
+			slog.Duration("budget", rep.Budget),
+			slog.Int("samples", rep.Samples),
+			slog.Duration("window", lm.window),
+		}
+
+		status := "ok"
+		if rep.Over {
+			status = "over_budget"
+			lm.logger.Warn("route over latency budget", attrs...)
+		} else {
+			lm.logger.Info("route back within latency budget", attrs...)
+		}
+
+		if lm.webhook == "" {
+			continue
+		}
+
+		alert := latencyAlert{
+			Route:    rep.Route,
+			Status:   status,
+			P95MS:    rep.P95.Milliseconds(),
+			BudgetMS: rep.Budget.Milliseconds(),
+			Samples:  rep.Samples,
+			Window:   lm.window.String(),
+		}
+
+		if err := lm.post(ctx, alert); err != nil {
+			lm.logger.Error(err.Error(), slog.String("route", rep.Route))
+		}
+	}
+}
+
+func (lm *latencyMonitor) post(ctx context.Context, alert latencyAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("encoding latency alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lm.webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating latency alert request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := lm.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting latency alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("posting latency alert: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
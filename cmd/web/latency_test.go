@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/latency"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+)
+
+func TestLatencyBudgets(t *testing.T) {
+	alerts := make(chan latencyAlert, 1)
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert latencyAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Error(err)
+		}
+
+		alerts <- alert
+	}))
+	defer webhook.Close()
+
+	app := newTestApplication(t)
+	// Every request takes longer than a nanosecond.
+	app.latency = latency.New(latency.Budgets{
+		Routes: map[string]time.Duration{"GET /ping": time.Nanosecond},
+	}, time.Minute)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for range 20 {
+		code, _, _ := ts.get(t, "/ping")
+		assert.Equal(t, code, http.StatusOK)
+	}
+
+	logs := logbuffer.New(10)
+
+	lm := &latencyMonitor{
+		tracker: app.latency,
+		logger:  slog.New(logs.Handler(slog.DiscardHandler)),
+		webhook: webhook.URL,
+		client:  webhook.Client(),
+		window:  time.Minute,
+	}
+	lm.runOnce(context.Background())
+
+	records := logs.Records(logbuffer.Filter{})
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Level, slog.LevelWarn)
+	assert.Equal(t, records[0].Message, "route over latency budget")
+	assert.Equal(t, records[0].Attr("route"), "GET /ping")
+
+	alert := <-alerts
+	assert.Equal(t, alert.Route, "GET /ping")
+	assert.Equal(t, alert.Status, "over_budget")
+	assert.Equal(t, alert.Samples, 20)
+	assert.Equal(t, alert.Window, "1m0s")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+const (
+	// mailInterval is how often the outbox is polled when nothing new was
+	// queued in the meantime.
+	mailInterval = 30 * time.Second
+
+	// mailBatchSize is the number of queued emails claimed per poll.
+	mailBatchSize = 50
+)
+
+// queueMailer implements mailer.Mailer by writing messages to the
+// persistent email queue, from which mailDispatcher delivers them.
+type queueMailer struct {
+	queue  models.EmailQueueModelInterface
+	notify chan<- struct{}
+}
+
+func (q *queueMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if err := q.queue.Enqueue(ctx, msg.To, msg.Subject, msg.Body, msg.HTML); err != nil {
+		return err
+	}
+
+	// Wake the dispatcher without blocking if it is already awake.
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// mailDispatcher delivers queued emails, rescheduling failed deliveries with
+// exponential backoff until models.MaxEmailAttempts is reached.
+type mailDispatcher struct {
+	queue  models.EmailQueueModelInterface
+	mailer mailer.Mailer
+	logger *slog.Logger
+	notify <-chan struct{}
+	now    func() time.Time
+}
+
+// newMailProviders returns the configured mail providers in order of
+// preference, falling back to logging emails when none is configured or in
+// dry-run mode.
+func newMailProviders(cfg mailConfig, logger *slog.Logger) []mailer.Provider {
+	if cfg.dryRun {
+		return []mailer.Provider{&mailer.LogMailer{Logger: logger, Reason: "dry run"}}
+	}
+
+	var providers []mailer.Provider
+
+	if cfg.smtpAddr != "" {
+		providers = append(providers, &mailer.SMTP{
+			Addr:     cfg.smtpAddr,
+			Username: cfg.smtpUsername,
+			Password: cfg.smtpPassword,
+			From:     cfg.from,
+		})
+	}
+
+	if cfg.mailgunDomain != "" {
+		providers = append(providers, &mailer.Mailgun{
+			Domain: cfg.mailgunDomain,
+			APIKey: cfg.mailgunAPIKey,
+			From:   cfg.from,
+		})
+	}
+
+	if cfg.sesRegion != "" {
+		providers = append(providers, &mailer.SES{
+			Region:          cfg.sesRegion,
+			AccessKeyID:     cfg.sesAccessKeyID,
+			SecretAccessKey: cfg.sesSecretAccessKey,
+			From:            cfg.from,
+		})
+	}
+
+	if len(providers) == 0 {
+		providers = append(providers, &mailer.LogMailer{Logger: logger})
+	}
+
+	return providers
+}
+
+// startMailer routes the application's email through the persistent queue
+// and delivers it in the background until shutdown.
+func (app *application) startMailer(cfg mailConfig) {
+	notify := make(chan struct{}, 1)
+
+	app.mailer = &queueMailer{queue: app.emailQueue, notify: notify}
+
+	d := &mailDispatcher{
+		queue:  app.emailQueue,
+		mailer: mailer.NewFailover(app.logger, newMailProviders(cfg, app.logger)...),
+		logger: app.logger,
+		notify: notify,
+		now:    time.Now,
+	}
+
+	app.workers.Go("mailer", func(ctx context.Context) {
+		d.run(ctx, mailInterval)
+	})
+}
+
+func (d *mailDispatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.notify:
+		}
+	}
+}
+
+func (d *mailDispatcher) runOnce(ctx context.Context) {
+	emails, err := d.queue.Due(ctx, mailBatchSize)
+	if err != nil {
+		d.logger.Error(err.Error())
+
+		return
+	}
+
+	for _, e := range emails {
+		d.deliver(ctx, e)
+	}
+}
+
+func (d *mailDispatcher) deliver(ctx context.Context, e models.QueuedEmail) {
+	msg := mailer.Message{To: e.Recipient, Subject: e.Subject, Body: e.Body, HTML: e.HTML}
+
+	sendErr := d.mailer.Send(ctx, msg)
+	if sendErr == nil {
+		if err := d.queue.MarkSent(ctx, e.ID); err != nil {
+			d.logger.Error(err.Error())
+		}
+
+		return
+	}
+
+	attempt := e.Attempts + 1
+	if attempt >= models.MaxEmailAttempts {
+		d.logger.Error("giving up on email",
+			slog.Int("id", e.ID), slog.Int("attempts", attempt), slog.String("err", sendErr.Error()))
+	}
+
+	next := d.now().Add(emailRetryDelay(attempt))
+	if err := d.queue.Retry(ctx, e.ID, next, sendErr.Error()); err != nil {
+		d.logger.Error(err.Error())
+	}
+}
+
+// emailRetryDelay doubles from one minute per failed attempt, up to six
+// hours.
+func emailRetryDelay(attempt int) time.Duration {
+	const maxDelay = 6 * time.Hour
+
+	if attempt > 20 {
+		return maxDelay
+	}
+
+	return min(time.Minute<<(attempt-1), maxDelay)
+}
+
+// adminEmailsLimit is the number of dead letters listed on the admin page.
+const adminEmailsLimit = 100
+
+func (app *application) adminEmails(w http.ResponseWriter, r *http.Request) {
+	emails, err := app.emailQueue.Failed(r.Context(), adminEmailsLimit)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Emails = emails
+
+	app.render(w, r, http.StatusOK, "admin_emails.tmpl", data)
+}
+
+// adminEmailRequeuePost gives a dead letter another round of delivery
+// attempts, e.g. once a misconfigured provider has been fixed.
+func (app *application) adminEmailRequeuePost(w http.ResponseWriter, r *http.Request) {
+	err := app.emailQueue.Requeue(r.Context(), pathInt(r, "id"))
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.flash(r, flashSuccess, "The email has been queued for delivery again.")
+
+	http.Redirect(w, r, "/admin/emails", http.StatusSeeOther)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// memoryQueue is an in-memory email queue.
+type memoryQueue struct {
+	emails  []models.QueuedEmail
+	sent    map[int]bool
+	next    map[int]time.Time
+	lastErr map[int]string
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{
+		sent:    map[int]bool{},
+		next:    map[int]time.Time{},
+		lastErr: map[int]string{},
+	}
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, recipient, subject, body, html string) error {
+	id := len(q.emails) + 1
+	q.emails = append(q.emails, models.QueuedEmail{ID: id, Recipient: recipient, Subject: subject, Body: body, HTML: html})
+
+	return nil
+}
+
+func (q *memoryQueue) Due(ctx context.Context, limit int) ([]models.QueuedEmail, error) {
+	var due []models.QueuedEmail
+
+	for _, e := range q.emails {
+		if !q.sent[e.ID] && e.Attempts < models.MaxEmailAttempts && q.next[e.ID].IsZero() {
+			due = append(due, e)
+		}
+	}
+
+	return due, nil
+}
+
+func (q *memoryQueue) MarkSent(ctx context.Context, id int) error {
+	q.sent[id] = true
+
+	return nil
+}
+
+func (q *memoryQueue) Retry(ctx context.Context, id int, next time.Time, lastError string) error {
+	q.emails[id-1].Attempts++
+	q.next[id] = next
+	q.lastErr[id] = lastError
+
+	return nil
+}
+
+func (q *memoryQueue) Failed(ctx context.Context, limit int) ([]models.QueuedEmail, error) {
+	var failed []models.QueuedEmail
+
+	for _, e := range slices.Backward(q.emails) {
+		if !q.sent[e.ID] && e.Attempts >= models.MaxEmailAttempts && len(failed) < limit {
+			e.LastError = q.lastErr[e.ID]
+			failed = append(failed, e)
+		}
+	}
+
+	return failed, nil
+}
+
+func (q *memoryQueue) Requeue(ctx context.Context, id int) error {
+	if id < 1 || id > len(q.emails) || q.sent[id] || q.emails[id-1].Attempts < models.MaxEmailAttempts {
+		return models.ErrNoRecord
+	}
+
+	q.emails[id-1].Attempts = 0
+	delete(q.next, id)
+	delete(q.lastErr, id)
+
+	return nil
+}
+
+type flakyMailer struct {
+	err error
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return m.err
+}
+
+func TestMailDispatcher(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	queue := newMemoryQueue()
+	notify := make(chan struct{}, 1)
+	qm := &queueMailer{queue: queue, notify: notify}
+
+	providers := &flakyMailer{err: errors.New("all providers failed")}
+
+	d := &mailDispatcher{
+		queue:  queue,
+		mailer: providers,
+		logger: slog.New(slog.DiscardHandler),
+		now:    func() time.Time { return now },
+	}
+
+	assert.NilError(t, qm.Send(t.Context(), mailer.Message{To: "alice@example.com", Subject: "Hi"}))
+	assert.NilError(t, qm.Send(t.Context(), mailer.Message{To: "bob@example.com", Subject: "Hi"}))
+
+	// Queueing wakes the dispatcher, even for several messages at once.
+	assert.Equal(t, len(notify), 1)
+
+	// During an outage the emails stay queued for a later attempt.
+	d.runOnce(t.Context())
+	assert.Equal(t, queue.sent[1], false)
+	assert.Equal(t, queue.next[1], now.Add(time.Minute))
+	assert.Equal(t, queue.lastErr[1], "all providers failed")
+
+	// Once a provider is back they are delivered.
+	providers.err = nil
+	delete(queue.next, 1)
+	delete(queue.next, 2)
+
+	d.runOnce(t.Context())
+	assert.Equal(t, queue.sent[1], true)
+	assert.Equal(t, queue.sent[2], true)
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	assert.Equal(t, emailRetryDelay(1), time.Minute)
+	assert.Equal(t, emailRetryDelay(4), 8*time.Minute)
+	assert.Equal(t, emailRetryDelay(10), 6*time.Hour)
+	assert.Equal(t, emailRetryDelay(100), 6*time.Hour)
+}
+
+func TestNewMailProvidersDryRun(t *testing.T) {
+	cfg := mailConfig{smtpAddr: "smtp.example.com:587", mailgunDomain: "mg.example.com"}
+	logger := slog.New(slog.DiscardHandler)
+
+	assert.Equal(t, len(newMailProviders(cfg, logger)), 2)
+
+	cfg.dryRun = true
+
+	providers := newMailProviders(cfg, logger)
+	assert.Equal(t, len(providers), 1)
+	assert.Equal(t, providers[0].Name(), "log")
+}
+
+func TestAdminEmails(t *testing.T) {
+	app := newTestApplication(t)
+	queue := app.emailQueue.(*memoryQueue)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	assert.NilError(t, queue.Enqueue(t.Context(), "alice@example.com", `Your snippet "<script>" expires soon`, "Hi", ""))
+	assert.NilError(t, queue.Enqueue(t.Context(), "bob@example.com", "Still trying", "Hi", ""))
+
+	// The first email used up its attempts; the second will be retried.
+	queue.emails[0].Attempts = models.MaxEmailAttempts
+	queue.lastErr[1] = "smtp: 550 mailbox unavailable"
+	queue.emails[1].Attempts = 3
+
+	ts.login(t, "admin@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/admin/emails")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>alice@example.com</td>")
+	assert.StringContains(t, body, "<td>Your snippet &#34;&lt;script&gt;&#34; expires soon</td>")
+	assert.StringContains(t, body, "<td>smtp: 550 mailbox unavailable</td>")
+	assert.Equal(t, strings.Contains(body, "bob@example.com"), false)
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, _, _ = ts.postForm(t, "/admin/emails/2/requeue", form)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, headers, _ := ts.postForm(t, "/admin/emails/1/requeue", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/admin/emails")
+	assert.Equal(t, queue.emails[0].Attempts, 0)
+
+	_, _, body = ts.get(t, "/admin/emails")
+	assert.StringContains(t, body, "The email has been queued for delivery again.")
+	assert.StringContains(t, body, "No emails have failed delivery.")
+}
@@ -4,24 +4,40 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
-	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"text/template"
 	"time"
 
-	//nolint:gosec // pprof is intentionally enabled in debug mode only
-	_ "net/http/pprof"
-
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/captcha"
+	"github.com/FABLOUSFALCON/snippetbox/internal/latency"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
 	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+	"github.com/FABLOUSFALCON/snippetbox/internal/scheduler"
+	"github.com/FABLOUSFALCON/snippetbox/internal/securecookie"
+	"github.com/FABLOUSFALCON/snippetbox/internal/webauthn"
+	"github.com/FABLOUSFALCON/snippetbox/internal/worker"
+	"github.com/FABLOUSFALCON/snippetbox/ui"
 	"github.com/alexedwards/scs/postgresstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 /* =========================
@@ -29,23 +45,200 @@ import (
    ========================= */
 
 type config struct {
-	addr     string
-	dsn      string
-	debug    bool
-	certFile string
-	keyFile  string
-	useTLS   bool
+	addr        string
+	dsn         string
+	replicaDSN  string
+	migrate     bool
+	dbTimeout   time.Duration
+	debug       bool
+	pprof       bool
+	pprofAddr   string
+	metricsAddr string
+	grpcAddr    string
+	grpcToken   string
+	certFile    string
+	keyFile     string
+	useTLS      bool
+	tosVersion  int
+
+	loginFreeAttempts int
+	loginBackoff      time.Duration
+	loginMaxBackoff   time.Duration
+	reauthWindow      time.Duration
+	cookieSecret      string
+
+	captchaProvider string
+	captchaSiteKey  string
+	captchaSecret   string
+
+	tokenRate       float64
+	tokenBurst      int
+	adminTokenRate  float64
+	adminTokenBurst int
+	apiIPRate       float64
+	apiIPBurst      int
+	apiSunset       string
+	ipRate          float64
+	ipBurst         int
+
+	healthInterval time.Duration
+	passwordMaxAge int
+	baseURL        string
+	basePath       string
+	templateDir    string
+	logBufferSize  int
+	logFormat      string
+	logLevel       string
+	errorCapture   time.Duration
+	expiryNotice   time.Duration
+
+	latencyBudgets string
+	latencyBudget  time.Duration
+	latencyWindow  time.Duration
+	latencyWebhook string
+
+	shutdownTimeout time.Duration
+
+	securityContact string
+	securityPolicy  string
+	securityExpires string
+	securityTxtFile string
+	abuseContact    string
+
+	signupAllowDomains    string
+	signupDenyDomains     string
+	signupDenyDomainsFile string
+
+	httpRedirectAddr string
+	acmeWebroot      string
+	trustedProxies   string
+	adminAllow       string
+
+	mail     mailConfig
+	cache    cacheConfig
+	autoTLS  autoTLSConfig
+	tracing  tracingConfig
+	timeouts timeoutConfig
+	sessions sessionConfig
+	jobs     jobsConfig
+	robots   robotsConfig
+	headers  headersConfig
+	bodyLog  bodyLogConfig
+}
+
+type mailConfig struct {
+	from               string
+	smtpAddr           string
+	smtpUsername       string
+	smtpPassword       string
+	mailgunDomain      string
+	mailgunAPIKey      string
+	sesRegion          string
+	sesAccessKeyID     string
+	sesSecretAccessKey string
+	dryRun             bool
 }
 
-func parseFlags() config {
+// parseFlags builds the configuration from the defaults, the config file
+// named by -config, SNIPPETBOX_* environment variables and the command line,
+// each layer overriding the one before.
+func parseFlags(args []string) (config, error) {
+	configFile := flag.String("config", os.Getenv(envPrefix+"CONFIG"), "YAML or TOML file of settings, keyed by flag name (or SNIPPETBOX_CONFIG env)")
 	addr := flag.String("addr", ":4001", "HTTP network address")
 	dsn := flag.String("dsn", "", "PostgreSQL data source name")
+	replicaDSN := flag.String("replica-dsn", "", "PostgreSQL data source name of a read replica for snippet views, lists and searches (empty reads from -dsn)")
+	migrate := flag.Bool("migrate", true, "Apply pending database migrations at startup")
+	dbTimeout := flag.Duration("db-connect-timeout", 30*time.Second, "How long to keep retrying the database connection at startup (0 tries once)")
 	debug := flag.Bool("debug", false, "Enable debug mode")
-	certFile := flag.String("cert", "./tls/localhost+1.pem", "TLS certificate file path")
-	keyFile := flag.String("key", "./tls/localhost+1-key.pem", "TLS key file path")
-	useTLS := flag.Bool("tls", false, "Enable TLS (use false for cloud platforms like Render)")
+	pprof := flag.Bool("debug-pprof", false, "Serve net/http/pprof profiles on -pprof-addr")
+	pprofAddr := flag.String("pprof-addr", "localhost:6060", "Network address of the pprof listener enabled by -debug-pprof; keep it private")
+	metricsAddr := flag.String("metrics-addr", "", "Network address serving Prometheus metrics at /metrics; keep it private (empty disables)")
+	grpcAddr := flag.String("grpc-addr", "", "Network address of the gRPC SnippetService for internal services (empty disables)")
+	grpcToken := flag.String("grpc-token", "", "Bearer token gRPC calls must present; required unless -grpc-addr is a loopback address (empty allows any local caller)")
+	certFile := flag.String("tls-cert", "", "TLS certificate file; serves HTTPS when set together with -tls-key")
+	keyFile := flag.String("tls-key", "", "TLS private key file")
+	flag.StringVar(certFile, "cert", "", "Deprecated: use -tls-cert")
+	flag.StringVar(keyFile, "key", "", "Deprecated: use -tls-key")
+	useTLS := flag.Bool("tls", false, "Serve HTTPS with the local development certificate in ./tls")
+	httpRedirectAddr := flag.String("http-redirect-addr", "", "HTTP network address that redirects to HTTPS and answers ACME challenges (default \":80\" with -auto-tls-domain, otherwise disabled)")
+	acmeWebroot := flag.String("acme-webroot", "", "Directory whose .well-known/acme-challenge files are served on -http-redirect-addr, e.g. certbot's webroot")
+	trustedProxies := flag.String("trusted-proxies", "", "Comma-separated IPs or CIDR ranges of reverse proxies whose X-Forwarded-For and X-Real-IP headers are trusted")
+	adminAllow := flag.String("admin-allow", "", "Comma-separated IPs or CIDR ranges that may reach the admin and debug pages (empty allows any address)")
+	tosVersion := flag.Int("tos-version", 1, "Current terms-of-service version users must accept")
+	loginFreeAttempts := flag.Int("login-free-attempts", 5, "Failed logins per account before backoff starts")
+	loginBackoff := flag.Duration("login-backoff", time.Second, "Initial per-account login backoff")
+	loginMaxBackoff := flag.Duration("login-max-backoff", 15*time.Minute, "Maximum per-account login backoff")
+	reauthWindow := flag.Duration("reauth-window", 15*time.Minute, "How recent a login must be for sensitive actions")
+	captchaProvider := flag.String("captcha-provider", "", "CAPTCHA provider for signup: hcaptcha or turnstile (empty disables)")
+	captchaSiteKey := flag.String("captcha-site-key", "", "CAPTCHA site key")
+	tokenRate := flag.Float64("token-rate", 1, "Sustained API requests per second allowed per token")
+	tokenBurst := flag.Int("token-burst", 60, "API request burst allowed per token")
+	adminTokenRate := flag.Float64("admin-token-rate", 5, "Sustained API requests per second allowed per token of an admin")
+	adminTokenBurst := flag.Int("admin-token-burst", 300, "API request burst allowed per token of an admin")
+	apiIPRate := flag.Float64("api-ip-rate", 2, "Sustained API requests per second allowed per client IP, whatever the token (0 disables)")
+	apiIPBurst := flag.Int("api-ip-burst", 120, "API request burst allowed per client IP")
+	apiSunset := flag.String("api-sunset", "", "Comma-separated dates deprecated API versions will be removed, announced in the Sunset header, e.g. \"v1=2027-04-30\"")
+	ipRate := flag.Float64("ip-rate", 10, "Sustained requests per second allowed per client IP (0 disables)")
+	ipBurst := flag.Int("ip-burst", 50, "Request burst allowed per client IP")
+	healthInterval := flag.Duration("health-interval", time.Minute, "How often to record health checks for /status (0 disables)")
+	passwordMaxAge := flag.Int("password-max-age", 0, "Days before a password must be changed at next login (0 disables)")
+	baseURL := flag.String("base-url", "http://localhost:4001", "Public URL of the site, used for links in emails and to scope passkeys")
+	basePath := flag.String("base-path", "", "URL path prefix to serve the site under, e.g. /snippetbox (empty serves from the root)")
+	signupAllowDomains := flag.String("signup-allow-domains", "", "Comma-separated email domains allowed to sign up (empty allows all)")
+	signupDenyDomains := flag.String("signup-deny-domains", "", "Comma-separated email domains not allowed to sign up")
+	signupDenyDomainsFile := flag.String("signup-deny-domains-file", "", "File of email domains not allowed to sign up, one per line")
+	expiryNotice := flag.Duration("expiry-notice", 24*time.Hour, "How long before expiry snippet owners are notified (0 disables)")
+	latencyBudgets := flag.String("latency-budgets", "", "Comma-separated per-route p95 latency budgets, e.g. \"GET /{$}=100ms,POST /snippet/create=500ms\"")
+	latencyBudget := flag.Duration("latency-budget", 0, "p95 latency budget for routes without their own (0 disables)")
+	latencyWindow := flag.Duration("latency-window", 5*time.Minute, "Sliding window over which route latencies are compared to their budgets")
+	latencyWebhook := flag.String("latency-webhook", "", "URL to POST a JSON alert to when a route goes over or back within its latency budget")
+	errorCapture := flag.Duration("error-capture", 0, "Keep sanitized snapshots of requests that caused server errors for this long (0 disables)")
+	securityContact := flag.String("security-contact", "", "Comma-separated security contacts (emails or URLs) for /.well-known/security.txt (empty disables it)")
+	securityPolicy := flag.String("security-policy", "", "URL of the vulnerability disclosure policy linked from security.txt")
+	securityExpires := flag.String("security-expires", "", "Expiry date of security.txt, e.g. 2026-12-31 (default 180 days from each request)")
+	securityTxtFile := flag.String("security-txt-file", "", "File served as security.txt instead of the generated one, e.g. a PGP-signed copy")
+	abuseContact := flag.String("abuse-contact", "", "Email address shown on /abuse, whose report form queues reports on /admin/reports (empty disables it)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 20*time.Second, "How long to wait for in-flight requests and background work on shutdown")
+	logFormat := flag.String("log-format", "text", "Log output format: text or json")
+	logLevel := flag.String("log-level", "", "Minimum log level: debug, info, warn or error (default info, or debug with -debug)")
+	logBufferSize := flag.Int("log-buffer", 1000, "Number of recent log records kept for /admin/logs")
+	templateDir := flag.String("template-dir", "", "Directory of template overrides that take precedence over the built-in templates")
+	captchaSecret := flag.String("captcha-secret", os.Getenv("CAPTCHA_SECRET"), "CAPTCHA secret key (or CAPTCHA_SECRET env)")
+	cookieSecret := flag.String("cookie-secret", "", "Secret for signing and encrypting non-session cookies; share it between instances (random per start if empty)")
+
+	var (
+		mailCfg     mailConfig
+		cacheCfg    cacheConfig
+		autoTLSCfg  autoTLSConfig
+		tracingCfg  tracingConfig
+		timeoutsCfg timeoutConfig
+		sessionsCfg sessionConfig
+		jobsCfg     jobsConfig
+		robotsCfg   robotsConfig
+		headersCfg  headersConfig
+		bodyLogCfg  bodyLogConfig
+	)
+
+	mailFlags(&mailCfg)
+	cacheFlags(&cacheCfg)
+	autoTLSFlags(&autoTLSCfg)
+	tracingFlags(&tracingCfg)
+	timeoutFlags(&timeoutsCfg)
+	sessionFlags(&sessionsCfg)
+	jobFlags(&jobsCfg)
+	robotsFlags(&robotsCfg)
+	headersFlags(&headersCfg)
+	bodyLogFlags(&bodyLogCfg)
 
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return config{}, err //nolint:wrapcheck // flag errors are already descriptive
+	}
+
+	if err := applyConfigLayers(flag.CommandLine, *configFile, os.LookupEnv); err != nil {
+		return config{}, err
+	}
+
+	warnDeprecatedFlags(flag.CommandLine)
 
 	// Priority: 1. Flag, 2. Env var, 3. Default
 	dsnValue := *dsn
@@ -57,14 +250,145 @@ func parseFlags() config {
 		}
 	}
 
-	return config{
-		addr:     *addr,
-		dsn:      dsnValue,
-		debug:    *debug,
-		certFile: *certFile,
-		keyFile:  *keyFile,
-		useTLS:   *useTLS,
+	cfg := config{
+		addr:        *addr,
+		dsn:         dsnValue,
+		replicaDSN:  *replicaDSN,
+		migrate:     *migrate,
+		dbTimeout:   *dbTimeout,
+		debug:       *debug,
+		pprof:       *pprof,
+		pprofAddr:   *pprofAddr,
+		metricsAddr: *metricsAddr,
+		grpcAddr:    *grpcAddr,
+		grpcToken:   *grpcToken,
+		certFile:    *certFile,
+		keyFile:     *keyFile,
+		useTLS:      *useTLS,
+		tosVersion:  *tosVersion,
+
+		loginFreeAttempts: *loginFreeAttempts,
+		loginBackoff:      *loginBackoff,
+		loginMaxBackoff:   *loginMaxBackoff,
+		reauthWindow:      *reauthWindow,
+		cookieSecret:      *cookieSecret,
+
+		captchaProvider: *captchaProvider,
+		captchaSiteKey:  *captchaSiteKey,
+		captchaSecret:   *captchaSecret,
+
+		tokenRate:       *tokenRate,
+		tokenBurst:      *tokenBurst,
+		adminTokenRate:  *adminTokenRate,
+		adminTokenBurst: *adminTokenBurst,
+		apiIPRate:       *apiIPRate,
+		apiIPBurst:      *apiIPBurst,
+		apiSunset:       *apiSunset,
+		ipRate:          *ipRate,
+		ipBurst:         *ipBurst,
+
+		healthInterval: *healthInterval,
+		passwordMaxAge: *passwordMaxAge,
+		baseURL:        *baseURL,
+		basePath:       *basePath,
+		templateDir:    *templateDir,
+		logBufferSize:  *logBufferSize,
+		logFormat:      *logFormat,
+		logLevel:       *logLevel,
+		errorCapture:   *errorCapture,
+		expiryNotice:   *expiryNotice,
+
+		latencyBudgets: *latencyBudgets,
+		latencyBudget:  *latencyBudget,
+		latencyWindow:  *latencyWindow,
+		latencyWebhook: *latencyWebhook,
+
+		shutdownTimeout: *shutdownTimeout,
+
+		securityContact: *securityContact,
+		securityPolicy:  *securityPolicy,
+		securityExpires: *securityExpires,
+		securityTxtFile: *securityTxtFile,
+		abuseContact:    *abuseContact,
+
+		signupAllowDomains:    *signupAllowDomains,
+		signupDenyDomains:     *signupDenyDomains,
+		signupDenyDomainsFile: *signupDenyDomainsFile,
+
+		httpRedirectAddr: *httpRedirectAddr,
+		acmeWebroot:      *acmeWebroot,
+		trustedProxies:   *trustedProxies,
+		adminAllow:       *adminAllow,
+
+		mail:     mailCfg,
+		cache:    cacheCfg,
+		autoTLS:  autoTLSCfg,
+		tracing:  tracingCfg,
+		timeouts: timeoutsCfg,
+		sessions: sessionsCfg,
+		jobs:     jobsCfg,
+		robots:   robotsCfg,
+		headers:  headersCfg,
+		bodyLog:  bodyLogCfg,
+	}
+
+	// -tls is a shorthand for the mkcert certificate used in development.
+	if cfg.useTLS && cfg.certFile == "" && cfg.keyFile == "" {
+		cfg.certFile = "./tls/localhost+1.pem"
+		cfg.keyFile = "./tls/localhost+1-key.pem"
 	}
+
+	cfg.useTLS = cfg.certFile != "" || cfg.keyFile != "" || cfg.autoTLS.enabled()
+
+	// Let's Encrypt needs port 80 for HTTP-01 challenges.
+	if cfg.autoTLS.enabled() && cfg.httpRedirectAddr == "" {
+		cfg.httpRedirectAddr = ":80"
+	}
+
+	return cfg, nil
+}
+
+// deprecatedFlags maps the flags kept for old deployments to the ones that
+// replaced them.
+var deprecatedFlags = map[string]string{
+	"cert": "tls-cert",
+	"key":  "tls-key",
+}
+
+// warnDeprecatedFlags prints a warning for every deprecated flag that was
+// set, whichever layer set it.
+func warnDeprecatedFlags(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		if replacement, ok := deprecatedFlags[f.Name]; ok {
+			fmt.Fprintf(fs.Output(), "warning: -%s is deprecated, use -%s\n", f.Name, replacement)
+		}
+	})
+}
+
+// mailFlags registers the mail provider flags. Providers are tried in the
+// order SMTP, Mailgun, SES; each one is enabled by setting its address,
+// domain or region.
+func mailFlags(cfg *mailConfig) {
+	flag.StringVar(&cfg.from, "mail-from", "Snippetbox <noreply@localhost>", "Sender address for outgoing email")
+	flag.StringVar(&cfg.smtpAddr, "smtp-addr", "", "SMTP server host:port (empty disables SMTP)")
+	flag.StringVar(&cfg.smtpUsername, "smtp-username", "", "SMTP username")
+	flag.StringVar(&cfg.smtpPassword, "smtp-password", os.Getenv("SMTP_PASSWORD"), "SMTP password (or SMTP_PASSWORD env)")
+	flag.StringVar(&cfg.mailgunDomain, "mailgun-domain", "", "Mailgun sending domain (empty disables Mailgun)")
+	flag.StringVar(&cfg.mailgunAPIKey, "mailgun-api-key", os.Getenv("MAILGUN_API_KEY"), "Mailgun API key (or MAILGUN_API_KEY env)")
+	flag.StringVar(&cfg.sesRegion, "ses-region", "", "Amazon SES region (empty disables SES)")
+	flag.StringVar(&cfg.sesAccessKeyID, "ses-access-key-id", os.Getenv("AWS_ACCESS_KEY_ID"), "SES access key ID (or AWS_ACCESS_KEY_ID env)")
+	flag.StringVar(&cfg.sesSecretAccessKey, "ses-secret-access-key", os.Getenv("AWS_SECRET_ACCESS_KEY"), "SES secret access key (or AWS_SECRET_ACCESS_KEY env)")
+	flag.BoolVar(&cfg.dryRun, "mail-dry-run", false, "Log outgoing email instead of sending it, even if a mail provider is configured")
+}
+
+// cacheFlags registers the cache flags.
+func cacheFlags(cfg *cacheConfig) {
+	flag.StringVar(&cfg.backend, "cache", "memory", "Cache backend: memory, redis or none")
+	flag.IntVar(&cfg.size, "cache-size", 10000, "Maximum number of entries in the memory cache")
+	flag.DurationVar(&cfg.ttl, "cache-ttl", time.Minute, "How long cached snippets, view counts and sessions are kept")
+	flag.StringVar(&cfg.redisAddr, "redis-addr", "localhost:6379", "Redis server host:port for the redis cache backend")
+	flag.StringVar(&cfg.redisPassword, "redis-password", os.Getenv("REDIS_PASSWORD"), "Redis password (or REDIS_PASSWORD env)")
+	flag.IntVar(&cfg.redisDB, "redis-db", 0, "Redis database number")
 }
 
 /* =========================
@@ -73,13 +397,69 @@ func parseFlags() config {
 
 type application struct {
 	debug          bool
+	tosVersion     int
+	reauthWindow   time.Duration
+	passwordMaxAge time.Duration
 	logger         *slog.Logger
 	snippets       models.SnippetModelInterface
 	users          models.UserModelInterface
-	templateCache  map[string]*template.Template
+	audit          models.AuditModelInterface
+	analytics      models.AnalyticsModelInterface
+	tokens         models.TokenModelInterface
+	prefs          models.PreferencesModelInterface
+	status         models.StatusModelInterface
+	uptimeCache    *cache.Cache
+	settings       models.SettingsModelInterface
+	settingsCache  atomic.Pointer[models.Settings]
+	exports        models.ExportModelInterface
+	passkeys       models.PasskeyModelInterface
+	notifications  models.NotificationModelInterface
+	webhooks       models.WebhookModelInterface
+	reports        models.ReportModelInterface
+	tx             models.TxRunner
+	webhookClient  *http.Client
+	webauthn       *webauthn.RelyingParty
+	cookies        *securecookie.Codec
+	feed           *snippetFeed
+	logs           *logbuffer.Buffer
+	signupDomains  emailDomainPolicy
+	captures       *captureStore
+	autocert       *autocert.Manager
+	contacts       contacts
+	robots         []byte
+	trustedProxies []netip.Prefix
+	adminAllow     []netip.Prefix
+	apiSunsets     map[string]time.Time
+	headers        headersConfig
+	latency        *latency.Tracker
+	metrics        *serverMetrics
+	bodyLog        *bodyLogger
+	caches         []*cache.Cache
+	mailer         mailer.Mailer
+	emailQueue     models.EmailQueueModelInterface
+	baseURL        string
+	basePath       string
+	handlerTimeout time.Duration
+	templateCache  atomic.Pointer[map[string]*template.Template]
+	emailTemplates *mailer.Templates
 	formDecoder    *form.Decoder
 	sessionManager *scs.SessionManager
+	sessionDB      *sql.DB
+	sessionStore   *postgresstore.PostgresStore
+	loginThrottle  *loginThrottle
+	captcha        captcha.Verifier
+	tokenLimiter   *ratelimit.Limiter
+	adminLimiter   *ratelimit.Limiter
+	apiIPLimiter   *ratelimit.Limiter
+	ipLimiter      *ratelimit.Limiter
+	tracer         trace.Tracer
 	db             *pgxpool.Pool
+	pingDB         func(ctx context.Context) error
+	missingTables  func(ctx context.Context) ([]string, error)
+	started        time.Time
+	workers        *worker.Group
+	scheduler      *scheduler.Scheduler
+	draining       atomic.Bool
 }
 
 /* =========================
@@ -87,49 +467,332 @@ type application struct {
    ========================= */
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	cfg := parseFlags()
+// cmdServe runs the web server until it receives SIGINT or SIGTERM.
+func cmdServe(args []string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	logs := logbuffer.New(cfg.logBufferSize)
+
+	logger, err := newLogger(os.Stdout, cfg.logFormat, cfg.logLevel, cfg.debug, logs)
+	if err != nil {
+		return err
+	}
 
-	logger := newLogger(cfg.debug)
+	templateCache, err := newTemplateCache(cfg.templateDir)
+	if err != nil {
+		return err
+	}
 
-	if cfg.debug {
-		startPprof(logger)
+	emailTemplates, err := newEmailTemplates()
+	if err != nil {
+		return err
 	}
 
-	templateCache, err := newTemplateCache()
+	captchaVerifier, err := captcha.New(cfg.captchaProvider, cfg.captchaSiteKey, cfg.captchaSecret)
 	if err != nil {
 		return err
 	}
 
-	db, err := openDB(cfg.dsn)
+	cacheBackend, err := newCacheBackend(cfg.cache)
+	if err != nil {
+		return err
+	}
+
+	latencyBudgets, err := latency.ParseBudgets(cfg.latencyBudgets)
+	if err != nil {
+		return err
+	}
+
+	signupDomains, err := newEmailDomainPolicy(cfg.signupAllowDomains, cfg.signupDenyDomains, cfg.signupDenyDomainsFile)
+	if err != nil {
+		return err
+	}
+
+	trustedProxies, err := parseAddrRanges(cfg.trustedProxies, "trusted proxy")
+	if err != nil {
+		return err
+	}
+
+	adminAllow, err := parseAddrRanges(cfg.adminAllow, "admin allowlist")
+	if err != nil {
+		return err
+	}
+
+	apiSunsets, err := parseAPISunsets(cfg.apiSunset)
+	if err != nil {
+		return err
+	}
+
+	robots, err := newRobotsTxt(cfg.robots, normalizeBasePath(cfg.basePath))
+	if err != nil {
+		return err
+	}
+
+	var tracer trace.Tracer
+
+	if cfg.tracing.enabled() {
+		var stopTracing func()
+
+		tracer, stopTracing, err = startTracing(cfg.tracing, logger)
+		if err != nil {
+			return err
+		}
+		defer stopTracing()
+	}
+
+	var metrics *serverMetrics
+	if cfg.metricsAddr != "" {
+		metrics = newServerMetrics()
+	}
+
+	db, err := connectDB(logger, cfg.dsn, tracer, metrics.queryTracer(), cfg.dbTimeout)
 	if err != nil {
 		return err
 	}
 	defer closeDB(logger, db)
 
-	app := newApplication(cfg, logger, templateCache, db, cfg.dsn)
+	if cfg.migrate {
+		if err := migrateUp(context.Background(), logger, db); err != nil {
+			return err
+		}
+	}
+
+	var replica *models.Replica
+
+	if cfg.replicaDSN != "" {
+		replica, err = openReplica(logger, cfg.replicaDSN, tracer, metrics.queryTracer())
+		if err != nil {
+			return err
+		}
+		defer closeDB(logger, replica.DB)
+	}
+
+	app := newApplication(cfg, logger, templateCache, db, replica, cfg.dsn)
+	defer app.closeSessionStore()
+
+	app.tracer = tracer
+	app.metrics = metrics
+
+	app.captcha = captchaVerifier
+	app.emailTemplates = emailTemplates
+	app.logs = logs
+	app.signupDomains = signupDomains
+	app.robots = robots
+	app.trustedProxies = trustedProxies
+	app.adminAllow = adminAllow
+	app.apiSunsets = apiSunsets
+
+	if err := app.contacts.loadSecurityTxt(cfg.securityExpires, cfg.securityTxtFile); err != nil {
+		return err
+	}
+
+	if cfg.cookieSecret == "" {
+		logger.Warn("no -cookie-secret set; banner dismissals and similar cookies reset on restart")
+	}
+
+	if app.cookies, err = newCookieCodec(cfg.cookieSecret); err != nil {
+		return err
+	}
+
+	if cacheBackend != nil {
+		app.useCache(cacheBackend, cfg.cache.ttl)
+	}
+
+	if err := app.loadSettings(context.Background()); err != nil {
+		return err
+	}
+
+	// Cancelled on SIGINT or SIGTERM, which starts a graceful shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(latencyBudgets) > 0 || cfg.latencyBudget > 0 {
+		app.latency = latency.New(latency.Budgets{Default: cfg.latencyBudget, Routes: latencyBudgets}, cfg.latencyWindow)
+	}
+
+	if err := app.scheduleJobs(cfg.jobs, db); err != nil {
+		return err
+	}
+
+	app.startWorkers(cfg, db)
 
 	srv := newHTTPServer(cfg, app, logger)
+	srv.RegisterOnShutdown(app.feed.close)
 
-	logger.Info("starting server", slog.String("addr", cfg.addr), slog.Bool("tls", cfg.useTLS))
+	if cfg.autoTLS.enabled() {
+		app.autocert = newAutocertManager(cfg.autoTLS)
+		useAutoTLS(srv, app.autocert)
+	}
 
-	// Use TLS only if configured (local dev), otherwise use plain HTTP (Render handles SSL)
-	if cfg.useTLS {
-		err = srv.ListenAndServeTLS(cfg.certFile, cfg.keyFile)
-	} else {
-		err = srv.ListenAndServe()
+	return app.serve(ctx, srv, cfg)
+}
+
+// startWorkers starts the background workers. They run until the server
+// has shut down, so the mailer can still send what the last requests queued.
+func (app *application) startWorkers(cfg config, db *pgxpool.Pool) {
+	app.startMailer(cfg.mail)
+
+	app.workers.Go("limiter-cleanup", func(ctx context.Context) {
+		app.cleanupLimiters(ctx, limiterCleanupInterval)
+	})
+
+	app.workers.Go("snippet-listener", func(ctx context.Context) {
+		app.listenSnippets(ctx, db)
+	})
+
+	app.workers.Go("scheduler", app.scheduler.Run)
+
+	if cfg.debug && (cfg.templateDir != "" || !ui.Embedded) {
+		app.workers.Go("template-watcher", func(ctx context.Context) {
+			app.watchTemplates(ctx, cfg.templateDir, templateWatchInterval)
+		})
+	}
+
+	if cfg.healthInterval > 0 {
+		monitor := newHealthMonitor(db.Ping, app.status, app.logger)
+		app.workers.Go("health-monitor", func(ctx context.Context) {
+			monitor.run(ctx, cfg.healthInterval)
+		})
+	}
+
+	if app.latency != nil {
+		monitor := &latencyMonitor{
+			tracker: app.latency,
+			logger:  app.logger,
+			webhook: cfg.latencyWebhook,
+			client:  &http.Client{Timeout: 10 * time.Second},
+			window:  cfg.latencyWindow,
+		}
+		app.workers.Go("latency-monitor", func(ctx context.Context) {
+			monitor.run(ctx, latencyCheckInterval)
+		})
+	}
+
+	if cfg.expiryNotice > 0 {
+		notifier := &expiryNotifier{
+			snippets: app.snippets,
+			notify:   app.notify,
+			logger:   app.logger,
+			notice:   cfg.expiryNotice,
+			now:      time.Now,
+		}
+		app.workers.Go("expiry-notifier", func(ctx context.Context) {
+			notifier.run(ctx, expiryCheckInterval)
+		})
+	}
+}
+
+// auxServer is an HTTP server that runs next to the main one.
+type auxServer struct {
+	name string
+	srv  *http.Server
+}
+
+// auxServers returns the auxiliary servers enabled by cfg.
+func (app *application) auxServers(cfg config) []auxServer {
+	var aux []auxServer
+
+	if cfg.useTLS && cfg.httpRedirectAddr != "" {
+		srv := newRedirectServer(cfg.httpRedirectAddr, cfg.addr, app.autocert, cfg.acmeWebroot, app.logger)
+		aux = append(aux, auxServer{"HTTPS redirect", srv})
+	}
+
+	if cfg.pprof {
+		srv := newPprofServer(cfg.pprofAddr, app.logger)
+		if app.adminAllow != nil {
+			srv.Handler = app.restrictAdminAccess(srv.Handler)
+		}
+
+		aux = append(aux, auxServer{"pprof", srv})
+	}
+
+	if app.metrics != nil {
+		aux = append(aux, auxServer{"metrics", newMetricsServer(cfg.metricsAddr, app.metrics, app.logger)})
+	}
+
+	if cfg.grpcAddr != "" {
+		aux = append(aux, auxServer{"gRPC", app.newGRPCServer(cfg.grpcAddr, cfg.grpcToken)})
 	}
 
-	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+	return aux
+}
+
+// serve runs srv until ctx is cancelled, then stops accepting connections,
+// lets in-flight requests and background work finish within the shutdown
+// timeout, and returns.
+func (app *application) serve(ctx context.Context, srv *http.Server, cfg config) error {
+	app.logger.Info("starting server", slog.String("addr", cfg.addr), slog.Bool("tls", cfg.useTLS))
+
+	aux := app.auxServers(cfg)
+	serveErr := make(chan error, 1+len(aux))
+
+	go func() {
+		// Terminate TLS ourselves only when given a certificate or asked to
+		// get one; on platforms like Render the proxy in front of us handles
+		// HTTPS.
+		switch {
+		case app.autocert != nil:
+			serveErr <- srv.ListenAndServeTLS("", "")
+		case cfg.useTLS:
+			serveErr <- srv.ListenAndServeTLS(cfg.certFile, cfg.keyFile)
+		default:
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	for _, a := range aux {
+		app.logger.Info("starting "+a.name+" server", slog.String("addr", a.srv.Addr))
+
+		go func() {
+			serveErr <- a.srv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		// A server failed to start, e.g. because the address is in use.
 		return err
+	case <-ctx.Done():
+	}
+
+	// Fail readiness probes from now on so that no new traffic is routed
+	// here while in-flight requests finish.
+	app.draining.Store(true)
+
+	app.logger.Info("shutting down server", slog.Duration("timeout", cfg.shutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down server: %w", err)
 	}
 
+	for _, a := range aux {
+		if err := a.srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("shutting down %s server: %w", a.name, err)
+		}
+	}
+
+	if err := app.workers.Stop(shutdownCtx); err != nil {
+		return err //nolint:wrapcheck // worker.ErrTimeout says what timed out
+	}
+
+	app.logger.Info("server stopped")
+
 	return nil
 }
 
@@ -137,38 +800,38 @@ func run() error {
    Logger
    ========================= */
 
-func newLogger(debug bool) *slog.Logger {
-	level := slog.LevelInfo
-	if debug {
-		level = slog.LevelDebug
+// newLogger returns the application logger writing to w in the given
+// format. Without an explicit level it logs from info, or from debug in debug
+// mode. Every record is also kept in logs for the admin log viewer, and
+// records logged with a request's context carry its request ID.
+func newLogger(w io.Writer, format, level string, debug bool, logs *logbuffer.Buffer) (*slog.Logger, error) {
+	var lvl slog.Level
+
+	switch {
+	case level != "":
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid -log-level %q: %w", level, err)
+		}
+	case debug:
+		lvl = slog.LevelDebug
+	default:
+		lvl = slog.LevelInfo
 	}
 
-	return slog.New(
-		slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
-		}),
-	)
-}
+	opts := &slog.HandlerOptions{Level: lvl}
 
-/* =========================
-   pprof (debug only)
-   ========================= */
+	var h slog.Handler
 
-func startPprof(logger *slog.Logger) {
-	pprofSrv := &http.Server{
-		Addr:         "localhost:6060",
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  30 * time.Second,
+	switch format {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid -log-format %q: must be text or json", format)
 	}
 
-	go func() {
-		logger.Info("pprof enabled", slog.String("addr", pprofSrv.Addr))
-		if err := pprofSrv.ListenAndServe(); err != nil &&
-			!errors.Is(err, http.ErrServerClosed) {
-			logger.Error("pprof server error", slog.String("err", err.Error()))
-		}
-	}()
+	return slog.New(requestIDHandler{logs.Handler(h)}), nil
 }
 
 /* =========================
@@ -180,6 +843,7 @@ func newApplication(
 	logger *slog.Logger,
 	templateCache map[string]*template.Template,
 	db *pgxpool.Pool,
+	replica *models.Replica,
 	dsn string,
 ) *application {
 	formDecoder := form.NewDecoder()
@@ -192,23 +856,105 @@ func newApplication(
 		sessionDB = nil
 	}
 
+	var sessionStore *postgresstore.PostgresStore
+
 	sessionManager := scs.New()
 	if sessionDB != nil {
-		sessionManager.Store = postgresstore.NewWithCleanupInterval(sessionDB, 30*time.Minute)
+		sessionStore = postgresstore.NewWithCleanupInterval(sessionDB, 30*time.Minute)
+		sessionManager.Store = sessionStore
 	}
-	sessionManager.Lifetime = 12 * time.Hour
+	sessionManager.Lifetime = cfg.sessions.lifetime
+	// With an idle timeout every request extends the session, up to Lifetime.
+	sessionManager.IdleTimeout = cfg.sessions.idleTimeout
 	// Only set secure cookies when using TLS
 	sessionManager.Cookie.Secure = cfg.useTLS
 
-	return &application{
+	app := &application{
 		debug:          cfg.debug,
+		tosVersion:     cfg.tosVersion,
+		reauthWindow:   cfg.reauthWindow,
+		passwordMaxAge: time.Duration(cfg.passwordMaxAge) * 24 * time.Hour,
 		logger:         logger,
-		snippets:       &models.SnippetModel{DB: db},
+		snippets:       &models.SnippetModel{DB: db, Replica: replica},
 		users:          &models.UserModel{DB: db},
-		templateCache:  templateCache,
+		audit:          &models.AuditModel{DB: db},
+		analytics:      &models.AnalyticsModel{DB: db},
+		tokens:         &models.TokenModel{DB: db},
+		prefs:          &models.PreferencesModel{DB: db},
+		status:         &models.StatusModel{DB: db},
+		uptimeCache:    cache.New(cache.NewMemory(1), "uptime"),
+		settings:       &models.SettingsModel{DB: db},
+		exports:        &models.ExportModel{DB: db},
+		passkeys:       &models.PasskeyModel{DB: db},
+		notifications:  &models.NotificationModel{DB: db},
+		webhooks:       &models.WebhookModel{DB: db},
+		reports:        &models.ReportModel{DB: db},
+		tx:             &models.PoolTxRunner{DB: db},
+		webhookClient:  newWebhookClient(),
+		emailQueue:     &models.EmailQueueModel{DB: db},
+		baseURL:        strings.TrimSuffix(cfg.baseURL, "/"),
+		basePath:       normalizeBasePath(cfg.basePath),
+		handlerTimeout: cfg.timeouts.handler,
+		headers:        cfg.headers,
+		contacts:       newContacts(cfg.securityContact, cfg.securityPolicy, cfg.abuseContact),
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		sessionDB:      sessionDB,
+		sessionStore:   sessionStore,
+		loginThrottle:  newLoginThrottle(cfg.loginFreeAttempts, cfg.loginBackoff, cfg.loginMaxBackoff),
+		tokenLimiter:   ratelimit.New(cfg.tokenRate, cfg.tokenBurst),
+		adminLimiter:   ratelimit.New(cfg.adminTokenRate, cfg.adminTokenBurst),
 		db:             db,
+		pingDB:         db.Ping,
+		missingTables:  func(ctx context.Context) ([]string, error) { return models.MissingTables(ctx, db) },
+		started:        time.Now(),
+		feed:           newSnippetFeed(),
+		workers:        worker.New(logger),
+		scheduler:      scheduler.New(logger),
+	}
+
+	app.templateCache.Store(&templateCache)
+
+	sessionManager.Cookie.Path = app.cookiePath()
+
+	if cfg.errorCapture > 0 {
+		app.captures = newCaptureStore(cfg.errorCapture)
+	}
+
+	if cfg.bodyLog.enabled() {
+		app.bodyLog = newBodyLogger(cfg.bodyLog, logger)
+		logger.Warn("logging sampled request and response bodies", slog.Float64("rate", cfg.bodyLog.rate))
+	}
+
+	if cfg.ipRate > 0 {
+		app.ipLimiter = ratelimit.New(cfg.ipRate, cfg.ipBurst)
+	}
+
+	if cfg.apiIPRate > 0 {
+		app.apiIPLimiter = ratelimit.New(cfg.apiIPRate, cfg.apiIPBurst)
+	}
+
+	// Passkeys are scoped to the host of the public URL; without a usable
+	// one they are disabled and only password logins are offered.
+	app.webauthn, err = webauthn.New("Snippetbox", cfg.baseURL)
+	if err != nil {
+		logger.Error("passkeys disabled", slog.String("err", err.Error()))
+	}
+
+	return app
+}
+
+// closeSessionStore stops the session cleanup goroutine and closes the
+// session database handle.
+func (app *application) closeSessionStore() {
+	if app.sessionStore != nil {
+		app.sessionStore.StopCleanup()
+	}
+
+	if app.sessionDB != nil {
+		if err := app.sessionDB.Close(); err != nil {
+			app.logger.Error(err.Error())
+		}
 	}
 }
 
@@ -222,19 +968,35 @@ func newHTTPServer(
 	logger *slog.Logger,
 ) *http.Server {
 	return &http.Server{
-		Addr:     cfg.addr,
-		Handler:  app.routes(),
-		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
-		TLSConfig: &tls.Config{
-			CurvePreferences: []tls.CurveID{
-				tls.X25519,
-				tls.CurveP256,
-			},
-			MinVersion: tls.VersionTLS12,
+		Addr:         cfg.addr,
+		Handler:      app.routes(),
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		TLSConfig:    newTLSConfig(),
+		IdleTimeout:  cfg.timeouts.idle,
+		ReadTimeout:  cfg.timeouts.read,
+		WriteTimeout: cfg.timeouts.write,
+	}
+}
+
+// newTLSConfig returns the TLS settings for serving HTTPS directly: TLS 1.2
+// or later, forward-secret AEAD cipher suites only, and modern key exchange
+// curves. TLS 1.3 suites are not configurable and are all acceptable.
+func newTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{
+			tls.X25519MLKEM768,
+			tls.X25519,
+			tls.CurveP256,
+		},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
 		},
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
 	}
 }
 
@@ -242,23 +1004,15 @@ func newHTTPServer(
    Database
    ========================= */
 
-func openDB(dsn string) (*pgxpool.Pool, error) {
+func openDB(dsn string, tracer trace.Tracer, queries pgx.QueryTracer) (*pgxpool.Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Parse config for connection pooling
-	config, err := pgxpool.ParseConfig(dsn)
+	config, err := poolConfig(dsn, tracer, queries)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set pool configuration for performance
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = 30 * time.Minute
-	config.HealthCheckPeriod = time.Minute
-
 	// Create pool
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -274,6 +1028,66 @@ func openDB(dsn string) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// replicaConnectTimeout bounds connecting to the read replica, so that
+// while it is unreachable reads fall back to the primary quickly instead of
+// waiting for the operating system to give up.
+const replicaConnectTimeout = 2 * time.Second
+
+// openReplica opens a pool for the read replica. Unlike the primary it is
+// not waited for: reads go to the primary until it answers.
+func openReplica(logger *slog.Logger, dsn string, tracer trace.Tracer, queries pgx.QueryTracer) (*models.Replica, error) {
+	config, err := poolConfig(dsn, tracer, queries)
+	if err != nil {
+		return nil, fmt.Errorf("parsing replica DSN: %w", err)
+	}
+
+	if config.ConnConfig.ConnectTimeout == 0 || config.ConnConfig.ConnectTimeout > replicaConnectTimeout {
+		config.ConnConfig.ConnectTimeout = replicaConnectTimeout
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("opening replica pool: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), replicaConnectTimeout)
+	defer cancel()
+
+	if err := pool.Ping(ctx); err != nil {
+		logger.Warn("read replica unreachable; reading from the primary until it answers", slog.String("err", err.Error()))
+	}
+
+	return &models.Replica{DB: pool, Logger: logger}, nil
+}
+
+// poolConfig parses dsn into the configuration of a connection pool.
+func poolConfig(dsn string, tracer trace.Tracer, queries pgx.QueryTracer) (*pgxpool.Config, error) {
+	// Parse config for connection pooling
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set pool configuration for performance
+	config.MaxConns = 25
+	config.MinConns = 5
+	config.MaxConnLifetime = time.Hour
+	config.MaxConnIdleTime = 30 * time.Minute
+	config.HealthCheckPeriod = time.Minute
+
+	// Development builds record queries for the request inspector, with
+	// tracing enabled every query gets a span, and with metrics enabled
+	// every query is timed.
+	var spans pgx.QueryTracer
+	if tracer != nil {
+		spans = dbTracer{tracer: tracer}
+	}
+
+	config.ConnConfig.Tracer = combineTracers(queryTracer(), spans, queries)
+
+	return config, nil
+}
+
 func closeDB(logger *slog.Logger, db *pgxpool.Pool) {
 	db.Close()
 	logger.Info("database connection pool closed")
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	app := newTestApplication(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	started := make(chan struct{})
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("done"))
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var workerStopped atomic.Bool
+
+	app.workers.Background(func() {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		workerStopped.Store(true)
+	})
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- app.serve(ctx, srv, config{addr: addr, shutdownTimeout: 5 * time.Second})
+	}()
+
+	// Wait for the server to accept connections.
+	for range 100 {
+		if conn, dialErr := net.Dial("tcp", addr); dialErr == nil {
+			conn.Close()
+
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	responded := make(chan int, 1)
+
+	go func() {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			t.Error(err)
+			responded <- 0
+
+			return
+		}
+		resp.Body.Close()
+		responded <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+
+	// The in-flight request is completed and the worker waited for.
+	assert.Equal(t, <-responded, http.StatusOK)
+	assert.NilError(t, <-served)
+	assert.Equal(t, workerStopped.Load(), true)
+
+	_, err = net.Dial("tcp", addr)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestServeListenError(t *testing.T) {
+	app := newTestApplication(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	srv := &http.Server{Addr: ln.Addr().String(), ReadHeaderTimeout: time.Second}
+
+	err = app.serve(context.Background(), srv, config{addr: srv.Addr, shutdownTimeout: time.Second})
+	assert.Equal(t, err != nil, true)
+}
+
+func TestTLSConfig(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	ts.TLS = newTLSConfig()
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		client  *tls.Config
+		wantErr bool
+	}{
+		{
+			name:   "TLS 1.3",
+			client: &tls.Config{MinVersion: tls.VersionTLS13},
+		},
+		{
+			name: "TLS 1.2 AEAD",
+			client: &tls.Config{
+				MaxVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+			},
+		},
+		{
+			name: "TLS 1.2 CBC",
+			client: &tls.Config{
+				MaxVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "TLS 1.1",
+			client:  &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}, //nolint:gosec // testing that old versions are refused
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := ts.Client()
+			transport := client.Transport.(*http.Transport).Clone()
+			tt.client.RootCAs = transport.TLSClientConfig.RootCAs
+			transport.TLSClientConfig = tt.client
+			client.Transport = transport
+
+			resp, err := client.Get(ts.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			assert.Equal(t, err != nil, tt.wantErr)
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logs := logbuffer.New(10)
+
+		logger, err := newLogger(&buf, "json", "", false, logs)
+		assert.NilError(t, err)
+
+		logger.Debug("hidden")
+		logger.Info("received request", "method", "GET")
+
+		var rec map[string]any
+		assert.NilError(t, json.Unmarshal(buf.Bytes(), &rec))
+		assert.Equal(t, rec["msg"], "received request")
+		assert.Equal(t, rec["level"], "INFO")
+		assert.Equal(t, rec["method"], "GET")
+
+		// The admin log viewer still gets a copy.
+		assert.Equal(t, len(logs.Records(logbuffer.Filter{})), 1)
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger, err := newLogger(&buf, "text", "warn", true, logbuffer.New(10))
+		assert.NilError(t, err)
+
+		logger.Info("hidden")
+		logger.Warn("shown")
+
+		assert.Equal(t, strings.Contains(buf.String(), "hidden"), false)
+		assert.StringContains(t, buf.String(), "level=WARN msg=shown")
+	})
+
+	t.Run("Debug mode", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		logger, err := newLogger(&buf, "text", "", true, logbuffer.New(10))
+		assert.NilError(t, err)
+
+		logger.Debug("shown")
+
+		assert.StringContains(t, buf.String(), "level=DEBUG msg=shown")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := newLogger(&bytes.Buffer{}, "xml", "", false, logbuffer.New(10))
+		assert.Equal(t, err != nil, true)
+
+		_, err = newLogger(&bytes.Buffer{}, "text", "loud", false, logbuffer.New(10))
+		assert.Equal(t, err != nil, true)
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/metrics"
+)
+
+// serverMetrics are the Prometheus metrics of the application. Requests are
+// labelled with the route pattern they matched rather than their path, so
+// /snippet/view/1 and /snippet/view/2 share a series, and queries with the
+// model method that made them, so a slow route can be traced to its query.
+type serverMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+	queries  *metrics.HistogramVec
+}
+
+func newServerMetrics() *serverMetrics {
+	r := metrics.New()
+
+	return &serverMetrics{
+		registry: r,
+		requests: r.NewCounterVec("snippetbox_http_requests_total",
+			"HTTP requests served, by method, route pattern and status.",
+			"method", "route", "status"),
+		duration: r.NewHistogramVec("snippetbox_http_request_duration_seconds",
+			"Time taken to serve HTTP requests, by method and route pattern.",
+			metrics.DefaultBuckets, "method", "route"),
+		queries: r.NewHistogramVec("snippetbox_db_query_duration_seconds",
+			"Time taken by database queries, by the model method that made them.",
+			metrics.DefaultBuckets, "query"),
+	}
+}
+
+// unmatchedRoute labels requests no route matched, such as 404s for
+// unknown paths, so that scanners cannot create a series per path.
+const unmatchedRoute = "unmatched"
+
+// routeLabel returns the route pattern a request matched without its
+// method, e.g. /snippet/view/{id}.
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return unmatchedRoute
+	}
+
+	if _, route, ok := strings.Cut(pattern, " "); ok {
+		return route
+	}
+
+	return pattern
+}
+
+// methodLabel returns the request method, folding methods the application
+// never routes into "other" for the same reason.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// recordMetrics counts every request and times it by route. Like
+// trackLatency it must sit next to the ServeMux, with only middleware that
+// passes the request on unchanged in between, to learn the route.
+func (app *application) recordMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		next.ServeHTTP(w, r)
+
+		method, route := methodLabel(r.Method), routeLabel(r.Pattern)
+
+		status := http.StatusOK
+		if rl, ok := r.Context().Value(requestLogContextKey).(*requestLog); ok {
+			status, _, _ = rl.result()
+		}
+
+		app.metrics.requests.Inc(method, route, strconv.Itoa(status))
+
+		// As with latency budgets, the duration of an event stream or
+		// websocket is how long the client stayed.
+		if r.Pattern == snippetEventsRoute || r.Pattern == liveRoute {
+			return
+		}
+
+		app.metrics.duration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+// queryTracer returns a pgx tracer timing every query for the metrics, or
+// nil when metrics are off.
+func (m *serverMetrics) queryTracer() pgx.QueryTracer {
+	if m == nil {
+		return nil
+	}
+
+	return queryMetricsTracer{durations: m.queries}
+}
+
+type queryMetricsTracer struct {
+	durations *metrics.HistogramVec
+}
+
+type queryMetricsKey struct{}
+
+type queryTiming struct {
+	caller string
+	start  time.Time
+}
+
+func (t queryMetricsTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryMetricsKey{}, queryTiming{caller: modelCaller(), start: time.Now()})
+}
+
+func (t queryMetricsTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	if timing, ok := ctx.Value(queryMetricsKey{}).(queryTiming); ok {
+		t.durations.Observe(time.Since(timing.start).Seconds(), timing.caller)
+	}
+}
+
+// modelsPackage prefixes the names of functions in internal/models.
+const modelsPackage = "github.com/FABLOUSFALCON/snippetbox/internal/models."
+
+// modelCaller names the model method whose query is starting, e.g.
+// SnippetModel.Get, by walking the stack from pgx up to the outermost
+// function of the models package it reaches; helpers a method calls count
+// towards the method. Queries made outside the models, like health checks,
+// are "other".
+func modelCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	caller := ""
+
+	for {
+		frame, more := frames.Next()
+
+		if name, ok := strings.CutPrefix(frame.Function, modelsPackage); ok {
+			caller = name
+		} else if caller != "" {
+			break
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	if caller == "" {
+		return "other"
+	}
+
+	return modelFuncName(caller)
+}
+
+// funcNameReplacer drops the pointer receiver and type parameter markers
+// from function names.
+var funcNameReplacer = strings.NewReplacer("(*", "", ")", "", "[...]", "")
+
+// modelFuncName tidies a function name as the runtime reports it, e.g.
+// (*SnippetModel).Get.func1, into SnippetModel.Get.
+func modelFuncName(name string) string {
+	name = funcNameReplacer.Replace(name)
+
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// newMetricsServer returns a server for the metrics on its own listener,
+// for Prometheus to scrape over a private network.
+func newMetricsServer(addr string, m *serverMetrics, logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.registry.Handler())
+
+	if !isLoopbackAddr(addr) {
+		logger.Warn("metrics are reachable beyond this host; keep their port private", slog.String("addr", addr))
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		IdleTimeout:       time.Minute,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+}
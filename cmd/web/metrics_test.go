@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	app := newTestApplication(t)
+	app.metrics = newServerMetrics()
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for _, urlPath := range []string{"/snippet/view/1", "/snippet/view/1", "/snippet/view/99", "/no/such/page", "/api/v1/snippets/1"} {
+		ts.get(t, urlPath)
+	}
+
+	var b strings.Builder
+
+	assert.NilError(t, app.metrics.registry.WriteText(&b))
+
+	for _, want := range []string{
+		`snippetbox_http_requests_total{method="GET",route="/snippet/view/{id}",status="200"} 2`,
+		`snippetbox_http_requests_total{method="GET",route="/snippet/view/{id}",status="404"} 1`,
+		`snippetbox_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`snippetbox_http_requests_total{method="GET",route="/api/v1/snippets/{id}",status="401"} 1`,
+		`snippetbox_http_request_duration_seconds_count{method="GET",route="/snippet/view/{id}"} 3`,
+	} {
+		assert.StringContains(t, b.String(), want+"\n")
+	}
+}
+
+func TestMetricsServer(t *testing.T) {
+	m := newServerMetrics()
+	m.queries.Observe(0.002, "SnippetModel.Get")
+
+	ts := newTestServer(t, newMetricsServer("localhost:9090", m, slog.New(slog.DiscardHandler)).Handler)
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/metrics")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, header.Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.StringContains(t, body, `snippetbox_db_query_duration_seconds_bucket{query="SnippetModel.Get",le="0.005"} 1`)
+}
+
+func TestModelFuncName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"(*SnippetModel).Get", "SnippetModel.Get"},
+		{"(*SnippetModel).Latest.func1", "SnippetModel.Latest"},
+		{"UserModel.Exists", "UserModel.Exists"},
+		{"scanAll[...]", "scanAll"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, modelFuncName(tt.name), tt.want)
+		})
+	}
+}
+
+func TestModelCallerOutsideModels(t *testing.T) {
+	assert.Equal(t, modelCaller(), "other")
+}
@@ -2,18 +2,33 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
 	"github.com/justinas/nosurf"
 )
 
-func commonHeaders(next http.Handler) http.Handler {
+// commonHeaders sets the security headers configured in app.headers on
+// every response. Browsers ignore Strict-Transport-Security received over
+// plain HTTP, so it is safe to send behind a TLS-terminating proxy too.
+func (app *application) commonHeaders(next http.Handler) http.Handler {
+	hsts := app.headers.hsts()
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().
-			Set("Content-Security-Policy", "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com")
-		w.Header().Set("Referrer-Policy", "origin-when-cross-origin")
+		if app.headers.csp != "" {
+			w.Header().Set("Content-Security-Policy", app.headers.csp)
+		}
+		if app.headers.referrerPolicy != "" {
+			w.Header().Set("Referrer-Policy", app.headers.referrerPolicy)
+		}
+		if hsts != "" {
+			w.Header().Set("Strict-Transport-Security", hsts)
+		}
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "deny")
 		w.Header().Set("X-XSS-Protection", "0")
@@ -22,27 +37,114 @@ func commonHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// requestLog collects what logRequest reports about a request. Handlers
+// further down the chain reach it through the request context. It is
+// guarded by a mutex since handlers such as the websocket one write from
+// goroutines of their own.
+type requestLog struct {
+	mu     sync.Mutex
+	status int
+	size   int
+	userID int
+}
+
+// written records a response of status and n more bytes. Only the first
+// status counts.
+func (rl *requestLog) written(status, n int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.status == 0 {
+		rl.status = status
+	}
+
+	rl.size += n
+}
+
+func (rl *requestLog) setUser(userID int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.userID = userID
+}
+
+// result returns what was recorded so far. The status is 200 if nothing
+// was written, as net/http answers then.
+func (rl *requestLog) result() (status, size, userID int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	status = rl.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return status, rl.size, rl.userID
+}
+
+// logResponseWriter records the status and size of the response in a
+// requestLog.
+type logResponseWriter struct {
+	http.ResponseWriter
+	log *requestLog
+}
+
+func (w *logResponseWriter) WriteHeader(status int) {
+	w.log.written(status, 0)
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *logResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.log.written(http.StatusOK, n)
+
+	return n, err //nolint:wrapcheck // pass the underlying writer's error through
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *logResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// logRequest logs every request once it has been served, with the status,
+// size and duration of the response, and the user who made it, if known.
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var (
-			ip     = r.RemoteAddr
-			proto  = r.Proto
-			method = r.Method
-			uri    = r.URL.RequestURI()
-		)
-
-		app.logger.Info(
-			"received request",
-			slog.String("ip", ip),
-			slog.String("proto", proto),
-			slog.String("method", method),
-			slog.String("uri", uri),
-		)
+		start := time.Now()
 
-		next.ServeHTTP(w, r)
+		rl := &requestLog{}
+		ctx := context.WithValue(r.Context(), requestLogContextKey, rl)
+
+		next.ServeHTTP(&logResponseWriter{ResponseWriter: w, log: rl}, r.WithContext(ctx))
+
+		status, size, userID := rl.result()
+
+		attrs := []any{
+			slog.String("ip", app.clientIP(r)),
+			slog.String("proto", r.Proto),
+			slog.String("method", r.Method),
+			slog.String("uri", r.URL.RequestURI()),
+			slog.Int("status", status),
+			slog.Int("size", size),
+			slog.Duration("duration", time.Since(start)),
+		}
+
+		if userID != 0 {
+			attrs = append(attrs, slog.Int("user_id", userID))
+		}
+
+		app.logger.InfoContext(r.Context(), "served request", attrs...)
 	})
 }
 
+// setRequestLogUser records the user a request was authenticated as, for
+// logRequest.
+func setRequestLogUser(r *http.Request, userID int) {
+	if rl, ok := r.Context().Value(requestLogContextKey).(*requestLog); ok {
+		rl.setUser(userID)
+	}
+}
+
 func (app *application) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -71,6 +173,119 @@ func (app *application) requireAuthencation(next http.Handler) http.Handler {
 	})
 }
 
+func (app *application) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+
+		user, err := app.users.Get(userID)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				app.clientError(w, http.StatusForbidden)
+			} else {
+				app.handleError(w, r, err)
+			}
+
+			return
+		}
+
+		if err := app.syncAdminSession(r, user.IsAdmin); err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		if !user.IsAdmin {
+			app.clientError(w, http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireTermsAcceptance sends users who have not accepted the current
+// terms-of-service version to the acceptance interstitial. The accepted
+// version is cached in the session so the database is only consulted when the
+// cached value is out of date. It must run after requireAuthencation.
+func (app *application) requireTermsAcceptance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.sessionManager.GetInt(r.Context(), "tosVersion") >= app.tosVersion {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		user, err := app.users.Get(app.authenticatedUserID(r))
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		if user.TOSVersion >= app.tosVersion {
+			app.sessionManager.Put(r.Context(), "tosVersion", user.TOSVersion)
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			app.sessionManager.Put(r.Context(), "redirectPathAfterTerms", r.URL.Path)
+		}
+
+		http.Redirect(w, r, "/terms/accept", http.StatusSeeOther)
+	})
+}
+
+// requireRecentAuth guards sensitive actions, such as changing credentials,
+// by asking the user to confirm their password again when their last
+// authentication is older than app.reauthWindow. It must run after
+// requireAuthencation.
+func (app *application) requireRecentAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticatedAt := app.sessionManager.GetInt64(r.Context(), "authenticatedAt")
+		if time.Since(time.Unix(authenticatedAt, 0)) <= app.reauthWindow {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		// A POST cannot be replayed after the detour, so send the user back
+		// to the page the form was submitted from instead.
+		path := r.URL.Path
+		if r.Method != http.MethodGet {
+			path = app.sameOriginRefererPath(r)
+		}
+
+		app.sessionManager.Put(r.Context(), "redirectPathAfterReauth", path)
+		http.Redirect(w, r, "/account/reauthenticate", http.StatusSeeOther)
+	})
+}
+
+// denySuspended stops suspended users from reaching the wrapped handler and
+// shows them why. It must run after requireAuthencation.
+func (app *application) denySuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := app.users.Get(app.authenticatedUserID(r))
+		if err != nil {
+			app.handleError(w, r, err)
+
+			return
+		}
+
+		if user.Suspended() {
+			data := app.newTemplateData(r)
+			data.User = user
+			app.render(w, r, http.StatusForbidden, "suspended.tmpl", data)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (app *application) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
@@ -82,7 +297,7 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 
 		exists, err := app.users.Exists(id)
 		if err != nil {
-			app.serverError(w, r, err)
+			app.handleError(w, r, err)
 
 			return
 		}
@@ -90,19 +305,52 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 		if exists {
 			ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, true)
 			r = r.WithContext(ctx)
+
+			setRequestLogUser(r, id)
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func noSurf(next http.Handler) http.Handler {
+func (app *application) noSurf(next http.Handler) http.Handler {
 	csrfHandler := nosurf.New(next)
 	csrfHandler.SetBaseCookie(http.Cookie{
 		HttpOnly: true,
-		Path:     "/",
+		Path:     app.cookiePath(),
 		Secure:   true,
 	})
 
 	return csrfHandler
 }
+
+// requirePasswordRotation keeps users whose password expired under the
+// rotation policy on the change-password interstitial until they pick a new
+// one. The flag is set at login, so the policy applies from the next login.
+func (app *application) requirePasswordRotation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.sessionManager.GetBool(r.Context(), "passwordExpired") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		http.Redirect(w, r, "/account/password/expired", http.StatusSeeOther)
+	})
+}
+
+// readOnlyDuringMaintenance rejects state-changing requests to the web UI
+// while maintenance mode is on. The JSON API is not affected, so admins can
+// still switch maintenance mode off.
+func (app *application) readOnlyDuringMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.currentSettings().MaintenanceMode || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Retry-After", "300")
+		app.render(w, r, http.StatusServiceUnavailable, "maintenance.tmpl", app.newTemplateData(r))
+	})
+}
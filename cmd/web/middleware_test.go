@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
 )
 
 func TestCommonHeaders(t *testing.T) {
@@ -29,7 +34,8 @@ func TestCommonHeaders(t *testing.T) {
 		}
 	})
 
-	commonHeaders(next).ServeHTTP(rr, r)
+	app := &application{headers: defaultHeaders}
+	app.commonHeaders(next).ServeHTTP(rr, r)
 
 	rs := rr.Result()
 
@@ -73,3 +79,160 @@ func TestCommonHeaders(t *testing.T) {
 
 	assert.Equal(t, string(body), "OK")
 }
+
+func TestCommonHeadersConfigured(t *testing.T) {
+	tests := []struct {
+		name         string
+		headers      headersConfig
+		wantCSP      string
+		wantReferrer string
+		wantHSTS     string
+	}{
+		{
+			name:         "Defaults",
+			headers:      defaultHeaders,
+			wantCSP:      defaultHeaders.csp,
+			wantReferrer: "origin-when-cross-origin",
+		},
+		{
+			name:         "Custom values",
+			headers:      headersConfig{csp: "default-src 'none'", referrerPolicy: "no-referrer"},
+			wantCSP:      "default-src 'none'",
+			wantReferrer: "no-referrer",
+		},
+		{
+			name:     "HSTS",
+			headers:  headersConfig{hstsMaxAge: 365 * 24 * time.Hour},
+			wantHSTS: "max-age=31536000",
+		},
+		{
+			name:     "HSTS with subdomains",
+			headers:  headersConfig{hstsMaxAge: time.Hour, hstsIncludeSubdomains: true},
+			wantHSTS: "max-age=3600; includeSubDomains",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{headers: tt.headers}
+
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			app.commonHeaders(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rr, r)
+
+			assert.Equal(t, rr.Header().Get("Content-Security-Policy"), tt.wantCSP)
+			assert.Equal(t, rr.Header().Get("Referrer-Policy"), tt.wantReferrer)
+			assert.Equal(t, rr.Header().Get("Strict-Transport-Security"), tt.wantHSTS)
+
+			// Empty values omit the header rather than sending it blank.
+			_, ok := rr.Header()["Content-Security-Policy"]
+			assert.Equal(t, ok, tt.wantCSP != "")
+		})
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// servedRequest returns the log record of the latest request to uri.
+	servedRequest := func(t *testing.T, uri string) logbuffer.Record {
+		t.Helper()
+
+		for _, rec := range app.logs.Records(logbuffer.Filter{Route: uri}) {
+			if rec.Message == "served request" && rec.Attr("uri") == uri {
+				return rec
+			}
+		}
+
+		t.Fatalf("no log record for %s", uri)
+
+		return logbuffer.Record{}
+	}
+
+	_, _, body := ts.get(t, "/ping")
+
+	rec := servedRequest(t, "/ping")
+	assert.Equal(t, rec.Attr("method"), http.MethodGet)
+	assert.Equal(t, rec.Attr("status"), "200")
+	assert.Equal(t, rec.Attr("size"), strconv.Itoa(len(body)))
+	assert.Equal(t, rec.Attr("user_id"), "")
+	assert.Equal(t, rec.Attr("duration") != "", true)
+
+	ts.get(t, "/snippet/view/99")
+
+	rec = servedRequest(t, "/snippet/view/99")
+	assert.Equal(t, rec.Attr("status"), "404")
+
+	ts.login(t, "alice@example.com", "pa$$word")
+	ts.get(t, "/account/view")
+
+	rec = servedRequest(t, "/account/view")
+	assert.Equal(t, rec.Attr("status"), "200")
+	assert.Equal(t, rec.Attr("user_id"), "1")
+
+	ts.apiGet(t, "/api/v1/whoami", mocks.MockTokenPlaintext)
+
+	rec = servedRequest(t, "/api/v1/whoami")
+	assert.Equal(t, rec.Attr("status"), "200")
+	assert.Equal(t, rec.Attr("user_id"), "1")
+}
+
+func TestRequestLogConcurrentWrites(t *testing.T) {
+	rl := &requestLog{}
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w := &logResponseWriter{ResponseWriter: httptest.NewRecorder(), log: rl}
+			w.Write([]byte("hi")) //nolint:errcheck // a recorder does not fail
+			rl.setUser(1)
+			rl.result()
+		}()
+	}
+
+	wg.Wait()
+
+	status, size, userID := rl.result()
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, size, 8)
+	assert.Equal(t, userID, 1)
+}
+
+func TestChain(t *testing.T) {
+	app := newTestApplication(t)
+
+	panics := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	app.chain().Then(panics).ServeHTTP(rr, r)
+
+	// The request ID is assigned outside the panic recovery, so the error
+	// response and the logged panic both carry it.
+	id := rr.Header().Get(requestIDHeader)
+	assert.Equal(t, rr.Code, http.StatusInternalServerError)
+	assert.Equal(t, validRequestID(id), true)
+
+	records := app.logs.Records(logbuffer.Filter{Level: slog.LevelError})
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Message, "boom")
+	assert.Equal(t, records[0].Attr("request_id"), id)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types handlers can offer to negotiate.
+const (
+	mediaHTML = "text/html"
+	mediaJSON = "application/json"
+	mediaText = "text/plain"
+)
+
+// negotiate returns the media type from offers that the request's Accept
+// header prefers, and adds Accept to the response's Vary header, since the
+// choice depends on it. Offers are listed in the server's order of
+// preference, which breaks ties. When the client accepts none of them, or
+// sends no Accept header, the first offer is returned: answering with
+// something beats a 406.
+func negotiate(w http.ResponseWriter, r *http.Request, offers ...string) string {
+	addVary(w.Header(), "Accept")
+
+	ranges := parseAccept(r.Header.Get("Accept"))
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ := offers[0], 0.0
+
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaRange is one entry of an Accept header, such as text/* or
+// application/json;q=0.9.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for part := range strings.SplitSeq(accept, ",") {
+		media, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(media)), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		q := 1.0
+
+		for param := range strings.SplitSeq(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the quality the client gives the media type: that of
+// the most specific range matching it, so text/html;q=0 refuses HTML even
+// when */* is accepted.
+func acceptQuality(ranges []mediaRange, media string) float64 {
+	typ, subtype, _ := strings.Cut(media, "/")
+
+	q, specificity := 0.0, -1
+
+	for _, mr := range ranges {
+		var s int
+
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+
+	return q
+}
+
+// addVary adds field to the Vary header unless it is already listed.
+func addVary(h http.Header, field string) {
+	for _, value := range h.Values("Vary") {
+		for existing := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), field) {
+				return
+			}
+		}
+	}
+
+	h.Add("Vary", field)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{mediaHTML, mediaJSON, mediaText}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"No header", "", mediaHTML},
+		{"Browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", mediaHTML},
+		{"Anything", "*/*", mediaHTML},
+		{"JSON", "application/json", mediaJSON},
+		{"Quality", "text/html;q=0.5, application/json;q=0.9", mediaJSON},
+		{"Type wildcard", "text/*", mediaHTML},
+		{"Refused", "text/html;q=0, */*", mediaJSON},
+		{"Case and spaces", " Application/JSON ; charset=utf-8", mediaJSON},
+		{"Parameters before q", "text/plain;format=flowed;q=0.9, text/html;q=0.1", mediaText},
+		{"None acceptable", "image/png", mediaHTML},
+		{"Malformed", "garbage", mediaHTML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, negotiate(rr, r, offers...), tt.want)
+			assert.Equal(t, rr.Header().Get("Vary"), "Accept")
+		})
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	h := http.Header{}
+	h.Add("Vary", "Accept-Encoding, Origin")
+
+	addVary(h, "Accept")
+	addVary(h, "accept")
+	addVary(h, "Origin")
+
+	assert.Equal(t, strings.Join(h.Values("Vary"), "; "), "Accept-Encoding, Origin; Accept")
+}
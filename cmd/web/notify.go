@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/ui"
+)
+
+const (
+	// expiryCheckInterval is how often snippets are checked for upcoming
+	// expiry.
+	expiryCheckInterval = time.Hour
+
+	// notifyTimeout bounds the work of sending a single notification.
+	notifyTimeout = 30 * time.Second
+)
+
+// notificationKind describes a notification users can opt in to or out of.
+type notificationKind struct {
+	Name    string
+	Label   string
+	Default bool
+}
+
+// notificationKinds lists every notification in the order shown on the
+// notifications page. Each needs a matching email template in ui/email.
+var notificationKinds = []notificationKind{
+	{models.NotifySnippetExpiring, "One of my snippets is about to expire", true},
+	{models.NotifyTokenNewIP, "One of my API tokens is used from a new network", true},
+}
+
+// notificationSetting is a notification kind with the user's choice for it.
+type notificationSetting struct {
+	Kind    notificationKind
+	Enabled bool
+}
+
+// notificationSettings merges the user's explicit choices with the defaults.
+func notificationSettings(choices map[string]bool) []notificationSetting {
+	settings := make([]notificationSetting, 0, len(notificationKinds))
+
+	for _, k := range notificationKinds {
+		enabled, ok := choices[k.Name]
+		if !ok {
+			enabled = k.Default
+		}
+
+		settings = append(settings, notificationSetting{Kind: k, Enabled: enabled})
+	}
+
+	return settings
+}
+
+// notificationEnabled reports whether notifications of kind are on, given
+// the user's explicit choices.
+func notificationEnabled(choices map[string]bool, kind string) bool {
+	if enabled, ok := choices[kind]; ok {
+		return enabled
+	}
+
+	for _, k := range notificationKinds {
+		if k.Name == kind {
+			return k.Default
+		}
+	}
+
+	return false
+}
+
+// notificationEmail is the data passed to notification email templates.
+type notificationEmail struct {
+	User    models.User
+	Locale  *i18n.Locale
+	BaseURL string
+	Data    any
+}
+
+// newEmailTemplates parses the notification email templates in ui/email,
+// one per notification kind.
+func newEmailTemplates() (*mailer.Templates, error) {
+	fsys, err := fs.Sub(ui.Files, "email")
+	if err != nil {
+		return nil, fmt.Errorf("opening email templates: %w", err)
+	}
+
+	names := make([]string, len(notificationKinds))
+	for i, k := range notificationKinds {
+		names[i] = k.Name
+	}
+
+	return mailer.ParseTemplates(fsys, functions, names...) //nolint:wrapcheck // already names the template
+}
+
+// notify emails the user a notification of kind, unless they opted out of
+// it. A non-empty ref identifies the event the notification is about, and
+// each event is notified once: it is recorded as sent only once the email
+// was queued, so a failure is retried on the next attempt. The email goes
+// through the mail queue, so delivery itself happens asynchronously.
+func (app *application) notify(ctx context.Context, userID int, kind, ref string, data any) error {
+	choices, err := app.notifications.Settings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !notificationEnabled(choices, kind) {
+		return nil
+	}
+
+	if ref != "" {
+		sent, err := app.notifications.Sent(ctx, userID, kind, ref)
+		if err != nil || sent {
+			return err
+		}
+	}
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		return fmt.Errorf("loading user %d: %w", userID, err)
+	}
+
+	prefs, err := app.prefs.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	locale, ok := i18n.Get(prefs.Language)
+	if !ok {
+		locale = i18n.Default()
+	}
+
+	msg, err := app.emailTemplates.Render(kind, user.Email, notificationEmail{
+		User:    user,
+		Locale:  locale,
+		BaseURL: app.baseURL,
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := app.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+
+	if ref != "" {
+		if _, err := app.notifications.MarkSent(ctx, userID, kind, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyInBackground is notify for callers serving a request, which should
+// neither wait for nor fail because of the notification.
+func (app *application) notifyInBackground(userID int, kind, ref string, data any) {
+	app.workers.Background(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+
+		if err := app.notify(ctx, userID, kind, ref, data); err != nil {
+			app.logger.Error(err.Error(), slog.String("notification", kind), slog.Int("user_id", userID))
+		}
+	})
+}
+
+// expiryNotifier warns owners of snippets that are about to expire.
+type expiryNotifier struct {
+	snippets models.SnippetModelInterface
+	notify   func(ctx context.Context, userID int, kind, ref string, data any) error
+	logger   *slog.Logger
+	notice   time.Duration
+	now      func() time.Time
+}
+
+// run checks for expiring snippets every interval until ctx is cancelled.
+func (en *expiryNotifier) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		en.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (en *expiryNotifier) runOnce(ctx context.Context) {
+	snippets, err := en.snippets.Expiring(ctx, en.now().Add(en.notice))
+	if err != nil {
+		en.logger.Error(err.Error())
+
+		return
+	}
+
+	for _, s := range snippets {
+		// A snippet that was created to last less than the notice period
+		// expires as its owner intended; warning them straight away would
+		// only be noise.
+		if s.Created.After(s.Expires.Add(-en.notice)) {
+			continue
+		}
+
+		// The expiry time is part of the reference, so a snippet whose
+		// expiry is extended gets a fresh notice later on.
+		ref := strconv.Itoa(s.ID) + "@" + strconv.FormatInt(s.Expires.Unix(), 10)
+
+		if err := en.notify(ctx, s.UserID, models.NotifySnippetExpiring, ref, s); err != nil {
+			en.logger.Error(err.Error(), slog.Int("snippet_id", s.ID))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+func TestNotify(t *testing.T) {
+	app := newTestApplication(t)
+	mail := app.mailer.(*testMailer)
+	ctx := context.Background()
+
+	notice := tokenNewIPNotice{Name: "CI", Range: "192.0.2.0/24"}
+
+	err := app.notify(ctx, 1, models.NotifyTokenNewIP, "", notice)
+	assert.NilError(t, err)
+
+	msgs := mail.messages()
+	assert.Equal(t, len(msgs), 1)
+	assert.Equal(t, msgs[0].To, "alice@example.com")
+	assert.Equal(t, msgs[0].Subject, `Your API token "CI" was used from a new network`)
+	assert.StringContains(t, msgs[0].Body, "used from 192.0.2.0/24")
+	assert.StringContains(t, msgs[0].Body, "https://snippetbox.test/account/notifications")
+	assert.StringContains(t, msgs[0].HTML, "<code>192.0.2.0/24</code>")
+	assert.StringContains(t, msgs[0].HTML, "<a href='https://snippetbox.test/account/tokens'>")
+
+	err = app.notifications.UpdateSettings(ctx, 1, map[string]bool{models.NotifyTokenNewIP: false})
+	assert.NilError(t, err)
+
+	err = app.notify(ctx, 1, models.NotifyTokenNewIP, "", notice)
+	assert.NilError(t, err)
+	assert.Equal(t, len(mail.messages()), 1)
+}
+
+func TestExpiryNotifier(t *testing.T) {
+	app := newTestApplication(t)
+	mail := app.mailer.(*testMailer)
+
+	en := &expiryNotifier{
+		snippets: app.snippets,
+		notify:   app.notify,
+		logger:   slog.New(slog.DiscardHandler),
+		notice:   24 * time.Hour,
+		now:      time.Now,
+	}
+
+	// The same snippet is only notified once.
+	en.runOnce(context.Background())
+	en.runOnce(context.Background())
+
+	msgs := mail.messages()
+	assert.Equal(t, len(msgs), 1)
+	assert.Equal(t, msgs[0].Subject, `Your snippet "An old silent pond" expires soon`)
+	assert.StringContains(t, msgs[0].Body, "https://snippetbox.test/snippet/view/1")
+	assert.StringContains(t, msgs[0].HTML, "<a href='https://snippetbox.test/snippet/view/1'>An old silent pond</a>")
+}
+
+func TestExpiryNotifierSkipsShortLivedSnippets(t *testing.T) {
+	app := newTestApplication(t)
+	mail := app.mailer.(*testMailer)
+
+	// The mock snippet lasts a week, all of it within the notice period.
+	en := &expiryNotifier{
+		snippets: app.snippets,
+		notify:   app.notify,
+		logger:   slog.New(slog.DiscardHandler),
+		notice:   30 * 24 * time.Hour,
+		now:      time.Now,
+	}
+
+	en.runOnce(context.Background())
+	assert.Equal(t, len(mail.messages()), 0)
+}
+
+func TestNotifyRetriesAfterFailedSend(t *testing.T) {
+	app := newTestApplication(t)
+	ctx := context.Background()
+
+	notice := tokenNewIPNotice{Name: "CI", Range: "192.0.2.0/24"}
+
+	app.mailer = &flakyMailer{err: errors.New("queue unavailable")}
+
+	err := app.notify(ctx, 1, models.NotifyTokenNewIP, "ref-1", notice)
+	assert.Equal(t, err != nil, true)
+
+	sent, err := app.notifications.Sent(ctx, 1, models.NotifyTokenNewIP, "ref-1")
+	assert.NilError(t, err)
+	assert.Equal(t, sent, false)
+}
+
+func TestAccountNotifications(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t, "alice@example.com", "pa$$word")
+
+	code, _, body := ts.get(t, "/account/notifications")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "value='snippet_expiring' id='notify-snippet_expiring' checked>")
+	assert.StringContains(t, body, "value='token_new_ip' id='notify-token_new_ip' checked>")
+
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("enabled", "token_new_ip")
+	form.Add("csrf_token", csrfToken)
+
+	code, headers, _ := ts.postForm(t, "/account/notifications", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, headers.Get("Location"), "/account/view")
+
+	_, _, body = ts.get(t, "/account/notifications")
+	assert.StringContains(t, body, "value='snippet_expiring' id='notify-snippet_expiring' >")
+	assert.StringContains(t, body, "value='token_new_ip' id='notify-token_new_ip' checked>")
+
+	form = url.Values{}
+	form.Add("enabled", "unknown")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ = ts.postForm(t, "/account/notifications", form)
+	assert.Equal(t, code, http.StatusBadRequest)
+}
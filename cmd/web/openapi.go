@@ -0,0 +1,770 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// The OpenAPI 3 document describing the JSON API, served for each version
+// at /api/<version>/openapi.json. It is maintained by hand next to the
+// handlers; TestOpenAPICoversRoutes fails when an API route is missing from
+// it.
+
+type openAPIDoc struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       openAPIInfo                     `json:"info"`
+	Servers    []openAPIServer                 `json:"servers"`
+	Security   []map[string][]string           `json:"security"`
+	Paths      map[string]map[string]openAPIOp `json:"paths"`
+	Components openAPIComponents               `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOp struct {
+	Summary     string                     `json:"summary"`
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []openAPIParam             `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	// Security overrides the document's requirement; an empty requirement
+	// lets anonymous clients call the operation.
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+type openAPIParam struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                   `json:"$ref,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Headers     map[string]openAPIHeader `json:"headers,omitempty"`
+	Content     map[string]openAPIMedia  `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMedia struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Maximum              *int                      `json:"maximum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	Responses       map[string]openAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+func schemaRef(name string) *openAPISchema {
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+func responseRef(name string) openAPIResponse {
+	return openAPIResponse{Ref: "#/components/responses/" + name}
+}
+
+func jsonContent(schema *openAPISchema) map[string]openAPIMedia {
+	return map[string]openAPIMedia{mediaJSON: {Schema: schema}}
+}
+
+func jsonResponse(description string, schema *openAPISchema) openAPIResponse {
+	return openAPIResponse{Description: description, Content: jsonContent(schema)}
+}
+
+func intRange(minimum, maximum int) *openAPISchema {
+	return &openAPISchema{Type: "integer", Minimum: &minimum, Maximum: &maximum}
+}
+
+func intAtLeast(minimum int) *openAPISchema {
+	return &openAPISchema{Type: "integer", Minimum: &minimum}
+}
+
+func arrayRange(items *openAPISchema, minItems, maxItems int) *openAPISchema {
+	return &openAPISchema{Type: "array", Items: items, MinItems: &minItems, MaxItems: &maxItems}
+}
+
+func webhookEventsEnum() []any {
+	events := make([]any, len(models.WebhookEvents))
+	for i, event := range models.WebhookEvents {
+		events[i] = event
+	}
+
+	return events
+}
+
+// openAPISpec returns the OpenAPI document of version v of the API as served
+// under the application's base path. A deprecated version's operations are
+// all marked deprecated.
+func (app *application) openAPISpec(v apiVersion) openAPIDoc {
+	idParam := openAPIParam{Name: "id", In: "path", Required: true, Schema: &openAPISchema{Type: "integer"}}
+	snippetInput := &openAPIBody{Required: true, Content: jsonContent(schemaRef("SnippetInput"))}
+	pageParam := openAPIParam{Name: "page", In: "query", Description: "Page to return, counted from 1", Schema: intAtLeast(1)}
+	limitParam := openAPIParam{Name: "limit", In: "query", Description: "Snippets per page", Schema: intRange(1, maxAPISnippets)}
+	fieldsParam := func(known []string) openAPIParam {
+		return openAPIParam{
+			Name: "fields", In: "query", Schema: &openAPISchema{Type: "string"},
+			Description: "Comma-separated fields each item includes, out of " + strings.Join(known, ", ") + "; all by default",
+		}
+	}
+	includeContent := openAPIParam{
+		Name: "include_content", In: "query", Schema: &openAPISchema{Type: "boolean"},
+		Description: "Set to false to leave out each snippet's content",
+	}
+	linkHeader := map[string]openAPIHeader{
+		"Link": {Description: "URLs of the first, prev, next and last pages", Schema: &openAPISchema{Type: "string"}},
+	}
+	etagHeader := map[string]openAPIHeader{
+		"ETag": {Description: "The snippet's entity tag", Schema: &openAPISchema{Type: "string"}},
+	}
+	ifNoneMatch := openAPIParam{
+		Name: "If-None-Match", In: "header", Schema: &openAPISchema{Type: "string"},
+		Description: "Answer 304 if the snippet still has one of these entity tags",
+	}
+	webhookInput := &openAPIBody{Required: true, Content: jsonContent(schemaRef("WebhookInput"))}
+	webhookNotFound := jsonResponse("No such webhook", schemaRef("Error"))
+	ifMatch := openAPIParam{
+		Name: "If-Match", In: "header", Schema: &openAPISchema{Type: "string"},
+		Description: "Answer 412 unless the snippet still has one of these entity tags",
+	}
+
+	doc := openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "Snippetbox API",
+			Version: strings.TrimPrefix(v.name, "v"),
+			Description: "Every response reports the caller's quota in X-RateLimit-Limit, " +
+				"X-RateLimit-Remaining and X-RateLimit-Reset (seconds until it is full again).",
+		},
+		Servers:  []openAPIServer{{URL: app.basePath + v.prefix()}},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+		Paths: map[string]map[string]openAPIOp{
+			"/status": {
+				"get": {
+					Summary:     "Describe the running build, its uptime and database latency",
+					OperationID: "getStatus",
+					Tags:        []string{"status"},
+					Security:    []map[string][]string{{}},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The status of the instance that answered", schemaRef("Status")),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+			},
+			"/whoami": {
+				"get": {
+					Summary:     "Describe the token and its owner",
+					OperationID: "whoami",
+					Tags:        []string{"account"},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The token's owner", schemaRef("Whoami")),
+						"401": responseRef("Unauthorized"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+			},
+			"/snippets": {
+				"get": {
+					Summary:     "List the latest snippets",
+					OperationID: "listSnippets",
+					Tags:        []string{"snippets"},
+					Parameters:  []openAPIParam{pageParam, limitParam, fieldsParam(apiSnippetFields), includeContent},
+					Responses: map[string]openAPIResponse{
+						"200": {
+							Description: "A page of snippets, newest first",
+							Headers:     linkHeader,
+							Content: jsonContent(&openAPISchema{
+								Type:       "object",
+								Properties: map[string]*openAPISchema{"snippets": {Type: "array", Items: schemaRef("Snippet")}},
+							}),
+						},
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+				"post": {
+					Summary:     "Create a snippet",
+					OperationID: "createSnippet",
+					Tags:        []string{"snippets"},
+					RequestBody: snippetInput,
+					Responses: map[string]openAPIResponse{
+						"201": {
+							Description: "The snippet was created",
+							Headers: map[string]openAPIHeader{
+								"Location": {Description: "URL of the new snippet", Schema: &openAPISchema{Type: "string"}},
+							},
+							Content: jsonContent(&openAPISchema{
+								Type:       "object",
+								Properties: map[string]*openAPISchema{"id": {Type: "integer"}},
+							}),
+						},
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"422": responseRef("ValidationFailed"),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/search": {
+				"get": {
+					Summary:     "Search the snippets",
+					OperationID: "searchSnippets",
+					Tags:        []string{"snippets"},
+					Parameters: []openAPIParam{
+						{
+							Name: "q", In: "query", Required: true, Schema: &openAPISchema{Type: "string"},
+							Description: "Words to find in the title or content; takes quoted phrases, OR and -word",
+						},
+						{Name: "author", In: "query", Description: "Only snippets of this user ID", Schema: intAtLeast(1)},
+						{
+							Name: "sort", In: "query", Description: "Order of the results; relevance by default",
+							Schema: &openAPISchema{Type: "string", Enum: []any{models.SearchRelevance, models.SearchNewest, models.SearchOldest}},
+						},
+						pageParam,
+						limitParam,
+						fieldsParam(apiSearchResultFields),
+						includeContent,
+					},
+					Responses: map[string]openAPIResponse{
+						"200": {
+							Description: "A page of matching snippets",
+							Headers:     linkHeader,
+							Content: jsonContent(&openAPISchema{
+								Type: "object",
+								Properties: map[string]*openAPISchema{
+									"results": {Type: "array", Items: schemaRef("SearchResult")},
+									"total":   {Type: "integer", Description: "How many snippets match"},
+								},
+								Required: []string{"results", "total"},
+							}),
+						},
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+			},
+			"/snippets:batch": {
+				"post": {
+					Summary:     "Create several snippets at once",
+					OperationID: "createSnippets",
+					Tags:        []string{"snippets"},
+					RequestBody: &openAPIBody{Required: true, Content: jsonContent(&openAPISchema{
+						Type: "object",
+						Properties: map[string]*openAPISchema{
+							"snippets": arrayRange(schemaRef("SnippetInput"), 1, maxAPISnippetBatch),
+						},
+						Required: []string{"snippets"},
+					})},
+					Responses: map[string]openAPIResponse{
+						"201": jsonResponse("All snippets were created", &openAPISchema{
+							Type: "object",
+							Properties: map[string]*openAPISchema{
+								"results": {Type: "array", Items: schemaRef("BatchResult")},
+							},
+							Required: []string{"results"},
+						}),
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"413": jsonResponse("The request body is too large", schemaRef("Error")),
+						"422": jsonResponse("Some snippets are invalid, so none was created", &openAPISchema{
+							Type: "object",
+							Properties: map[string]*openAPISchema{
+								"error":   {Type: "string"},
+								"results": {Type: "array", Items: schemaRef("BatchResult")},
+							},
+							Required: []string{"error", "results"},
+						}),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/snippets/{id}": {
+				"get": {
+					Summary:     "Get a snippet",
+					OperationID: "getSnippet",
+					Tags:        []string{"snippets"},
+					Parameters:  []openAPIParam{idParam, ifNoneMatch},
+					Responses: map[string]openAPIResponse{
+						"200": {Description: "The snippet", Headers: etagHeader, Content: jsonContent(schemaRef("Snippet"))},
+						"304": {Description: "The cached snippet is current", Headers: etagHeader},
+						"401": responseRef("Unauthorized"),
+						"404": responseRef("NotFound"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+				"put": {
+					Summary:     "Replace one of your snippets",
+					OperationID: "updateSnippet",
+					Tags:        []string{"snippets"},
+					Parameters:  []openAPIParam{idParam, ifMatch},
+					RequestBody: snippetInput,
+					Responses: map[string]openAPIResponse{
+						"200": {Description: "The updated snippet", Headers: etagHeader, Content: jsonContent(schemaRef("Snippet"))},
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": responseRef("NotFound"),
+						"412": responseRef("PreconditionFailed"),
+						"422": responseRef("ValidationFailed"),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+				"delete": {
+					Summary:     "Delete one of your snippets",
+					OperationID: "deleteSnippet",
+					Tags:        []string{"snippets"},
+					Parameters:  []openAPIParam{idParam, ifMatch},
+					Responses: map[string]openAPIResponse{
+						"204": {Description: "The snippet was deleted"},
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": responseRef("NotFound"),
+						"412": responseRef("PreconditionFailed"),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/webhooks": {
+				"get": {
+					Summary:     "List your webhooks",
+					OperationID: "listWebhooks",
+					Tags:        []string{"webhooks"},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("Your webhooks, without their secrets", &openAPISchema{
+							Type:       "object",
+							Properties: map[string]*openAPISchema{"webhooks": {Type: "array", Items: schemaRef("Webhook")}},
+						}),
+						"401": responseRef("Unauthorized"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+				"post": {
+					Summary:     "Register a webhook",
+					OperationID: "createWebhook",
+					Tags:        []string{"webhooks"},
+					RequestBody: webhookInput,
+					Responses: map[string]openAPIResponse{
+						"201": {
+							Description: "The webhook with its secret, which is not shown again",
+							Headers: map[string]openAPIHeader{
+								"Location": {Description: "URL of the new webhook", Schema: &openAPISchema{Type: "string"}},
+							},
+							Content: jsonContent(schemaRef("Webhook")),
+						},
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"409": jsonResponse("You have as many webhooks as allowed", schemaRef("Error")),
+						"422": responseRef("ValidationFailed"),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/webhooks/{id}": {
+				"get": {
+					Summary:     "Get one of your webhooks",
+					OperationID: "getWebhook",
+					Tags:        []string{"webhooks"},
+					Parameters:  []openAPIParam{idParam},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The webhook, without its secret", schemaRef("Webhook")),
+						"401": responseRef("Unauthorized"),
+						"404": webhookNotFound,
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+				"put": {
+					Summary:     "Replace the URL and events of one of your webhooks",
+					OperationID: "updateWebhook",
+					Tags:        []string{"webhooks"},
+					Parameters:  []openAPIParam{idParam},
+					RequestBody: webhookInput,
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The updated webhook, without its secret", schemaRef("Webhook")),
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": webhookNotFound,
+						"422": responseRef("ValidationFailed"),
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+				"delete": {
+					Summary:     "Delete one of your webhooks",
+					OperationID: "deleteWebhook",
+					Tags:        []string{"webhooks"},
+					Parameters:  []openAPIParam{idParam},
+					Responses: map[string]openAPIResponse{
+						"204": {Description: "The webhook was deleted"},
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": webhookNotFound,
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/webhooks/{id}/test": {
+				"post": {
+					Summary:     "Send a ping event to one of your webhooks",
+					OperationID: "testWebhook",
+					Tags:        []string{"webhooks"},
+					Parameters:  []openAPIParam{idParam},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("How the webhook answered", schemaRef("WebhookTest")),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": webhookNotFound,
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/webhooks/{id}/rotate-secret": {
+				"post": {
+					Summary:     "Replace the secret of one of your webhooks",
+					OperationID: "rotateWebhookSecret",
+					Tags:        []string{"webhooks"},
+					Parameters:  []openAPIParam{idParam},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The webhook with its new secret, which is not shown again", schemaRef("Webhook")),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"404": webhookNotFound,
+						"429": responseRef("TooManyRequests"),
+						"503": responseRef("Maintenance"),
+					},
+				},
+			},
+			"/admin/settings": {
+				"get": {
+					Summary:     "Get the instance settings",
+					OperationID: "getSettings",
+					Tags:        []string{"admin"},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The settings", schemaRef("Settings")),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+				"put": {
+					Summary:     "Change the instance settings; settings left out keep their value",
+					OperationID: "updateSettings",
+					Tags:        []string{"admin"},
+					RequestBody: &openAPIBody{Required: true, Content: jsonContent(schemaRef("Settings"))},
+					Responses: map[string]openAPIResponse{
+						"200": jsonResponse("The settings now in effect", schemaRef("Settings")),
+						"400": responseRef("BadRequest"),
+						"401": responseRef("Unauthorized"),
+						"403": responseRef("Forbidden"),
+						"422": responseRef("ValidationFailed"),
+						"429": responseRef("TooManyRequests"),
+					},
+				},
+			},
+		},
+		Components: openAPIComponents{
+			Schemas: map[string]*openAPISchema{
+				"Snippet": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"id":      {Type: "integer"},
+						"title":   {Type: "string"},
+						"content": {Type: "string"},
+						"created": {Type: "string", Format: "date-time"},
+						"updated": {Type: "string", Format: "date-time"},
+						"expires": {Type: "string", Format: "date-time"},
+					},
+					Required: []string{"id", "title", "content", "created", "updated", "expires"},
+				},
+				"SearchResult": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"id":      {Type: "integer"},
+						"title":   {Type: "string"},
+						"content": {Type: "string"},
+						"created": {Type: "string", Format: "date-time"},
+						"updated": {Type: "string", Format: "date-time"},
+						"expires": {Type: "string", Format: "date-time"},
+						"score":   {Type: "number", Description: "Relevance to the query; only comparable within one search"},
+						"headline": {
+							Type:        "string",
+							Description: "Excerpts of the content around the matches, as HTML with matching words in <mark> elements",
+						},
+					},
+					Required: []string{"id", "title", "content", "created", "updated", "expires", "score", "headline"},
+				},
+				"SnippetInput": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"title":   {Type: "string", Description: "At most 100 characters"},
+						"content": {Type: "string"},
+						"expires": {Type: "integer", Description: "Days until the snippet expires", Enum: []any{1, 7, 365}},
+					},
+					Required: []string{"title", "content", "expires"},
+				},
+				"BatchResult": {
+					Type:        "object",
+					Description: "The outcome for the snippet at the same index in the request",
+					Properties: map[string]*openAPISchema{
+						"id":     {Type: "integer", Description: "The new snippet's ID"},
+						"fields": {Type: "object", AdditionalProperties: &openAPISchema{Type: "string"}, Description: "The snippet's invalid fields"},
+					},
+				},
+				"Webhook": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"id":      {Type: "integer"},
+						"url":     {Type: "string", Format: "uri"},
+						"events":  {Type: "array", Items: &openAPISchema{Type: "string", Enum: webhookEventsEnum()}},
+						"secret":  {Type: "string", Description: "Key of the HMAC-SHA256 in X-Snippetbox-Signature; only returned when new"},
+						"created": {Type: "string", Format: "date-time"},
+						"updated": {Type: "string", Format: "date-time"},
+					},
+					Required: []string{"id", "url", "events", "created", "updated"},
+				},
+				"WebhookInput": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"url":    {Type: "string", Format: "uri", Description: "An http or https URL of a public host"},
+						"events": {Type: "array", Items: &openAPISchema{Type: "string", Enum: webhookEventsEnum()}},
+					},
+					Required: []string{"url", "events"},
+				},
+				"WebhookTest": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"delivered": {Type: "boolean"},
+						"status":    {Type: "integer", Description: "The HTTP status the webhook answered with"},
+						"error":     {Type: "string", Description: "Why the delivery failed"},
+					},
+					Required: []string{"delivered"},
+				},
+				"Status": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"version":        {Type: "string"},
+						"commit":         {Type: "string", Description: "VCS revision the binary was built from"},
+						"build_date":     {Type: "string", Format: "date-time"},
+						"go_version":     {Type: "string"},
+						"uptime":         {Type: "string", Description: "Time since the instance started, e.g. 3h2m1s"},
+						"uptime_seconds": {Type: "integer"},
+						"database": {
+							Type: "object",
+							Properties: map[string]*openAPISchema{
+								"status":     {Type: "string", Enum: []any{"ok", "unreachable"}},
+								"latency_ms": {Type: "number", Description: "Round trip of a ping; absent when unreachable"},
+							},
+							Required: []string{"status"},
+						},
+					},
+					Required: []string{"version", "go_version", "uptime", "uptime_seconds", "database"},
+				},
+				"Whoami": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"id":    {Type: "integer"},
+						"name":  {Type: "string"},
+						"email": {Type: "string", Format: "email"},
+						"token": {Type: "string", Description: "Name of the token"},
+					},
+				},
+				"Settings": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"registration_mode":   {Type: "string", Enum: []any{"open", "closed"}},
+						"max_snippet_length":  intRange(1, 1000000),
+						"maintenance_mode":    {Type: "boolean"},
+						"maintenance_message": {Type: "string"},
+						"banner":              {Type: "string"},
+					},
+				},
+				"Error": {
+					Type:       "object",
+					Properties: map[string]*openAPISchema{"error": {Type: "string"}},
+					Required:   []string{"error"},
+				},
+				"ValidationError": {
+					Type: "object",
+					Properties: map[string]*openAPISchema{
+						"error":  {Type: "string"},
+						"fields": {Type: "object", AdditionalProperties: &openAPISchema{Type: "string"}},
+					},
+					Required: []string{"error", "fields"},
+				},
+			},
+			Responses: map[string]openAPIResponse{
+				"BadRequest":       jsonResponse("The request is malformed", schemaRef("Error")),
+				"Unauthorized":     jsonResponse("The bearer token is missing or invalid", schemaRef("Error")),
+				"Forbidden":        jsonResponse("The token may not do this", schemaRef("Error")),
+				"NotFound":         jsonResponse("No such snippet", schemaRef("Error")),
+				"ValidationFailed": jsonResponse("Some fields are invalid", schemaRef("ValidationError")),
+				"PreconditionFailed": {
+					Description: "The snippet has changed since the client fetched it",
+					Headers: map[string]openAPIHeader{
+						"ETag": {Description: "The snippet's current entity tag", Schema: &openAPISchema{Type: "string"}},
+					},
+					Content: jsonContent(schemaRef("Error")),
+				},
+				"TooManyRequests": {
+					Description: "The quota is used up",
+					Headers: map[string]openAPIHeader{
+						"Retry-After": {Description: "Seconds until the next request is allowed", Schema: &openAPISchema{Type: "integer"}},
+					},
+					Content: jsonContent(schemaRef("Error")),
+				},
+				"Maintenance": {
+					Description: "The site is read-only during maintenance",
+					Headers: map[string]openAPIHeader{
+						"Retry-After": {Description: "Seconds to wait before trying again", Schema: &openAPISchema{Type: "integer"}},
+					},
+					Content: jsonContent(schemaRef("Error")),
+				},
+			},
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "A personal access token created on the account's tokens page",
+				},
+			},
+		},
+	}
+
+	if !v.deprecated.IsZero() {
+		doc.Info.Description += fmt.Sprintf(" This version is deprecated; use %s%s instead.",
+			app.basePath, currentAPIVersion().prefix())
+
+		for _, ops := range doc.Paths {
+			for method, op := range ops {
+				op.Deprecated = true
+				ops[method] = op
+			}
+		}
+	}
+
+	return doc
+}
+
+// apiSpec serves the OpenAPI document. It needs no token, so clients can
+// discover the API before they have one.
+func (app *application) apiSpec(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, r, http.StatusOK, app.openAPISpec(apiVersionOf(r)))
+}
+
+// apiOperation is an operation of the API as listed on the docs page.
+type apiOperation struct {
+	Method     string
+	Path       string
+	Summary    string
+	Deprecated bool
+	Parameters []openAPIParam
+}
+
+// apiDocs is what the API docs page shows of an OpenAPI document.
+type apiDocs struct {
+	Spec       string
+	Server     string
+	Operations []apiOperation
+	// Paste is where shell pastes go; see pasteCreate.
+	Paste string
+}
+
+// newAPIDocs lists the operations of the document for version v, by path and
+// then method.
+func (app *application) newAPIDocs(v apiVersion) apiDocs {
+	doc := app.openAPISpec(v)
+	docs := apiDocs{
+		Spec:   app.basePath + v.prefix() + "/openapi.json",
+		Server: doc.Servers[0].URL,
+		Paste:  app.baseURL + "/",
+	}
+
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			docs.Operations = append(docs.Operations, apiOperation{
+				Method:     strings.ToUpper(method),
+				Path:       path,
+				Summary:    op.Summary,
+				Deprecated: op.Deprecated,
+				Parameters: op.Parameters,
+			})
+		}
+	}
+
+	slices.SortFunc(docs.Operations, func(a, b apiOperation) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+	})
+
+	return docs
+}
+
+// apiDocs renders the API documentation from the OpenAPI document of the
+// current version. It is rendered on the server rather than by a script
+// from a third-party origin, since the page carries the user's session and
+// CSRF token.
+func (app *application) apiDocs(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.APIDocs = app.newAPIDocs(currentAPIVersion())
+
+	app.render(w, r, http.StatusOK, "api_docs.tmpl", data)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// The document needs no token.
+	code, header, body := ts.get(t, "/api/v1/openapi.json")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Type"), "application/json")
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+
+	assert.NilError(t, json.Unmarshal([]byte(body), &doc))
+	assert.Equal(t, doc.OpenAPI, "3.0.3")
+	assert.Equal(t, doc.Info.Version, "1")
+	assert.Equal(t, doc.Servers[0].URL, "/api/v1")
+	assert.Equal(t, strings.Contains(body, `"deprecated":true`), false)
+
+	app.basePath = "/app"
+	assert.Equal(t, app.openAPISpec(apiVersions[0]).Servers[0].URL, "/app/api/v1")
+}
+
+func TestOpenAPISpecDeprecated(t *testing.T) {
+	withTestAPIVersions(t)
+
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// The deprecated version's document says so.
+	code, _, body := ts.get(t, "/api/v1/openapi.json")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `"deprecated":true`)
+	assert.StringContains(t, body, "use /api/v2 instead")
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	app := newTestApplication(t)
+	patterns := app.router().patterns
+
+	for _, v := range apiVersions {
+		spec := app.openAPISpec(v)
+
+		for _, pattern := range patterns {
+			method, path, _ := strings.Cut(pattern, " ")
+
+			path, ok := strings.CutPrefix(path, v.prefix())
+			if !ok || path == "/openapi.json" {
+				continue
+			}
+
+			if _, ok := spec.Paths[path][strings.ToLower(method)]; !ok {
+				t.Errorf("%s is missing from the OpenAPI document", pattern)
+			}
+		}
+	}
+}
+
+func TestAPIDocs(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/api/docs")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `<a href='/api/v1/openapi.json'>`)
+	assert.StringContains(t, body, "<td><code>GET</code></td>\n<td><code>/snippets/{id}</code></td>")
+	assert.StringContains(t, body, `curl -H "Authorization: Bearer $TOKEN" --data-binary @- https://snippetbox.test/`)
+	assert.Equal(t, strings.Contains(header.Get("Content-Security-Policy"), "cdn.jsdelivr.net"), false)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// pagination is the page of a list the API was asked for with ?page,
+// counted from 1, and ?limit.
+type pagination struct {
+	page, limit int
+}
+
+func (p pagination) offset() int {
+	return (p.page - 1) * p.limit
+}
+
+// parsePagination reads ?page and ?limit, which default to the first page
+// and defaultLimit. If either is out of range, or the page lies so far out
+// that its offset would not fit in a Postgres integer, it answers 400 and
+// returns false.
+func (app *application) parsePagination(w http.ResponseWriter, r *http.Request, defaultLimit, maxLimit int) (pagination, bool) {
+	p := pagination{page: 1, limit: defaultLimit}
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			app.apiError(w, r, http.StatusBadRequest, "page must be a positive integer")
+
+			return p, false
+		}
+
+		p.page = n
+	}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			app.apiError(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+
+			return p, false
+		}
+
+		p.limit = n
+	}
+
+	if p.page > math.MaxInt32/p.limit {
+		app.apiError(w, r, http.StatusBadRequest, "page is out of range")
+
+		return p, false
+	}
+
+	return p, true
+}
+
+// setPageLinks adds an RFC 8288 Link header pointing at the first, previous,
+// next and last pages of a list of total items, as far as they exist. The
+// links keep the request's other query parameters.
+func (app *application) setPageLinks(w http.ResponseWriter, r *http.Request, p pagination, total int) {
+	last := max(1, (total+p.limit-1)/p.limit)
+
+	link := func(page int, rel string) string {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(p.limit))
+
+		return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, app.basePath, r.URL.Path, query.Encode(), rel)
+	}
+
+	links := []string{link(1, "first")}
+
+	if p.page > 1 {
+		links = append(links, link(min(p.page-1, last), "prev"))
+	}
+
+	if p.page < last {
+		links = append(links, link(p.page+1, "next"))
+	}
+
+	links = append(links, link(last, "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+)
+
+func TestSetPageLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		basePath string
+		target   string
+		page     int
+		total    int
+		want     string
+	}{
+		{
+			name:   "First page",
+			target: "/api/v1/snippets",
+			page:   1,
+			total:  25,
+			want: `</api/v1/snippets?limit=10&page=1>; rel="first", ` +
+				`</api/v1/snippets?limit=10&page=2>; rel="next", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="last"`,
+		},
+		{
+			name:   "Middle page",
+			target: "/api/v1/snippets?page=2",
+			page:   2,
+			total:  25,
+			want: `</api/v1/snippets?limit=10&page=1>; rel="first", ` +
+				`</api/v1/snippets?limit=10&page=1>; rel="prev", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="next", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="last"`,
+		},
+		{
+			name:   "Last page",
+			target: "/api/v1/snippets?page=3",
+			page:   3,
+			total:  30,
+			want: `</api/v1/snippets?limit=10&page=1>; rel="first", ` +
+				`</api/v1/snippets?limit=10&page=2>; rel="prev", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="last"`,
+		},
+		{
+			name:   "Past the end",
+			target: "/api/v1/snippets?page=9",
+			page:   9,
+			total:  25,
+			want: `</api/v1/snippets?limit=10&page=1>; rel="first", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="prev", ` +
+				`</api/v1/snippets?limit=10&page=3>; rel="last"`,
+		},
+		{
+			name:   "Empty list",
+			target: "/api/v1/snippets",
+			page:   1,
+			want: `</api/v1/snippets?limit=10&page=1>; rel="first", ` +
+				`</api/v1/snippets?limit=10&page=1>; rel="last"`,
+		},
+		{
+			name:     "Base path and other parameters",
+			basePath: "/app",
+			target:   "/api/v1/snippets?sort=new",
+			page:     1,
+			total:    5,
+			want: `</app/api/v1/snippets?limit=10&page=1&sort=new>; rel="first", ` +
+				`</app/api/v1/snippets?limit=10&page=1&sort=new>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{basePath: tt.basePath}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, tt.target, nil)
+			assert.NilError(t, err)
+
+			rr := httptest.NewRecorder()
+			app.setPageLinks(rr, r, pagination{page: tt.page, limit: 10}, tt.total)
+
+			assert.Equal(t, rr.Header().Get("Link"), tt.want)
+		})
+	}
+}
+
+func TestAPISnippetListLinks(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, _ := ts.apiGet(t, "/api/v1/snippets?limit=5", mocks.MockTokenPlaintext)
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Link"),
+		`</api/v1/snippets?limit=5&page=1>; rel="first", </api/v1/snippets?limit=5&page=1>; rel="last"`)
+}
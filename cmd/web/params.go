@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// paramConstraint checks the value of a path wildcard. It returns the value
+// converted to the type handlers want, or false if the request should be
+// answered with a 404 before the handler runs.
+type paramConstraint func(value string) (any, bool)
+
+// paramConstraints apply to every route with a wildcard of the same name.
+var paramConstraints = map[string]paramConstraint{
+	"id":   positiveIntParam,
+	"hash": hashParam,
+}
+
+// positiveIntParam accepts database IDs: positive integers that fit in the
+// int4 columns they refer to.
+func positiveIntParam(value string) (any, bool) {
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id < 1 {
+		return nil, false
+	}
+
+	return int(id), true
+}
+
+// hashParam accepts a content hash prefix long enough to pin a snippet
+// revision, normalised to lower case.
+func hashParam(value string) (any, bool) {
+	prefix := strings.ToLower(value)
+
+	return prefix, len(prefix) >= minContentHashPrefix && len(prefix) <= sha256.Size*2 && isHex(prefix)
+}
+
+// router is a http.ServeMux that enforces paramConstraints. The constraints
+// for a route are looked up once, when the route is registered. Unknown paths
+// and rejected parameters are answered by notFound.
+type router struct {
+	*http.ServeMux
+	notFound http.HandlerFunc
+	// patterns lists the registered routes, so tests can check them all.
+	patterns []string
+}
+
+func newRouter(notFound http.HandlerFunc) *router {
+	return &router{ServeMux: http.NewServeMux(), notFound: notFound}
+}
+
+func (rt *router) Handle(pattern string, handler http.Handler) {
+	rt.patterns = append(rt.patterns, pattern)
+	rt.ServeMux.Handle(pattern, constrainParams(pattern, handler, rt.notFound))
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// No pattern means the mux will answer 404 or 405 itself.
+	if _, pattern := rt.ServeMux.Handler(r); pattern == "" {
+		w = &notFoundWriter{ResponseWriter: w, r: r, notFound: rt.notFound}
+	}
+
+	rt.ServeMux.ServeHTTP(w, r)
+}
+
+func (rt *router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// patternWildcards returns the names of the wildcards in a ServeMux pattern.
+func patternWildcards(pattern string) []string {
+	var names []string
+
+	for segment := range strings.SplitSeq(pattern, "/") {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "}"), "...")
+		if name != "$" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func constrainParams(pattern string, next http.Handler, notFound http.HandlerFunc) http.Handler {
+	var names []string
+
+	for _, name := range patternWildcards(pattern) {
+		if _, ok := paramConstraints[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := make(map[string]any, len(names))
+
+		for _, name := range names {
+			value, ok := paramConstraints[name](r.PathValue(name))
+			if !ok {
+				notFound(w, r)
+
+				return
+			}
+
+			params[name] = value
+		}
+
+		ctx := context.WithValue(r.Context(), pathParamsContextKey, params)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// pathInt returns the converted value of an integer path parameter. It must
+// only be used for parameters with a constraint, e.g. {id}.
+func pathInt(r *http.Request, name string) int {
+	params, _ := r.Context().Value(pathParamsContextKey).(map[string]any)
+	value, _ := params[name].(int)
+
+	return value
+}
+
+// pathString returns the normalised value of a constrained string path
+// parameter such as {hash}.
+func pathString(r *http.Request, name string) string {
+	params, _ := r.Context().Value(pathParamsContextKey).(map[string]any)
+	value, _ := params[name].(string)
+
+	return value
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestPatternWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "GET /{$}", want: nil},
+		{pattern: "GET /snippet/view/{id}", want: []string{"id"}},
+		{pattern: "GET /raw/{id}/{hash}", want: []string{"id", "hash"}},
+		{pattern: "GET /files/{path...}", want: []string{"path"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, fmt.Sprint(patternWildcards(tt.pattern)), fmt.Sprint(tt.want))
+		})
+	}
+}
+
+func TestRouterConstraints(t *testing.T) {
+	rt := newRouter(http.NotFound)
+	rt.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, pathInt(r, "id"))
+	})
+	rt.HandleFunc("GET /raw/{hash}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, pathString(r, "hash"))
+	})
+	rt.HandleFunc("GET /free/{name}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.PathValue("name"))
+	})
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Valid ID", urlPath: "/items/42", wantCode: http.StatusOK, wantBody: "42"},
+		{name: "Zero ID", urlPath: "/items/0", wantCode: http.StatusNotFound},
+		{name: "Negative ID", urlPath: "/items/-1", wantCode: http.StatusNotFound},
+		{name: "Decimal ID", urlPath: "/items/1.5", wantCode: http.StatusNotFound},
+		{name: "String ID", urlPath: "/items/foo", wantCode: http.StatusNotFound},
+		{name: "Overflowing ID", urlPath: "/items/2147483648", wantCode: http.StatusNotFound},
+		{name: "Valid hash", urlPath: "/raw/DEADBEEF", wantCode: http.StatusOK, wantBody: "deadbeef"},
+		{name: "Short hash", urlPath: "/raw/dead", wantCode: http.StatusNotFound},
+		{name: "Non-hex hash", urlPath: "/raw/deadbeeg", wantCode: http.StatusNotFound},
+		{name: "Unconstrained", urlPath: "/free/Any_Thing", wantCode: http.StatusOK, wantBody: "Any_Thing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			rt.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.urlPath, nil))
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, rr.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
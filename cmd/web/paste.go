@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/validator"
+)
+
+// pasteField is the form field pastebins like sprunge and ix.io take the
+// text from, as in curl -F 'f:1=<-'.
+const pasteField = "f:1"
+
+type plainTextErrorsContextKey struct{}
+
+// answerInPlainText makes apiError and apiValidationError answer with plain
+// text instead of JSON, for routes meant to be used from a shell.
+func answerInPlainText(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), plainTextErrorsContextKey{}, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func plainTextErrors(r *http.Request) bool {
+	plain, _ := r.Context().Value(plainTextErrorsContextKey{}).(bool)
+
+	return plain
+}
+
+// pasteCreate creates a snippet from a request body straight from curl:
+//
+//	cat file | curl -H "Authorization: Bearer $TOKEN" --data-binary @- https://host/
+//
+// The text is the f:1 field of a form, or else the whole body. ?title sets
+// the title, which defaults to the first line of the text, and ?expires the
+// days until it expires. The answer is the snippet's URL as plain text.
+func (app *application) pasteCreate(w http.ResponseWriter, r *http.Request) {
+	content, err := pasteContent(w, r)
+	if err != nil {
+		app.apiError(w, r, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	title := query.Get("title")
+	if title == "" {
+		title = pasteTitle(content)
+	}
+
+	expires := 7
+	if s := query.Get("expires"); s != "" {
+		if expires, err = strconv.Atoi(s); err != nil {
+			app.apiError(w, r, http.StatusBadRequest, "expires must be 1, 7 or 365")
+
+			return
+		}
+	}
+
+	var v validator.Validator
+
+	app.checkSnippet(&v, title, content, expires)
+
+	if !v.Valid() {
+		app.apiValidationError(w, r, "invalid snippet", v)
+
+		return
+	}
+
+	token, _ := app.apiToken(r)
+
+	id, err := app.snippets.Insert(r.Context(), token.UserID, title, content, expires)
+	if err != nil {
+		app.handleError(w, r, err)
+
+		return
+	}
+
+	app.snippetCreated(token.UserID, id, title)
+
+	// mountBasePath adds the base path to root-relative locations.
+	w.Header().Set("Location", fmt.Sprintf("/snippet/view/%d", id))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "%s/snippet/view/%d\n", app.baseURL, id)
+}
+
+// pasteContent returns the text of a paste: the f:1 field of a form, or the
+// body itself. curl --data-binary labels any body a URL-encoded form, so a
+// body that is not one with an f:1 field is taken as it is.
+func pasteContent(w http.ResponseWriter, r *http.Request) (string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxFormBytes); err != nil {
+			return "", fmt.Errorf("invalid form: %w", err)
+		}
+
+		if r.MultipartForm != nil && len(r.MultipartForm.File[pasteField]) > 0 {
+			f, err := r.MultipartForm.File[pasteField][0].Open()
+			if err != nil {
+				return "", fmt.Errorf("invalid form: %w", err)
+			}
+			defer f.Close()
+
+			b, err := io.ReadAll(f)
+			if err != nil {
+				return "", fmt.Errorf("reading %s: %w", pasteField, err)
+			}
+
+			return string(b), nil
+		}
+
+		return r.FormValue(pasteField), nil
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading body: %w", err)
+	}
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(string(b)); err == nil && form.Has(pasteField) {
+			return form.Get(pasteField), nil
+		}
+	}
+
+	return string(b), nil
+}
+
+// pasteTitle is the first line of content that is not blank, shortened to
+// the longest title allowed.
+func pasteTitle(content string) string {
+	var title string
+
+	for line := range strings.Lines(content) {
+		if title = strings.TrimSpace(line); title != "" {
+			break
+		}
+	}
+
+	if utf8.RuneCountInString(title) > 100 {
+		title = string([]rune(title)[:99]) + "…"
+	}
+
+	return title
+}
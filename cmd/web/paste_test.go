@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+func TestPasteCreate(t *testing.T) {
+	app := newTestApplication(t)
+	app.tokenLimiter = ratelimit.New(1, 20)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	var form bytes.Buffer
+
+	mw := multipart.NewWriter(&form)
+	mw.WriteField("f:1", "from curl -F") //nolint:errcheck // writing to a buffer
+	mw.Close()
+
+	urlencoded := "application/x-www-form-urlencoded"
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		token       string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{"Raw body", "/", mocks.MockTokenPlaintext, urlencoded, "panic: oops&x=1\n", http.StatusCreated, "https://snippetbox.test/snippet/view/2\n"},
+		{"Form field", "/paste?title=Log", mocks.MockTokenPlaintext, urlencoded, "f%3A1=hello+world", http.StatusCreated, "https://snippetbox.test/snippet/view/2\n"},
+		{"Multipart", "/paste", mocks.MockTokenPlaintext, mw.FormDataContentType(), form.String(), http.StatusCreated, "https://snippetbox.test/snippet/view/2\n"},
+		{"Empty", "/", mocks.MockTokenPlaintext, "text/plain", " \n", http.StatusUnprocessableEntity, "invalid snippet\ncontent: This field cannot be blank\ntitle: This field cannot be blank."},
+		{"Invalid expires", "/?expires=2", mocks.MockTokenPlaintext, "text/plain", "x", http.StatusUnprocessableEntity, "expires: This field must be equal 1, 7, or 365."},
+		{"Expires not a number", "/?expires=week", mocks.MockTokenPlaintext, "text/plain", "x", http.StatusBadRequest, "expires must be 1, 7 or 365\n"},
+		{"Without token", "/", "", "text/plain", "x", http.StatusUnauthorized, "missing bearer token\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.URL+tt.urlPath, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", tt.contentType)
+
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, resp.StatusCode, tt.wantCode)
+			assert.StringContains(t, resp.Header.Get("Content-Type"), "text/plain")
+			assert.StringContains(t, string(body), tt.wantBody)
+
+			if resp.StatusCode == http.StatusCreated {
+				assert.Equal(t, resp.Header.Get("Location"), "/snippet/view/2")
+			}
+		})
+	}
+}
+
+func TestPasteContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"Raw", "text/plain", "a=b&c", "a=b&c"},
+		{"Form field", "application/x-www-form-urlencoded", "f%3A1=a%26b", "a&b"},
+		{"Form without field", "application/x-www-form-urlencoded", "2024-01-01 ERROR x=1", "2024-01-01 ERROR x=1"},
+		{"No content type", "", "plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.Header.Set("Content-Type", tt.contentType)
+
+			got, err := pasteContent(httptest.NewRecorder(), r)
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestPasteTitle(t *testing.T) {
+	assert.Equal(t, pasteTitle("\n  \n first line \nsecond"), "first line")
+	assert.Equal(t, pasteTitle(strings.Repeat("x", 120)), strings.Repeat("x", 99)+"…")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// newPprofServer returns a server for the net/http/pprof handlers. It runs
+// on its own listener so that profiles are never reachable through the
+// public address; operators reach it over a loopback address or a private
+// network, e.g. with kubectl port-forward.
+func newPprofServer(addr string, logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	if !isLoopbackAddr(addr) {
+		logger.Warn("pprof is reachable beyond this host; keep its port private", slog.String("addr", addr))
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		IdleTimeout:       time.Minute,
+		ReadHeaderTimeout: 5 * time.Second,
+		// CPU profiles and traces stream for as many seconds as requested,
+		// 30 by default, and pprof refuses durations beyond this timeout.
+		WriteTimeout: 2 * time.Minute,
+	}
+}
+
+// isLoopbackAddr reports whether addr only listens on the local host.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
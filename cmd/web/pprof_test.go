@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestPprofServer(t *testing.T) {
+	srv := newPprofServer("localhost:6060", slog.New(slog.DiscardHandler))
+
+	ts := newTestServer(t, srv.Handler)
+	defer ts.Close()
+
+	tests := []struct {
+		urlPath  string
+		wantCode int
+	}{
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/pprof/heap?debug=1", http.StatusOK},
+		{"/debug/pprof/goroutine?debug=1", http.StatusOK},
+		{"/debug/pprof/cmdline", http.StatusOK},
+		{"/", http.StatusNotFound},
+		{"/snippet/view/1", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.urlPath, func(t *testing.T) {
+			code, _, _ := ts.get(t, tt.urlPath)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:6060", true},
+		{"127.0.0.1:6060", true},
+		{"[::1]:6060", true},
+		{":6060", false},
+		{"0.0.0.0:6060", false},
+		{"10.0.0.5:6060", false},
+		{"6060", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, isLoopbackAddr(tt.addr), tt.want)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+// limiterCleanupInterval is how often idle rate limiter buckets are dropped.
+const limiterCleanupInterval = time.Minute
+
+// clientIPKey is the rate limiting key of the client. IPv6 clients are
+// limited per /64, the smallest network usually handed to a single host, so
+// they cannot dodge the limit by rotating addresses.
+func clientIPKey(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+
+	if ip.To4() != nil {
+		return ip.String()
+	}
+
+	return ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+}
+
+// limitByIP rejects clients that exceed the per-IP rate limit with 429 Too
+// Many Requests. Static assets are exempt, since a single page load fetches
+// several of them.
+func (app *application) limitByIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, app.basePath+"/static/") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		allowed, wait := app.ipLimiter.Allow(clientIPKey(app.clientIP(r)))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+
+			if strings.HasPrefix(r.URL.Path, app.basePath+"/api/") {
+				app.apiError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+			} else {
+				app.clientError(w, http.StatusTooManyRequests)
+			}
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitAPIByIP applies the per-IP API quota. It runs before the token is
+// checked, so it also slows down clients guessing tokens, and caps what one
+// address can do by spreading its requests over several tokens.
+func (app *application) limitAPIByIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.apiIPLimiter != nil && !app.applyQuota(w, r, app.apiIPLimiter.Take(clientIPKey(app.clientIP(r)))) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenQuota returns the limiter for the tokens of user: admins get their
+// own, usually larger, quota.
+func (app *application) tokenQuota(user models.User) *ratelimit.Limiter {
+	if user.IsAdmin {
+		return app.adminLimiter
+	}
+
+	return app.tokenLimiter
+}
+
+// applyQuota reports res in the X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers, the latter in seconds until the quota is full
+// again. If the request is over the quota it answers 429 with Retry-After
+// and returns false. A request passes several quotas, so the headers
+// describe whichever has the fewest requests remaining, or the one that
+// refused it.
+func (app *application) applyQuota(w http.ResponseWriter, r *http.Request, res ratelimit.Result) bool {
+	h := w.Header()
+
+	prev, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil || res.Remaining < prev || !res.Allowed {
+		h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(res.Reset.Seconds()))))
+	}
+
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+		app.apiError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+
+		return false
+	}
+
+	return true
+}
+
+// cleanupLimiters periodically forgets the buckets of clients and tokens
+// that have gone quiet, until ctx is cancelled.
+func (app *application) cleanupLimiters(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, l := range []*ratelimit.Limiter{app.ipLimiter, app.apiIPLimiter, app.tokenLimiter, app.adminLimiter} {
+				if l != nil {
+					l.Cleanup(max(interval, l.RefillTime()))
+				}
+			}
+		}
+	}
+}
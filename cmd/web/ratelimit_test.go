@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+)
+
+func TestClientIPKey(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		want       string
+	}{
+		{remoteAddr: "192.0.2.10:51234", want: "192.0.2.10"},
+		{remoteAddr: "[2001:db8:1:2:3:4:5:6]:443", want: "2001:db8:1:2::/64"},
+		{remoteAddr: "[2001:db8:1:2:ffff::1]:443", want: "2001:db8:1:2::/64"},
+		{remoteAddr: "pipe", want: "pipe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			assert.Equal(t, clientIPKey(tt.remoteAddr), tt.want)
+		})
+	}
+}
+
+func TestLimitByIP(t *testing.T) {
+	app := newTestApplication(t)
+	app.ipLimiter = ratelimit.New(0.01, 2)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for range 2 {
+		code, _, _ := ts.get(t, "/ping")
+		assert.Equal(t, code, http.StatusOK)
+	}
+
+	code, header, _ := ts.get(t, "/ping")
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, header.Get("Retry-After"), "100")
+
+	// Static assets do not count against the limit.
+	code, _, _ = ts.get(t, "/static/css/main.css")
+	assert.Equal(t, code, http.StatusOK)
+
+	code, header, body := ts.get(t, "/api/v1/whoami")
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, header.Get("Content-Type"), "application/json")
+	assert.StringContains(t, body, "rate limit exceeded")
+}
+
+func TestCleanupLimiters(t *testing.T) {
+	app := newTestApplication(t)
+	app.ipLimiter = ratelimit.New(1000, 1)
+	app.ipLimiter.Allow("192.0.2.10")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app.workers.Background(func() {
+		app.cleanupLimiters(ctx, 10*time.Millisecond)
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for app.ipLimiter.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	assert.Equal(t, app.ipLimiter.Len(), 0)
+
+	cancel()
+	app.workers.Wait()
+}
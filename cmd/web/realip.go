@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseAddrRanges parses a comma-separated list of IP addresses and CIDR
+// ranges, as taken by -trusted-proxies and -admin-allow. what names the list
+// in errors.
+func parseAddrRanges(value, what string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s range %q: %w", what, entry, err)
+			}
+
+			prefixes = append(prefixes, prefix.Masked())
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s address %q: %w", what, entry, err)
+		}
+
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// trustedProxy reports whether addr belongs to one of the trusted proxies.
+func (app *application) trustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range app.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromTrustedProxy reports whether r came in over a connection from a
+// trusted proxy.
+func (app *application) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+
+	return err == nil && app.trustedProxy(peer)
+}
+
+// clientIP returns the IP address of the client that made r. Forwarding
+// headers are only believed when the connection comes from a trusted proxy,
+// since anyone else can set them to whatever they like. X-Forwarded-For is
+// read from the right, skipping the trusted proxies that appended to it, so
+// the first untrusted hop is the client.
+func (app *application) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !app.trustedProxy(peer) {
+		return host
+	}
+
+	if hops := r.Header.Values("X-Forwarded-For"); len(hops) > 0 {
+		hops = strings.Split(strings.Join(hops, ","), ",")
+
+		client := peer
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+
+			client = addr.Unmap()
+			if !app.trustedProxy(client) {
+				break
+			}
+		}
+
+		return client.String()
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+
+	return host
+}
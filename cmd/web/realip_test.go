@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestParseAddrRanges(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "10.0.0.1", want: []string{"10.0.0.1/32"}},
+		{value: " 10.0.0.0/8 , ::1", want: []string{"10.0.0.0/8", "::1/128"}},
+		{value: "192.168.1.7/24", want: []string{"192.168.1.0/24"}},
+		{value: "proxy.internal", wantErr: true},
+		{value: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			prefixes, err := parseAddrRanges(tt.value, "trusted proxy")
+			assert.Equal(t, err != nil, tt.wantErr)
+
+			var got []string
+			for _, p := range prefixes {
+				got = append(got, p.String())
+			}
+
+			assert.Equal(t, len(got), len(tt.want))
+
+			for i := range got {
+				assert.Equal(t, got[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseAddrRanges("10.0.0.0/8", "trusted proxy")
+	assert.NilError(t, err)
+
+	app := &application{trustedProxies: proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "Direct client",
+			remoteAddr: "203.0.113.5:4321",
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "Untrusted peer cannot spoof",
+			remoteAddr: "203.0.113.5:4321",
+			forwarded:  []string{"198.51.100.1"},
+			realIP:     "198.51.100.2",
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "Trusted proxy",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "Spoofed hop left of the client",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"1.2.3.4, 198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "Chain of trusted proxies",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"198.51.100.1, 10.0.0.9", "10.0.0.3"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "X-Real-IP",
+			remoteAddr: "10.0.0.2:4321",
+			realIP:     "198.51.100.2",
+			want:       "198.51.100.2",
+		},
+		{
+			name:       "Garbage header",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"not-an-ip"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "IPv6 client",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"2001:db8::1"},
+			want:       "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			assert.Equal(t, app.clientIP(r), tt.want)
+		})
+	}
+}
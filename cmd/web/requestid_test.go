@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"3f2a9c0d1e4b5a6f", true},
+		{"req-42_a.b:c", true},
+		{"", false},
+		{strings.Repeat("a", maxRequestIDLength), true},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+		{"has space", false},
+		{"<script>", false},
+		{"line\nbreak", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			assert.Equal(t, validRequestID(tt.id), tt.want)
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	app := newTestApplication(t)
+	app.trustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		adopt      bool
+	}{
+		{name: "Generated", remoteAddr: "10.0.0.1:1234"},
+		{name: "Adopted", remoteAddr: "10.0.0.1:1234", header: "lb-1234", adopt: true},
+		{name: "Invalid replaced", remoteAddr: "10.0.0.1:1234", header: "bad id"},
+		{name: "Untrusted replaced", remoteAddr: "203.0.113.9:1234", header: "lb-1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.RemoteAddr = tt.remoteAddr
+
+			if tt.header != "" {
+				r.Header.Set(requestIDHeader, tt.header)
+			}
+
+			var seen string
+
+			next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				seen = requestIDFromContext(r.Context())
+			})
+
+			rr := httptest.NewRecorder()
+			app.requestID(next).ServeHTTP(rr, r)
+
+			assert.Equal(t, rr.Header().Get(requestIDHeader), seen)
+			assert.Equal(t, validRequestID(seen), true)
+			assert.Equal(t, seen == tt.header, tt.adopt)
+		})
+	}
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(requestIDHandler{slog.NewTextHandler(&buf, nil)}).With("component", "test")
+
+	ctx := context.WithValue(context.Background(), requestIDContextKey, "req-9")
+
+	logger.InfoContext(ctx, "inside a request")
+	logger.Info("outside a request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 2)
+	assert.StringContains(t, lines[0], "component=test request_id=req-9")
+	assert.Equal(t, strings.Contains(lines[1], "request_id"), false)
+}
+
+func TestServerErrorRequestID(t *testing.T) {
+	app := newTestApplication(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey, "req-5"))
+
+	rr := httptest.NewRecorder()
+	app.serverError(rr, r, errors.New("boom"))
+
+	assert.Equal(t, rr.Code, http.StatusInternalServerError)
+	assert.Equal(t, rr.Body.String(), "Internal Server Error\nRequest ID: req-5\n")
+
+	records := app.logs.Records(logbuffer.Filter{RequestID: "req-5"})
+	assert.Equal(t, len(records), 1)
+}
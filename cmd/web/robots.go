@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type robotsConfig struct {
+	file        string
+	disallowRaw bool
+}
+
+// robotsFlags registers the flags that control robots.txt.
+func robotsFlags(cfg *robotsConfig) {
+	flag.StringVar(&cfg.file, "robots-file", "", "File served as robots.txt instead of the generated one")
+	flag.BoolVar(&cfg.disallowRaw, "robots-disallow-raw", false, "Ask crawlers not to fetch raw snippet content")
+}
+
+// robotsPrivatePaths are never worth crawling: they are personal, need a
+// login or only accept API tokens.
+var robotsPrivatePaths = []string{"/account/", "/admin/", "/api/", "/user/"}
+
+// robotsRawPaths serve snippet content without the surrounding page.
+var robotsRawPaths = []string{"/snippet/raw/", "/raw/"}
+
+// newRobotsTxt returns the robots.txt to serve: the operator's file if one is
+// configured, otherwise rules that keep crawlers out of private pages. Paths
+// are prefixed with basePath; a site served under a prefix must copy the
+// rules into the robots.txt at its root for crawlers to find them.
+func newRobotsTxt(cfg robotsConfig, basePath string) ([]byte, error) {
+	if cfg.file != "" {
+		b, err := os.ReadFile(cfg.file)
+		if err != nil {
+			return nil, fmt.Errorf("reading robots file: %w", err)
+		}
+
+		return b, nil
+	}
+
+	paths := robotsPrivatePaths
+	if cfg.disallowRaw {
+		paths = append(paths[:len(paths):len(paths)], robotsRawPaths...)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("User-agent: *\n")
+
+	for _, path := range paths {
+		fmt.Fprintf(&b, "Disallow: %s%s\n", basePath, path)
+	}
+
+	return []byte(b.String()), nil
+}
+
+func (app *application) robotsTxt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if _, err := w.Write(app.robots); err != nil {
+		app.logger.ErrorContext(r.Context(), err.Error())
+	}
+}
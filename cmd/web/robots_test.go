@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNewRobotsTxt(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "robots.txt")
+	assert.NilError(t, os.WriteFile(custom, []byte("User-agent: *\nDisallow: /\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		cfg      robotsConfig
+		basePath string
+		want     string
+	}{
+		{
+			name: "Default",
+			want: "User-agent: *\nDisallow: /account/\nDisallow: /admin/\nDisallow: /api/\nDisallow: /user/\n",
+		},
+		{
+			name: "Disallow raw",
+			cfg:  robotsConfig{disallowRaw: true},
+			want: "User-agent: *\nDisallow: /account/\nDisallow: /admin/\nDisallow: /api/\nDisallow: /user/\n" +
+				"Disallow: /snippet/raw/\nDisallow: /raw/\n",
+		},
+		{
+			name:     "Base path",
+			basePath: "/snippetbox",
+			want: "User-agent: *\nDisallow: /snippetbox/account/\nDisallow: /snippetbox/admin/\n" +
+				"Disallow: /snippetbox/api/\nDisallow: /snippetbox/user/\n",
+		},
+		{
+			name: "Custom file",
+			cfg:  robotsConfig{file: custom, disallowRaw: true},
+			want: "User-agent: *\nDisallow: /\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRobotsTxt(tt.cfg, tt.basePath)
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), tt.want)
+		})
+	}
+
+	_, err := newRobotsTxt(robotsConfig{file: filepath.Join(t.TempDir(), "missing")}, "")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestRobotsTxt(t *testing.T) {
+	app := newTestApplication(t)
+	app.robots = []byte("User-agent: *\nDisallow: /admin/\n")
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/robots.txt")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Type"), "text/plain; charset=utf-8")
+	assert.Equal(t, body, "User-agent: *\nDisallow: /admin/")
+}
+
+func TestMetaRobots(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	browser := http.Header{"Accept": {"text/html"}}
+
+	_, _, body := ts.getWithHeaders(t, "/no/such/page", browser)
+	assert.StringContains(t, body, "<meta name='robots' content='noindex'>")
+
+	_, _, body = ts.getWithHeaders(t, "/", browser)
+	assert.Equal(t, strings.Contains(body, "name='robots'"), false)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/justinas/alice"
+)
+
+// routeGroup registers routes that share a path prefix and a middleware
+// chain, e.g. every /admin route requiring an admin. Groups nest: a group
+// made from another extends its prefix and runs its middleware after the
+// parent's.
+type routeGroup struct {
+	rt     *router
+	prefix string
+	chain  alice.Chain
+}
+
+// group returns a group of routes under prefix that run through
+// middleware, in order, before their handler.
+func (rt *router) group(prefix string, middleware ...alice.Constructor) *routeGroup {
+	return &routeGroup{rt: rt, prefix: prefix, chain: alice.New(middleware...)}
+}
+
+// group returns a subgroup under g's prefix followed by prefix, whose
+// routes also run through middleware.
+func (g *routeGroup) group(prefix string, middleware ...alice.Constructor) *routeGroup {
+	return &routeGroup{rt: g.rt, prefix: g.prefix + prefix, chain: g.chain.Append(middleware...)}
+}
+
+// with returns a subgroup with the same prefix whose routes also run through
+// middleware.
+func (g *routeGroup) with(middleware ...alice.Constructor) *routeGroup {
+	return g.group("", middleware...)
+}
+
+// handle registers handler for method and path, relative to the group's
+// prefix.
+func (g *routeGroup) handle(method, path string, handler http.HandlerFunc) {
+	g.rt.Handle(method+" "+g.prefix+path, g.chain.Then(handler))
+}
+
+func (g *routeGroup) get(path string, handler http.HandlerFunc) {
+	g.handle(http.MethodGet, path, handler)
+}
+
+func (g *routeGroup) post(path string, handler http.HandlerFunc) {
+	g.handle(http.MethodPost, path, handler)
+}
+
+func (g *routeGroup) put(path string, handler http.HandlerFunc) {
+	g.handle(http.MethodPut, path, handler)
+}
+
+func (g *routeGroup) delete(path string, handler http.HandlerFunc) {
+	g.handle(http.MethodDelete, path, handler)
+}
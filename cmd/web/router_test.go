@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestRouteGroup(t *testing.T) {
+	// mark appends name to the X-Chain header, recording the order the
+	// middleware ran in.
+	mark := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Chain", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	rt := newRouter(http.NotFound)
+	root := rt.group("", mark("root"))
+	admin := root.group("/admin", mark("admin"))
+	sensitive := admin.with(mark("sensitive"))
+
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	root.get("/about", ok)
+	admin.get("/users/{id}", ok)
+	sensitive.post("/users/{id}/suspend", ok)
+	sensitive.put("/settings", ok)
+
+	assert.Equal(t, fmt.Sprint(rt.patterns), "[GET /about GET /admin/users/{id} POST /admin/users/{id}/suspend PUT /admin/settings]")
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/about", "root"},
+		{http.MethodGet, "/admin/users/1", "root,admin"},
+		{http.MethodPost, "/admin/users/1/suspend", "root,admin,sensitive"},
+		{http.MethodPut, "/admin/settings", "root,admin,sensitive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			rt.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, rr.Code, http.StatusOK)
+			assert.Equal(t, strings.Join(rr.Header().Values("X-Chain"), ","), tt.want)
+		})
+	}
+}
+
+// routePath fills in the wildcards of a route pattern with values its
+// parameter constraints accept, and returns the method and path.
+func routePath(pattern string) (string, string) {
+	method, path, _ := strings.Cut(pattern, " ")
+
+	for _, name := range patternWildcards(path) {
+		value := "x"
+
+		switch name {
+		case "id":
+			value = "1"
+		case "hash":
+			value = "0123456789abcdef"
+		}
+
+		path = strings.Replace(path, "{"+name+"}", value, 1)
+	}
+
+	return method, path
+}
+
+// TestRouteGuards checks every GET route of the account, admin and API groups
+// against the guard its group promises, so a route registered in the wrong
+// group is caught.
+func TestRouteGuards(t *testing.T) {
+	app := newTestApplication(t)
+
+	anonymous := newTestServer(t, app.routes())
+	defer anonymous.Close()
+
+	user := newTestServer(t, app.routes())
+	defer user.Close()
+	user.login(t, "alice@example.com", "pa$$word")
+
+	var checked int
+
+	for _, pattern := range app.router().patterns {
+		method, path := routePath(pattern)
+		if method != http.MethodGet {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(path, "/account/"):
+			t.Run(pattern, func(t *testing.T) {
+				code, header, _ := anonymous.get(t, path)
+				assert.Equal(t, code, http.StatusSeeOther)
+				assert.Equal(t, header.Get("Location"), "/user/login")
+			})
+		case strings.HasPrefix(path, "/admin/"):
+			t.Run(pattern, func(t *testing.T) {
+				code, header, _ := anonymous.get(t, path)
+				assert.Equal(t, code, http.StatusSeeOther)
+				assert.Equal(t, header.Get("Location"), "/user/login")
+
+				code, _, _ = user.get(t, path)
+				assert.Equal(t, code, http.StatusForbidden)
+			})
+		// The OpenAPI document and the status are public, so clients can
+		// read them before they have a token.
+		case isVersionedAPIPath(path) && !strings.HasSuffix(path, "/openapi.json") && !strings.HasSuffix(path, "/status"):
+			t.Run(pattern, func(t *testing.T) {
+				code, _, _ := anonymous.get(t, path)
+				assert.Equal(t, code, http.StatusUnauthorized)
+			})
+		default:
+			continue
+		}
+
+		checked++
+	}
+
+	if checked == 0 {
+		t.Fatal("no guarded routes found")
+	}
+}
+
+func isVersionedAPIPath(path string) bool {
+	return slices.ContainsFunc(apiVersions, func(v apiVersion) bool {
+		return strings.HasPrefix(path, v.prefix()+"/")
+	})
+}
@@ -3,40 +3,219 @@ package main
 import (
 	"net/http"
 
-	"github.com/FABLOUSFALCON/snippetbox/ui"
 	"github.com/justinas/alice"
 )
 
-func (app *application) routes() http.Handler {
-	// Use the http.NewServeMux() function to initialize a  new servemux, then
-	// register the home function as the handler for the "/" URL pattern.
-	mux := http.NewServeMux()
+// router registers every route with the middleware its group requires.
+func (app *application) router() *router {
+	// The router is a http.ServeMux that also rejects malformed {id} and
+	// {hash} path parameters with a 404 before the handlers run.
+	mux := newRouter(app.notFound)
 
 	// Adding FileServe to serve the static files.
-	mux.Handle("GET /static/", http.FileServerFS(ui.Files))
+	mux.Handle("GET /static/", staticFiles())
 
 	mux.HandleFunc("GET /ping", ping)
+	mux.HandleFunc("GET /healthz", app.healthz)
+	mux.HandleFunc("GET /livez", app.livez)
+	mux.HandleFunc("GET /readyz", app.readyz)
+	mux.HandleFunc("GET /.well-known/security.txt", app.securityTxt)
+	mux.HandleFunc("GET /robots.txt", app.robotsTxt)
+
+	// The event stream and websocket stay open indefinitely, so they are
+	// exempt from the handler timeout, and need no session since every
+	// snippet is public.
+	mux.HandleFunc(snippetEventsRoute, app.snippetEvents)
+	mux.HandleFunc(liveRoute, app.live)
+
+	// Everything that queries the database answers within the handler
+	// timeout, so a hung query cannot hold the connection open.
+	timeout := mux.group("", app.timeout)
+
+	// Raw content and the feed are served without the session middleware
+	// so responses stay cacheable by shared proxies.
+	timeout.get("/snippet/raw/{id}", app.snippetRaw)
+	timeout.get("/raw/{id}/{hash}", app.snippetRawPinned)
+	timeout.get("/feed.json", app.jsonFeed)
+
+	// Pastes come from curl with a token rather than a session, so they
+	// pass the API's checks but answer in plain text.
+	paste := timeout.with(answerInPlainText, app.limitAPIByIP, app.authenticateAPIToken, app.requireAPISnippetWriter)
+
+	paste.post("/{$}", app.pasteCreate)
+	paste.post("/paste", app.pasteCreate)
+
+	// Development builds can list recent requests at /_debug/requests.
+	app.inspectorRoutes(mux)
+
+	web := timeout.with(app.sessionManager.LoadAndSave, app.inspect, app.parseForms, app.noSurf, app.authenticate, app.readOnlyDuringMaintenance)
+
+	web.get("/about", app.about)
+	web.get("/terms", app.terms)
+	web.get("/status", app.statusPage)
+	web.get("/abuse", app.abuse)
+	web.post("/abuse", app.abusePost)
+	web.get("/api/docs", app.apiDocs)
+	web.post("/locale", app.localePost)
+	web.post("/banner/dismiss", app.bannerDismissPost)
+	web.get("/{$}", app.home)
+	web.get("/snippet/view/{id}", app.snippetView)
+	web.get("/user/signup", app.userSignup)
+	web.post("/user/signup", app.userSignupPost)
+	web.get("/user/login", app.userLogin)
+	web.post("/user/login", app.userLoginPost)
+	web.post("/user/login/passkey", app.userLoginPasskeyPost)
+
+	authenticated := web.with(app.requireAuthencation)
+
+	// Logging out, accepting the terms and rotating an expired password must
+	// stay reachable for users who are held back by those policies.
+	authenticated.post("/user/logout", app.userLogoutPost)
+	authenticated.get("/terms/accept", app.termsAccept)
+	authenticated.post("/terms/accept", app.termsAcceptPost)
+	authenticated.get("/account/reauthenticate", app.reauthenticate)
+	authenticated.post("/account/reauthenticate", app.reauthenticatePost)
+	authenticated.post("/account/reauthenticate/passkey", app.reauthenticatePasskeyPost)
+	authenticated.get("/account/password/expired", app.accountPasswordExpired)
+	authenticated.post("/account/password/expired", app.accountPasswordExpiredPost)
+
+	protected := authenticated.with(app.requireTermsAcceptance, app.requirePasswordRotation)
+
+	creator := protected.with(app.denySuspended)
+
+	creator.get("/snippet/create", app.snippetCreate)
+	creator.post("/snippet/create", app.snippetCreatePost)
+	protected.get("/snippet/stats/{id}", app.snippetStats)
+
+	account := protected.group("/account")
+
+	account.get("/view", app.accountView)
+	account.get("/tokens", app.accountTokens)
+	account.post("/tokens/{id}/delete", app.accountTokenDeletePost)
+	account.get("/passkeys", app.accountPasskeys)
+	account.post("/passkeys/{id}/delete", app.accountPasskeyDeletePost)
+	account.get("/export-data", app.accountExport)
+	account.get("/export-data/{token}", app.accountExportDownload)
+	account.get("/preferences", app.accountPreferences)
+	account.post("/preferences", app.accountPreferencesPost)
+	account.get("/notifications", app.accountNotifications)
+	account.post("/notifications", app.accountNotificationsPost)
+
+	// Changing or creating credentials and exporting personal data need a
+	// recent login.
+	sensitive := account.with(app.requireRecentAuth)
 
-	dynamic := alice.New(app.sessionManager.LoadAndSave, noSurf, app.authenticate)
-	mux.Handle("GET /about", dynamic.ThenFunc(app.about))
+	sensitive.get("/password", app.accountPasswordUpdate)
+	sensitive.post("/password", app.accountPasswordUpdatePost)
+	sensitive.post("/tokens", app.accountTokenCreatePost)
+	sensitive.post("/passkeys", app.accountPasskeyCreatePost)
+	sensitive.post("/export-data", app.accountExportPost)
 
-	mux.Handle("GET /{$}", dynamic.ThenFunc(app.home))
-	mux.Handle("GET /snippet/view/{id}", dynamic.ThenFunc(app.snippetView))
-	mux.Handle("GET /user/signup", dynamic.ThenFunc(app.userSignup))
-	mux.Handle("POST /user/signup", dynamic.ThenFunc(app.userSignupPost))
-	mux.Handle("GET /user/login", dynamic.ThenFunc(app.userLogin))
-	mux.Handle("POST /user/login", dynamic.ThenFunc(app.userLoginPost))
+	admin := protected.group("/admin", app.requireAdmin)
 
-	protected := dynamic.Append(app.requireAuthencation)
+	admin.get("/audit", app.adminAudit)
+	admin.get("/logs", app.adminLogs)
+	admin.get("/errors", app.adminErrors)
+	admin.get("/errors/{capture}", app.adminErrorView)
+	admin.get("/cache", app.adminCache)
+	admin.get("/workers", app.adminWorkers)
+	admin.get("/jobs", app.adminJobs)
+	admin.get("/emails", app.adminEmails)
+	admin.post("/emails/{id}/requeue", app.adminEmailRequeuePost)
+	admin.get("/reports", app.adminReports)
+	admin.post("/reports/{id}/resolve", app.adminReportResolvePost)
+	admin.get("/users/{id}", app.adminUserView)
+	admin.get("/incidents", app.adminIncidents)
+	admin.post("/incidents", app.adminIncidentCreatePost)
+	admin.get("/incidents/{id}", app.adminIncidentView)
+	admin.post("/incidents/{id}", app.adminIncidentUpdatePost)
+
+	// Admin actions that change other accounts need a recent login.
+	adminSensitive := admin.with(app.requireRecentAuth)
+
+	adminSensitive.post("/users/{id}/suspend", app.adminUserSuspendPost)
+	adminSensitive.post("/users/{id}/unsuspend", app.adminUserUnsuspendPost)
+
+	// Every version of the JSON API is served until it is retired.
+	for _, v := range apiVersions {
+		// The OpenAPI document is public, so clients can read it before
+		// they have a token.
+		mux.group(v.prefix(), app.withAPIVersion(v)).get("/openapi.json", app.apiSpec)
+		app.apiRoutes(timeout.group(v.prefix(), app.withAPIVersion(v)))
+	}
+
+	return mux
+}
+
+// apiRoutes registers the routes of one version of the JSON API on version,
+// the group under its prefix. The API authenticates with bearer tokens
+// instead of session cookies, so it needs neither the session nor the CSRF
+// middleware.
+func (app *application) apiRoutes(version *routeGroup) {
+	// The status is public, so deploy tools need no token to check which
+	// build is running.
+	version.with(app.limitAPIByIP).get("/status", app.apiStatus)
+
+	api := version.with(app.limitAPIByIP, app.authenticateAPIToken)
+
+	api.get("/whoami", app.apiWhoami)
+	api.get("/snippets", app.apiSnippetList)
+	api.get("/snippets/{id}", app.apiSnippetGet)
+	api.get("/search", app.apiSearch)
+	api.get("/webhooks", app.apiWebhookList)
+	api.get("/webhooks/{id}", app.apiWebhookGet)
+
+	apiWriter := api.with(app.requireAPISnippetWriter)
+
+	apiWriter.post("/snippets", app.apiSnippetCreate)
+	apiWriter.post("/snippets:batch", app.apiSnippetBatchCreate)
+	apiWriter.put("/snippets/{id}", app.apiSnippetUpdate)
+	apiWriter.delete("/snippets/{id}", app.apiSnippetDelete)
+	apiWriter.post("/webhooks", app.apiWebhookCreate)
+	apiWriter.put("/webhooks/{id}", app.apiWebhookUpdate)
+	apiWriter.delete("/webhooks/{id}", app.apiWebhookDelete)
+	apiWriter.post("/webhooks/{id}/test", app.apiWebhookTest)
+	apiWriter.post("/webhooks/{id}/rotate-secret", app.apiWebhookRotateSecret)
+
+	apiAdmin := api.group("/admin", app.requireAPIAdmin)
+
+	apiAdmin.get("/settings", app.apiAdminSettings)
+	apiAdmin.put("/settings", app.apiAdminSettingsUpdate)
+}
+
+func (app *application) routes() http.Handler {
+	return app.chain().Then(app.router())
+}
 
-	mux.Handle("GET /snippet/create", protected.ThenFunc(app.snippetCreate))
-	mux.Handle("POST /snippet/create", protected.ThenFunc(app.snippetCreatePost))
-	mux.Handle("GET /account/view", protected.ThenFunc(app.accountView))
-	mux.Handle("POST /user/logout", protected.ThenFunc(app.userLogoutPost))
-	mux.Handle("GET /account/password/update", protected.ThenFunc(app.accountPasswordUpdate))
-	mux.Handle("POST /account/password/update", protected.ThenFunc(app.accountPasswordUpdatePost))
+// chain returns the middleware every request passes through, outermost
+// first. The order matters: requests get their ID before anything can log
+// or panic, panics are recovered inside that so their response still carries
+// the ID, and optional middleware that is off is left out of the chain.
+func (app *application) chain() alice.Chain {
+	chain := alice.New(app.requestID, app.recoverPanic, compress, app.logRequest, app.commonHeaders)
 
-	standard := alice.New(app.recoverPanic, app.logRequest, commonHeaders)
+	if app.ipLimiter != nil {
+		chain = chain.Append(app.limitByIP)
+	}
+	chain = chain.Append(app.canonicalURL)
+	if app.basePath != "" {
+		chain = chain.Append(app.mountBasePath)
+	}
+	if app.adminAllow != nil {
+		chain = chain.Append(app.restrictAdminAccess)
+	}
+	if app.tracer != nil {
+		chain = chain.Append(app.traceRequest)
+	}
+	if app.bodyLog != nil {
+		chain = chain.Append(app.logBodies)
+	}
+	if app.metrics != nil {
+		chain = chain.Append(app.recordMetrics)
+	}
+	if app.latency != nil {
+		chain = chain.Append(app.trackLatency)
+	}
 
-	return standard.Then(mux)
+	return chain
 }
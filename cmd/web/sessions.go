@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"time"
+)
+
+type sessionConfig struct {
+	lifetime    time.Duration
+	idleTimeout time.Duration
+}
+
+// sessionFlags registers the flags that bound how long a login lasts.
+func sessionFlags(cfg *sessionConfig) {
+	flag.DurationVar(&cfg.lifetime, "session-lifetime", 12*time.Hour, "Absolute session lifetime; users sign in again afterwards however active they are")
+	flag.DurationVar(&cfg.idleTimeout, "session-idle-timeout", 0, "Sign users out after this long without a request (0 disables)")
+}
+
+// validate rejects lifetimes that would sign everyone out at once or make
+// the idle timeout meaningless.
+func (cfg sessionConfig) validate() error {
+	switch {
+	case cfg.lifetime <= 0:
+		return errors.New("-session-lifetime must be positive")
+	case cfg.idleTimeout < 0:
+		return errors.New("-session-idle-timeout cannot be negative")
+	case cfg.idleTimeout > cfg.lifetime:
+		return errors.New("-session-idle-timeout cannot be longer than -session-lifetime")
+	}
+
+	return nil
+}
+
+// syncAdminSession issues a fresh session token the first time a session
+// acts with admin rights it did not have when the token was issued, e.g.
+// after the user was promoted, so a token captured before the promotion
+// never carries admin rights. Demotion clears the flag again.
+func (app *application) syncAdminSession(r *http.Request, isAdmin bool) error {
+	if app.sessionManager.GetBool(r.Context(), "isAdmin") == isAdmin {
+		return nil
+	}
+
+	if isAdmin {
+		if err := app.sessionManager.RenewToken(r.Context()); err != nil {
+			return err //nolint:wrapcheck // reported by the caller as a server error
+		}
+	}
+
+	app.sessionManager.Put(r.Context(), "isAdmin", isAdmin)
+
+	return nil
+}
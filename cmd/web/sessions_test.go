@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestSessionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     sessionConfig
+		wantErr bool
+	}{
+		{"Defaults", sessionConfig{lifetime: 12 * time.Hour}, false},
+		{"Idle timeout", sessionConfig{lifetime: 12 * time.Hour, idleTimeout: time.Hour}, false},
+		{"No lifetime", sessionConfig{}, true},
+		{"Negative idle timeout", sessionConfig{lifetime: time.Hour, idleTimeout: -time.Minute}, true},
+		{"Idle longer than lifetime", sessionConfig{lifetime: time.Hour, idleTimeout: 2 * time.Hour}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.cfg.validate() != nil, tt.wantErr)
+		})
+	}
+}
+
+func TestSyncAdminSession(t *testing.T) {
+	app := newTestApplication(t)
+
+	var cookie *http.Cookie
+
+	// serve runs fn in a request carrying the session cookie from the
+	// previous call and reports whether the session token changed.
+	serve := func(fn func(r *http.Request)) bool {
+		t.Helper()
+
+		var before, after string
+
+		handler := app.sessionManager.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			before = app.sessionManager.Token(r.Context())
+			fn(r)
+			after = app.sessionManager.Token(r.Context())
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		if cookies := rr.Result().Cookies(); len(cookies) > 0 {
+			cookie = cookies[0]
+		}
+
+		return before != after
+	}
+
+	sync := func(isAdmin bool) func(r *http.Request) {
+		return func(r *http.Request) {
+			assert.NilError(t, app.syncAdminSession(r, isAdmin))
+		}
+	}
+
+	serve(func(r *http.Request) { app.sessionManager.Put(r.Context(), "authenticatedUserID", 1) })
+
+	assert.Equal(t, serve(sync(false)), false)
+	assert.Equal(t, serve(sync(true)), true)
+	assert.Equal(t, serve(sync(true)), false)
+	assert.Equal(t, serve(sync(false)), false)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// emailDomainPolicy restricts the email domains accounts can be created
+// with. An entry also covers its subdomains, so "example.com" matches
+// "eu.example.com". The allowlist is empty when every domain is allowed.
+type emailDomainPolicy struct {
+	allow []string
+	deny  []string
+}
+
+// newEmailDomainPolicy builds the policy from comma-separated allow and
+// deny lists, plus an optional file listing further denied domains one per
+// line, e.g. a published list of disposable email providers. Blank lines
+// and lines starting with # are ignored.
+func newEmailDomainPolicy(allow, deny, denyFile string) (emailDomainPolicy, error) {
+	p := emailDomainPolicy{
+		allow: parseDomains(strings.Split(allow, ",")),
+		deny:  parseDomains(strings.Split(deny, ",")),
+	}
+
+	if denyFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(denyFile)
+	if err != nil {
+		return emailDomainPolicy{}, fmt.Errorf("opening denied domains: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return emailDomainPolicy{}, fmt.Errorf("reading denied domains: %w", err)
+	}
+
+	p.deny = append(p.deny, parseDomains(lines)...)
+
+	return p, nil
+}
+
+func parseDomains(entries []string) []string {
+	var domains []string
+
+	for _, d := range entries {
+		d = strings.Trim(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" && !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+
+	return domains
+}
+
+// matchesDomain reports whether domain is one of list or a subdomain of one.
+func matchesDomain(domain string, list []string) bool {
+	for _, d := range list {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check returns why email may not be used to sign up, or "" if it may.
+func (p emailDomainPolicy) check(email string) string {
+	_, domain, _ := strings.Cut(email, "@")
+	domain = strings.ToLower(domain)
+
+	if len(p.allow) > 0 && !matchesDomain(domain, p.allow) {
+		return "Sign-ups are limited to addresses at " + strings.Join(p.allow, ", ")
+	}
+
+	if matchesDomain(domain, p.deny) {
+		return "Addresses at this domain cannot be used to sign up"
+	}
+
+	return ""
+}
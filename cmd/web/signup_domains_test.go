@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestEmailDomainPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "disposable.txt")
+	err := os.WriteFile(file, []byte("# disposable providers\nmailinator.com\n\n  Trashmail.COM \n"), 0o600)
+	assert.NilError(t, err)
+
+	open, err := newEmailDomainPolicy("", " spam.example , ", file)
+	assert.NilError(t, err)
+
+	corporate, err := newEmailDomainPolicy("Example.com,example.org", "", "")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name   string
+		policy emailDomainPolicy
+		email  string
+		want   bool
+	}{
+		{"Open", open, "bob@gmail.com", true},
+		{"Denied", open, "bob@spam.example", false},
+		{"Denied from file", open, "bob@mailinator.com", false},
+		{"Denied subdomain", open, "bob@eu.trashmail.com", false},
+		{"Lookalike", open, "bob@notmailinator.com", true},
+		{"Allowed", corporate, "bob@example.com", true},
+		{"Allowed case-insensitively", corporate, "bob@EXAMPLE.ORG", true},
+		{"Allowed subdomain", corporate, "bob@eu.example.com", true},
+		{"Not allowed", corporate, "bob@example.net", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.policy.check(tt.email) == "", tt.want)
+		})
+	}
+
+	_, err = newEmailDomainPolicy("", "", filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// The locale-aware functions take the request's locale as their first
+// argument, e.g. {{formatDate $.Locale .Created}}. Functions meant for
+// pipelines take the piped value last, e.g. {{.Content | truncate 80}}.
+var functions = template.FuncMap{
+	"humanDate":   humanDate,
+	"humanDateIn": humanDateIn,
+	"timeago":     timeago,
+	"truncate":    truncate,
+	"pluralize":   pluralize,
+	"bytesize":    bytesize,
+	"markdown":    markdown,
+	"asset":       asset,
+	"dir":         i18n.Direction,
+	"formatDate": func(l *i18n.Locale, t time.Time) string {
+		return l.FormatDate(t)
+	},
+	"formatNumber": func(l *i18n.Locale, n int) string {
+		return l.FormatNumber(int64(n))
+	},
+	"formatDecimal": func(l *i18n.Locale, f float64, prec int) string {
+		return l.FormatDecimal(f, prec)
+	},
+	"plural": func(l *i18n.Locale, key string, n int) string {
+		return l.Plural(key, int64(n))
+	},
+}
+
+func humanDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format("02 Jan 2006 at 15:04")
+}
+
+// locations caches the time zones loaded by humanDateIn, keyed by IANA name.
+var locations sync.Map
+
+// humanDateIn is humanDate in the IANA time zone tz, followed by the zone's
+// abbreviation. Unknown zones fall back to UTC.
+func humanDateIn(tz string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	loc := time.UTC
+
+	if cached, ok := locations.Load(tz); ok {
+		loc, _ = cached.(*time.Location)
+	} else if l, err := time.LoadLocation(tz); err == nil {
+		locations.Store(tz, l)
+		loc = l
+	}
+
+	return t.In(loc).Format("02 Jan 2006 at 15:04 MST")
+}
+
+// timeago describes t relative to the current time, e.g. "5 minutes ago" or
+// "in 2 days".
+func timeago(t time.Time) string {
+	return timeagoFrom(t, time.Now())
+}
+
+func timeagoFrom(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := now.Sub(t)
+
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var s string
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		s = pluralize(int(d/time.Minute), "minute", "minutes")
+	case d < 24*time.Hour:
+		s = pluralize(int(d/time.Hour), "hour", "hours")
+	case d < 30*24*time.Hour:
+		s = pluralize(int(d/(24*time.Hour)), "day", "days")
+	case d < 365*24*time.Hour:
+		s = pluralize(int(d/(30*24*time.Hour)), "month", "months")
+	default:
+		s = pluralize(int(d/(365*24*time.Hour)), "year", "years")
+	}
+
+	if future {
+		return "in " + s
+	}
+
+	return s + " ago"
+}
+
+// truncate shortens s to at most n characters, replacing the cut-off tail
+// with an ellipsis.
+func truncate(n int, s string) string {
+	if n <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return string(runes[:n-1]) + "…"
+}
+
+// pluralize returns n followed by the singular or plural noun, e.g.
+// "1 snippet" or "3 snippets". Use the locale-aware plural function for
+// translated text.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 || n == -1 {
+		return strconv.Itoa(n) + " " + singular
+	}
+
+	return strconv.Itoa(n) + " " + plural
+}
+
+// bytesize formats a byte count with binary multiples, e.g. "512 B",
+// "1.5 KB" or "8 MB".
+func bytesize(n int64) string {
+	const unit = 1024
+
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	f := float64(n)
+	units := []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+
+	i := -1
+	for ; (f >= unit || f <= -unit) && i < len(units)-1; i++ {
+		f /= unit
+	}
+
+	return strings.TrimSuffix(strconv.FormatFloat(f, 'f', 1, 64), ".0") + " " + units[i]
+}
+
+// md renders Markdown with GitHub's extensions. Raw HTML in the source is
+// dropped and dangerous link schemes are stripped, so the output is safe to
+// embed in a page.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// markdown renders s as HTML.
+func markdown(s string) (string, error) {
+	var buf bytes.Buffer
+
+	if err := md.Convert([]byte(s), &buf); err != nil {
+		return "", fmt.Errorf("rendering markdown: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestHumanDate(t *testing.T) {
+	testCases := []struct {
+		name string
+		tm   time.Time
+		want string
+	}{
+		{
+			name: "UTC",
+			tm:   time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC),
+			want: "17 Mar 2024 at 10:15",
+		},
+		{
+			name: "Empty",
+			tm:   time.Time{},
+			want: "",
+		},
+		{
+			name: "CET",
+			tm:   time.Date(2024, 3, 17, 10, 15, 0, 0, time.FixedZone("CET", 1*60*60)),
+			want: "17 Mar 2024 at 09:15",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			hd := humanDate(tC.tm)
+
+			assert.Equal(t, hd, tC.want)
+		})
+	}
+}
+
+func TestHumanDateIn(t *testing.T) {
+	tm := time.Date(2024, 7, 17, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		tz   string
+		want string
+	}{
+		{"UTC", "17 Jul 2024 at 10:15 UTC"},
+		{"Europe/Berlin", "17 Jul 2024 at 12:15 CEST"},
+		{"America/New_York", "17 Jul 2024 at 06:15 EDT"},
+		{"Not/AZone", "17 Jul 2024 at 10:15 UTC"},
+		{"", "17 Jul 2024 at 10:15 UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tz, func(t *testing.T) {
+			assert.Equal(t, humanDateIn(tt.tz, tm), tt.want)
+		})
+	}
+
+	assert.Equal(t, humanDateIn("UTC", time.Time{}), "")
+}
+
+func TestTimeago(t *testing.T) {
+	now := time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		tm   time.Time
+		want string
+	}{
+		{"Zero", time.Time{}, ""},
+		{"Seconds", now.Add(-30 * time.Second), "just now"},
+		{"Minute", now.Add(-time.Minute), "1 minute ago"},
+		{"Minutes", now.Add(-45 * time.Minute), "45 minutes ago"},
+		{"Hours", now.Add(-3 * time.Hour), "3 hours ago"},
+		{"Days", now.AddDate(0, 0, -2), "2 days ago"},
+		{"Months", now.AddDate(0, -3, 0), "3 months ago"},
+		{"Years", now.AddDate(-2, 0, 0), "2 years ago"},
+		{"Future", now.Add(25 * time.Hour), "in 1 day"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, timeagoFrom(tt.tm, now), tt.want)
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		s    string
+		want string
+	}{
+		{"Short", 10, "hello", "hello"},
+		{"Exact", 5, "hello", "hello"},
+		{"Long", 5, "hello world", "hell…"},
+		{"Multibyte", 3, "日本語です", "日本…"},
+		{"Zero", 0, "hello", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, truncate(tt.n, tt.s), tt.want)
+		})
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 snippets"},
+		{1, "1 snippet"},
+		{2, "2 snippets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, pluralize(tt.n, "snippet", "snippets"), tt.want)
+		})
+	}
+}
+
+func TestBytesize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1 KB"},
+		{1536, "1.5 KB"},
+		{8 << 20, "8 MB"},
+		{3 << 30, "3 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, bytesize(tt.n), tt.want)
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"Emphasis", "Some *emphasis*", "<p>Some <em>emphasis</em></p>\n"},
+		{"Code", "`x := 1`", "<p><code>x := 1</code></p>\n"},
+		{"Escapes text", "a < b & c", "<p>a &lt; b &amp; c</p>\n"},
+		{"Drops raw HTML", "<script>alert(1)</script>", "<!-- raw HTML omitted -->\n"},
+		{"Strips javascript links", "[x](javascript:alert(1))", "<p><a href=\"\">x</a></p>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := markdown(tt.src)
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
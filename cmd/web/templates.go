@@ -1,69 +1,368 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"maps"
+	"path"
+	"slices"
+	"strings"
 	"text/template"
-	"time"
+	"text/template/parse"
 
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/captcha"
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
 	"github.com/FABLOUSFALCON/snippetbox/internal/models"
-	"github.com/FABLOUSFALCON/snippetbox/ui"
+	"github.com/FABLOUSFALCON/snippetbox/internal/scheduler"
+	"github.com/FABLOUSFALCON/snippetbox/internal/worker"
 )
 
 type templateData struct {
-	CurrentYear     int
-	Snippet         models.Snippet
-	Snippets        []models.Snippet
-	Form            any
-	Flash           string
-	IsAuthenticated bool
-	CSRFToken       string
-	User            models.User
+	BasePath            string
+	BaseURL             string
+	AbuseContact        string
+	SecurityTxt         bool
+	RequestID           string
+	Robots              string
+	CurrentYear         int
+	Snippet             models.Snippet
+	Snippets            []models.Snippet
+	Form                any
+	Flash               *flashMessage
+	IsAuthenticated     bool
+	AuthenticatedUserID int
+	CSRFToken           string
+	User                models.User
+	AuditEvents         []models.AuditEvent
+	Stats               models.SnippetStats
+	TOSVersion          int
+	Captcha             *captcha.Widget
+	Tokens              []models.Token
+	NewToken            string
+	Preferences         models.Preferences
+	Uptime              []uptimeWindow
+	Incidents           []models.Incident
+	Incident            models.Incident
+	Settings            models.Settings
+	BannerDismissed     bool
+	Export              models.Export
+	Locale              *i18n.Locale
+	Locales             []*i18n.Locale
+	Passkeys            []models.Passkey
+	Passkey             *passkeyOptions
+	LogRecords          []logbuffer.Record
+	LogLevels           []string
+	Captures            []requestCapture
+	Reports             []models.Report
+	Capture             requestCapture
+	CaptureEnabled      bool
+	Notifications       []notificationSetting
+	CacheStats          []cache.Stats
+	WorkerStats         []worker.Stats
+	BackgroundTasks     int
+	Jobs                []scheduler.Status
+	Emails              []models.QueuedEmail
+	MaxFormSize         string
+	MaxFieldSize        string
+	APIDocs             apiDocs
 }
 
-func humanDate(t time.Time) string {
-	if t.IsZero() {
-		return ""
+// newTemplateCache parses every page template. Templates found in
+// overrideDir, if set, take precedence over the embedded ones.
+func newTemplateCache(overrideDir string) (map[string]*template.Template, error) {
+	files, err := templateFS(overrideDir)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := parseTemplates(files)
+	if err != nil {
+		return nil, err
 	}
 
-	return t.UTC().Format("02 Jan 2006 at 15:04")
+	return set.pages, nil
 }
 
-var functions = template.FuncMap{
-	"humanDate": humanDate,
+// pageEntry is the template every page is executed from.
+const pageEntry = "base"
+
+// templateSet is the parsed page templates, keyed by file name such as
+// 'home.tmpl'. The base layout and the partials are parsed once into a root
+// that every page is cloned from, so the pages share their parse trees.
+// Each page records the files its templates come from, so that when files
+// change only the pages depending on them need to be parsed again.
+type templateSet struct {
+	files fs.FS
+	root  *template.Template
+	// defines maps each file to the names of the templates it defines, and
+	// rootOwners the templates of the root to the file defining them.
+	defines    map[string][]string
+	rootOwners map[string]string
+	pages      map[string]*template.Template
+	// uses maps each page to the templates it executes, directly or
+	// through other templates, and deps to the files defining them.
+	uses map[string][]string
+	deps map[string][]string
 }
 
-func newTemplateCache() (map[string]*template.Template, error) {
-	// Initialize a new map to act as the cache
-	cache := map[string]*template.Template{}
+// parseTemplates parses the base layout, the partials and every page in
+// files.
+func parseTemplates(files fs.FS) (*templateSet, error) {
+	s := &templateSet{
+		files:   files,
+		defines: make(map[string][]string),
+		pages:   make(map[string]*template.Template),
+		uses:    make(map[string][]string),
+		deps:    make(map[string][]string),
+	}
+
+	if err := s.parseRoot(); err != nil {
+		return nil, err
+	}
 
-	pages, err := fs.Glob(ui.Files, "html/pages/*.tmpl")
+	pages, err := fs.Glob(files, "pages/*.tmpl")
 	if err != nil {
 		return nil, fmt.Errorf("finding page templates failed: %w", err)
 	}
 
-	// Loop through the page filepaths one-by-one.
 	for _, page := range pages {
-		// Extract the file name (like 'home.tmpl') from the full filepath
-		// and assign it to the name variable.
-		name := filepath.Base(page)
+		if err := s.parsePage(page); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// isPageFile reports whether file is a page rather than part of the root.
+func isPageFile(file string) bool {
+	return strings.HasPrefix(file, "pages/")
+}
+
+// parseRoot parses the base layout and the partials into a new root.
+func (s *templateSet) parseRoot() error {
+	partials, err := fs.Glob(s.files, "partials/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("finding partial templates failed: %w", err)
+	}
+
+	for file := range s.defines {
+		if !isPageFile(file) {
+			delete(s.defines, file)
+		}
+	}
+
+	// The root is named after its first file, as ParseFS would. Were it
+	// named after a template the files define, such as "base", clones would
+	// get the empty root in place of that template.
+	root := template.New("base.tmpl").Funcs(functions)
+	s.rootOwners = make(map[string]string)
+
+	// Later files win when two define the same template, as with ParseFS.
+	for _, file := range append([]string{"base.tmpl"}, partials...) {
+		if err := s.parseFile(root, file); err != nil {
+			return err
+		}
+
+		for _, name := range s.defines[file] {
+			s.rootOwners[name] = file
+		}
+	}
+
+	s.root = root
+
+	return nil
+}
+
+// parsePage parses the page in file into a clone of the root.
+func (s *templateSet) parsePage(file string) error {
+	ts, err := s.root.Clone()
+	if err != nil {
+		return fmt.Errorf("cloning base templates failed: %w", err)
+	}
+
+	if err := s.parseFile(ts, file); err != nil {
+		return err
+	}
+
+	uses := executedTemplates(ts, pageEntry)
+
+	var deps []string
 
-		patterns := []string{
-			"html/base.tmpl",
-			"html/partials/*.tmpl",
-			page,
+	for _, name := range uses {
+		owner := s.rootOwners[name]
+		if slices.Contains(s.defines[file], name) {
+			owner = file
 		}
-		ts, err := template.New(name).Funcs(functions).ParseFS(ui.Files, patterns...)
-		if err != nil {
-			return nil, fmt.Errorf("parsing template failed: %w", err)
+
+		if owner != "" && !slices.Contains(deps, owner) {
+			deps = append(deps, owner)
 		}
+	}
+
+	slices.Sort(deps)
+
+	name := path.Base(file)
+	s.pages[name] = ts
+	s.uses[name] = uses
+	s.deps[name] = deps
+
+	return nil
+}
+
+// parseFile parses file into ts and records the templates it defines.
+func (s *templateSet) parseFile(ts *template.Template, file string) error {
+	b, err := fs.ReadFile(s.files, file)
+	if err != nil {
+		return fmt.Errorf("reading template failed: %w", err)
+	}
+
+	if _, err := ts.New(path.Base(file)).Parse(string(b)); err != nil {
+		return fmt.Errorf("parsing template failed: %w", err)
+	}
+
+	// Parsing the file on its own tells which templates it defines; ts
+	// already checked that the functions it calls exist.
+	tree := parse.New(file)
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
 
-		// Add the template set to the map, using the name of the page
-		// (like 'home.tmpl') as the key.
-		cache[name] = ts
+	if _, err := tree.Parse(string(b), "", "", trees); err != nil {
+		return fmt.Errorf("parsing template failed: %w", err)
 	}
 
-	// Return the map
-	return cache, nil
+	names := slices.Sorted(maps.Keys(trees))
+	s.defines[file] = slices.DeleteFunc(names, func(name string) bool { return name == file })
+
+	return nil
+}
+
+// reload parses the changed files, given by their paths in the template file
+// system, again along with the pages depending on them, and returns the
+// names of the pages that were parsed. Pages whose files were deleted are
+// dropped. On error the set is left as it was.
+func (s *templateSet) reload(changed []string) ([]string, error) {
+	next := &templateSet{
+		files:      s.files,
+		root:       s.root,
+		defines:    maps.Clone(s.defines),
+		rootOwners: s.rootOwners,
+		pages:      maps.Clone(s.pages),
+		uses:       maps.Clone(s.uses),
+		deps:       maps.Clone(s.deps),
+	}
+
+	var stale []string
+
+	// redefined holds the templates that changed root files define now, in
+	// case a page executes one that used to come from another file.
+	redefined := make(map[string]bool)
+
+	if slices.ContainsFunc(changed, func(file string) bool { return !isPageFile(file) }) {
+		if err := next.parseRoot(); err != nil {
+			return nil, err
+		}
+
+		for _, file := range changed {
+			for _, name := range next.defines[file] {
+				redefined[name] = true
+			}
+		}
+	}
+
+	for page := range s.pages {
+		if slices.ContainsFunc(s.deps[page], func(file string) bool { return slices.Contains(changed, file) }) ||
+			slices.ContainsFunc(s.uses[page], func(name string) bool { return redefined[name] }) {
+			stale = append(stale, "pages/"+page)
+		}
+	}
+
+	for _, file := range changed {
+		if isPageFile(file) && path.Ext(file) == ".tmpl" && !slices.Contains(stale, file) {
+			stale = append(stale, file)
+		}
+	}
+
+	slices.Sort(stale)
+
+	var reloaded []string
+
+	for _, file := range stale {
+		name := path.Base(file)
+
+		if _, err := fs.Stat(next.files, file); errors.Is(err, fs.ErrNotExist) {
+			delete(next.pages, name)
+			delete(next.uses, name)
+			delete(next.deps, name)
+			delete(next.defines, file)
+
+			continue
+		}
+
+		if err := next.parsePage(file); err != nil {
+			return nil, err
+		}
+
+		reloaded = append(reloaded, name)
+	}
+
+	*s = *next
+
+	return reloaded, nil
+}
+
+// executedTemplates returns the names of the templates executing entry in
+// ts runs, entry included, sorted.
+func executedTemplates(ts *template.Template, entry string) []string {
+	seen := make(map[string]bool)
+
+	var visit func(name string)
+
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+
+		seen[name] = true
+
+		t := ts.Lookup(name)
+		if t == nil || t.Tree == nil {
+			return
+		}
+
+		walkTemplateCalls(t.Tree.Root, visit)
+	}
+
+	visit(entry)
+
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// walkTemplateCalls calls fn with the name of every template invoked under
+// node.
+func walkTemplateCalls(node parse.Node, fn func(name string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			walkTemplateCalls(child, fn)
+		}
+	case *parse.TemplateNode:
+		fn(n.Name)
+	case *parse.IfNode:
+		walkTemplateCalls(n.List, fn)
+		walkTemplateCalls(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkTemplateCalls(n.List, fn)
+		walkTemplateCalls(n.ElseList, fn)
+	case *parse.WithNode:
+		walkTemplateCalls(n.List, fn)
+		walkTemplateCalls(n.ElseList, fn)
+	}
 }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/ui"
+)
+
+// templateWatchInterval is how often the override directory is checked for
+// changes in debug mode.
+const templateWatchInterval = time.Second
+
+// overlayFS serves files from upper when they exist there and from lower
+// otherwise. Directory listings are merged, so an override directory only
+// needs to contain the templates it replaces or adds.
+type overlayFS struct {
+	upper fs.FS
+	lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if err == nil {
+		info, err := f.Stat()
+		if err == nil && !info.IsDir() {
+			return f, nil
+		}
+
+		f.Close()
+	}
+
+	return o.lower.Open(name) //nolint:wrapcheck // fs.FS implementations return *fs.PathError as is
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	lower, lowerErr := fs.ReadDir(o.lower, name)
+	upper, upperErr := fs.ReadDir(o.upper, name)
+
+	if lowerErr != nil && upperErr != nil {
+		return nil, lowerErr //nolint:wrapcheck // see Open
+	}
+
+	entries := slices.Clone(upper)
+
+	for _, e := range lower {
+		if !slices.ContainsFunc(upper, func(u fs.DirEntry) bool { return u.Name() == e.Name() }) {
+			entries = append(entries, e)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// templateFS returns the file system templates are parsed from: the embedded
+// ui/html directory, overlaid with dir if one is given. Overrides use the
+// same layout, e.g. dir/pages/home.tmpl or dir/partials/nav.tmpl.
+func templateFS(dir string) (fs.FS, error) {
+	embedded, err := fs.Sub(ui.Files, "html")
+	if err != nil {
+		return nil, fmt.Errorf("opening embedded templates: %w", err)
+	}
+
+	if dir == "" {
+		return embedded, nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening template overrides: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template overrides: %s is not a directory", dir)
+	}
+
+	return overlayFS{upper: os.DirFS(dir), lower: embedded}, nil
+}
+
+// templateVersions returns the size and modification time of every file
+// in fsys, keyed by path, so that comparing two results tells which files
+// changed.
+func templateVersions(fsys fs.FS) (map[string]string, error) {
+	versions := make(map[string]string)
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		versions[path] = fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning templates: %w", err)
+	}
+
+	return versions, nil
+}
+
+// changedFiles returns the paths whose versions differ between before and
+// after, including added and removed files, sorted.
+func changedFiles(before, after map[string]string) []string {
+	var changed []string
+
+	for path, v := range after {
+		if before[path] != v {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+// watchTemplates reparses templates whenever their files change, so edits
+// can be previewed without a restart: those in the override directory dir,
+// and in development builds the built-in ones too. Only the pages depending
+// on the changed files are parsed again. It is only used in debug mode and
+// returns when ctx is done.
+func (app *application) watchTemplates(ctx context.Context, dir string, interval time.Duration) {
+	files, err := templateFS(dir)
+	if err != nil {
+		app.logger.Error(err.Error())
+
+		return
+	}
+
+	set, err := parseTemplates(files)
+	if err != nil {
+		app.logger.Error(err.Error())
+
+		return
+	}
+
+	versions, err := templateVersions(files)
+	if err != nil {
+		app.logger.Error(err.Error())
+	}
+
+	var pending []string
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := templateVersions(files)
+		if err != nil {
+			app.logger.Error(err.Error())
+
+			continue
+		}
+
+		changed := changedFiles(versions, current)
+		if len(changed) == 0 {
+			continue
+		}
+
+		versions = current
+
+		// Files changed while another one was broken are reloaded together
+		// with the fix.
+		for _, file := range changed {
+			if !slices.Contains(pending, file) {
+				pending = append(pending, file)
+			}
+		}
+
+		reloaded, err := set.reload(pending)
+		if err != nil {
+			// Keep serving the previous templates until the file is fixed.
+			app.logger.Error(err.Error())
+
+			continue
+		}
+
+		pages := set.pages
+		app.templateCache.Store(&pages)
+		app.logger.Info("reloaded templates", slog.Any("changed", pending), slog.Any("pages", reloaded))
+
+		pending = nil
+	}
+}
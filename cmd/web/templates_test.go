@@ -1,39 +1,149 @@
 package main
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
 )
 
-func TestHumanDate(t *testing.T) {
-	testCases := []struct {
-		name string
-		tm   time.Time
-		want string
-	}{
-		{
-			name: "UTC",
-			tm:   time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC),
-			want: "17 Mar 2024 at 10:15",
-		},
-		{
-			name: "Empty",
-			tm:   time.Time{},
-			want: "",
-		},
-		{
-			name: "CET",
-			tm:   time.Date(2024, 3, 17, 10, 15, 0, 0, time.FixedZone("CET", 1*60*60)),
-			want: "17 Mar 2024 at 09:15",
-		},
+func TestNewTemplateCacheOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile := func(name, content string) {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeFile("pages/about.tmpl", `{{define "title"}}About{{end}}{{define "main"}}Our own about page{{end}}`)
+	writeFile("partials/extra.tmpl", `{{define "extra"}}unused{{end}}`)
+
+	cache, err := newTemplateCache(dir)
+	assert.NilError(t, err)
+
+	render := func(page string) string {
+		t.Helper()
+
+		ts, ok := cache[page]
+		assert.Equal(t, ok, true)
+
+		var buf bytes.Buffer
+		assert.NilError(t, ts.ExecuteTemplate(&buf, "main", templateData{}))
+
+		return buf.String()
+	}
+
+	// Overridden pages replace the embedded ones, the rest are untouched.
+	assert.Equal(t, render("about.tmpl"), "Our own about page")
+	assert.StringContains(t, render("terms.tmpl"), "Terms of Service")
+
+	// Broken overrides are reported when the cache is built.
+	writeFile("base.tmpl", `{{define "base"}}{{end`)
+
+	_, err = newTemplateCache(dir)
+	assert.Equal(t, err != nil, true)
+
+	_, err = newTemplateCache(filepath.Join(dir, "missing"))
+	assert.Equal(t, err != nil, true)
+}
+
+func TestTemplateSetDependencies(t *testing.T) {
+	files, err := templateFS("")
+	assert.NilError(t, err)
+
+	set, err := parseTemplates(files)
+	assert.NilError(t, err)
+
+	assert.Equal(t, strings.Join(set.deps["signup.tmpl"], " "), "base.tmpl pages/signup.tmpl partials/captcha.tmpl partials/flash.tmpl partials/nav.tmpl")
+	assert.Equal(t, slices.Contains(set.deps["home.tmpl"], "partials/captcha.tmpl"), false)
+
+	// The base layout and partials are parsed once and shared by the pages.
+	assert.Equal(t, set.pages["home.tmpl"].Lookup("nav").Tree == set.pages["about.tmpl"].Lookup("nav").Tree, true)
+}
+
+func TestTemplateSetReload(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile := func(name, content string) {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
 	}
-	for _, tC := range testCases {
-		t.Run(tC.name, func(t *testing.T) {
-			hd := humanDate(tC.tm)
 
-			assert.Equal(t, hd, tC.want)
-		})
+	files, err := templateFS(dir)
+	assert.NilError(t, err)
+
+	set, err := parseTemplates(files)
+	assert.NilError(t, err)
+
+	pageCount := len(set.pages)
+	home := set.pages["home.tmpl"]
+
+	render := func(page, name string) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		assert.NilError(t, set.pages[page].ExecuteTemplate(&buf, name, templateData{}))
+
+		return buf.String()
 	}
+
+	// A partial only reparses the pages using it.
+	writeFile("partials/captcha.tmpl", `{{define "captcha"}}no robots{{end}}`)
+
+	reloaded, err := set.reload([]string{"partials/captcha.tmpl"})
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Join(reloaded, " "), "signup.tmpl")
+	assert.Equal(t, render("signup.tmpl", "captcha"), "no robots")
+	assert.Equal(t, set.pages["home.tmpl"] == home, true)
+
+	// The base layout reparses every page.
+	reloaded, err = set.reload([]string{"base.tmpl"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(reloaded), pageCount)
+
+	// Pages can be added, and a new partial reparses the pages calling a
+	// template it defines.
+	writeFile("pages/new.tmpl", `{{define "title"}}New{{end}}{{define "main"}}{{template "extra"}}{{end}}`)
+
+	reloaded, err = set.reload([]string{"pages/new.tmpl"})
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Join(reloaded, " "), "new.tmpl")
+
+	writeFile("partials/extra.tmpl", `{{define "extra"}}extra content{{end}}`)
+
+	reloaded, err = set.reload([]string{"partials/extra.tmpl"})
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Join(reloaded, " "), "new.tmpl")
+	assert.Equal(t, render("new.tmpl", "main"), "extra content")
+
+	// A broken file leaves the set as it was.
+	writeFile("partials/extra.tmpl", `{{define "extra"}}{{end`)
+
+	_, err = set.reload([]string{"partials/extra.tmpl"})
+	assert.Equal(t, err != nil, true)
+	assert.Equal(t, render("new.tmpl", "main"), "extra content")
+
+	// Deleted pages are dropped.
+	assert.NilError(t, os.Remove(filepath.Join(dir, "pages/new.tmpl")))
+
+	_, err = set.reload([]string{"pages/new.tmpl"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(set.pages), pageCount)
+}
+
+func TestChangedFiles(t *testing.T) {
+	before := map[string]string{"base.tmpl": "1", "pages/a.tmpl": "1", "pages/b.tmpl": "1"}
+	after := map[string]string{"base.tmpl": "1", "pages/a.tmpl": "2", "pages/c.tmpl": "1"}
+
+	assert.Equal(t, strings.Join(changedFiles(before, after), " "), "pages/a.tmpl pages/b.tmpl pages/c.tmpl")
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"html"
 	"io"
@@ -11,10 +12,19 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"regexp"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/FABLOUSFALCON/snippetbox/internal/cache"
+	"github.com/FABLOUSFALCON/snippetbox/internal/logbuffer"
+	"github.com/FABLOUSFALCON/snippetbox/internal/mailer"
 	"github.com/FABLOUSFALCON/snippetbox/internal/models/mocks"
+	"github.com/FABLOUSFALCON/snippetbox/internal/ratelimit"
+	"github.com/FABLOUSFALCON/snippetbox/internal/scheduler"
+	"github.com/FABLOUSFALCON/snippetbox/internal/webauthn"
+	"github.com/FABLOUSFALCON/snippetbox/internal/worker"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
 )
@@ -35,7 +45,12 @@ func extractCSRFToken(t *testing.T, body string) string {
 func newTestApplication(t *testing.T) *application {
 	t.Helper()
 
-	templateCache, err := newTemplateCache()
+	templateCache, err := newTemplateCache("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emailTemplates, err := newEmailTemplates()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -46,14 +61,116 @@ func newTestApplication(t *testing.T) *application {
 	sessionManager.Lifetime = 12 * time.Hour
 	sessionManager.Cookie.Secure = true
 
-	return &application{
-		logger:         slog.New(slog.DiscardHandler),
+	logs := logbuffer.New(100)
+
+	app := &application{
+		logger:         slog.New(requestIDHandler{logs.Handler(slog.DiscardHandler)}),
+		logs:           logs,
+		tosVersion:     1,
+		reauthWindow:   15 * time.Minute,
 		snippets:       &mocks.SnippetModel{},
 		users:          &mocks.UserModel{},
-		templateCache:  templateCache,
+		audit:          &mocks.AuditModel{},
+		analytics:      &mocks.AnalyticsModel{},
+		tokens:         &mocks.TokenModel{},
+		prefs:          &mocks.PreferencesModel{},
+		status:         &mocks.StatusModel{},
+		uptimeCache:    cache.New(cache.NewMemory(1), "uptime"),
+		settings:       &mocks.SettingsModel{},
+		exports:        &mocks.ExportModel{},
+		passkeys:       &mocks.PasskeyModel{},
+		notifications:  &mocks.NotificationModel{},
+		webhooks:       &mocks.WebhookModel{},
+		reports:        &mocks.ReportModel{},
+		tx:             &mocks.TxRunner{},
+		webhookClient:  &http.Client{Transport: &testWebhookReceiver{}},
+		emailTemplates: emailTemplates,
+		mailer:         &testMailer{},
+		emailQueue:     newMemoryQueue(),
+		baseURL:        "https://snippetbox.test",
+		headers:        defaultHeaders,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		loginThrottle:  newLoginThrottle(3, time.Minute, time.Hour),
+		tokenLimiter:   ratelimit.New(1, 5),
+		adminLimiter:   ratelimit.New(1, 5),
+		pingDB:         func(context.Context) error { return nil },
+		missingTables:  func(context.Context) ([]string, error) { return nil, nil },
+		started:        time.Now(),
+		feed:           newSnippetFeed(),
+		workers:        worker.New(slog.New(slog.DiscardHandler)),
+		scheduler:      scheduler.New(slog.New(slog.DiscardHandler)),
+	}
+
+	app.templateCache.Store(&templateCache)
+
+	app.webauthn, err = webauthn.New("Snippetbox", app.baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.cookies, err = newCookieCodec("test cookie secret")
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	return app
+}
+
+// testMailer records sent messages instead of delivering them.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, msg)
+
+	return nil
+}
+
+func (m *testMailer) messages() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.sent)
+}
+
+// testWebhookReceiver records webhook deliveries instead of sending them,
+// answering each with status, or 204 if it is zero.
+type testWebhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rt *testWebhookReceiver) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.requests = append(rt.requests, r)
+	rt.bodies = append(rt.bodies, string(body))
+
+	status := cmp.Or(rt.status, http.StatusNoContent)
+
+	return &http.Response{StatusCode: status, Body: http.NoBody, Header: make(http.Header), Request: r}, nil
+}
+
+// deliveries returns the requests received so far and their bodies.
+func (rt *testWebhookReceiver) deliveries() ([]*http.Request, []string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return slices.Clone(rt.requests), slices.Clone(rt.bodies)
 }
 
 type testServer struct {
@@ -82,6 +199,17 @@ func newTestServer(t *testing.T, h http.Handler) *testServer {
 func (ts *testServer) get(t *testing.T, urlPath string) (int, http.Header, string) {
 	t.Helper()
 
+	return ts.getWithHeaders(t, urlPath, nil)
+}
+
+// getWithHeaders is like get but sends the given request headers as well.
+func (ts *testServer) getWithHeaders(
+	t *testing.T,
+	urlPath string,
+	header http.Header,
+) (int, http.Header, string) {
+	t.Helper()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -95,6 +223,10 @@ func (ts *testServer) get(t *testing.T, urlPath string) (int, http.Header, strin
 		t.Fatal(err)
 	}
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
 	rs, err := ts.Client().Do(req)
 	if err != nil {
 		t.Fatal(err)
@@ -111,7 +243,6 @@ func (ts *testServer) get(t *testing.T, urlPath string) (int, http.Header, strin
 	return rs.StatusCode, rs.Header, string(body)
 }
 
-//nolint:unparam //unparam http.Header is kept for future extensibility
 func (ts *testServer) postForm(
 	t *testing.T,
 	urlPath string,
@@ -119,6 +250,19 @@ func (ts *testServer) postForm(
 ) (int, http.Header, string) {
 	t.Helper()
 
+	return ts.postFormFrom(t, urlPath, urlPath, form)
+}
+
+// postFormFrom is postForm for a form on the page at pagePath, which is sent
+// as the Referer like a browser would.
+func (ts *testServer) postFormFrom(
+	t *testing.T,
+	pagePath string,
+	urlPath string,
+	form url.Values,
+) (int, http.Header, string) {
+	t.Helper()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -132,7 +276,7 @@ func (ts *testServer) postForm(
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// Simulate browser behavior so nosurf's referer check passes.
-	req.Header.Set("Referer", ts.URL+urlPath)
+	req.Header.Set("Referer", ts.URL+pagePath)
 
 	rs, err := ts.Client().Do(req)
 	if err != nil {
@@ -149,3 +293,22 @@ func (ts *testServer) postForm(
 
 	return rs.StatusCode, rs.Header, string(body)
 }
+
+// login signs the test server's client in with the given credentials, so that
+// later requests made through ts carry an authenticated session cookie.
+func (ts *testServer) login(t *testing.T, email, password string) {
+	t.Helper()
+
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", email)
+	form.Add("password", password)
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ := ts.postForm(t, "/user/login", form)
+	if code != http.StatusSeeOther {
+		t.Fatalf("login as %s failed with status %d", email, code)
+	}
+}
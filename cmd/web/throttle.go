@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// loginThrottle slows down password guessing against a single account. It
+// is keyed by email address rather than client IP, so credential stuffing
+// spread across many IPs is throttled as well. After freeAttempts
+// consecutive failures every further failure locks the account out for an
+// exponentially growing delay, capped at maxDelay.
+type loginThrottle struct {
+	mu           sync.Mutex
+	entries      map[string]*throttleEntry
+	freeAttempts int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	lastSweep    time.Time
+	now          func() time.Time
+}
+
+type throttleEntry struct {
+	failures    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+func newLoginThrottle(freeAttempts int, baseDelay, maxDelay time.Duration) *loginThrottle {
+	return &loginThrottle{
+		entries:      make(map[string]*throttleEntry),
+		freeAttempts: freeAttempts,
+		baseDelay:    baseDelay,
+		maxDelay:     maxDelay,
+		now:          time.Now,
+	}
+}
+
+func throttleKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Wait returns how long the caller must wait before another login attempt
+// for email is allowed. Zero means an attempt may be made now.
+func (lt *loginThrottle) Wait(email string) time.Duration {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	e, ok := lt.entries[throttleKey(email)]
+	if !ok {
+		return 0
+	}
+
+	wait := e.lockedUntil.Sub(lt.now())
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// Failure records a failed attempt for email and returns the resulting
+// lockout, if any.
+func (lt *loginThrottle) Failure(email string) time.Duration {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	now := lt.now()
+	lt.sweep(now)
+
+	key := throttleKey(email)
+
+	e, ok := lt.entries[key]
+	if !ok {
+		e = &throttleEntry{}
+		lt.entries[key] = e
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	excess := e.failures - lt.freeAttempts
+	if excess <= 0 {
+		return 0
+	}
+
+	delay := lt.maxDelay
+	// Guard the shift so large failure counts cannot overflow.
+	if excess < 32 {
+		if d := lt.baseDelay << (excess - 1); d > 0 && d < lt.maxDelay {
+			delay = d
+		}
+	}
+
+	e.lockedUntil = now.Add(delay)
+
+	return delay
+}
+
+// Success clears the failure history for email.
+func (lt *loginThrottle) Success(email string) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	delete(lt.entries, throttleKey(email))
+}
+
+// sweep drops entries whose lockout has expired and that have been quiet for
+// longer than maxDelay, so the map cannot grow without bound. It runs at most
+// once per maxDelay and must be called with mu held.
+func (lt *loginThrottle) sweep(now time.Time) {
+	if now.Sub(lt.lastSweep) < lt.maxDelay {
+		return
+	}
+
+	lt.lastSweep = now
+
+	for key, e := range lt.entries {
+		if now.After(e.lockedUntil) && now.Sub(e.lastFailure) > lt.maxDelay {
+			delete(lt.entries, key)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	lt := newLoginThrottle(2, time.Second, 10*time.Second)
+	lt.now = func() time.Time { return now }
+
+	const email = "Bob@Example.com"
+
+	// The first failures are free.
+	assert.Equal(t, lt.Failure(email), time.Duration(0))
+	assert.Equal(t, lt.Failure(email), time.Duration(0))
+	assert.Equal(t, lt.Wait(email), time.Duration(0))
+
+	// Then the delay doubles with every failure...
+	assert.Equal(t, lt.Failure(email), time.Second)
+	assert.Equal(t, lt.Failure(email), 2*time.Second)
+	assert.Equal(t, lt.Failure(email), 4*time.Second)
+	assert.Equal(t, lt.Failure(email), 8*time.Second)
+
+	// ...up to the cap.
+	assert.Equal(t, lt.Failure(email), 10*time.Second)
+	assert.Equal(t, lt.Failure(email), 10*time.Second)
+
+	// The lockout applies regardless of email case.
+	assert.Equal(t, lt.Wait("bob@example.com"), 10*time.Second)
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, lt.Wait(email), 6*time.Second)
+
+	now = now.Add(6 * time.Second)
+	assert.Equal(t, lt.Wait(email), time.Duration(0))
+
+	// Other accounts are unaffected.
+	assert.Equal(t, lt.Wait("alice@example.com"), time.Duration(0))
+
+	lt.Success(email)
+	assert.Equal(t, lt.Failure(email), time.Duration(0))
+}
+
+func TestLoginThrottleSweep(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	lt := newLoginThrottle(1, time.Second, time.Minute)
+	lt.now = func() time.Time { return now }
+
+	lt.Failure("old@example.com")
+
+	now = now.Add(2 * time.Minute)
+	lt.Failure("new@example.com")
+
+	_, ok := lt.entries["old@example.com"]
+	assert.Equal(t, ok, false)
+
+	_, ok = lt.entries["new@example.com"]
+	assert.Equal(t, ok, true)
+}
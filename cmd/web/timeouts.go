@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"time"
+)
+
+// handlerTimeoutMessage is the body of the 503 sent when a handler runs
+// longer than -handler-timeout.
+const handlerTimeoutMessage = "The server took too long to respond. Please try again."
+
+type timeoutConfig struct {
+	read    time.Duration
+	write   time.Duration
+	idle    time.Duration
+	handler time.Duration
+}
+
+// timeoutFlags registers the flags for the main server's connection timeouts
+// and for the deadline of dynamic handlers.
+func timeoutFlags(cfg *timeoutConfig) {
+	flag.DurationVar(&cfg.read, "read-timeout", 5*time.Second, "Maximum time to read a request, including its body")
+	flag.DurationVar(&cfg.write, "write-timeout", 10*time.Second, "Maximum time to write a response")
+	flag.DurationVar(&cfg.idle, "idle-timeout", time.Minute, "How long keep-alive connections are kept open between requests")
+	flag.DurationVar(&cfg.handler, "handler-timeout", 8*time.Second, "Maximum time dynamic pages and the API may take before answering 503; keep it below -write-timeout (0 disables)")
+}
+
+// validate rejects a handler timeout that the write timeout would cut off
+// first, which would drop the connection instead of sending the 503.
+func (cfg timeoutConfig) validate() error {
+	if cfg.handler > 0 && cfg.write > 0 && cfg.handler >= cfg.write {
+		return errors.New("-handler-timeout must be shorter than -write-timeout")
+	}
+
+	return nil
+}
+
+// timeout answers 503 when next has not finished within the handler
+// timeout. The request context is cancelled at the same moment, so database
+// queries made with it are abandoned rather than left to hold a connection.
+func (app *application) timeout(next http.Handler) http.Handler {
+	if app.handlerTimeout <= 0 {
+		return next
+	}
+
+	return http.TimeoutHandler(next, app.handlerTimeout, handlerTimeoutMessage)
+}
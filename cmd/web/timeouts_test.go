@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestTimeoutConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     timeoutConfig
+		wantErr bool
+	}{
+		{"Defaults", timeoutConfig{write: 10 * time.Second, handler: 8 * time.Second}, false},
+		{"Handler disabled", timeoutConfig{write: 10 * time.Second}, false},
+		{"No write timeout", timeoutConfig{handler: time.Minute}, false},
+		{"Equal", timeoutConfig{write: 10 * time.Second, handler: 10 * time.Second}, true},
+		{"Longer", timeoutConfig{write: 10 * time.Second, handler: time.Minute}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.cfg.validate() != nil, tt.wantErr)
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	// slow blocks until the request context is cancelled, like a handler
+	// waiting on a hung database query.
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			w.Write([]byte("too late"))
+		}
+	})
+
+	fast := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		handler  http.Handler
+		wantCode int
+		wantBody string
+	}{
+		{"Fast", 50 * time.Millisecond, fast, http.StatusOK, "OK"},
+		{"Slow", 50 * time.Millisecond, slow, http.StatusServiceUnavailable, handlerTimeoutMessage},
+		{"Disabled", 0, fast, http.StatusOK, "OK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{handlerTimeout: tt.timeout}
+
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			app.timeout(tt.handler).ServeHTTP(rr, r)
+
+			assert.Equal(t, rr.Code, tt.wantCode)
+			assert.Equal(t, rr.Body.String(), tt.wantBody)
+		})
+	}
+}
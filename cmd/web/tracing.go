@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this application.
+const tracerName = "github.com/FABLOUSFALCON/snippetbox"
+
+// tracingConfig configures OpenTelemetry tracing. Spans are exported over
+// OTLP/HTTP, which Jaeger, Tempo and the OpenTelemetry Collector accept.
+type tracingConfig struct {
+	endpoint    string
+	serviceName string
+	sampleRatio float64
+}
+
+// tracingFlags registers the tracing flags. They default to the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_SERVICE_NAME variables.
+func tracingFlags(cfg *tracingConfig) {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "snippetbox"
+	}
+
+	flag.StringVar(&cfg.endpoint, "otel-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "OTLP/HTTP collector URL to send traces to, e.g. http://localhost:4318 (or OTEL_EXPORTER_OTLP_ENDPOINT env; empty disables tracing)")
+	flag.StringVar(&cfg.serviceName, "otel-service-name", serviceName, "Service name reported in traces (or OTEL_SERVICE_NAME env)")
+	flag.Float64Var(&cfg.sampleRatio, "otel-sample-ratio", 1, "Fraction of new traces to sample, from 0 to 1; traces started by callers follow their decision")
+}
+
+func (c tracingConfig) enabled() bool {
+	return c.endpoint != ""
+}
+
+// otlpTracesURL turns a collector base URL into the URL traces are posted
+// to, adding the standard /v1/traces path unless a path is given.
+func otlpTracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parsing -otel-endpoint: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("-otel-endpoint %q must be an http or https URL", endpoint)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+
+	return u.String(), nil
+}
+
+// startTracing installs a tracer provider exporting to cfg.endpoint as the
+// global one and returns a tracer for the application, along with a
+// function that flushes pending spans on shutdown.
+func startTracing(cfg tracingConfig, logger *slog.Logger) (trace.Tracer, func(), error) {
+	if cfg.sampleRatio < 0 || cfg.sampleRatio > 1 {
+		return nil, nil, errors.New("-otel-sample-ratio must be between 0 and 1")
+	}
+
+	endpoint, err := otlpTracesURL(cfg.endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := context.Background()
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.serviceName),
+			semconv.ServiceVersion(buildVersion()),
+		),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.sampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("tracing error", slog.String("err", err.Error()))
+	}))
+
+	logger.Info("tracing enabled", slog.String("endpoint", endpoint), slog.Float64("sample_ratio", cfg.sampleRatio))
+
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := tp.Shutdown(ctx); err != nil {
+			logger.Error("flushing traces", slog.String("err", err.Error()))
+		}
+	}
+
+	return tp.Tracer(tracerName), shutdown, nil
+}
+
+// traceRequest records a server span for every request, continuing the
+// caller's trace if the request carries a traceparent header. Like
+// trackLatency it must wrap the ServeMux directly to learn the route.
+func (app *application) traceRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := app.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+				semconv.ClientAddress(clientIPKey(app.clientIP(r))),
+			),
+		)
+		defer span.End()
+
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+
+		if _, route, ok := strings.Cut(r.Pattern, " "); ok {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		if rl, ok := r.Context().Value(requestLogContextKey).(*requestLog); ok {
+			status, _, _ := rl.result()
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}
+	})
+}
+
+// dbTracer records a client span for every query. Queries made with a
+// request's context become children of its span.
+type dbTracer struct {
+	tracer trace.Tracer
+}
+
+func (t dbTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := queryOperation(data.SQL)
+
+	ctx, _ = t.tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(op),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+
+	return ctx
+}
+
+func (t dbTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+
+	// No rows is an expected outcome, e.g. for a missing snippet.
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+
+	span.End()
+}
+
+// queryOperation returns the SQL keyword a query starts with, e.g. SELECT.
+func queryOperation(sql string) string {
+	op := strings.TrimSpace(sql)
+	if i := strings.IndexFunc(op, unicode.IsSpace); i >= 0 {
+		op = op[:i]
+	}
+
+	return strings.ToUpper(op)
+}
+
+// combineTracers merges the non-nil query tracers into one, or returns nil
+// if there are none.
+func combineTracers(tracers ...pgx.QueryTracer) pgx.QueryTracer {
+	var active []pgx.QueryTracer
+
+	for _, t := range tracers {
+		if t != nil {
+			active = append(active, t)
+		}
+	}
+
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	default:
+		return multitracer.New(active...)
+	}
+}
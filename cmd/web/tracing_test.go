@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer returns a tracer whose finished spans are kept in the
+// returned recorder.
+func newTestTracer(t *testing.T) (trace.Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return tp.Tracer(tracerName), recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestOtlpTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{endpoint: "http://localhost:4318", want: "http://localhost:4318/v1/traces"},
+		{endpoint: "https://otel.example.com/", want: "https://otel.example.com/v1/traces"},
+		{endpoint: "https://otel.example.com/custom/traces", want: "https://otel.example.com/custom/traces"},
+		{endpoint: "localhost:4318", wantErr: true},
+		{endpoint: "grpc://localhost:4317", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := otlpTracesURL(tt.endpoint)
+
+			assert.Equal(t, err != nil, tt.wantErr)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestTraceRequest(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagator) })
+
+	app := newTestApplication(t)
+
+	var recorder *tracetest.SpanRecorder
+	app.tracer, recorder = newTestTracer(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	parent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	code, _, _ := ts.getWithHeaders(t, "/snippet/view/1", http.Header{"Traceparent": {parent}})
+	assert.Equal(t, code, http.StatusOK)
+
+	code, _, _ = ts.get(t, "/snippet/view/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	spans := recorder.Ended()
+	assert.Equal(t, len(spans), 2)
+
+	view := spans[0]
+	assert.Equal(t, view.Name(), "GET /snippet/view/{id}")
+	assert.Equal(t, view.SpanKind(), trace.SpanKindServer)
+	assert.Equal(t, view.Parent().TraceID().String(), "0af7651916cd43dd8448eb211c80319c")
+	assert.Equal(t, spanAttr(view, "http.route").AsString(), "/snippet/view/{id}")
+	assert.Equal(t, spanAttr(view, "http.response.status_code").AsInt64(), int64(http.StatusOK))
+
+	missing := spans[1]
+	assert.Equal(t, missing.Parent().IsValid(), false)
+	assert.Equal(t, spanAttr(missing, "http.response.status_code").AsInt64(), int64(http.StatusNotFound))
+	assert.Equal(t, missing.Status().Code, codes.Unset)
+}
+
+func TestDBTracer(t *testing.T) {
+	tracer, recorder := newTestTracer(t)
+	db := dbTracer{tracer: tracer}
+
+	ctx, parent := tracer.Start(context.Background(), "GET /{$}")
+
+	queries := []struct {
+		sql string
+		err error
+	}{
+		{sql: "\n\t\tSELECT id, title FROM snippets\n\t\tWHERE id = $1", err: pgx.ErrNoRows},
+		{sql: "INSERT INTO snippets (title) VALUES ($1)", err: errors.New("unique violation")},
+	}
+
+	for _, q := range queries {
+		qctx := db.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: q.sql})
+		db.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{Err: q.err})
+	}
+
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, len(spans), 3)
+
+	selectSpan, insertSpan := spans[0], spans[1]
+
+	assert.Equal(t, selectSpan.Name(), "SELECT")
+	assert.Equal(t, selectSpan.SpanKind(), trace.SpanKindClient)
+	assert.Equal(t, selectSpan.Parent().SpanID(), parent.SpanContext().SpanID())
+	assert.Equal(t, spanAttr(selectSpan, "db.system.name").AsString(), "postgresql")
+	assert.Equal(t, selectSpan.Status().Code, codes.Unset)
+
+	assert.Equal(t, insertSpan.Name(), "INSERT")
+	assert.Equal(t, insertSpan.Status().Code, codes.Error)
+	assert.Equal(t, insertSpan.Status().Description, "unique violation")
+}
+
+func TestCombineTracers(t *testing.T) {
+	tracer, _ := newTestTracer(t)
+	db := dbTracer{tracer: tracer}
+
+	assert.Equal(t, combineTracers(nil, nil), pgx.QueryTracer(nil))
+	assert.Equal(t, combineTracers(nil, db), pgx.QueryTracer(db))
+
+	if _, ok := combineTracers(db, nil, db).(*multitracer.Tracer); !ok {
+		t.Error("expected several tracers to be combined")
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+const (
+	// webhookTimeout bounds a whole delivery, including the response.
+	webhookTimeout = 10 * time.Second
+
+	// webhookPing is the event of test deliveries.
+	webhookPing = "ping"
+)
+
+// specialPrefixes are the special-purpose ranges from the IANA registries
+// that IsGlobalUnicast and IsPrivate let through but that are not on the
+// public internet, or that tunnel to addresses that may not be.
+var specialPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("5f00::/16"),       // segment routing
+}
+
+// webhookDelivery is the body of every webhook request.
+type webhookDelivery struct {
+	ID      string    `json:"id"`
+	Event   string    `json:"event"`
+	Created time.Time `json:"created"`
+	Data    any       `json:"data"`
+}
+
+// webhookSnippet is the data of a snippet.created delivery.
+type webhookSnippet struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// newWebhookClient returns the client that delivers webhooks. Their URLs
+// come from users, so it refuses to connect to loopback, private and other
+// non-public addresses, which would let anyone with a token probe services
+// behind the firewall. Checking the address when connecting also catches
+// host names that resolve to such addresses. Redirects are not followed.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err //nolint:wrapcheck // the address is all the context there is
+			}
+
+			if addr, err := netip.ParseAddr(host); err != nil || !publicAddr(addr) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // always a *http.Transport
+	transport.DialContext = dialer.DialContext
+	// A proxy would connect on the client's behalf, bypassing the check.
+	transport.Proxy = nil
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   webhookTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddr reports whether addr is a unicast address on the public
+// internet.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range specialPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// signWebhook returns the X-Snippetbox-Signature of a delivery body: the
+// hex-encoded HMAC-SHA256 of the body keyed with the webhook's secret.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook POSTs event and its data to hook and returns the status of
+// the response. A status of 300 or more is an error as well.
+func (app *application) deliverWebhook(ctx context.Context, hook models.Webhook, event string, data any) (int, error) {
+	delivery := webhookDelivery{ID: rand.Text(), Event: event, Created: time.Now().UTC(), Data: data}
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return 0, fmt.Errorf("encoding webhook delivery: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("creating webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Snippetbox-Webhook")
+	req.Header.Set("X-Snippetbox-Event", event)
+	req.Header.Set("X-Snippetbox-Delivery", delivery.ID)
+	req.Header.Set("X-Snippetbox-Signature", signWebhook(hook.Secret, body))
+
+	resp, err := app.webhookClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("delivering webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("delivering webhook: unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// dispatchWebhooks delivers event to the user's webhooks subscribed to it in
+// the background, logging failed deliveries. There are no retries.
+func (app *application) dispatchWebhooks(userID int, event string, data any) {
+	if userID == 0 {
+		return
+	}
+
+	app.workers.Background(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*webhookTimeout)
+		defer cancel()
+
+		hooks, err := app.webhooks.Subscribed(ctx, userID, event)
+		if err != nil {
+			app.logger.Error(err.Error(), slog.String("event", event), slog.Int("user_id", userID))
+
+			return
+		}
+
+		for _, hook := range hooks {
+			if _, err := app.deliverWebhook(ctx, hook, event, data); err != nil {
+				app.logger.Warn(err.Error(), slog.String("event", event), slog.Int("webhook_id", hook.ID))
+			}
+		}
+	})
+}
+
+// snippetCreated announces a new snippet to its owner's webhooks.
+func (app *application) snippetCreated(userID, id int, title string) {
+	app.dispatchWebhooks(userID, models.WebhookSnippetCreated, webhookSnippet{
+		ID:    id,
+		Title: title,
+		URL:   app.baseURL + "/snippet/view/" + strconv.Itoa(id),
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:4700::6810:84e5", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"192.168.0.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"192.0.0.8", false},
+		{"203.0.113.7", false},
+		{"64:ff9b::7f00:1", false},
+		{"2002:7f00:1::1", false},
+		{"2001:0:4136:e378::1", false},
+		{"2001:db8::1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, publicAddr(netip.MustParseAddr(tt.addr)), tt.want)
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// As computed by: printf '{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, signWebhook("secret", []byte("{}")),
+		"sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13")
+}
+
+func TestWebhookClientRefusesLoopback(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook was delivered to a loopback address")
+	}))
+	defer receiver.Close()
+
+	app := &application{webhookClient: newWebhookClient()}
+
+	hook := models.Webhook{URL: receiver.URL, Secret: "secret"}
+
+	_, err := app.deliverWebhook(context.Background(), hook, webhookPing, nil)
+	if err == nil {
+		t.Fatal("got no error delivering to a loopback address")
+	}
+
+	assert.StringContains(t, err.Error(), "is not public")
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/i18n"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+const (
+	// liveRoute is the pattern of the websocket endpoint for live updates.
+	liveRoute = "GET /ws"
+
+	// livePingInterval is how often an idle connection is pinged, and
+	// livePongTimeout how long the client has to answer before it is
+	// dropped.
+	livePingInterval = 30 * time.Second
+	livePongTimeout  = 10 * time.Second
+
+	// liveWriteTimeout bounds each write to a connection, so a client that
+	// stops reading is dropped instead of holding its goroutines forever.
+	liveWriteTimeout = 10 * time.Second
+
+	// liveReadLimit bounds the size of client messages, which are only
+	// ever small subscription requests.
+	liveReadLimit = 4096
+)
+
+// liveTopics are the topics a connection can subscribe to.
+var liveTopics = map[string]bool{
+	"snippets": true,
+}
+
+// liveRequest is a message from the client, e.g.
+// {"type":"subscribe","topic":"snippets"}.
+type liveRequest struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic"`
+}
+
+// liveMessage is a message to the client: a confirmation of a
+// (un)subscription, an error, or an event on a subscribed topic.
+type liveMessage struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic,omitempty"`
+	Error string `json:"error,omitempty"`
+	Data  any    `json:"data,omitempty"`
+}
+
+// liveSubscriptions is the set of topics one connection subscribed to.
+type liveSubscriptions struct {
+	mu     sync.Mutex
+	topics map[string]bool
+}
+
+func (s *liveSubscriptions) set(topic string, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics[topic] = on
+}
+
+func (s *liveSubscriptions) has(topic string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.topics[topic]
+}
+
+// live upgrades the request to a websocket that pushes events on the topics
+// the client subscribes to. Connections are pinged to detect dead peers and
+// closed with "going away" when the server shuts down.
+func (app *application) live(w http.ResponseWriter, r *http.Request) {
+	// Accept rejects cross-origin handshakes, so other sites cannot open a
+	// connection with the visitor's cookies.
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow() //nolint:errcheck // only reached when Close was not
+
+	conn.SetReadLimit(liveReadLimit)
+
+	// Hijacked connections are invisible to http.Server.Shutdown, so the
+	// shutdown waits for them like it does for background work.
+	defer app.workers.Track()()
+
+	snippets, unsubscribe := app.feed.subscribe()
+	defer unsubscribe()
+
+	if snippets == nil {
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+
+		return
+	}
+
+	locale, ok := i18n.Match(r.Header.Get("Accept-Language"))
+	if !ok {
+		locale = i18n.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+
+	subs := &liveSubscriptions{topics: make(map[string]bool)}
+
+	go func() {
+		defer cancel()
+
+		app.readLive(ctx, conn, subs)
+	}()
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			pingCtx, cancelPing := context.WithTimeout(ctx, livePongTimeout)
+			err := conn.Ping(pingCtx)
+			cancelPing()
+
+			if err != nil {
+				return
+			}
+		case s, ok := <-snippets:
+			if !ok {
+				_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+
+				return
+			}
+
+			if !subs.has("snippets") {
+				continue
+			}
+
+			msg := liveMessage{Type: "snippet", Topic: "snippets", Data: snippetEvent{
+				ID:      s.ID,
+				Title:   s.Title,
+				URL:     app.basePath + "/snippet/view/" + strconv.Itoa(s.ID),
+				Created: s.Created,
+				Date:    locale.FormatDate(s.Created),
+			}}
+
+			if err := writeLive(ctx, conn, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLive handles the client's subscription requests until the connection
+// closes.
+func (app *application) readLive(ctx context.Context, conn *websocket.Conn, subs *liveSubscriptions) {
+	for {
+		var req liveRequest
+		if err := wsjson.Read(ctx, conn, &req); err != nil {
+			status := websocket.CloseStatus(err)
+			if status == -1 && !errors.Is(err, context.Canceled) {
+				app.logger.Debug("websocket read failed", slog.String("err", err.Error()))
+			}
+
+			return
+		}
+
+		reply := liveMessage{Type: req.Type, Topic: req.Topic}
+
+		switch {
+		case req.Type != "subscribe" && req.Type != "unsubscribe":
+			reply = liveMessage{Type: "error", Error: "unknown message type"}
+		case !liveTopics[req.Topic]:
+			reply = liveMessage{Type: "error", Topic: req.Topic, Error: "unknown topic"}
+		default:
+			subs.set(req.Topic, req.Type == "subscribe")
+		}
+
+		if err := writeLive(ctx, conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// writeLive sends msg to the client within liveWriteTimeout. The websocket
+// library closes the connection if the write times out.
+func writeLive(ctx context.Context, conn *websocket.Conn, msg liveMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, liveWriteTimeout)
+	defer cancel()
+
+	return wsjson.Write(ctx, conn, msg) //nolint:wrapcheck // callers only check for failure
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+func TestLive(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, rs, err := websocket.Dial(ctx, "wss"+strings.TrimPrefix(ts.URL, "https")+"/ws", &websocket.DialOptions{
+		HTTPClient: ts.Client(),
+		HTTPHeader: http.Header{
+			"Accept-Encoding": {"gzip"},
+			"Accept-Language": {"de"},
+		},
+	})
+	assert.NilError(t, err)
+	defer conn.CloseNow() //nolint:errcheck // the server closes the connection
+
+	assert.Equal(t, rs.StatusCode, http.StatusSwitchingProtocols)
+
+	request := func(typ, topic string) liveMessage {
+		t.Helper()
+
+		assert.NilError(t, wsjson.Write(ctx, conn, liveRequest{Type: typ, Topic: topic}))
+
+		var reply liveMessage
+		assert.NilError(t, wsjson.Read(ctx, conn, &reply))
+
+		return reply
+	}
+
+	reply := request("subscribe", "comments")
+	assert.Equal(t, reply.Type, "error")
+	assert.Equal(t, reply.Error, "unknown topic")
+
+	reply = request("subscribe", "snippets")
+	assert.Equal(t, reply.Type, "subscribe")
+	assert.Equal(t, reply.Topic, "snippets")
+
+	app.feed.publish(models.Snippet{
+		ID:      7,
+		Title:   "Live <snippet>",
+		Created: time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC),
+	})
+
+	var event struct {
+		Type string       `json:"type"`
+		Data snippetEvent `json:"data"`
+	}
+
+	assert.NilError(t, wsjson.Read(ctx, conn, &event))
+	assert.Equal(t, event.Type, "snippet")
+	assert.Equal(t, event.Data.ID, 7)
+	assert.Equal(t, event.Data.Title, "Live <snippet>")
+	assert.Equal(t, event.Data.URL, "/snippet/view/7")
+	assert.StringContains(t, event.Data.Date, "17.03.2024")
+
+	// Unsubscribed connections get no events.
+	reply = request("unsubscribe", "snippets")
+	assert.Equal(t, reply.Type, "unsubscribe")
+
+	app.feed.publish(models.Snippet{ID: 8})
+
+	// Shutting down closes open connections as going away, so the next
+	// message is the close rather than the snippet.
+	app.feed.close()
+
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.CloseStatus(err), websocket.StatusGoingAway)
+}
@@ -0,0 +1,194 @@
+// Package assets builds minified, fingerprinted bundles of CSS and JavaScript
+// files. A bundle's output name contains a hash of its content, so it can be
+// cached forever, and a manifest maps each bundle to its current output.
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ManifestName is the name of the manifest within the output directory.
+const ManifestName = "manifest.json"
+
+// Bundle is one output file, built by concatenating the files matching
+// Patterns in order. Patterns are fs.Glob patterns; the matches of each are
+// sorted.
+type Bundle struct {
+	// Name is the path of the bundle as linked from pages, e.g.
+	// "css/main.css". Its extension selects the minifier.
+	Name     string
+	Patterns []string
+}
+
+// Manifest maps the name of each bundle to the path of its output, both
+// relative to the source root, e.g. "css/main.css" to
+// "dist/css/main.1a2b3c4d.css".
+type Manifest map[string]string
+
+// Build builds bundles from the files in src and returns the contents of
+// the outputs, keyed by their paths relative to src, along with the
+// manifest. Outputs are placed under outDir, also relative to src.
+func Build(src fs.FS, outDir string, bundles []Bundle) (map[string][]byte, Manifest, error) {
+	outputs := make(map[string][]byte, len(bundles))
+	manifest := make(Manifest, len(bundles))
+
+	for _, b := range bundles {
+		content, err := build(src, outDir, b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("building %s: %w", b.Name, err)
+		}
+
+		sum := sha256.Sum256(content)
+		ext := path.Ext(b.Name)
+		name := path.Join(outDir, strings.TrimSuffix(b.Name, ext)+"."+hex.EncodeToString(sum[:4])+ext)
+
+		outputs[name] = content
+		manifest[b.Name] = name
+	}
+
+	return outputs, manifest, nil
+}
+
+func build(src fs.FS, outDir string, b Bundle) ([]byte, error) {
+	var minify func([]byte) []byte
+
+	switch path.Ext(b.Name) {
+	case ".css":
+		minify = CSS
+	case ".js":
+		minify = JS
+	default:
+		return nil, fmt.Errorf("no minifier for %q", path.Ext(b.Name))
+	}
+
+	var files []string
+
+	for _, pattern := range b.Patterns {
+		matches, err := fs.Glob(src, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+
+		slices.Sort(matches)
+		files = append(files, matches...)
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no files match")
+	}
+
+	parts := make([][]byte, 0, len(files))
+
+	for _, file := range files {
+		content, err := fs.ReadFile(src, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+
+		if path.Ext(file) == ".css" {
+			content = rebaseURLs(content, path.Dir(file), path.Dir(path.Join(outDir, file)))
+		}
+
+		parts = append(parts, minify(content))
+	}
+
+	// A semicolon between scripts keeps one that ends without one from
+	// running into the next.
+	sep := []byte("\n")
+	if path.Ext(b.Name) == ".js" {
+		sep = []byte(";\n")
+	}
+
+	return bytes.Join(parts, sep), nil
+}
+
+// cssURLRe matches url() references in a stylesheet.
+var cssURLRe = regexp.MustCompile(`url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+
+// rebaseURLs rewrites the relative url() references of a stylesheet in
+// fromDir so they still point at the same files from toDir. Both are
+// relative to the source root.
+func rebaseURLs(css []byte, fromDir, toDir string) []byte {
+	return cssURLRe.ReplaceAllFunc(css, func(m []byte) []byte {
+		sub := cssURLRe.FindSubmatch(m)
+		quote, ref := string(sub[1]), string(sub[2])
+
+		if ref == "" || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") || strings.Contains(ref, ":") {
+			return m
+		}
+
+		target := path.Join(fromDir, ref)
+
+		up := ""
+		if toDir != "." {
+			up = strings.Repeat("../", strings.Count(toDir, "/")+1)
+		}
+
+		return []byte("url(" + quote + up + target + quote + ")")
+	})
+}
+
+// Write replaces the contents of outDir under the directory root with
+// outputs, as returned by Build, and the manifest, so outputs of earlier
+// builds do not pile up.
+func Write(root, outDir string, outputs map[string][]byte, manifest Manifest) error {
+	dir := filepath.Join(root, filepath.FromSlash(outDir))
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing old assets: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec // assets are public
+		return fmt.Errorf("creating asset directory: %w", err)
+	}
+
+	for name, content := range outputs {
+		file := filepath.Join(root, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil { //nolint:gosec // assets are public
+			return fmt.Errorf("creating asset directory: %w", err)
+		}
+
+		if err := os.WriteFile(file, content, 0o644); err != nil { //nolint:gosec // assets are public
+			return fmt.Errorf("writing asset: %w", err)
+		}
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(b, '\n'), 0o644); err != nil { //nolint:gosec // assets are public
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadManifest reads the manifest in outDir of fsys.
+func ReadManifest(fsys fs.FS, outDir string) (Manifest, error) {
+	b, err := fs.ReadFile(fsys, path.Join(outDir, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("reading asset manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parsing asset manifest: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestBuild(t *testing.T) {
+	src := fstest.MapFS{
+		"css/b.css":  {Data: []byte("b { background: url('../img/b.png') }")},
+		"css/a.css":  {Data: []byte("a { background: url(data:image/png;base64,AA==) }\n")},
+		"js/main.js": {Data: []byte("go() // no semicolon\n")},
+		"js/more.js": {Data: []byte("(function () {})()\n")},
+	}
+
+	bundles := []Bundle{
+		{Name: "css/main.css", Patterns: []string{"css/*.css"}},
+		{Name: "js/main.js", Patterns: []string{"js/*.js"}},
+	}
+
+	outputs, manifest, err := Build(src, "dist", bundles)
+	assert.NilError(t, err)
+
+	css := manifest["css/main.css"]
+	assert.Equal(t, strings.HasPrefix(css, "dist/css/main."), true)
+	assert.Equal(t, strings.HasSuffix(css, ".css"), true)
+	assert.Equal(t, string(outputs[css]), "a{background:url(data:image/png;base64,AA==)}\nb{background:url('../../img/b.png')}")
+
+	js := manifest["js/main.js"]
+	assert.Equal(t, string(outputs[js]), "go();\n(function(){})()")
+
+	// The name changes with the content.
+	src["js/more.js"] = &fstest.MapFile{Data: []byte("stop()")}
+
+	_, changed, err := Build(src, "dist", bundles)
+	assert.NilError(t, err)
+	assert.Equal(t, changed["css/main.css"], css)
+	assert.Equal(t, changed["js/main.js"] != js, true)
+
+	_, _, err = Build(src, "dist", []Bundle{{Name: "js/none.js", Patterns: []string{"none/*.js"}}})
+	assert.Equal(t, err != nil, true)
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, "dist", "css", "main.0000.css")
+	assert.NilError(t, os.MkdirAll(filepath.Dir(stale), 0o755))
+	assert.NilError(t, os.WriteFile(stale, nil, 0o600))
+
+	outputs := map[string][]byte{"dist/css/main.1234.css": []byte("a{}")}
+	manifest := Manifest{"css/main.css": "dist/css/main.1234.css"}
+
+	assert.NilError(t, Write(root, "dist", outputs, manifest))
+
+	_, err := os.Stat(stale)
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	got, err := ReadManifest(os.DirFS(root), "dist")
+	assert.NilError(t, err)
+	assert.Equal(t, got["css/main.css"], "dist/css/main.1234.css")
+}
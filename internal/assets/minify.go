@@ -0,0 +1,236 @@
+package assets
+
+import (
+	"bytes"
+	"strings"
+)
+
+// CSS minifies a stylesheet: it drops comments, collapses whitespace and
+// removes it where CSS ignores it, around braces, semicolons, commas and
+// child combinators and after colons. Strings are kept as they are.
+func CSS(src []byte) []byte {
+	var out bytes.Buffer
+
+	// space records whitespace seen since the last character written, which
+	// is only written if both neighbours need it.
+	space := false
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3
+			}
+
+			space = true
+
+			continue
+		case isSpace(c):
+			space = true
+
+			continue
+		}
+
+		if space && out.Len() > 0 && !strings.ContainsRune("{};,>:", rune(lastByte(&out))) && !strings.ContainsRune("{};,>", rune(c)) {
+			out.WriteByte(' ')
+		}
+
+		space = false
+
+		switch c {
+		case '"', '\'':
+			end := stringEnd(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		case '}':
+			// The last declaration of a block needs no semicolon.
+			if lastByte(&out) == ';' {
+				out.Truncate(out.Len() - 1)
+			}
+
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.Bytes()
+}
+
+// JS minifies a script conservatively: it drops comments and collapses
+// whitespace, removing it next to punctuation where that cannot change the
+// meaning. Line breaks are kept, so automatic semicolon insertion works as
+// before. Strings, template literals and regular expression literals are
+// kept as they are; template literals must not nest.
+func JS(src []byte) []byte {
+	var out bytes.Buffer
+
+	// space and newline record whitespace seen since the last character
+	// written.
+	space, newline := false, false
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end - 1
+			}
+
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+
+			if bytes.IndexByte(src[i:i+2+end], '\n') >= 0 {
+				newline = true
+			}
+
+			space = true
+			i += end + 3
+
+			continue
+		case c == '\n':
+			newline = true
+
+			continue
+		case isSpace(c):
+			space = true
+
+			continue
+		}
+
+		prev := lastByte(&out)
+
+		switch {
+		case out.Len() == 0:
+		case newline:
+			out.WriteByte('\n')
+		case space && !isJSPunct(prev) && !isJSPunct(c):
+			out.WriteByte(' ')
+		}
+
+		space, newline = false, false
+
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end := stringEnd(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		case c == '/' && regexAllowed(out.Bytes()):
+			end := regexEnd(src, i)
+			out.Write(src[i:end])
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.Bytes()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isJSPunct reports whether whitespace next to c can be dropped. Operators
+// such as + and - are left out, since "a + +b" must not become "a++b".
+func isJSPunct(c byte) bool {
+	return strings.IndexByte("{}()[];,=:<>!&|?", c) >= 0
+}
+
+func lastByte(b *bytes.Buffer) byte {
+	if b.Len() == 0 {
+		return 0
+	}
+
+	return b.Bytes()[b.Len()-1]
+}
+
+// stringEnd returns the index just past the string literal starting with
+// the quote at src[start].
+func stringEnd(src []byte, start int) int {
+	quote := src[start]
+
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+
+	return len(src)
+}
+
+// regexAllowed reports whether a slash following out starts a regular
+// expression rather than being a division, judging by the token before it.
+func regexAllowed(out []byte) bool {
+	out = bytes.TrimRight(out, " \n")
+	if len(out) == 0 {
+		return true
+	}
+
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", out[len(out)-1]) >= 0 {
+		return true
+	}
+
+	for _, keyword := range []string{"return", "typeof", "case", "do", "else", "in", "of", "void", "yield"} {
+		if bytes.HasSuffix(out, []byte(keyword)) {
+			rest := out[:len(out)-len(keyword)]
+			if len(rest) == 0 || !isIdentByte(rest[len(rest)-1]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// regexEnd returns the index just past the regular expression literal,
+// flags included, starting at src[start].
+func regexEnd(src []byte, start int) int {
+	inClass := false
+
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if inClass {
+				continue
+			}
+
+			i++
+			for i < len(src) && isIdentByte(src[i]) {
+				i++
+			}
+
+			return i
+		}
+	}
+
+	return len(src)
+}
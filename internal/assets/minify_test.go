@@ -0,0 +1,51 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"Whitespace", "a {\n\tcolor: red;\n\tmargin: 0 auto;\n}\n", "a{color:red;margin:0 auto}"},
+		{"Comments", "/* header */\nh1 { /* big */ font-size: 2em }", "h1{font-size:2em}"},
+		{"Selectors", "nav a:hover,\nnav  >  b,\ndiv :first-child {}", "nav a:hover,nav>b,div :first-child{}"},
+		{"Strings", "a::after { content: ' }  /* '; }", "a::after{content:' }  /* '}"},
+		{"Calc", "div { width: calc(100% - 2 * 8px); }", "div{width:calc(100% - 2 * 8px)}"},
+		{"Media", "@media (max-width: 600px) {\n  nav { display: none; }\n}", "@media (max-width:600px){nav{display:none}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, string(CSS([]byte(tt.src))), tt.want)
+		})
+	}
+}
+
+func TestJS(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"Whitespace", "if (a  ==  b) {\n\tgo();\n}\n", "if(a==b){\ngo();\n}"},
+		{"Line breaks kept", "var a = 1\nvar b = 2\n\n\n(c)", "var a=1\nvar b=2\n(c)"},
+		{"Comments", "// intro\nvar a; /* inline */ var b;\n/* multi\nline */ go()", "var a;var b;\ngo()"},
+		{"Strings", `var s = "a  // b" + 'c /* d */' + ` + "`e  ${f}`", `var s="a  // b" + 'c /* d */' + ` + "`e  ${f}`"},
+		{"Unary operators", "a = b + +c - -d", "a=b + +c - -d"},
+		{"Regex", "s.replace(/\\/  +/g, \"/\")", "s.replace(/\\/  +/g,\"/\")"},
+		{"Regex after return", "return /[/]  x/i.test(s)", "return /[/]  x/i.test(s)"},
+		{"Division", "var x = (a) / 2 / b", "var x=(a)/ 2 / b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, string(JS([]byte(tt.src))), tt.want)
+		})
+	}
+}
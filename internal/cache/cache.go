@@ -0,0 +1,166 @@
+// Package cache provides a namespaced read-through cache on top of a
+// pluggable key-value backend, with stampede protection and hit/miss
+// statistics.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Backend stores raw values with a time to live. Implementations must be
+// safe for concurrent use.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// Stats are the counters of one namespace.
+type Stats struct {
+	Namespace string
+	Hits      uint64
+	Misses    uint64
+	Errors    uint64
+	// Evictions counts entries the backend dropped before they expired to
+	// make room for new ones. It is shared by all namespaces of a backend
+	// and always zero for backends that do not report it.
+	Evictions uint64
+}
+
+// evictionCounter is implemented by backends that report evictions.
+type evictionCounter interface {
+	Evictions() uint64
+}
+
+// Cache is a view of a backend restricted to one namespace. Keys are
+// prefixed with the namespace, so namespaces sharing a backend never see
+// each other's entries.
+type Cache struct {
+	backend   Backend
+	namespace string
+	group     singleflight.Group
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	errors    atomic.Uint64
+}
+
+// New returns the cache for namespace on backend.
+func New(backend Backend, namespace string) *Cache {
+	return &Cache{backend: backend, namespace: namespace}
+}
+
+func (c *Cache) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+// Get returns the value stored under key. Backend errors are counted and
+// reported as misses, since a cache that is down must not take the
+// application with it.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok, err := c.backend.Get(ctx, c.key(key))
+	if err != nil {
+		c.errors.Add(1)
+	}
+
+	if !ok || err != nil {
+		c.misses.Add(1)
+
+		return nil, false
+	}
+
+	c.hits.Add(1)
+
+	return value, true
+}
+
+// Set stores value under key for ttl. A ttl of zero or less stores nothing.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := c.backend.Set(ctx, c.key(key), value, ttl); err != nil {
+		c.errors.Add(1)
+
+		return fmt.Errorf("cache set %s: %w", c.key(key), err)
+	}
+
+	return nil
+}
+
+// Del removes key.
+func (c *Cache) Del(ctx context.Context, key string) error {
+	if err := c.backend.Del(ctx, c.key(key)); err != nil {
+		c.errors.Add(1)
+
+		return fmt.Errorf("cache del %s: %w", c.key(key), err)
+	}
+
+	return nil
+}
+
+// Stats returns the counters of the namespace.
+func (c *Cache) Stats() Stats {
+	s := Stats{
+		Namespace: c.namespace,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Errors:    c.errors.Load(),
+	}
+
+	if ec, ok := c.backend.(evictionCounter); ok {
+		s.Evictions = ec.Evictions()
+	}
+
+	return s
+}
+
+// Fetch returns the value cached under key, calling load to compute it on a
+// miss. Concurrent misses for the same key share a single call to load, so
+// an expiring hot key does not stampede the database. load also returns the
+// ttl to cache its value for; results with a ttl of zero are not cached.
+// Values are stored as JSON.
+func Fetch[T any](
+	ctx context.Context,
+	c *Cache,
+	key string,
+	load func(ctx context.Context) (T, time.Duration, error),
+) (T, error) {
+	var v T
+
+	if b, ok := c.Get(ctx, key); ok {
+		if err := json.Unmarshal(b, &v); err == nil {
+			return v, nil
+		}
+
+		c.errors.Add(1)
+	}
+
+	res, err, _ := c.group.Do(key, func() (any, error) {
+		loaded, ttl, loadErr := load(ctx)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		if b, marshalErr := json.Marshal(loaded); marshalErr == nil {
+			// A failed write only costs a later miss; it is counted in the
+			// stats.
+			_ = c.Set(ctx, key, b, ttl)
+		}
+
+		return loaded, nil
+	})
+	if err != nil {
+		return v, fmt.Errorf("loading %s: %w", c.key(key), err)
+	}
+
+	v, _ = res.(T)
+
+	return v, nil
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestCacheNamespaces(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory(10)
+
+	a := New(backend, "a")
+	b := New(backend, "b")
+
+	assert.NilError(t, a.Set(ctx, "key", []byte("1"), time.Minute))
+
+	v, ok := a.Get(ctx, "key")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, string(v), "1")
+
+	_, ok = b.Get(ctx, "key")
+	assert.Equal(t, ok, false)
+
+	assert.NilError(t, a.Del(ctx, "key"))
+
+	_, ok = a.Get(ctx, "key")
+	assert.Equal(t, ok, false)
+
+	assert.Equal(t, a.Stats(), Stats{Namespace: "a", Hits: 1, Misses: 1})
+	assert.Equal(t, b.Stats(), Stats{Namespace: "b", Misses: 1})
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemory(10), "test")
+
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+	)
+
+	load := func(ctx context.Context) (int, time.Duration, error) {
+		calls.Add(1)
+		<-release
+
+		return 42, time.Minute, nil
+	}
+
+	// Concurrent misses share a single load.
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := Fetch(ctx, c, "answer", load)
+			assert.NilError(t, err)
+			assert.Equal(t, v, 42)
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, calls.Load(), int32(1))
+
+	v, err := Fetch(ctx, c, "answer", load)
+	assert.NilError(t, err)
+	assert.Equal(t, v, 42)
+	assert.Equal(t, calls.Load(), int32(1))
+	assert.Equal(t, c.Stats().Hits, uint64(1))
+
+	// Failed loads are not cached.
+	errBoom := errors.New("boom")
+
+	_, err = Fetch(ctx, c, "broken", func(ctx context.Context) (int, time.Duration, error) {
+		return 0, time.Minute, errBoom
+	})
+	assert.Equal(t, errors.Is(err, errBoom), true)
+
+	_, ok := c.Get(ctx, "broken")
+	assert.Equal(t, ok, false)
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	m := NewMemory(2)
+	m.now = func() time.Time { return now }
+
+	assert.NilError(t, m.Set(ctx, "short", []byte("1"), time.Minute))
+	assert.NilError(t, m.Set(ctx, "long", []byte("2"), time.Hour))
+
+	// The entry closest to expiry makes room for a new one.
+	assert.NilError(t, m.Set(ctx, "new", []byte("3"), time.Hour))
+	assert.Equal(t, m.Evictions(), uint64(1))
+
+	_, ok, _ := m.Get(ctx, "short")
+	assert.Equal(t, ok, false)
+
+	now = now.Add(2 * time.Hour)
+
+	_, ok, _ = m.Get(ctx, "long")
+	assert.Equal(t, ok, false)
+
+	// Expired entries are dropped without counting as evictions.
+	assert.NilError(t, m.Set(ctx, "a", []byte("4"), time.Hour))
+	assert.NilError(t, m.Set(ctx, "b", []byte("5"), time.Hour))
+	assert.Equal(t, m.Evictions(), uint64(1))
+
+	v, ok, _ := m.Get(ctx, "b")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, string(v), "5")
+}
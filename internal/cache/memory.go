@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time
+}
+
+// Memory is an in-process Backend holding at most a fixed number of entries.
+// When it is full, expired entries are dropped first and then the entry
+// closest to expiry, which is counted as an eviction.
+type Memory struct {
+	mu        sync.Mutex
+	items     map[string]memoryItem
+	maxItems  int
+	evictions atomic.Uint64
+	now       func() time.Time
+}
+
+// NewMemory returns an in-memory backend holding up to maxItems entries.
+func NewMemory(maxItems int) *Memory {
+	return &Memory{
+		items:    make(map[string]memoryItem),
+		maxItems: maxItems,
+		now:      time.Now,
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !m.now().Before(item.expires) {
+		delete(m.items, key)
+
+		return nil, false, nil
+	}
+
+	return item.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	if _, ok := m.items[key]; !ok && len(m.items) >= m.maxItems {
+		m.makeRoom(now)
+	}
+
+	m.items[key] = memoryItem{value: value, expires: now.Add(ttl)}
+
+	return nil
+}
+
+func (m *Memory) Del(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.items, key)
+
+	return nil
+}
+
+// Evictions returns the number of live entries dropped to make room.
+func (m *Memory) Evictions() uint64 {
+	return m.evictions.Load()
+}
+
+// makeRoom frees at least one slot. It must be called with mu held.
+func (m *Memory) makeRoom(now time.Time) {
+	var (
+		victim  string
+		earlier time.Time
+	)
+
+	for key, item := range m.items {
+		if !now.Before(item.expires) {
+			delete(m.items, key)
+
+			continue
+		}
+
+		if victim == "" || item.expires.Before(earlier) {
+			victim, earlier = key, item.expires
+		}
+	}
+
+	if len(m.items) >= m.maxItems && victim != "" {
+		delete(m.items, victim)
+		m.evictions.Add(1)
+	}
+}
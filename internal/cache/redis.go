@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxIdleRedisConns is the number of idle connections kept for reuse.
+const maxIdleRedisConns = 8
+
+// ErrRedis is returned when the Redis server replies with an error.
+var ErrRedis = errors.New("cache: redis error")
+
+// Redis is a Backend storing entries in a Redis server, so that several
+// application instances share one cache. It speaks just enough of the
+// RESP protocol for GET, SET and DEL.
+type Redis struct {
+	Addr     string
+	Password string
+	DB       int
+	Timeout  time.Duration
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (rd *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := rd.do(ctx, "GET", []byte(key))
+	if err != nil {
+		return nil, false, err
+	}
+
+	b, ok := reply.([]byte)
+
+	return b, ok, nil
+}
+
+func (rd *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms := strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)
+
+	_, err := rd.do(ctx, "SET", []byte(key), value, []byte("PX"), []byte(ms))
+
+	return err
+}
+
+func (rd *Redis) Del(ctx context.Context, key string) error {
+	_, err := rd.do(ctx, "DEL", []byte(key))
+
+	return err
+}
+
+// nilReply is the reply to GET for a missing key.
+type nilReply struct{}
+
+// do sends one command and returns its reply: a []byte for bulk strings,
+// a string for status replies, an int64 for integers and nilReply for a nil
+// bulk string.
+func (rd *Redis) do(ctx context.Context, cmd string, args ...[]byte) (any, error) {
+	c, err := rd.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(ctx, rd.Timeout, cmd, args...)
+	if err != nil {
+		// An error reply leaves the connection usable; anything else may
+		// have left it mid-reply.
+		if errors.Is(err, ErrRedis) {
+			rd.release(c)
+		} else {
+			_ = c.Close()
+		}
+
+		return nil, fmt.Errorf("redis %s: %w", cmd, err)
+	}
+
+	rd.release(c)
+
+	return reply, nil
+}
+
+func (rd *Redis) conn(ctx context.Context) (*redisConn, error) {
+	rd.mu.Lock()
+	if n := len(rd.idle); n > 0 {
+		c := rd.idle[n-1]
+		rd.idle = rd.idle[:n-1]
+		rd.mu.Unlock()
+
+		return c, nil
+	}
+	rd.mu.Unlock()
+
+	d := net.Dialer{Timeout: rd.Timeout}
+
+	nc, err := d.DialContext(ctx, "tcp", rd.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to redis: %w", err)
+	}
+
+	c := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+
+	if rd.Password != "" {
+		if _, err := c.roundTrip(ctx, rd.Timeout, "AUTH", []byte(rd.Password)); err != nil {
+			_ = c.Close()
+
+			return nil, fmt.Errorf("authenticating to redis: %w", err)
+		}
+	}
+
+	if rd.DB != 0 {
+		if _, err := c.roundTrip(ctx, rd.Timeout, "SELECT", []byte(strconv.Itoa(rd.DB))); err != nil {
+			_ = c.Close()
+
+			return nil, fmt.Errorf("selecting redis database: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func (rd *Redis) release(c *redisConn) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if len(rd.idle) >= maxIdleRedisConns {
+		_ = c.Close()
+
+		return
+	}
+
+	rd.idle = append(rd.idle, c)
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, timeout time.Duration, cmd string, args ...[]byte) (any, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	buf := fmt.Appendf(nil, "*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(cmd), cmd)
+	for _, arg := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n", len(arg))
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+
+	if _, err := c.Write(buf); err != nil {
+		return nil, fmt.Errorf("writing command: %w", err)
+	}
+
+	return c.readReply()
+}
+
+func (c *redisConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("reading reply: %w", err)
+	}
+
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("reading reply: malformed line %q", line)
+	}
+
+	return line[:len(line)-2], nil
+}
+
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", ErrRedis, line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("reading integer reply: %w", err)
+		}
+
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("reading bulk reply: %w", err)
+		}
+
+		if n < 0 {
+			return nilReply{}, nil
+		}
+
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, b); err != nil {
+			return nil, fmt.Errorf("reading bulk reply: %w", err)
+		}
+
+		return b[:n], nil
+	default:
+		return nil, fmt.Errorf("reading reply: unsupported type %q", line[0])
+	}
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+// fakeRedis serves GET, SET and DEL from a map, ignoring expiry.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	commands []string
+}
+
+func (f *fakeRedis) serve(t *testing.T, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.handle(t, conn)
+	}
+}
+
+func (f *fakeRedis) handle(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+
+		var reply string
+
+		switch args[0] {
+		case "AUTH":
+			reply = "+OK\r\n"
+		case "GET":
+			if v, ok := f.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			f.data[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case "DEL":
+			delete(f.data, args[1])
+			reply = ":1\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			t.Error(err)
+
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	f := &fakeRedis{data: make(map[string]string)}
+	go f.serve(t, ln)
+
+	ctx := context.Background()
+	rd := &Redis{Addr: ln.Addr().String(), Password: "secret", Timeout: time.Second}
+
+	_, ok, err := rd.Get(ctx, "key")
+	assert.NilError(t, err)
+	assert.Equal(t, ok, false)
+
+	assert.NilError(t, rd.Set(ctx, "key", []byte("value"), 1500*time.Millisecond))
+
+	v, ok, err := rd.Get(ctx, "key")
+	assert.NilError(t, err)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, string(v), "value")
+
+	assert.NilError(t, rd.Del(ctx, "key"))
+
+	_, err = rd.do(ctx, "PING")
+	assert.Equal(t, err != nil, true)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The connection is authenticated once and then reused.
+	assert.Equal(t, strings.Join(f.commands, "|"),
+		"AUTH secret|GET key|SET key value PX 1500|GET key|DEL key|PING")
+}
@@ -0,0 +1,76 @@
+package captcha
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestNew(t *testing.T) {
+	v, err := New("", "", "")
+	assert.NilError(t, err)
+	assert.Equal(t, v, nil)
+
+	v, err = New("Turnstile", "site", "secret")
+	assert.NilError(t, err)
+	assert.Equal(t, v.Widget().ResponseField, "cf-turnstile-response")
+	assert.Equal(t, v.Widget().SiteKey, "site")
+
+	_, err = New("recaptcha", "site", "secret")
+	assert.Equal(t, errors.Is(err, ErrUnknownProvider), true)
+
+	_, err = New("hcaptcha", "site", "")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestSiteVerifierVerify(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+
+		if r.PostForm.Get("secret") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if r.PostForm.Get("response") == "good" {
+			w.Write([]byte(`{"success": true}`))
+		} else {
+			w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}
+	}))
+	defer ts.Close()
+
+	v := &SiteVerifier{VerifyURL: ts.URL, Secret: "secret", Client: ts.Client()}
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "Valid token", token: "good", want: true},
+		{name: "Invalid token", token: "bad", want: false},
+		{name: "Empty token", token: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := v.Verify(t.Context(), tt.token, "127.0.0.1")
+
+			assert.NilError(t, err)
+			assert.Equal(t, ok, tt.want)
+		})
+	}
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		bad := &SiteVerifier{VerifyURL: ts.URL, Secret: "wrong", Client: ts.Client()}
+
+		_, err := bad.Verify(t.Context(), "good", "")
+		assert.Equal(t, err != nil, true)
+	})
+}
@@ -0,0 +1,173 @@
+// Package errorx classifies errors by kind and records where they were
+// wrapped, so a handler can pass any error to one place that picks the HTTP
+// status, the log severity and, for internal errors, the stack to report.
+package errorx
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Kind classifies an error by how a request that failed with it is
+// answered.
+type Kind uint8
+
+const (
+	// Internal is a failure of the server, such as a database error. It is
+	// the kind of every error that was not given another.
+	Internal Kind = iota
+	// NotFound means the requested resource does not exist.
+	NotFound
+	// Validation means the request itself is invalid.
+	Validation
+)
+
+func (k Kind) String() string {
+	switch k {
+	case NotFound:
+		return "not found"
+	case Validation:
+		return "validation"
+	default:
+		return "internal"
+	}
+}
+
+// Status returns the HTTP status a request that failed with an error of
+// kind k is answered with.
+func (k Kind) Status() int {
+	switch k {
+	case NotFound:
+		return http.StatusNotFound
+	case Validation:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Level returns the severity errors of kind k are logged with. Only
+// internal errors need someone to look at them.
+func (k Kind) Level() slog.Level {
+	switch k {
+	case NotFound, Validation:
+		return slog.LevelInfo
+	default:
+		return slog.LevelError
+	}
+}
+
+// maxDepth bounds the number of frames recorded for a stack.
+const maxDepth = 32
+
+// Error is an error with a kind and, if it was created by Wrap or Errorf,
+// the stack at that point.
+type Error struct {
+	kind  Kind
+	msg   string
+	err   error
+	stack []uintptr
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.err == nil:
+		return e.msg
+	case e.msg == "":
+		return e.err.Error()
+	default:
+		return e.msg + ": " + e.err.Error()
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// New returns an error of kind with the message msg. It records no stack,
+// since it is meant for sentinel errors declared at package level, such as
+// models.ErrNoRecord.
+func New(kind Kind, msg string) error {
+	return &Error{kind: kind, msg: msg}
+}
+
+// Errorf is like fmt.Errorf, %w included, for an error of kind. It records
+// the caller's stack.
+func Errorf(kind Kind, format string, args ...any) error {
+	return &Error{kind: kind, err: fmt.Errorf(format, args...), stack: callers()}
+}
+
+// Wrap returns err as an error of kind, prefixed with msg unless msg is
+// empty. It records the caller's stack unless err already carries one, so
+// the stack points at where the error first surfaced. Wrap returns nil if
+// err is nil.
+func Wrap(err error, kind Kind, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{kind: kind, msg: msg, err: err}
+
+	if Stack(err) == "" {
+		e.stack = callers()
+	}
+
+	return e
+}
+
+func callers() []uintptr {
+	pcs := make([]uintptr, maxDepth)
+	// Skip runtime.Callers, callers and the function of this package that
+	// called it.
+	n := runtime.Callers(3, pcs)
+
+	return pcs[:n]
+}
+
+// KindOf returns the kind of the outermost Error in err's chain, or Internal
+// if there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+
+	return Internal
+}
+
+// Stack returns the stack recorded deepest in err's chain, one
+// "function\n\tfile:line" entry per frame as in a panic, or "" if none was.
+func Stack(err error) string {
+	var stack []uintptr
+
+	for err != nil {
+		if e, ok := err.(*Error); ok && len(e.stack) > 0 { //nolint:errorlint // walking the chain by hand to find the deepest stack
+			stack = e.stack
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	if stack == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	frames := runtime.CallersFrames(stack)
+
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package errorx
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+var errMissing = New(NotFound, "no such thing")
+
+func lookUp() error {
+	return Wrap(errMissing, NotFound, "looking up thing")
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantKind   Kind
+		wantStatus int
+		wantLevel  slog.Level
+	}{
+		{"Plain error", errors.New("boom"), Internal, http.StatusInternalServerError, slog.LevelError},
+		{"Sentinel", errMissing, NotFound, http.StatusNotFound, slog.LevelInfo},
+		{"Wrapped by fmt", fmt.Errorf("loading: %w", errMissing), NotFound, http.StatusNotFound, slog.LevelInfo},
+		{"Errorf", Errorf(Validation, "title %q is too long", "x"), Validation, http.StatusUnprocessableEntity, slog.LevelInfo},
+		{"Reclassified", Wrap(errMissing, Internal, "the admin account is missing"), Internal, http.StatusInternalServerError, slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind := KindOf(tt.err)
+			assert.Equal(t, kind, tt.wantKind)
+			assert.Equal(t, kind.Status(), tt.wantStatus)
+			assert.Equal(t, kind.Level(), tt.wantLevel)
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, Wrap(nil, Internal, "unused"), nil)
+
+	err := lookUp()
+	assert.Equal(t, err.Error(), "looking up thing: no such thing")
+	assert.Equal(t, errors.Is(err, errMissing), true)
+
+	// The stack starts where the error was first wrapped, and wrapping it
+	// again keeps that stack.
+	stack := Stack(err)
+	assert.Equal(t, strings.HasPrefix(stack, "github.com/FABLOUSFALCON/snippetbox/internal/errorx.lookUp\n\t"), true)
+	assert.StringContains(t, stack, "errorx_test.go:")
+	assert.Equal(t, Stack(Wrap(fmt.Errorf("handler: %w", err), Internal, "")), stack)
+
+	assert.Equal(t, Stack(errMissing), "")
+	assert.Equal(t, Stack(errors.New("boom")), "")
+}
@@ -0,0 +1,44 @@
+package i18n
+
+import "unicode"
+
+// Text directions, as used by the HTML dir attribute.
+const (
+	LTR  = "ltr"
+	RTL  = "rtl"
+	Auto = "auto"
+)
+
+// rtlScripts are the scripts written right to left.
+var rtlScripts = []*unicode.RangeTable{
+	unicode.Arabic,
+	unicode.Hebrew,
+	unicode.Syriac,
+	unicode.Thaana,
+	unicode.Nko,
+	unicode.Samaritan,
+	unicode.Mandaic,
+	unicode.Adlam,
+	unicode.Hanifi_Rohingya,
+}
+
+// Direction reports the base direction of s following the first strong
+// character rule of the Unicode bidirectional algorithm: the first letter
+// (or explicit LRM/RLM mark) decides. Text without any, such as a title made
+// only of digits and punctuation, is Auto.
+func Direction(s string) string {
+	for _, r := range s {
+		switch {
+		case r == '\u200e':
+			return LTR
+		case r == '\u200f':
+			return RTL
+		case unicode.IsOneOf(rtlScripts, r):
+			return RTL
+		case unicode.IsLetter(r):
+			return LTR
+		}
+	}
+
+	return Auto
+}
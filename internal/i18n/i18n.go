@@ -0,0 +1,265 @@
+// Package i18n formats dates, numbers and pluralised messages for the
+// locales Snippetbox supports, and picks the locale for a request.
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plural forms, named after the CLDR plural categories.
+const (
+	One   = "one"
+	Other = "other"
+)
+
+// Locale holds the formatting rules of one language.
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "fr".
+	Tag string
+	// Name is the language's name in that language.
+	Name string
+
+	dateLayout string
+	decimal    string
+	group      string
+	plural     func(n int64) string
+	messages   map[string]map[string]string
+}
+
+var english = &Locale{
+	Tag:        "en",
+	Name:       "English",
+	dateLayout: "02 Jan 2006 at 15:04",
+	decimal:    ".",
+	group:      ",",
+	plural:     oneIfExactlyOne,
+	messages: map[string]map[string]string{
+		"snippets": {One: "%s snippet", Other: "%s snippets"},
+		"views":    {One: "%s view", Other: "%s views"},
+	},
+}
+
+var locales = []*Locale{
+	english,
+	{
+		Tag:        "de",
+		Name:       "Deutsch",
+		dateLayout: "02.01.2006 um 15:04",
+		decimal:    ",",
+		group:      ".",
+		plural:     oneIfExactlyOne,
+		messages: map[string]map[string]string{
+			"snippets": {One: "%s Snippet", Other: "%s Snippets"},
+			"views":    {One: "%s Aufruf", Other: "%s Aufrufe"},
+		},
+	},
+	{
+		Tag:        "es",
+		Name:       "Español",
+		dateLayout: "02/01/2006 a las 15:04",
+		decimal:    ",",
+		group:      ".",
+		plural:     oneIfExactlyOne,
+		messages: map[string]map[string]string{
+			"snippets": {One: "%s fragmento", Other: "%s fragmentos"},
+			"views":    {One: "%s visita", Other: "%s visitas"},
+		},
+	},
+	{
+		Tag:        "fr",
+		Name:       "Français",
+		dateLayout: "02/01/2006 à 15:04",
+		decimal:    ",",
+		group:      "\u202f",
+		plural:     oneIfZeroOrOne,
+		messages: map[string]map[string]string{
+			"snippets": {One: "%s extrait", Other: "%s extraits"},
+			"views":    {One: "%s vue", Other: "%s vues"},
+		},
+	},
+}
+
+func oneIfExactlyOne(n int64) string {
+	if n == 1 {
+		return One
+	}
+
+	return Other
+}
+
+// French treats zero as singular.
+func oneIfZeroOrOne(n int64) string {
+	if n == 0 || n == 1 {
+		return One
+	}
+
+	return Other
+}
+
+// Default is the locale used when nothing better is known.
+func Default() *Locale {
+	return english
+}
+
+// Supported returns every supported locale.
+func Supported() []*Locale {
+	return slices.Clone(locales)
+}
+
+// Get returns the locale for an exact, case-insensitive language tag.
+func Get(tag string) (*Locale, bool) {
+	for _, l := range locales {
+		if strings.EqualFold(l.Tag, tag) {
+			return l, true
+		}
+	}
+
+	return nil, false
+}
+
+// Match picks the supported locale the client prefers most according to an
+// Accept-Language header. Region subtags are ignored, so "fr-CA" matches
+// "fr".
+func Match(acceptLanguage string) (*Locale, bool) {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		if tag == "" || q <= 0 {
+			continue
+		}
+
+		base, _, _ := strings.Cut(tag, "-")
+		candidates = append(candidates, candidate{tag: base, q: q})
+	}
+
+	// A stable sort keeps the header order between equal weights.
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, c := range candidates {
+		if l, ok := Get(c.tag); ok {
+			return l, true
+		}
+	}
+
+	return nil, false
+}
+
+// FormatDate formats t in UTC using the locale's date and time layout. The
+// zero time formats as an empty string.
+func (l *Locale) FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(l.dateLayout)
+}
+
+// FormatNumber formats n with the locale's digit grouping.
+func (l *Locale) FormatNumber(n int64) string {
+	s := strconv.FormatInt(n, 10)
+
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	return sign + l.groupDigits(s)
+}
+
+// FormatDecimal formats f with prec fractional digits and the locale's
+// decimal separator and digit grouping.
+func (l *Locale) FormatDecimal(f float64, prec int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	s := strconv.FormatFloat(math.Abs(f), 'f', prec, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	out := l.groupDigits(whole)
+	if frac != "" {
+		out += l.decimal + frac
+	}
+
+	if f < 0 && strings.Trim(s, "0.") != "" {
+		out = "-" + out
+	}
+
+	return out
+}
+
+func (l *Locale) groupDigits(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.group)
+		}
+
+		b.WriteString(digits[i : i+3])
+	}
+
+	return b.String()
+}
+
+// PluralForm returns the plural category of n in this locale.
+func (l *Locale) PluralForm(n int64) string {
+	return l.plural(n)
+}
+
+// Plural formats the message key for the count n, picking the plural form
+// the locale requires, e.g. Plural("views", 1200) is "1,200 views" in
+// English. Unknown keys fall back to English, then to the formatted number
+// followed by the key.
+func (l *Locale) Plural(key string, n int64) string {
+	forms, ok := l.messages[key]
+	if !ok {
+		forms, ok = english.messages[key]
+		if !ok {
+			return fmt.Sprintf("%s %s", l.FormatNumber(n), key)
+		}
+	}
+
+	return fmt.Sprintf(forms[l.plural(n)], l.FormatNumber(n))
+}
@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+		wantOK bool
+	}{
+		{name: "Exact", header: "de", want: "de", wantOK: true},
+		{name: "Region", header: "fr-CA", want: "fr", wantOK: true},
+		{name: "Weights", header: "en;q=0.5, es;q=0.8", want: "es", wantOK: true},
+		{name: "Header order", header: "de, fr", want: "de", wantOK: true},
+		{name: "Skips unsupported", header: "ja, fr;q=0.1", want: "fr", wantOK: true},
+		{name: "Refused", header: "fr;q=0", wantOK: false},
+		{name: "Unsupported", header: "ja, zh", wantOK: false},
+		{name: "Empty", header: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, ok := Match(tt.header)
+			assert.Equal(t, ok, tt.wantOK)
+
+			if ok {
+				assert.Equal(t, l.Tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tm := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "en", want: "17 Mar 2024 at 10:15"},
+		{tag: "de", want: "17.03.2024 um 10:15"},
+		{tag: "fr", want: "17/03/2024 à 10:15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, _ := Get(tt.tag)
+			assert.Equal(t, l.FormatDate(tm), tt.want)
+		})
+	}
+
+	assert.Equal(t, Default().FormatDate(time.Time{}), "")
+}
+
+func TestFormatNumber(t *testing.T) {
+	en, _ := Get("en")
+	de, _ := Get("de")
+	fr, _ := Get("fr")
+
+	assert.Equal(t, en.FormatNumber(0), "0")
+	assert.Equal(t, en.FormatNumber(999), "999")
+	assert.Equal(t, en.FormatNumber(1234567), "1,234,567")
+	assert.Equal(t, en.FormatNumber(-1234), "-1,234")
+	assert.Equal(t, de.FormatNumber(1234567), "1.234.567")
+	assert.Equal(t, fr.FormatNumber(12345), "12 345")
+
+	assert.Equal(t, en.FormatDecimal(1234.5678, 2), "1,234.57")
+	assert.Equal(t, de.FormatDecimal(99.5, 2), "99,50")
+	assert.Equal(t, en.FormatDecimal(-0.001, 2), "0.00")
+	assert.Equal(t, en.FormatDecimal(-2.5, 1), "-2.5")
+	assert.Equal(t, en.FormatDecimal(3, 0), "3")
+}
+
+func TestPlural(t *testing.T) {
+	en, _ := Get("en")
+	fr, _ := Get("fr")
+
+	tests := []struct {
+		name   string
+		locale *Locale
+		key    string
+		n      int64
+		want   string
+	}{
+		{name: "English one", locale: en, key: "views", n: 1, want: "1 view"},
+		{name: "English zero", locale: en, key: "views", n: 0, want: "0 views"},
+		{name: "English many", locale: en, key: "snippets", n: 1200, want: "1,200 snippets"},
+		{name: "French zero", locale: fr, key: "views", n: 0, want: "0 vue"},
+		{name: "French many", locale: fr, key: "snippets", n: 2, want: "2 extraits"},
+		{name: "Unknown key", locale: en, key: "widgets", n: 3, want: "3 widgets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.locale.Plural(tt.key, tt.n), tt.want)
+		})
+	}
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "Latin", s: "An old silent pond", want: LTR},
+		{name: "Hebrew", s: "שלום עולם", want: RTL},
+		{name: "Arabic", s: "مرحبا بالعالم", want: RTL},
+		{name: "Leading digits", s: "2024: مرحبا", want: RTL},
+		{name: "Mixed, Latin first", s: "Go في العربية", want: LTR},
+		{name: "Right-to-left mark", s: "\u200f2024", want: RTL},
+		{name: "No letters", s: "42 - #1", want: Auto},
+		{name: "Empty", s: "", want: Auto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Direction(tt.s), tt.want)
+		})
+	}
+}
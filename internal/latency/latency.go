@@ -0,0 +1,187 @@
+// Package latency tracks request latencies per route over a sliding window
+// and reports routes whose 95th percentile exceeds their latency budget.
+package latency
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSamples caps the samples kept per route, so a busy route cannot
+	// grow memory without bound. The oldest samples are dropped first.
+	maxSamples = 1000
+
+	// minSamples is the number of samples a route needs in the window
+	// before its percentile is trusted.
+	minSamples = 20
+)
+
+// Budgets maps route patterns, as registered with http.ServeMux (e.g.
+// "GET /snippet/view/{id}"), to the 95th percentile latency they may not
+// exceed. Default applies to routes without their own budget; zero means
+// such routes are not tracked.
+type Budgets struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// ParseBudgets parses a comma-separated list of PATTERN=DURATION pairs,
+// e.g. "GET /{$}=100ms,POST /snippet/create=500ms".
+func ParseBudgets(s string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration)
+
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("latency budget %q: missing '='", entry)
+		}
+
+		budget, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("latency budget %q: invalid duration", entry)
+		}
+
+		routes[strings.TrimSpace(pattern)] = budget
+	}
+
+	return routes, nil
+}
+
+// Report is the state of a route whose budget status changed.
+type Report struct {
+	Route   string
+	P95     time.Duration
+	Budget  time.Duration
+	Samples int
+	// Over is true when the route went over its budget and false when it
+	// recovered.
+	Over bool
+}
+
+type sample struct {
+	at time.Time
+	d  time.Duration
+}
+
+type route struct {
+	samples []sample
+	over    bool
+}
+
+// Tracker records request latencies. It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	budgets Budgets
+	window  time.Duration
+	routes  map[string]*route
+	now     func() time.Time
+}
+
+// New returns a Tracker that evaluates budgets over the given window.
+func New(budgets Budgets, window time.Duration) *Tracker {
+	return &Tracker{
+		budgets: budgets,
+		window:  window,
+		routes:  make(map[string]*route),
+		now:     time.Now,
+	}
+}
+
+func (t *Tracker) budget(pattern string) time.Duration {
+	if b, ok := t.budgets.Routes[pattern]; ok {
+		return b
+	}
+
+	return t.budgets.Default
+}
+
+// Observe records that a request to the route pattern took d. Routes
+// without a budget are ignored.
+func (t *Tracker) Observe(pattern string, d time.Duration) {
+	if pattern == "" || t.budget(pattern) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.routes[pattern]
+	if !ok {
+		r = &route{}
+		t.routes[pattern] = r
+	}
+
+	if len(r.samples) == maxSamples {
+		r.samples = slices.Delete(r.samples, 0, 1)
+	}
+
+	r.samples = append(r.samples, sample{at: t.now(), d: d})
+}
+
+// Check drops samples that left the window and returns a report for every
+// route that went over its budget or recovered since the previous call.
+// Routes with too few samples keep their previous status.
+func (t *Tracker) Check() []Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.now().Add(-t.window)
+
+	var reports []Report
+
+	for pattern, r := range t.routes {
+		i, _ := slices.BinarySearchFunc(r.samples, cutoff, func(s sample, cutoff time.Time) int {
+			return s.at.Compare(cutoff)
+		})
+		r.samples = slices.Delete(r.samples, 0, i)
+
+		if len(r.samples) < minSamples {
+			continue
+		}
+
+		p95 := percentile(r.samples, 0.95)
+		budget := t.budget(pattern)
+		over := p95 > budget
+
+		if over != r.over {
+			r.over = over
+			reports = append(reports, Report{
+				Route:   pattern,
+				P95:     p95,
+				Budget:  budget,
+				Samples: len(r.samples),
+				Over:    over,
+			})
+		}
+	}
+
+	slices.SortFunc(reports, func(a, b Report) int {
+		return strings.Compare(a.Route, b.Route)
+	})
+
+	return reports
+}
+
+// percentile returns the nearest-rank percentile p of the sample durations.
+func percentile(samples []sample, p float64) time.Duration {
+	ds := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		ds[i] = s.d
+	}
+
+	slices.Sort(ds)
+
+	rank := int(math.Ceil(p*float64(len(ds)))) - 1
+
+	return ds[max(0, min(rank, len(ds)-1))]
+}
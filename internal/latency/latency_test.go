@@ -0,0 +1,72 @@
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestParseBudgets(t *testing.T) {
+	routes, err := ParseBudgets(" GET /{$}=100ms, POST /snippet/create = 1s ,")
+	assert.NilError(t, err)
+	assert.Equal(t, len(routes), 2)
+	assert.Equal(t, routes["GET /{$}"], 100*time.Millisecond)
+	assert.Equal(t, routes["POST /snippet/create"], time.Second)
+
+	for _, s := range []string{"GET /", "GET /=fast", "GET /=-1s"} {
+		_, err := ParseBudgets(s)
+		assert.Equal(t, err != nil, true)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	tr := New(Budgets{
+		Default: time.Second,
+		Routes:  map[string]time.Duration{"GET /fast": 10 * time.Millisecond, "GET /untracked": 0},
+	}, time.Minute)
+	tr.now = func() time.Time { return now }
+
+	// With 19 fast requests and 1 slow one, p95 is still a fast sample.
+	for i := range minSamples {
+		d := 5 * time.Millisecond
+		if i == minSamples-1 {
+			d = 50 * time.Millisecond
+		}
+
+		tr.Observe("GET /fast", d)
+		tr.Observe("GET /other", d)
+		tr.Observe("GET /untracked", time.Hour)
+	}
+
+	assert.Equal(t, len(tr.Check()), 0)
+
+	// Two more slow requests push p95 over the budget; the default budget of
+	// the other route is not exceeded.
+	tr.Observe("GET /fast", 50*time.Millisecond)
+	tr.Observe("GET /fast", 50*time.Millisecond)
+
+	reports := tr.Check()
+	assert.Equal(t, len(reports), 1)
+	assert.Equal(t, reports[0].Route, "GET /fast")
+	assert.Equal(t, reports[0].Over, true)
+	assert.Equal(t, reports[0].P95, 50*time.Millisecond)
+	assert.Equal(t, reports[0].Samples, 22)
+
+	// Status is only reported when it changes.
+	assert.Equal(t, len(tr.Check()), 0)
+
+	// Once the slow samples leave the window, the route recovers.
+	now = now.Add(2 * time.Minute)
+
+	for range minSamples {
+		tr.Observe("GET /fast", time.Millisecond)
+	}
+
+	reports = tr.Check()
+	assert.Equal(t, len(reports), 1)
+	assert.Equal(t, reports[0].Over, false)
+	assert.Equal(t, reports[0].Samples, minSamples)
+}
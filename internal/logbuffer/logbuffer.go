@@ -0,0 +1,208 @@
+// Package logbuffer keeps the most recent structured log records in memory
+// so they can be searched from the admin interface without shell access to
+// the host.
+package logbuffer
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a captured log record. Attributes of groups are flattened into
+// dotted keys, e.g. "request.id".
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// Attr returns the value of the attribute key as a string, or "" if the
+// record does not have it.
+func (r Record) Attr(key string) string {
+	for _, a := range r.Attrs {
+		if a.Key == key {
+			return a.Value.String()
+		}
+	}
+
+	return ""
+}
+
+// Filter narrows down the records returned by Records. Zero values mean "no
+// restriction".
+type Filter struct {
+	// Level is the minimum level to include.
+	Level slog.Level
+	// Route matches records whose "uri" attribute contains it.
+	Route string
+	// UserID matches the "user_id" attribute exactly.
+	UserID string
+	// RequestID matches the "request_id" attribute exactly.
+	RequestID string
+	// Limit caps the number of records returned, newest first.
+	Limit int
+}
+
+func (f Filter) match(r Record) bool {
+	switch {
+	case r.Level < f.Level:
+		return false
+	case f.Route != "" && !strings.Contains(r.Attr("uri"), f.Route):
+		return false
+	case f.UserID != "" && r.Attr("user_id") != f.UserID:
+		return false
+	case f.RequestID != "" && r.Attr("request_id") != f.RequestID:
+		return false
+	}
+
+	return true
+}
+
+// Buffer is a fixed-size ring of the latest log records. It is safe for
+// concurrent use.
+type Buffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// New returns a Buffer holding up to size records.
+func New(size int) *Buffer {
+	return &Buffer{records: make([]Record, max(size, 1))}
+}
+
+func (b *Buffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Records returns the buffered records matching f, newest first.
+func (b *Buffer) Records(f Filter) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.next
+	if b.full {
+		n = len(b.records)
+	}
+
+	var out []Record
+
+	for i := range n {
+		r := b.records[(b.next-1-i+len(b.records))%len(b.records)]
+		if !f.match(r) {
+			continue
+		}
+
+		out = append(out, r)
+
+		if f.Limit > 0 && len(out) >= f.Limit {
+			break
+		}
+	}
+
+	return out
+}
+
+// Handler returns a slog.Handler that records every record at Info level or
+// above, and anything else next is enabled for, in the buffer before passing
+// it on to next. Records next is not enabled for are only buffered.
+func (b *Buffer) Handler(next slog.Handler) slog.Handler {
+	return &handler{buf: b, next: next}
+}
+
+type handler struct {
+	buf    *Buffer
+	next   slog.Handler
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	rec := Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   slices.Clone(h.attrs),
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = appendAttr(rec.Attrs, h.prefix, a)
+
+		return true
+	})
+
+	h.buf.add(rec)
+
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r) //nolint:wrapcheck // pass the wrapped handler's error through
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = slices.Clone(h.attrs)
+
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// appendAttr flattens a, prefixing its key with the enclosing groups.
+func appendAttr(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+
+		return attrs
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+
+	a.Key = prefix + a.Key
+
+	return append(attrs, a)
+}
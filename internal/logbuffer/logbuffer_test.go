@@ -0,0 +1,48 @@
+package logbuffer
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	buf := New(3)
+	logger := slog.New(buf.Handler(slog.DiscardHandler))
+
+	logger.Info("first", slog.String("uri", "/snippet/view/1"))
+	logger.Debug("not enabled")
+	logger.Warn("second", slog.String("uri", "/user/login"), slog.Int("user_id", 7))
+	logger.With(slog.String("request_id", "abc")).Error("third", slog.String("uri", "/snippet/create"))
+	logger.WithGroup("db").Info("fourth", slog.Group("pool", slog.Int("conns", 4)))
+
+	all := buf.Records(Filter{})
+	assert.Equal(t, len(all), 3)
+	assert.Equal(t, all[0].Message, "fourth")
+	assert.Equal(t, all[0].Attr("db.pool.conns"), "4")
+	assert.Equal(t, all[2].Message, "second")
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"Level", Filter{Level: slog.LevelWarn}, []string{"third", "second"}},
+		{"Route", Filter{Route: "/snippet/"}, []string{"third"}},
+		{"User", Filter{UserID: "7"}, []string{"second"}},
+		{"Request ID", Filter{RequestID: "abc"}, []string{"third"}},
+		{"Limit", Filter{Limit: 1}, []string{"fourth"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := buf.Records(tt.filter)
+			assert.Equal(t, len(records), len(tt.want))
+
+			for i, r := range records {
+				assert.Equal(t, r.Message, tt.want[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrNoProviders is returned by Failover.Send when no provider could
+// deliver the message.
+var ErrNoProviders = errors.New("mailer: all providers failed")
+
+// ProviderStatus is the health of a single provider as tracked by Failover.
+type ProviderStatus struct {
+	Name                string
+	Healthy             bool
+	ConsecutiveFailures int
+	LastError           string
+	LastSuccess         time.Time
+	RetryAfter          time.Time
+}
+
+// Failover sends through the first healthy provider, in order of
+// preference. A provider that fails is skipped for a cooldown that doubles
+// with every consecutive failure, up to MaxCooldown; one success restores
+// it. When every provider is cooling down, all of them are tried anyway so
+// a recovered service is noticed without waiting.
+type Failover struct {
+	BaseCooldown time.Duration
+	MaxCooldown  time.Duration
+	Logger       *slog.Logger
+
+	mu        sync.Mutex
+	providers []Provider
+	status    []ProviderStatus
+	now       func() time.Time
+}
+
+func NewFailover(logger *slog.Logger, providers ...Provider) *Failover {
+	status := make([]ProviderStatus, len(providers))
+	for i, p := range providers {
+		status[i] = ProviderStatus{Name: p.Name(), Healthy: true}
+	}
+
+	return &Failover{
+		BaseCooldown: 30 * time.Second,
+		MaxCooldown:  30 * time.Minute,
+		Logger:       logger,
+		providers:    providers,
+		status:       status,
+		now:          time.Now,
+	}
+}
+
+func (f *Failover) Send(ctx context.Context, msg Message) error {
+	var errs []error
+
+	for _, i := range f.order() {
+		err := f.providers[i].Send(ctx, msg)
+		f.record(i, err)
+
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrNoProviders, errors.Join(errs...))
+}
+
+// Status returns a snapshot of every provider's health.
+func (f *Failover) Status() []ProviderStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]ProviderStatus, len(f.status))
+	copy(out, f.status)
+
+	return out
+}
+
+// order lists the provider indexes to try: healthy ones first, in order of
+// preference, then those cooling down.
+func (f *Failover) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+
+	var ready, cooling []int
+
+	for i, s := range f.status {
+		if now.Before(s.RetryAfter) {
+			cooling = append(cooling, i)
+		} else {
+			ready = append(ready, i)
+		}
+	}
+
+	return append(ready, cooling...)
+}
+
+func (f *Failover) record(i int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := &f.status[i]
+	now := f.now()
+
+	if err == nil {
+		if !s.Healthy {
+			f.Logger.Info("mail provider recovered", slog.String("provider", s.Name))
+		}
+
+		s.Healthy = true
+		s.ConsecutiveFailures = 0
+		s.LastSuccess = now
+		s.RetryAfter = time.Time{}
+
+		return
+	}
+
+	s.ConsecutiveFailures++
+	s.LastError = err.Error()
+
+	cooldown := f.MaxCooldown
+	// Guard the shift so long failure streaks cannot overflow.
+	if s.ConsecutiveFailures < 32 {
+		if d := f.BaseCooldown << (s.ConsecutiveFailures - 1); d > 0 && d < f.MaxCooldown {
+			cooldown = d
+		}
+	}
+
+	s.RetryAfter = now.Add(cooldown)
+
+	if s.Healthy {
+		f.Logger.Warn("mail provider unhealthy", slog.String("provider", s.Name), slog.String("err", err.Error()))
+	}
+
+	s.Healthy = false
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+type fakeProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Send(ctx context.Context, msg Message) error {
+	p.calls++
+
+	return p.err
+}
+
+func TestFailover(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	primary := &fakeProvider{name: "primary", err: errors.New("connection refused")}
+	secondary := &fakeProvider{name: "secondary"}
+
+	f := NewFailover(slog.New(slog.DiscardHandler), primary, secondary)
+	f.now = func() time.Time { return now }
+
+	msg := Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"}
+
+	// The primary fails and the secondary takes over.
+	assert.NilError(t, f.Send(t.Context(), msg))
+	assert.Equal(t, primary.calls, 1)
+	assert.Equal(t, secondary.calls, 1)
+
+	status := f.Status()
+	assert.Equal(t, status[0].Healthy, false)
+	assert.Equal(t, status[0].LastError, "connection refused")
+	assert.Equal(t, status[0].RetryAfter, now.Add(30*time.Second))
+	assert.Equal(t, status[1].Healthy, true)
+
+	// While cooling down the primary is not tried first.
+	assert.NilError(t, f.Send(t.Context(), msg))
+	assert.Equal(t, primary.calls, 1)
+	assert.Equal(t, secondary.calls, 2)
+
+	// Once the cooldown is over it is tried again, and recovers.
+	now = now.Add(time.Minute)
+	primary.err = nil
+
+	assert.NilError(t, f.Send(t.Context(), msg))
+	assert.Equal(t, primary.calls, 2)
+	assert.Equal(t, secondary.calls, 2)
+	assert.Equal(t, f.Status()[0].Healthy, true)
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	primary := &fakeProvider{name: "primary", err: errors.New("timeout")}
+	secondary := &fakeProvider{name: "secondary", err: errors.New("status 500")}
+
+	f := NewFailover(slog.New(slog.DiscardHandler), primary, secondary)
+
+	err := f.Send(t.Context(), Message{To: "alice@example.com"})
+	assert.Equal(t, errors.Is(err, ErrNoProviders), true)
+
+	// Providers that are all cooling down are still tried.
+	err = f.Send(t.Context(), Message{To: "alice@example.com"})
+	assert.Equal(t, errors.Is(err, ErrNoProviders), true)
+	assert.Equal(t, primary.calls, 2)
+	assert.Equal(t, secondary.calls, 2)
+	assert.Equal(t, f.Status()[0].ConsecutiveFailures, 2)
+}
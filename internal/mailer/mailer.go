@@ -0,0 +1,63 @@
+// Package mailer sends transactional email such as data export links.
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// sendTimeout bounds a single delivery attempt, so one unresponsive
+// provider cannot stall the queue.
+const sendTimeout = 30 * time.Second
+
+// Message is a plain-text email with an optional HTML alternative.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// HTML, if set, is sent alongside Body for clients that display HTML.
+	HTML string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Provider is a Mailer backed by a named mail service, used by Failover.
+type Provider interface {
+	Mailer
+	Name() string
+}
+
+// LogMailer writes messages to a logger instead of delivering them. It is
+// the default when no mail provider is configured, which keeps local
+// development free of SMTP setup, and replaces the providers in dry-run
+// mode.
+type LogMailer struct {
+	Logger *slog.Logger
+	// Reason is logged with every message, "no mail provider configured"
+	// if empty.
+	Reason string
+}
+
+func (m *LogMailer) Name() string {
+	return "log"
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	reason := m.Reason
+	if reason == "" {
+		reason = "no mail provider configured"
+	}
+
+	m.Logger.InfoContext(ctx, "email not sent, "+reason,
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+		slog.Bool("html", msg.HTML != ""),
+	)
+
+	return nil
+}
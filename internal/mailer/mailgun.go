@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mailgun delivers messages through the Mailgun HTTP API. BaseURL defaults
+// to the US region; EU domains use https://api.eu.mailgun.net.
+type Mailgun struct {
+	Domain  string
+	APIKey  string
+	From    string
+	BaseURL string
+	Client  *http.Client
+}
+
+func (m *Mailgun) Name() string {
+	return "mailgun"
+}
+
+func (m *Mailgun) Send(ctx context.Context, msg Message) error {
+	base := m.BaseURL
+	if base == "" {
+		base = "https://api.mailgun.net"
+	}
+
+	form := url.Values{}
+	form.Set("from", m.From)
+	form.Set("to", msg.To)
+	form.Set("subject", msg.Subject)
+	form.Set("text", msg.Body)
+
+	if msg.HTML != "" {
+		form.Set("html", msg.HTML)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", strings.TrimSuffix(base, "/"), url.PathEscape(m.Domain))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("building mailgun request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("api", m.APIKey)
+
+	return doAPIRequest(client(m.Client), req, "mailgun")
+}
+
+func client(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+
+	return &http.Client{Timeout: sendTimeout}
+}
+
+// doAPIRequest sends req and turns non-2xx responses into errors that
+// include the start of the response body.
+func doAPIRequest(c *http.Client, req *http.Request, provider string) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending via %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("sending via %s: status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestMailgunSend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+
+		switch {
+		case r.URL.Path != "/v3/mg.example.com/messages":
+			http.NotFound(w, r)
+		case user != "api" || pass != "key-123":
+			http.Error(w, "Forbidden", http.StatusUnauthorized)
+		case r.FormValue("to") != "alice@example.com" || r.FormValue("subject") != "Hi":
+			http.Error(w, "bad form", http.StatusBadRequest)
+		default:
+			w.Write([]byte(`{"message": "Queued. Thank you."}`)) //nolint:errcheck // test server
+		}
+	}))
+	defer ts.Close()
+
+	m := &Mailgun{Domain: "mg.example.com", APIKey: "key-123", From: "noreply@example.com", BaseURL: ts.URL}
+	msg := Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"}
+
+	assert.NilError(t, m.Send(t.Context(), msg))
+
+	m.APIKey = "wrong"
+
+	err := m.Send(t.Context(), msg)
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("got %v; want status 401 error", err)
+	}
+}
+
+func TestSMTPComposeStripsHeaderBreaks(t *testing.T) {
+	m := &SMTP{From: "noreply@example.com"}
+
+	raw := string(m.compose(Message{
+		To:      "alice@example.com",
+		Subject: "Hi\r\nBcc: mallory@example.com",
+		Body:    "line one\nline two",
+	}))
+
+	assert.StringContains(t, raw, "Subject: HiBcc: mallory@example.com\r\n")
+	assert.StringContains(t, raw, "\r\n\r\nline one\r\nline two")
+}
+
+func TestSMTPComposeMultipart(t *testing.T) {
+	m := &SMTP{From: "noreply@example.com"}
+
+	raw := m.compose(Message{
+		To:      "alice@example.com",
+		Subject: "Hi",
+		Body:    "Hello Alice",
+		HTML:    "<p>Hello <b>Alice</b></p>",
+	})
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	assert.NilError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NilError(t, err)
+	assert.Equal(t, mediaType, "multipart/alternative")
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", "Hello Alice"},
+		{"text/html; charset=UTF-8", "<p>Hello <b>Alice</b></p>"},
+	} {
+		part, err := mr.NextPart()
+		assert.NilError(t, err)
+		assert.Equal(t, part.Header.Get("Content-Type"), want.contentType)
+
+		// The multipart reader decodes quoted-printable parts.
+		body, err := io.ReadAll(part)
+		assert.NilError(t, err)
+		assert.Equal(t, string(body), want.body)
+	}
+
+	_, err = mr.NextPart()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestSMTPSendStopsAtDeadline(t *testing.T) {
+	// A server that accepts connections but never greets the client.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn) //nolint:errcheck // test server
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	m := &SMTP{Addr: ln.Addr().String(), From: "noreply@example.com"}
+
+	start := time.Now()
+	err = m.Send(ctx, Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"})
+
+	if err == nil {
+		t.Fatal("got nil error; want a timeout")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send took %v; want it to stop at the deadline", elapsed)
+	}
+}
@@ -0,0 +1,138 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SES delivers messages through the Amazon SES v2 API.
+type SES struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	From            string
+	Client          *http.Client
+
+	// Endpoint overrides the regional API endpoint, e.g. for testing.
+	Endpoint string
+}
+
+func (m *SES) Name() string {
+	return "ses"
+}
+
+func (m *SES) Send(ctx context.Context, msg Message) error {
+	content := map[string]any{
+		"Text": map[string]string{"Data": msg.Body, "Charset": "UTF-8"},
+	}
+
+	if msg.HTML != "" {
+		content["Html"] = map[string]string{"Data": msg.HTML, "Charset": "UTF-8"}
+	}
+
+	payload := map[string]any{
+		"FromEmailAddress": m.From,
+		"Destination":      map[string]any{"ToAddresses": []string{msg.To}},
+		"Content": map[string]any{
+			"Simple": map[string]any{
+				"Subject": map[string]string{"Data": msg.Subject, "Charset": "UTF-8"},
+				"Body":    content,
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding ses request: %w", err)
+	}
+
+	endpoint := m.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", m.Region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(endpoint, "/")+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building ses request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	signV4(req, body, m.AccessKeyID, m.SecretAccessKey, m.Region, "ses", time.Now())
+
+	return doAPIRequest(client(m.Client), req, "ses")
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req,
+// signing the host and x-amz-date headers and the payload.
+func signV4(req *http.Request, body []byte, accessKeyID, secret, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+
+	const signedHeaders = "host;x-amz-date"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		canonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" + "x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(q url.Values) string {
+	// Encode sorts by key; AWS expects spaces as %20 rather than +.
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,154 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTP delivers messages through an SMTP server, authenticating with PLAIN
+// auth when a username is set.
+type SMTP struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTP) Name() string {
+	return "smtp"
+}
+
+func (m *SMTP) Send(ctx context.Context, msg Message) error {
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return fmt.Errorf("parsing smtp address: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return fmt.Errorf("sending via smtp: %w", err)
+	}
+
+	// The deadline covers the whole conversation, so a server that stops
+	// responding cannot hold the worker.
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	if err := m.send(conn, host, msg); err != nil {
+		conn.Close()
+
+		return fmt.Errorf("sending via smtp: %w", err)
+	}
+
+	return nil
+}
+
+// send runs the SMTP conversation that smtp.SendMail would, over conn.
+func (m *SMTP) send(conn net.Conn, host string, msg Message) error {
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(m.compose(msg)); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// compose renders msg as an RFC 5322 message, a multipart/alternative one
+// if it has an HTML version.
+func (m *SMTP) compose(msg Message) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(m.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+		return b.Bytes()
+	}
+
+	mw := multipart.NewWriter(&b)
+
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%s\r\n", mw.Boundary())
+	b.WriteString("\r\n")
+
+	// Clients show the last alternative they support, so HTML comes last.
+	writePart(mw, "text/plain", msg.Body)
+	writePart(mw, "text/html", msg.HTML)
+
+	_ = mw.Close() // writes to a bytes.Buffer cannot fail
+
+	return b.Bytes()
+}
+
+// writePart adds content as a quoted-printable part, which keeps long lines
+// within the limits of SMTP.
+func writePart(mw *multipart.Writer, contentType, content string) {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	// Writes to a bytes.Buffer cannot fail.
+	part, _ := mw.CreatePart(header)
+	qp := quotedprintable.NewWriter(part)
+	_, _ = qp.Write([]byte(strings.ReplaceAll(content, "\n", "\r\n")))
+	_ = qp.Close()
+}
+
+// headerValue strips line breaks so values cannot inject extra headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
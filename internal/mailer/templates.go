@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates renders emails from a directory of templates, such as an
+// embed.FS. Each email NAME has a plain-text template NAME.txt.tmpl
+// defining a "subject" and a "body", and may have an HTML alternative
+// NAME.html.tmpl defining a "content". The shared base.txt.tmpl and
+// base.html.tmpl, if present, are parsed into every template, and the HTML
+// one must define an "html" template that wraps the "content".
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// ParseTemplates parses the templates of the named emails from fsys. funcs
+// are made available to both the text and the HTML templates.
+func ParseTemplates(fsys fs.FS, funcs map[string]any, names ...string) (*Templates, error) {
+	t := &Templates{
+		text: make(map[string]*texttemplate.Template, len(names)),
+		html: make(map[string]*htmltemplate.Template, len(names)),
+	}
+
+	for _, name := range names {
+		files, err := withBase(fsys, "base.txt.tmpl", name+".txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := texttemplate.New(name).Funcs(funcs).ParseFS(fsys, files...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s email template: %w", name, err)
+		}
+
+		t.text[name] = text
+
+		if _, err := fs.Stat(fsys, name+".html.tmpl"); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		files, err = withBase(fsys, "base.html.tmpl", name+".html.tmpl")
+		if err != nil {
+			return nil, err
+		}
+
+		html, err := htmltemplate.New(name).Funcs(funcs).ParseFS(fsys, files...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s html email template: %w", name, err)
+		}
+
+		t.html[name] = html
+	}
+
+	return t, nil
+}
+
+// withBase prepends base to files if it exists in fsys.
+func withBase(fsys fs.FS, base string, files ...string) ([]string, error) {
+	_, err := fs.Stat(fsys, base)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return files, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading email templates: %w", err)
+	}
+
+	return append([]string{base}, files...), nil
+}
+
+// Render renders the email name, addressed to to, with data.
+func (t *Templates) Render(name, to string, data any) (Message, error) {
+	text, ok := t.text[name]
+	if !ok {
+		return Message{}, fmt.Errorf("the email template for %s does not exist", name)
+	}
+
+	var subject, body bytes.Buffer
+
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s email subject: %w", name, err)
+	}
+
+	if err := text.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, fmt.Errorf("rendering %s email body: %w", name, err)
+	}
+
+	msg := Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    body.String(),
+	}
+
+	if html, ok := t.html[name]; ok {
+		var b bytes.Buffer
+
+		if err := html.ExecuteTemplate(&b, "html", data); err != nil {
+			return Message{}, fmt.Errorf("rendering %s html email: %w", name, err)
+		}
+
+		msg.HTML = b.String()
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	fsys := fstest.MapFS{
+		"base.txt.tmpl":     file(`{{define "footer"}}-- {{shout "the team"}}{{end}}`),
+		"base.html.tmpl":    file(`{{define "html"}}<body>{{template "content" .}}</body>{{end}}`),
+		"welcome.txt.tmpl":  file(`{{define "subject"}} Welcome, {{.}} {{end}}{{define "body"}}Hi {{.}}{{template "footer"}}{{end}}`),
+		"welcome.html.tmpl": file(`{{define "content"}}<p>Hi {{.}}</p>{{end}}`),
+		"plain.txt.tmpl":    file(`{{define "subject"}}Plain{{end}}{{define "body"}}Just text{{end}}`),
+	}
+
+	funcs := map[string]any{"shout": strings.ToUpper}
+
+	ts, err := ParseTemplates(fsys, funcs, "welcome", "plain")
+	assert.NilError(t, err)
+
+	msg, err := ts.Render("welcome", "alice@example.com", "<Alice>")
+	assert.NilError(t, err)
+	assert.Equal(t, msg.To, "alice@example.com")
+	assert.Equal(t, msg.Subject, "Welcome, <Alice>")
+	assert.Equal(t, msg.Body, "Hi <Alice>-- THE TEAM")
+	// The HTML alternative escapes its data.
+	assert.Equal(t, msg.HTML, "<body><p>Hi &lt;Alice&gt;</p></body>")
+
+	msg, err = ts.Render("plain", "bob@example.com", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, msg.Body, "Just text")
+	assert.Equal(t, msg.HTML, "")
+
+	_, err = ts.Render("missing", "bob@example.com", nil)
+	assert.Equal(t, err != nil, true)
+
+	_, err = ParseTemplates(fsys, funcs, "unknown")
+	assert.Equal(t, err != nil, true)
+}
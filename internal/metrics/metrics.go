@@ -0,0 +1,272 @@
+// Package metrics keeps counters and histograms with labels and exposes
+// them in the Prometheus text format, version 0.0.4, for scraping.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of histogram buckets
+// suited to request and query durations.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds the metrics of an application.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// metric is a family of series sharing a name.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{}
+}
+
+// labelKey joins label values into a map key. The separator cannot appear
+// in valid UTF-8 text.
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*counter
+}
+
+type counter struct {
+	values []string
+	count  float64
+}
+
+// NewCounterVec registers a counter family called name with the given label
+// names.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, series: make(map[string]*counter)}
+	r.register(c)
+
+	return c
+}
+
+// Inc adds one to the counter with the given label values, which must be
+// as many as the family's labels.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the counter with the given
+// label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	checkLabels(c.name, c.labels, values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := labelKey(values)
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counter{values: slices.Clone(values)}
+		c.series[key] = s
+	}
+
+	s.count += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+
+	for _, key := range slices.Sorted(maps.Keys(c.series)) {
+		s := c.series[key]
+		writeSample(w, c.name, c.labels, s.values, "", s.count)
+	}
+}
+
+// HistogramVec is a family of histograms partitioned by labels.
+type HistogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	values []string
+	// counts[i] is the number of observations in bucket i alone; they are
+	// made cumulative when written.
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram family called name with the given
+// bucket upper bounds, in increasing order, and label names.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !slices.IsSorted(buckets) {
+		panic("metrics: buckets of " + name + " are not in increasing order")
+	}
+
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: slices.Clone(buckets),
+		series:  make(map[string]*histogram),
+	}
+	r.register(h)
+
+	return h
+}
+
+// Observe records v in the histogram with the given label values.
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	checkLabels(h.name, h.labels, values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := labelKey(values)
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{values: slices.Clone(values), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+
+	labels := append(slices.Clone(h.labels), "le")
+
+	for _, key := range slices.Sorted(maps.Keys(h.series)) {
+		s := h.series[key]
+
+		var cumulative uint64
+
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.name, labels, append(slices.Clone(s.values), formatFloat(bound)), "_bucket", float64(cumulative))
+		}
+
+		writeSample(w, h.name, labels, append(slices.Clone(s.values), "+Inf"), "_bucket", float64(s.count))
+		writeSample(w, h.name, h.labels, s.values, "_sum", s.sum)
+		writeSample(w, h.name, h.labels, s.values, "_count", float64(s.count))
+	}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText writes every metric in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, m := range metrics {
+		m.write(bw)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Handler serves the metrics to Prometheus.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w) //nolint:errcheck // the scraper sees a truncated response
+	})
+}
+
+// checkLabels panics if values do not match labels, a programming error
+// that would otherwise produce series Prometheus rejects.
+func checkLabels(name string, labels, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", name, len(labels), len(values)))
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, kind)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, suffix string, v float64) {
+	w.WriteString(name)
+	w.WriteString(suffix)
+
+	if len(labels) > 0 {
+		w.WriteByte('{')
+
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			fmt.Fprintf(w, `%s="%s"`, label, labelEscaper.Replace(values[i]))
+		}
+
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	r := New()
+
+	requests := r.NewCounterVec("http_requests_total", "Requests served.\nBy route.", "route", "status")
+	requests.Inc("/snippet/view/{id}", "200")
+	requests.Inc("/snippet/view/{id}", "200")
+	requests.Inc(`/say "hi"\`, "404")
+
+	durations := r.NewHistogramVec("query_seconds", "Query durations.", []float64{0.1, 1}, "query")
+	durations.Observe(0.05, "Get")
+	durations.Observe(0.1, "Get")
+	durations.Observe(0.5, "Get")
+	durations.Observe(3, "Get")
+
+	var b strings.Builder
+
+	assert.NilError(t, r.WriteText(&b))
+	assert.Equal(t, b.String(), `# HELP http_requests_total Requests served.\nBy route.
+# TYPE http_requests_total counter
+http_requests_total{route="/say \"hi\"\\",status="404"} 1
+http_requests_total{route="/snippet/view/{id}",status="200"} 2
+# HELP query_seconds Query durations.
+# TYPE query_seconds histogram
+query_seconds_bucket{query="Get",le="0.1"} 2
+query_seconds_bucket{query="Get",le="1"} 3
+query_seconds_bucket{query="Get",le="+Inf"} 4
+query_seconds_sum{query="Get"} 3.65
+query_seconds_count{query="Get"} 4
+`)
+}
+
+func TestHandler(t *testing.T) {
+	r := New()
+	r.NewCounterVec("up_total", "Scrapes.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+	assert.StringContains(t, rec.Body.String(), "up_total 1\n")
+}
+
+func TestLabelMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a missing label value")
+		}
+	}()
+
+	New().NewCounterVec("requests_total", "Requests.", "route", "status").Inc("/")
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AnalyticsModelInterface interface {
+	RecordView(ctx context.Context, snippetID int, referrer, uaFamily string) error
+	SnippetStats(ctx context.Context, snippetID int) (SnippetStats, error)
+}
+
+// StatCount is a label with the number of views attributed to it.
+type StatCount struct {
+	Label string
+	Views int
+}
+
+// SnippetStats summarises the view rollups of a single snippet.
+type SnippetStats struct {
+	TotalViews   int
+	TopReferrers []StatCount
+	UserAgents   []StatCount
+}
+
+type AnalyticsModel struct {
+	DB *pgxpool.Pool
+}
+
+// RecordView increments today's rollup bucket for the given snippet,
+// referrer host and user-agent family.
+func (m *AnalyticsModel) RecordView(ctx context.Context, snippetID int, referrer, uaFamily string) error {
+	stmt := `
+		INSERT INTO snippet_view_rollups (snippet_id, day, referrer, ua_family, views)
+		VALUES ($1, (NOW() AT TIME ZONE 'UTC')::date, $2, $3, 1)
+		ON CONFLICT (snippet_id, day, referrer, ua_family)
+		DO UPDATE SET views = snippet_view_rollups.views + 1
+	`
+
+	_, err := m.DB.Exec(ctx, stmt, snippetID, referrer, uaFamily)
+	if err != nil {
+		return fmt.Errorf("recording snippet view: %w", err)
+	}
+
+	return nil
+}
+
+func (m *AnalyticsModel) SnippetStats(ctx context.Context, snippetID int) (SnippetStats, error) {
+	var stats SnippetStats
+
+	stmt := `SELECT COALESCE(SUM(views), 0) FROM snippet_view_rollups WHERE snippet_id = $1`
+
+	err := m.DB.QueryRow(ctx, stmt, snippetID).Scan(&stats.TotalViews)
+	if err != nil {
+		return SnippetStats{}, fmt.Errorf("counting snippet views: %w", err)
+	}
+
+	stats.TopReferrers, err = m.topCounts(ctx, "referrer", snippetID)
+	if err != nil {
+		return SnippetStats{}, err
+	}
+
+	stats.UserAgents, err = m.topCounts(ctx, "ua_family", snippetID)
+	if err != nil {
+		return SnippetStats{}, err
+	}
+
+	return stats, nil
+}
+
+// topCounts groups the rollups of a snippet by column, which must be one of
+// the fixed column names used above and never user input.
+func (m *AnalyticsModel) topCounts(ctx context.Context, column string, snippetID int) ([]StatCount, error) {
+	//nolint:gosec // column is a hard-coded identifier, not user input
+	stmt := fmt.Sprintf(`
+		SELECT %[1]s, SUM(views) AS total
+		FROM snippet_view_rollups
+		WHERE snippet_id = $1
+		GROUP BY %[1]s
+		ORDER BY total DESC, %[1]s
+		LIMIT 10
+	`, column)
+
+	rows, err := m.DB.Query(ctx, stmt, snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s counts: %w", column, err)
+	}
+	defer rows.Close()
+
+	var counts []StatCount
+
+	for rows.Next() {
+		var c StatCount
+		if err := rows.Scan(&c.Label, &c.Views); err != nil {
+			return nil, fmt.Errorf("scanning %s count: %w", column, err)
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating %s counts: %w", column, err)
+	}
+
+	return counts, nil
+}
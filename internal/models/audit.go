@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Audit event names recorded in the audit_events table.
+const (
+	AuditSignup         = "signup"
+	AuditLogin          = "login"
+	AuditLoginFailed    = "login_failed"
+	AuditPasswordChange = "password_change"
+	AuditTokenCreate    = "token_create"
+	AuditSuspend        = "suspend"
+	AuditUnsuspend      = "unsuspend"
+	AuditReauth         = "reauth"
+	AuditReauthFailed   = "reauth_failed"
+	AuditTokenRevoke    = "token_revoke"
+	AuditTokenNewIP     = "token_new_ip"
+	AuditIncidentCreate = "incident_create"
+	AuditIncidentUpdate = "incident_update"
+	AuditSettingsUpdate = "settings_update"
+	AuditDataExport     = "data_export"
+	AuditPasskeyAdd     = "passkey_add"
+	AuditPasskeyRemove  = "passkey_remove"
+)
+
+type AuditModelInterface interface {
+	Insert(ctx context.Context, userID int, event, ip, details string) error
+	List(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
+}
+
+// AuditEvent is a single row of the audit log. UserID is 0 when the event
+// could not be tied to an account, e.g. a failed login for an unknown email.
+type AuditEvent struct {
+	ID      int
+	UserID  int
+	Event   string
+	IP      string
+	Details string
+	Created time.Time
+}
+
+// AuditFilter narrows down the events returned by List. Zero values mean
+// "no restriction".
+type AuditFilter struct {
+	UserID int
+	Event  string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+type AuditModel struct {
+	DB *pgxpool.Pool
+}
+
+func (m *AuditModel) Insert(ctx context.Context, userID int, event, ip, details string) error {
+	stmt := `
+		INSERT INTO audit_events (user_id, event, ip, details, created)
+		VALUES (NULLIF($1, 0), $2, $3, $4, NOW() AT TIME ZONE 'UTC')
+	`
+
+	_, err := dbFor(ctx, m.DB).Exec(ctx, stmt, userID, event, ip, details)
+	if err != nil {
+		return fmt.Errorf("inserting audit event: %w", err)
+	}
+
+	return nil
+}
+
+func (m *AuditModel) List(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	var (
+		conds []string
+		args  []any
+	)
+
+	if filter.UserID != 0 {
+		args = append(args, filter.UserID)
+		conds = append(conds, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+
+	if filter.Event != "" {
+		args = append(args, filter.Event)
+		conds = append(conds, fmt.Sprintf("event = $%d", len(args)))
+	}
+
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.UTC())
+		conds = append(conds, fmt.Sprintf("created >= $%d", len(args)))
+	}
+
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.UTC())
+		conds = append(conds, fmt.Sprintf("created < $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	args = append(args, limit)
+
+	stmt := `SELECT id, COALESCE(user_id, 0), event, ip, details, created FROM audit_events`
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += fmt.Sprintf(" ORDER BY created DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := dbFor(ctx, m.DB).Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []AuditEvent
+
+	for rows.Next() {
+		var e AuditEvent
+		err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.IP, &e.Details, &e.Created)
+		if err != nil {
+			return nil, fmt.Errorf("scanning audit event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxEmailAttempts is how often delivery of a queued email is attempted
+// before it is given up. Emails given up on stay in the queue as dead
+// letters until an admin requeues them.
+const MaxEmailAttempts = 10
+
+type EmailQueueModelInterface interface {
+	Enqueue(ctx context.Context, recipient, subject, body, html string) error
+	Due(ctx context.Context, limit int) ([]QueuedEmail, error)
+	MarkSent(ctx context.Context, id int) error
+	Retry(ctx context.Context, id int, next time.Time, lastError string) error
+	Failed(ctx context.Context, limit int) ([]QueuedEmail, error)
+	Requeue(ctx context.Context, id int) error
+}
+
+// QueuedEmail is an email waiting in the outbox.
+type QueuedEmail struct {
+	ID        int
+	Recipient string
+	Subject   string
+	Body      string
+	HTML      string
+	Attempts  int
+	Created   time.Time
+	// LastError is the error of the last failed delivery attempt.
+	LastError string
+}
+
+type EmailQueueModel struct {
+	DB *pgxpool.Pool
+}
+
+// Enqueue queues an email for delivery. html is its HTML alternative, or
+// empty for a plain-text email.
+func (m *EmailQueueModel) Enqueue(ctx context.Context, recipient, subject, body, html string) error {
+	stmt := `
+		INSERT INTO email_queue (recipient, subject, body, html, created, next_attempt)
+		VALUES ($1, $2, $3, $4, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC')
+	`
+
+	if _, err := m.DB.Exec(ctx, stmt, recipient, subject, body, html); err != nil {
+		return fmt.Errorf("queueing email: %w", err)
+	}
+
+	return nil
+}
+
+// Due returns unsent emails whose next attempt is due, oldest first. Rows
+// are locked with SKIP LOCKED so several instances can share the queue
+// without sending an email twice; the lock only lasts for the query, so
+// callers must handle each email promptly.
+func (m *EmailQueueModel) Due(ctx context.Context, limit int) ([]QueuedEmail, error) {
+	stmt := `
+		UPDATE email_queue
+		SET next_attempt = NOW() AT TIME ZONE 'UTC' + INTERVAL '5 minutes'
+		WHERE id IN (
+			SELECT id FROM email_queue
+			WHERE sent IS NULL AND attempts < $1 AND next_attempt <= NOW() AT TIME ZONE 'UTC'
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, body, html, attempts, created
+	`
+
+	rows, err := m.DB.Query(ctx, stmt, MaxEmailAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claiming queued emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []QueuedEmail
+
+	for rows.Next() {
+		var e QueuedEmail
+		if err := rows.Scan(&e.ID, &e.Recipient, &e.Subject, &e.Body, &e.HTML, &e.Attempts, &e.Created); err != nil {
+			return nil, fmt.Errorf("scanning queued email: %w", err)
+		}
+		emails = append(emails, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating queued emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+func (m *EmailQueueModel) MarkSent(ctx context.Context, id int) error {
+	stmt := `UPDATE email_queue SET sent = NOW() AT TIME ZONE 'UTC', attempts = attempts + 1 WHERE id = $1`
+
+	if _, err := m.DB.Exec(ctx, stmt, id); err != nil {
+		return fmt.Errorf("marking email sent: %w", err)
+	}
+
+	return nil
+}
+
+// Retry records a failed delivery attempt and schedules the next one.
+func (m *EmailQueueModel) Retry(ctx context.Context, id int, next time.Time, lastError string) error {
+	stmt := `
+		UPDATE email_queue
+		SET attempts = attempts + 1, next_attempt = $2, last_error = $3
+		WHERE id = $1
+	`
+
+	if _, err := m.DB.Exec(ctx, stmt, id, next.UTC(), lastError); err != nil {
+		return fmt.Errorf("rescheduling email: %w", err)
+	}
+
+	return nil
+}
+
+// Failed returns the most recent dead letters: unsent emails that used up
+// all their delivery attempts, newest first.
+func (m *EmailQueueModel) Failed(ctx context.Context, limit int) ([]QueuedEmail, error) {
+	stmt := `
+		SELECT id, recipient, subject, body, html, attempts, created, last_error
+		FROM email_queue
+		WHERE sent IS NULL AND attempts >= $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := m.DB.Query(ctx, stmt, MaxEmailAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying failed emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []QueuedEmail
+
+	for rows.Next() {
+		var e QueuedEmail
+
+		err := rows.Scan(&e.ID, &e.Recipient, &e.Subject, &e.Body, &e.HTML, &e.Attempts, &e.Created, &e.LastError)
+		if err != nil {
+			return nil, fmt.Errorf("scanning failed email: %w", err)
+		}
+		emails = append(emails, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating failed emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+// Requeue gives a dead letter a fresh set of delivery attempts, starting
+// now. It returns ErrNoRecord if id is not a dead letter.
+func (m *EmailQueueModel) Requeue(ctx context.Context, id int) error {
+	stmt := `
+		UPDATE email_queue
+		SET attempts = 0, next_attempt = NOW() AT TIME ZONE 'UTC', last_error = ''
+		WHERE id = $1 AND sent IS NULL AND attempts >= $2
+	`
+
+	tag, err := m.DB.Exec(ctx, stmt, id, MaxEmailAttempts)
+	if err != nil {
+		return fmt.Errorf("requeueing email: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
@@ -1,9 +1,19 @@
 package models
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
+)
 
 var (
-	ErrNoRecord           = errors.New("models: no matching record found")
+	// ErrNoRecord is a not found error, so handlers passing it to
+	// errorx-aware code answer with a 404 without checking for it.
+	ErrNoRecord           = errorx.New(errorx.NotFound, "models: no matching record found")
 	ErrInvalidCredentials = errors.New("models: invalid credentials")
 	ErrDuplicateEmail     = errors.New("models: duplicate email")
+	ErrDuplicatePasskey   = errors.New("models: duplicate passkey")
+	// ErrEditConflict means a record was changed by someone else since
+	// the version a change was based on.
+	ErrEditConflict = errors.New("models: edit conflict")
 )
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// snippetCreatedChannel is the notification channel that the trigger added
+// by migration 0002 announces new snippet ids on.
+const snippetCreatedChannel = "snippet_created"
+
+// ListenSnippetsCreated calls fn with the id of every snippet inserted from
+// now on, by this or any other instance, until ctx is cancelled or the
+// connection fails. It holds one connection of the pool while it runs.
+func ListenSnippetsCreated(ctx context.Context, db *pgxpool.Pool, fn func(id int)) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection to listen: %w", err)
+	}
+	// The connection is still listening, so it must not go back to the pool.
+	defer func() {
+		_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+snippetCreatedChannel); err != nil {
+		return fmt.Errorf("listening for new snippets: %w", err)
+	}
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for new snippets: %w", err)
+		}
+
+		id, err := strconv.Atoi(n.Payload)
+		if err != nil {
+			continue
+		}
+
+		fn(id)
+	}
+}
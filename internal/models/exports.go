@@ -0,0 +1,150 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExportModelInterface interface {
+	Create(ctx context.Context, userID int) (Export, string, error)
+	Complete(ctx context.Context, id int, archive []byte) error
+	Latest(ctx context.Context, userID int) (Export, error)
+	Download(ctx context.Context, userID int, token string) (Export, error)
+}
+
+// Export is a user's data export. The archive is generated in the
+// background; Completed is the zero time until it is ready.
+type Export struct {
+	ID        int
+	UserID    int
+	Created   time.Time
+	Completed time.Time
+	Expires   time.Time
+	Archive   []byte
+}
+
+func (e Export) Ready() bool {
+	return !e.Completed.IsZero()
+}
+
+type ExportModel struct {
+	DB *pgxpool.Pool
+}
+
+// Create replaces any previous export of the user with a pending one and
+// returns it together with the download token, which is only stored hashed.
+func (m *ExportModel) Create(ctx context.Context, userID int) (Export, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return Export{}, "", fmt.Errorf("generating export token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return Export{}, "", fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	if _, err := tx.Exec(ctx, `DELETE FROM data_exports WHERE user_id = $1`, userID); err != nil {
+		return Export{}, "", fmt.Errorf("deleting previous exports: %w", err)
+	}
+
+	stmt := `
+		INSERT INTO data_exports (user_id, token_hash, created, expires)
+		VALUES ($1, $2, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC' + INTERVAL '7 days')
+		RETURNING id, created, expires
+	`
+
+	e := Export{UserID: userID}
+
+	err = tx.QueryRow(ctx, stmt, userID, hashToken(token)).Scan(&e.ID, &e.Created, &e.Expires)
+	if err != nil {
+		return Export{}, "", fmt.Errorf("inserting export: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Export{}, "", fmt.Errorf("committing export: %w", err)
+	}
+
+	return e, token, nil
+}
+
+func (m *ExportModel) Complete(ctx context.Context, id int, archive []byte) error {
+	stmt := `UPDATE data_exports SET archive = $1, completed = NOW() AT TIME ZONE 'UTC' WHERE id = $2`
+
+	tag, err := m.DB.Exec(ctx, stmt, archive, id)
+	if err != nil {
+		return fmt.Errorf("completing export: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// Latest returns the user's most recent export without its archive.
+func (m *ExportModel) Latest(ctx context.Context, userID int) (Export, error) {
+	stmt := `
+		SELECT id, user_id, created, completed, expires
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY created DESC
+		LIMIT 1
+	`
+
+	var (
+		e         Export
+		completed *time.Time
+	)
+
+	err := m.DB.QueryRow(ctx, stmt, userID).Scan(&e.ID, &e.UserID, &e.Created, &completed, &e.Expires)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Export{}, ErrNoRecord
+		}
+
+		return Export{}, fmt.Errorf("fetching export: %w", err)
+	}
+
+	if completed != nil {
+		e.Completed = *completed
+	}
+
+	return e, nil
+}
+
+// Download returns the completed, unexpired export of the user matching the
+// token, or ErrNoRecord.
+func (m *ExportModel) Download(ctx context.Context, userID int, token string) (Export, error) {
+	stmt := `
+		SELECT id, user_id, created, completed, expires, archive
+		FROM data_exports
+		WHERE user_id = $1 AND token_hash = $2
+		AND completed IS NOT NULL AND expires > NOW() AT TIME ZONE 'UTC'
+	`
+
+	var e Export
+
+	err := m.DB.QueryRow(ctx, stmt, userID, hashToken(token)).
+		Scan(&e.ID, &e.UserID, &e.Created, &e.Completed, &e.Expires, &e.Archive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Export{}, ErrNoRecord
+		}
+
+		return Export{}, fmt.Errorf("fetching export archive: %w", err)
+	}
+
+	return e, nil
+}
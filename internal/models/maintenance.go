@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PurgeExpiredSnippets deletes the expired snippets, along with their view
+// rollups, and returns how many it deleted.
+func PurgeExpiredSnippets(ctx context.Context, db *pgxpool.Pool) (int64, error) {
+	stmt := `DELETE FROM snippets WHERE expires < NOW() AT TIME ZONE 'UTC'`
+
+	tag, err := db.Exec(ctx, stmt)
+	if err != nil {
+		return 0, fmt.Errorf("purging expired snippets: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// RollupDailyStats recomputes the daily_stats rows of every day from since
+// to today, in UTC. Totals never go down, so days whose snippets have since
+// been purged keep the counts recorded before the purge.
+func RollupDailyStats(ctx context.Context, db *pgxpool.Pool, since time.Time) error {
+	stmt := `
+		WITH days AS (
+			SELECT generate_series($1::date, (NOW() AT TIME ZONE 'UTC')::date, '1 day')::date AS day
+		)
+		INSERT INTO daily_stats (day, snippets_created, users_created, views, updated)
+		SELECT d.day,
+			(SELECT COUNT(*) FROM snippets WHERE created::date = d.day),
+			(SELECT COUNT(*) FROM users WHERE created::date = d.day),
+			(SELECT COALESCE(SUM(views), 0) FROM snippet_view_rollups WHERE day = d.day),
+			NOW() AT TIME ZONE 'UTC'
+		FROM days d
+		ON CONFLICT (day) DO UPDATE SET
+			snippets_created = GREATEST(daily_stats.snippets_created, EXCLUDED.snippets_created),
+			users_created = GREATEST(daily_stats.users_created, EXCLUDED.users_created),
+			views = GREATEST(daily_stats.views, EXCLUDED.views),
+			updated = EXCLUDED.updated
+	`
+
+	if _, err := db.Exec(ctx, stmt, since.UTC()); err != nil {
+		return fmt.Errorf("rolling up daily stats: %w", err)
+	}
+
+	return nil
+}
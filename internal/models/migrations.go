@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationFiles holds the schema as numbered migrations, e.g.
+// 0002_add_tags.up.sql and 0002_add_tags.down.sql. A migration is never
+// edited once released; changes go into a new one.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey is the advisory lock held while migrating, so instances
+// starting at the same time do not apply the same migration twice.
+const migrationLockKey = 7_165_535_301
+
+// Migration is one versioned step of the database schema.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Migrations returns the embedded migrations, oldest first.
+func Migrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	return loadMigrations(sub)
+}
+
+// loadMigrations reads the NNNN_name.up.sql and NNNN_name.down.sql files in
+// fsys. Versions must start at 1 and leave no gaps, and every migration
+// needs both directions.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, file := range names {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), ".")
+		version, name, found := strings.Cut(base, "_")
+		n, err := strconv.Atoi(version)
+
+		if !ok || !found || err != nil || n < 1 || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql or 0001_name.down.sql", file)
+		}
+
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading migration %s: %w", file, err)
+		}
+
+		m, exists := byVersion[n]
+		if !exists {
+			m = &Migration{Version: n, Name: name}
+			byVersion[n] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", n, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.up = string(b)
+		} else {
+			m.down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, m := range migrations {
+		switch {
+		case m.Version != i+1:
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		case m.up == "":
+			return nil, fmt.Errorf("migration %s has no up file", m)
+		case m.down == "":
+			return nil, fmt.Errorf("migration %s has no down file", m)
+		}
+	}
+
+	return migrations, nil
+}
+
+// SchemaVersion returns the version of the newest migration applied to the
+// database, or 0 if none has been.
+func SchemaVersion(ctx context.Context, db *pgxpool.Pool) (int, error) {
+	var version int
+
+	stmt := `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+
+	err := db.QueryRow(ctx, stmt).Scan(&version)
+
+	// schema_version does not exist until the first migration runs
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// MigrateUp applies every migration newer than the database's version and
+// returns the ones it applied.
+func MigrateUp(ctx context.Context, db *pgxpool.Pool) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn, version int) error {
+		for _, m := range migrations {
+			if m.Version <= version {
+				continue
+			}
+
+			stmt := `INSERT INTO schema_version (version, name) VALUES ($1, $2)`
+
+			if err := runMigration(ctx, conn, m, m.up, stmt); err != nil {
+				return err
+			}
+
+			applied = append(applied, m)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown reverts the newest steps migrations applied to the database
+// and returns the ones it reverted.
+func MigrateDown(ctx context.Context, db *pgxpool.Pool, steps int) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn, version int) error {
+		if version > len(migrations) {
+			return fmt.Errorf("database is at version %d, newer than this binary knows (%d)", version, len(migrations))
+		}
+
+		for ; steps > 0 && version > 0; steps-- {
+			m := migrations[version-1]
+
+			stmt := `DELETE FROM schema_version WHERE version = $1 AND name = $2`
+
+			if err := runMigration(ctx, conn, m, m.down, stmt); err != nil {
+				return err
+			}
+
+			reverted = append(reverted, m)
+			version--
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// withMigrationLock calls fn with a connection holding the migration lock
+// and the database's current version, creating the schema_version table on
+// first use.
+func withMigrationLock(ctx context.Context, db *pgxpool.Pool, fn func(conn *pgxpool.Conn, version int) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection for migrations: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("locking migrations: %w", err)
+	}
+
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			// Closing the connection releases the lock as well, rather
+			// than returning it to the pool still locked.
+			_ = conn.Conn().Close(ctx)
+		}
+	}()
+
+	stmt := `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)
+	`
+
+	if _, err := conn.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("creating schema_version: %w", err)
+	}
+
+	var version int
+
+	stmt = `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+
+	if err := conn.QueryRow(ctx, stmt).Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	return fn(conn, version)
+}
+
+// runMigration runs the sql of one direction of m and records the change
+// with versionStmt in the same transaction, so a failed migration leaves
+// the database as it was.
+func runMigration(ctx context.Context, conn *pgxpool.Conn, m Migration, sql, versionStmt string) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err //nolint:wrapcheck // wrapped with the migration below
+		}
+
+		_, err := tx.Exec(ctx, versionStmt, m.Version, m.Name)
+
+		return err //nolint:wrapcheck // wrapped with the migration below
+	})
+	if err != nil {
+		return fmt.Errorf("migration %s: %w", m, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+-- Drop everything 0001_initial.up.sql creates, dependent tables first.
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS notifications_sent;
+DROP TABLE IF EXISTS notification_settings;
+DROP TABLE IF EXISTS passkeys;
+DROP TABLE IF EXISTS email_queue;
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS status_incidents;
+DROP TABLE IF EXISTS health_checks;
+DROP TABLE IF EXISTS user_preferences;
+DROP TABLE IF EXISTS api_token_ips;
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS snippet_view_rollups;
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_immutable();
+DROP TABLE IF EXISTS snippets;
+DROP TABLE IF EXISTS users;
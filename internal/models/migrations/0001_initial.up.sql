@@ -0,0 +1,221 @@
+-- PostgreSQL Schema for Snippetbox Application
+-- Converted from MySQL for maximum performance with pgx driver
+--
+-- Databases set up by hand before migrations existed already have these
+-- tables, so every statement here is idempotent.
+
+-- Create snippets table (matches original MySQL schema)
+CREATE TABLE IF NOT EXISTS snippets (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    content TEXT NOT NULL,
+    created TIMESTAMP NOT NULL,
+    expires TIMESTAMP NOT NULL
+);
+
+-- Add index on created column for better query performance
+CREATE INDEX IF NOT EXISTS idx_snippets_created ON snippets(created);
+
+-- Create users table
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    hashed_password CHAR(60) NOT NULL,
+    created TIMESTAMP NOT NULL
+);
+
+-- Add unique constraint on email
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_uc_email') THEN
+        ALTER TABLE users ADD CONSTRAINT users_uc_email UNIQUE (email);
+    END IF;
+END $$;
+
+-- Mark administrators; promote a user with
+-- UPDATE users SET is_admin = TRUE WHERE email = '...';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Allow administrators to suspend accounts until a given time
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason TEXT NOT NULL DEFAULT '';
+
+-- Track which terms-of-service version each user last accepted
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tos_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tos_accepted_at TIMESTAMP;
+
+-- Track password age for the optional rotation policy. Rows from before this
+-- column existed fall back to the account creation time.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
+
+-- Create append-only audit log for authentication events
+CREATE TABLE IF NOT EXISTS audit_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    event VARCHAR(50) NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_created ON audit_events(user_id, created);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events(created);
+
+-- Reject updates and deletes so the audit log stays append-only
+CREATE OR REPLACE FUNCTION audit_events_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_modify ON audit_events;
+CREATE TRIGGER audit_events_no_modify
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_immutable();
+
+-- Record the owner of snippets created by signed-in users
+ALTER TABLE snippets ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_snippets_user_id ON snippets(user_id);
+
+-- Daily rollup of snippet views by referrer host and user-agent family.
+-- No client IPs are stored here.
+CREATE TABLE IF NOT EXISTS snippet_view_rollups (
+    snippet_id INTEGER NOT NULL REFERENCES snippets(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    referrer VARCHAR(255) NOT NULL,
+    ua_family VARCHAR(50) NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (snippet_id, day, referrer, ua_family)
+);
+
+-- Personal access tokens for the JSON API. Only a SHA-256 hash of each token
+-- is stored.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created TIMESTAMP NOT NULL,
+    last_used TIMESTAMP,
+    request_count BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- Source IP ranges (/24 for IPv4, /48 for IPv6) each token was used from
+CREATE TABLE IF NOT EXISTS api_token_ips (
+    token_id INTEGER NOT NULL REFERENCES api_tokens(id) ON DELETE CASCADE,
+    ip_range VARCHAR(64) NOT NULL,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (token_id, ip_range)
+);
+
+-- Per-user display and editing preferences
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    theme VARCHAR(20) NOT NULL DEFAULT 'light',
+    snippets_per_page INTEGER NOT NULL DEFAULT 10,
+    default_expiry INTEGER NOT NULL DEFAULT 365,
+    language VARCHAR(10) NOT NULL DEFAULT ''
+);
+
+-- Periodic health-check results backing the public status page
+CREATE TABLE IF NOT EXISTS health_checks (
+    id BIGSERIAL PRIMARY KEY,
+    checked_at TIMESTAMP NOT NULL,
+    ok BOOLEAN NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_health_checks_checked_at ON health_checks(checked_at);
+
+-- Incidents shown on the status page, maintained by admins
+CREATE TABLE IF NOT EXISTS status_incidents (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    started TIMESTAMP NOT NULL,
+    resolved TIMESTAMP
+);
+
+-- Runtime-tunable instance settings, one JSON value per key
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Personal data exports, generated in the background and kept for a week
+CREATE TABLE IF NOT EXISTS data_exports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created TIMESTAMP NOT NULL,
+    completed TIMESTAMP,
+    expires TIMESTAMP NOT NULL,
+    archive BYTEA
+);
+
+-- Outgoing email, delivered by a background dispatcher so messages composed
+-- while every mail provider is down are sent later
+CREATE TABLE IF NOT EXISTS email_queue (
+    id SERIAL PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created TIMESTAMP NOT NULL,
+    next_attempt TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_queue_pending ON email_queue(next_attempt) WHERE sent IS NULL;
+
+-- WebAuthn credentials (passkeys) users can sign in with instead of a password
+CREATE TABLE IF NOT EXISTS passkeys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    created TIMESTAMP NOT NULL,
+    last_used TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id);
+
+-- Per-user opt-in/out choices for email notifications. Kinds without a row
+-- use the default defined in the application.
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, kind)
+);
+
+-- Notifications already sent, so periodic checks notify only once per event
+CREATE TABLE IF NOT EXISTS notifications_sent (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    ref VARCHAR(100) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, kind, ref)
+);
+
+-- Create sessions table for scs/postgresstore
+CREATE TABLE IF NOT EXISTS sessions (
+    token TEXT PRIMARY KEY,
+    data BYTEA NOT NULL,
+    expiry TIMESTAMP(6) NOT NULL
+);
+
+-- Create index on expiry for session cleanup
+CREATE INDEX IF NOT EXISTS sessions_expiry_idx ON sessions(expiry);
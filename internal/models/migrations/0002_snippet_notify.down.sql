@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS snippets_notify_created ON snippets;
+DROP FUNCTION IF EXISTS notify_snippet_created();
@@ -0,0 +1,13 @@
+-- Announce every new snippet on the snippet_created channel with its id as
+-- payload, so the app can push it to open home pages.
+CREATE OR REPLACE FUNCTION notify_snippet_created() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('snippet_created', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS snippets_notify_created ON snippets;
+CREATE TRIGGER snippets_notify_created
+    AFTER INSERT ON snippets
+    FOR EACH ROW EXECUTE FUNCTION notify_snippet_created();
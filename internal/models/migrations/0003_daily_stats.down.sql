@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_snippets_expires;
+DROP TABLE IF EXISTS daily_stats;
@@ -0,0 +1,12 @@
+-- Site-wide totals per day, rolled up hourly by the stats job. They outlive
+-- the snippets and view rollups that the nightly purge deletes.
+CREATE TABLE daily_stats (
+    day DATE PRIMARY KEY,
+    snippets_created INTEGER NOT NULL DEFAULT 0,
+    users_created INTEGER NOT NULL DEFAULT 0,
+    views INTEGER NOT NULL DEFAULT 0,
+    updated TIMESTAMP NOT NULL
+);
+
+-- The purge looks up expired snippets by their expiry.
+CREATE INDEX idx_snippets_expires ON snippets(expires);
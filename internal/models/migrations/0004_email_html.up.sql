@@ -0,0 +1,2 @@
+-- The HTML alternative of a queued email, empty for plain-text ones.
+ALTER TABLE email_queue ADD COLUMN html TEXT NOT NULL DEFAULT '';
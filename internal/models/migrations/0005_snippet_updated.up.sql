@@ -0,0 +1,4 @@
+-- When a snippet's title, content or expiry last changed, for conditional
+-- requests now that snippets can be edited through the API.
+ALTER TABLE snippets ADD COLUMN updated TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC');
+UPDATE snippets SET updated = created;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_snippets_search;
+ALTER TABLE snippets DROP COLUMN IF EXISTS search;
@@ -0,0 +1,7 @@
+-- Full-text search over snippets, with matches in the title ranked above
+-- matches in the content.
+ALTER TABLE snippets ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', content), 'B')
+) STORED;
+CREATE INDEX idx_snippets_search ON snippets USING GIN (search);
@@ -0,0 +1,13 @@
+-- Callbacks registered through the API. The secret signs every delivery,
+-- so it is stored as is rather than hashed.
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
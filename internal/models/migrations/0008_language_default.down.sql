@@ -0,0 +1,2 @@
+UPDATE user_preferences SET language = 'en' WHERE language = '';
+ALTER TABLE user_preferences ALTER COLUMN language SET DEFAULT 'en';
@@ -0,0 +1,6 @@
+-- An empty language lets the browser's Accept-Language header decide. Rows
+-- saved before this stored the old 'en' default, which cannot be told apart
+-- from an explicit choice of English; English speakers' browsers pick it
+-- anyway, so they lose nothing.
+ALTER TABLE user_preferences ALTER COLUMN language SET DEFAULT '';
+UPDATE user_preferences SET language = '' WHERE language = 'en';
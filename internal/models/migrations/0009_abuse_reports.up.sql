@@ -0,0 +1,13 @@
+-- Abuse reports from the /abuse form, queued for moderators. The snippet
+-- reference survives the snippet, so a report can still be reviewed once
+-- the snippet expired or was deleted.
+CREATE TABLE abuse_reports (
+    id SERIAL PRIMARY KEY,
+    snippet_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    contact VARCHAR(255) NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL,
+    resolved TIMESTAMP
+);
+
+CREATE INDEX idx_abuse_reports_open ON abuse_reports (created) WHERE resolved IS NULL;
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	assert.NilError(t, err)
+
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	assert.Equal(t, migrations[0].String(), "0001_initial")
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		wantErr  string
+		wantLast string
+	}{
+		{
+			name: "Valid",
+			files: fstest.MapFS{
+				"0002_tags.up.sql":      file("CREATE TABLE tags ();"),
+				"0002_tags.down.sql":    file("DROP TABLE tags;"),
+				"0001_initial.up.sql":   file("CREATE TABLE a ();"),
+				"0001_initial.down.sql": file("DROP TABLE a;"),
+			},
+			wantLast: "0002_tags",
+		},
+		{
+			name: "Gap",
+			files: fstest.MapFS{
+				"0001_initial.up.sql":   file("x"),
+				"0001_initial.down.sql": file("x"),
+				"0003_later.up.sql":     file("x"),
+				"0003_later.down.sql":   file("x"),
+			},
+			wantErr: "migration 2 is missing",
+		},
+		{
+			name: "Missing down",
+			files: fstest.MapFS{
+				"0001_initial.up.sql": file("x"),
+			},
+			wantErr: "has no down file",
+		},
+		{
+			name: "Conflicting names",
+			files: fstest.MapFS{
+				"0001_initial.up.sql": file("x"),
+				"0001_other.down.sql": file("x"),
+			},
+			wantErr: "has two names",
+		},
+		{
+			name: "Bad file name",
+			files: fstest.MapFS{
+				"initial.sql": file("x"),
+			},
+			wantErr: "name must look like",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files)
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q", tt.wantErr)
+				}
+
+				assert.StringContains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, migrations[len(migrations)-1].String(), tt.wantLast)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+type AnalyticsModel struct{}
+
+func (m *AnalyticsModel) RecordView(
+	ctx context.Context,
+	snippetID int,
+	referrer string,
+	uaFamily string,
+) error {
+	return nil
+}
+
+func (m *AnalyticsModel) SnippetStats(
+	ctx context.Context,
+	snippetID int,
+) (models.SnippetStats, error) {
+	stats := models.SnippetStats{
+		TotalViews:   3,
+		TopReferrers: []models.StatCount{{Label: "news.ycombinator.com", Views: 2}},
+		UserAgents:   []models.StatCount{{Label: "Firefox", Views: 3}},
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+type AuditModel struct{}
+
+func (m *AuditModel) Insert(
+	ctx context.Context,
+	userID int,
+	event string,
+	ip string,
+	details string,
+) error {
+	return nil
+}
+
+func (m *AuditModel) List(
+	ctx context.Context,
+	filter models.AuditFilter,
+) ([]models.AuditEvent, error) {
+	e := models.AuditEvent{
+		ID:      1,
+		UserID:  1,
+		Event:   models.AuditLogin,
+		IP:      "127.0.0.1",
+		Created: time.Now(),
+	}
+
+	if filter.UserID != 0 && filter.UserID != e.UserID {
+		return nil, nil
+	}
+
+	if filter.Event != "" && filter.Event != e.Event {
+		return nil, nil
+	}
+
+	return []models.AuditEvent{e}, nil
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// MockExportToken downloads the completed export of user 1.
+const MockExportToken = "export-test-token"
+
+var mockExport = models.Export{
+	ID:        1,
+	UserID:    1,
+	Created:   time.Now(),
+	Completed: time.Now(),
+	Expires:   time.Now().Add(7 * 24 * time.Hour),
+	Archive:   []byte("PK mock archive"),
+}
+
+type ExportModel struct{}
+
+func (m *ExportModel) Create(
+	ctx context.Context,
+	userID int,
+) (models.Export, string, error) {
+	e := models.Export{
+		ID:      2,
+		UserID:  userID,
+		Created: time.Now(),
+		Expires: time.Now().Add(7 * 24 * time.Hour),
+	}
+
+	return e, MockExportToken, nil
+}
+
+func (m *ExportModel) Complete(
+	ctx context.Context,
+	id int,
+	archive []byte,
+) error {
+	return nil
+}
+
+func (m *ExportModel) Latest(
+	ctx context.Context,
+	userID int,
+) (models.Export, error) {
+	if userID == mockExport.UserID {
+		return mockExport, nil
+	}
+
+	return models.Export{}, models.ErrNoRecord
+}
+
+func (m *ExportModel) Download(
+	ctx context.Context,
+	userID int,
+	token string,
+) (models.Export, error) {
+	if userID == mockExport.UserID && token == MockExportToken {
+		return mockExport, nil
+	}
+
+	return models.Export{}, models.ErrNoRecord
+}
@@ -0,0 +1,85 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// NotificationModel keeps settings and sent notifications in memory, so
+// tests can observe opt-outs and deduplication.
+type NotificationModel struct {
+	mu       sync.Mutex
+	settings map[int]map[string]bool
+	sent     map[string]bool
+}
+
+func (m *NotificationModel) Settings(
+	ctx context.Context,
+	userID int,
+) (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := make(map[string]bool)
+	maps.Copy(settings, m.settings[userID])
+
+	return settings, nil
+}
+
+func (m *NotificationModel) UpdateSettings(
+	ctx context.Context,
+	userID int,
+	settings map[string]bool,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.settings == nil {
+		m.settings = make(map[int]map[string]bool)
+	}
+
+	if m.settings[userID] == nil {
+		m.settings[userID] = make(map[string]bool)
+	}
+
+	maps.Copy(m.settings[userID], settings)
+
+	return nil
+}
+
+func (m *NotificationModel) Sent(
+	ctx context.Context,
+	userID int,
+	kind string,
+	ref string,
+) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sent[fmt.Sprintf("%d/%s/%s", userID, kind, ref)], nil
+}
+
+func (m *NotificationModel) MarkSent(
+	ctx context.Context,
+	userID int,
+	kind string,
+	ref string,
+) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sent == nil {
+		m.sent = make(map[string]bool)
+	}
+
+	key := fmt.Sprintf("%d/%s/%s", userID, kind, ref)
+	if m.sent[key] {
+		return false, nil
+	}
+
+	m.sent[key] = true
+
+	return true, nil
+}
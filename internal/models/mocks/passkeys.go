@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+var mockPasskey = models.Passkey{
+	ID:           1,
+	UserID:       1,
+	Name:         "Phone",
+	CredentialID: []byte("mock-credential"),
+	Created:      time.Now(),
+}
+
+type PasskeyModel struct{}
+
+func (m *PasskeyModel) Insert(
+	ctx context.Context,
+	userID int,
+	name string,
+	credentialID, publicKey []byte,
+	signCount uint32,
+) error {
+	if bytes.Equal(credentialID, mockPasskey.CredentialID) {
+		return models.ErrDuplicatePasskey
+	}
+
+	return nil
+}
+
+func (m *PasskeyModel) List(ctx context.Context, userID int) ([]models.Passkey, error) {
+	if userID == mockPasskey.UserID {
+		return []models.Passkey{mockPasskey}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *PasskeyModel) Get(ctx context.Context, credentialID []byte) (models.Passkey, error) {
+	if bytes.Equal(credentialID, mockPasskey.CredentialID) {
+		return mockPasskey, nil
+	}
+
+	return models.Passkey{}, models.ErrNoRecord
+}
+
+func (m *PasskeyModel) RecordUse(ctx context.Context, id int, signCount uint32) error {
+	return nil
+}
+
+func (m *PasskeyModel) Delete(ctx context.Context, userID, id int) error {
+	if userID == mockPasskey.UserID && id == mockPasskey.ID {
+		return nil
+	}
+
+	return models.ErrNoRecord
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+type PreferencesModel struct{}
+
+func (m *PreferencesModel) Get(
+	ctx context.Context,
+	userID int,
+) (models.Preferences, error) {
+	if userID == 1 {
+		p := models.Preferences{
+			Theme:           "dark",
+			SnippetsPerPage: 20,
+			DefaultExpiry:   7,
+			Language:        "en",
+		}
+
+		return p, nil
+	}
+
+	return models.DefaultPreferences, nil
+}
+
+func (m *PreferencesModel) Update(
+	ctx context.Context,
+	userID int,
+	p models.Preferences,
+) error {
+	return nil
+}
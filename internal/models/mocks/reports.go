@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// ReportModel keeps abuse reports in memory, so tests can see what was
+// queued and resolved.
+type ReportModel struct {
+	mu       sync.Mutex
+	reports  []models.Report
+	resolved map[int]bool
+}
+
+func (m *ReportModel) Insert(
+	ctx context.Context,
+	snippetID int,
+	reason string,
+	contact string,
+) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := models.Report{
+		ID:        len(m.reports) + 1,
+		SnippetID: snippetID,
+		Reason:    reason,
+		Contact:   contact,
+		Created:   time.Now(),
+	}
+	m.reports = append(m.reports, r)
+
+	return r.ID, nil
+}
+
+func (m *ReportModel) Open(ctx context.Context, limit int) ([]models.Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var open []models.Report
+
+	for _, r := range m.reports {
+		if !m.resolved[r.ID] && len(open) < limit {
+			open = append(open, r)
+		}
+	}
+
+	return open, nil
+}
+
+func (m *ReportModel) Resolve(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id < 1 || id > len(m.reports) || m.resolved[id] {
+		return models.ErrNoRecord
+	}
+
+	if m.resolved == nil {
+		m.resolved = make(map[int]bool)
+	}
+
+	m.resolved[id] = true
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+type SettingsModel struct{}
+
+func (m *SettingsModel) Get(ctx context.Context) (models.Settings, error) {
+	return models.DefaultSettings, nil
+}
+
+func (m *SettingsModel) Update(
+	ctx context.Context,
+	s models.Settings,
+	updatedBy int,
+) error {
+	return nil
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/FABLOUSFALCON/snippetbox/internal/models"
@@ -12,13 +13,16 @@ var mockSnippet = models.Snippet{
 	Title:   "An old silent pond",
 	Content: "An old silent pond...",
 	Created: time.Now(),
+	Updated: time.Now(),
 	Expires: time.Now(),
+	UserID:  1,
 }
 
 type SnippetModel struct{}
 
 func (m *SnippetModel) Insert(
 	ctx context.Context,
+	userID int,
 	title string,
 	content string,
 	expires int,
@@ -26,6 +30,20 @@ func (m *SnippetModel) Insert(
 	return 2, nil
 }
 
+// InsertBatch numbers the new snippets from 2, like Insert.
+func (m *SnippetModel) InsertBatch(
+	ctx context.Context,
+	userID int,
+	snippets []models.NewSnippet,
+) ([]int, error) {
+	ids := make([]int, len(snippets))
+	for i := range ids {
+		ids[i] = i + 2
+	}
+
+	return ids, nil
+}
+
 func (m *SnippetModel) Get(
 	ctx context.Context,
 	id int,
@@ -40,6 +58,94 @@ func (m *SnippetModel) Get(
 
 func (m *SnippetModel) Latest(
 	ctx context.Context,
+	limit int,
+) ([]models.Snippet, error) {
+	return []models.Snippet{mockSnippet}, nil
+}
+
+func (m *SnippetModel) LatestPage(
+	ctx context.Context,
+	limit int,
+	offset int,
 ) ([]models.Snippet, error) {
+	if offset > 0 {
+		return nil, nil
+	}
+
 	return []models.Snippet{mockSnippet}, nil
 }
+
+func (m *SnippetModel) Count(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+// Search matches the mock snippet if its title or content contains the
+// query, ignoring case.
+func (m *SnippetModel) Search(
+	ctx context.Context,
+	search models.SnippetSearch,
+) ([]models.SnippetMatch, int, error) {
+	query := strings.ToLower(search.Query)
+	matches := strings.Contains(strings.ToLower(mockSnippet.Title), query) ||
+		strings.Contains(strings.ToLower(mockSnippet.Content), query)
+
+	if !matches || (search.AuthorID != 0 && search.AuthorID != mockSnippet.UserID) {
+		return nil, 0, nil
+	}
+
+	if search.Offset > 0 {
+		return nil, 1, nil
+	}
+
+	headline := models.HeadlineStart + "An old silent pond" + models.HeadlineStop + "..."
+
+	return []models.SnippetMatch{{Snippet: mockSnippet, Rank: 0.5, Headline: headline}}, 1, nil
+}
+
+func (m *SnippetModel) ByUser(
+	ctx context.Context,
+	userID int,
+) ([]models.Snippet, error) {
+	if userID == mockSnippet.UserID {
+		return []models.Snippet{mockSnippet}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *SnippetModel) Expiring(
+	ctx context.Context,
+	before time.Time,
+) ([]models.Snippet, error) {
+	// The mock snippet as if it had been created to last a week.
+	s := mockSnippet
+	s.Created = s.Expires.Add(-7 * 24 * time.Hour)
+
+	return []models.Snippet{s}, nil
+}
+
+func (m *SnippetModel) Update(
+	ctx context.Context,
+	id int,
+	version time.Time,
+	title string,
+	content string,
+	expires int,
+) error {
+	return m.change(id, version)
+}
+
+func (m *SnippetModel) Delete(ctx context.Context, id int, version time.Time) error {
+	return m.change(id, version)
+}
+
+func (m *SnippetModel) change(id int, version time.Time) error {
+	switch {
+	case id != mockSnippet.ID:
+		return models.ErrNoRecord
+	case !version.IsZero() && !version.Equal(mockSnippet.Updated):
+		return models.ErrEditConflict
+	}
+
+	return nil
+}
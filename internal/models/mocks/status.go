@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+var mockIncident = models.Incident{
+	ID:      1,
+	Title:   "Database maintenance",
+	Notes:   "Snippets were read-only for ten minutes.",
+	Started: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+}
+
+type StatusModel struct{}
+
+func (m *StatusModel) RecordCheck(
+	ctx context.Context,
+	hc models.HealthCheck,
+) error {
+	return nil
+}
+
+func (m *StatusModel) PruneChecks(
+	ctx context.Context,
+	before time.Time,
+) error {
+	return nil
+}
+
+func (m *StatusModel) Uptime(
+	ctx context.Context,
+	since time.Time,
+) (models.Uptime, error) {
+	return models.Uptime{Checks: 200, OK: 199}, nil
+}
+
+func (m *StatusModel) Incidents(
+	ctx context.Context,
+	limit int,
+) ([]models.Incident, error) {
+	return []models.Incident{mockIncident}, nil
+}
+
+func (m *StatusModel) GetIncident(
+	ctx context.Context,
+	id int,
+) (models.Incident, error) {
+	if id == 1 {
+		return mockIncident, nil
+	}
+
+	return models.Incident{}, models.ErrNoRecord
+}
+
+func (m *StatusModel) InsertIncident(
+	ctx context.Context,
+	title string,
+	notes string,
+) (int, error) {
+	return 2, nil
+}
+
+func (m *StatusModel) UpdateIncident(
+	ctx context.Context,
+	id int,
+	notes string,
+	resolved bool,
+) error {
+	if id != 1 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
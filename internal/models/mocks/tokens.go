@@ -0,0 +1,84 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// MockTokenPlaintext authenticates as token 1, owned by user 1.
+const MockTokenPlaintext = models.TokenPrefix + "valid-test-token"
+
+// MockAdminTokenPlaintext authenticates as token 3, owned by admin user 2.
+const MockAdminTokenPlaintext = models.TokenPrefix + "admin-test-token"
+
+var mockAdminToken = models.Token{
+	ID:      3,
+	UserID:  2,
+	Name:    "provisioning",
+	Created: time.Now(),
+}
+
+var mockToken = models.Token{
+	ID:           1,
+	UserID:       1,
+	Name:         "laptop",
+	Created:      time.Now(),
+	LastUsed:     time.Now(),
+	RequestCount: 42,
+}
+
+type TokenModel struct{}
+
+func (m *TokenModel) Create(
+	ctx context.Context,
+	userID int,
+	name string,
+) (string, models.Token, error) {
+	t := models.Token{ID: 2, UserID: userID, Name: name, Created: time.Now()}
+
+	return models.TokenPrefix + "new-test-token", t, nil
+}
+
+func (m *TokenModel) List(ctx context.Context, userID int) ([]models.Token, error) {
+	if userID == mockToken.UserID {
+		return []models.Token{mockToken}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *TokenModel) Delete(ctx context.Context, userID, id int) error {
+	if userID == mockToken.UserID && id == mockToken.ID {
+		return nil
+	}
+
+	return models.ErrNoRecord
+}
+
+func (m *TokenModel) Authenticate(ctx context.Context, plaintext string) (models.Token, error) {
+	switch plaintext {
+	case MockTokenPlaintext:
+		return mockToken, nil
+	case MockAdminTokenPlaintext:
+		return mockAdminToken, nil
+	}
+
+	return models.Token{}, models.ErrInvalidCredentials
+}
+
+func (m *TokenModel) RecordUse(ctx context.Context, tokenID int, ipRange string) (bool, error) {
+	return false, nil
+}
+
+func (m *TokenModel) IPs(ctx context.Context, tokenID int) ([]models.TokenIP, error) {
+	ip := models.TokenIP{
+		Range:        "192.0.2.0/24",
+		FirstSeen:    time.Now(),
+		LastSeen:     time.Now(),
+		RequestCount: 42,
+	}
+
+	return []models.TokenIP{ip}, nil
+}
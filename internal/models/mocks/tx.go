@@ -0,0 +1,11 @@
+package mocks
+
+import "context"
+
+// TxRunner calls its functions directly; the mock models have nothing to
+// roll back.
+type TxRunner struct{}
+
+func (r *TxRunner) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
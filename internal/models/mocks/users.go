@@ -9,12 +9,12 @@ import (
 
 type UserModel struct{}
 
-func (m *UserModel) Insert(name, email, password string) error {
+func (m *UserModel) Insert(name, email, password string, tosVersion int) (int, error) {
 	switch email {
 	case "dupe@example.com":
-		return models.ErrDuplicateEmail
+		return 0, models.ErrDuplicateEmail
 	default:
-		return nil
+		return 4, nil
 	}
 }
 
@@ -23,12 +23,20 @@ func (m *UserModel) Authenticate(email, password string) (int, error) {
 		return 1, nil
 	}
 
+	if email == "admin@example.com" && password == "pa$$word" {
+		return 2, nil
+	}
+
+	if email == "mallory@example.com" && password == "pa$$word" {
+		return 3, nil
+	}
+
 	return 0, models.ErrInvalidCredentials
 }
 
 func (m *UserModel) Exists(id int) (bool, error) {
 	switch id {
-	case 1:
+	case 1, 2, 3:
 		return true, nil
 	default:
 		return false, nil
@@ -38,10 +46,39 @@ func (m *UserModel) Exists(id int) (bool, error) {
 func (m *UserModel) Get(id int) (models.User, error) {
 	if id == 1 {
 		u := models.User{
-			ID:      1,
-			Name:    "Alice",
-			Email:   "alice@example.com",
-			Created: time.Now(),
+			ID:                1,
+			Name:              "Alice",
+			Email:             "alice@example.com",
+			Created:           time.Now().AddDate(-1, 0, 0),
+			TOSVersion:        1,
+			PasswordChangedAt: time.Now().AddDate(0, 0, -100),
+		}
+
+		return u, nil
+	}
+
+	if id == 2 {
+		u := models.User{
+			ID:         2,
+			Name:       "Admin",
+			Email:      "admin@example.com",
+			Created:    time.Now(),
+			TOSVersion: 1,
+			IsAdmin:    true,
+		}
+
+		return u, nil
+	}
+
+	if id == 3 {
+		u := models.User{
+			ID:               3,
+			Name:             "Mallory",
+			Email:            "mallory@example.com",
+			Created:          time.Now(),
+			TOSVersion:       1,
+			SuspendedUntil:   time.Now().Add(24 * time.Hour),
+			SuspensionReason: "Spamming",
 		}
 
 		return u, nil
@@ -61,3 +98,43 @@ func (m *UserModel) PasswordUpdate(id int, currentPassword, newPassword string)
 
 	return models.ErrNoRecord
 }
+
+func (m *UserModel) Suspend(id int, until time.Time, reason string) error {
+	switch id {
+	case 1, 2, 3:
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) SetAdmin(email string, admin bool) (int, error) {
+	switch email {
+	case "alice@example.com":
+		return 1, nil
+	case "admin@example.com":
+		return 2, nil
+	case "mallory@example.com":
+		return 3, nil
+	default:
+		return 0, models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) Unsuspend(id int) error {
+	switch id {
+	case 1, 2, 3:
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) AcceptTOS(id, version int) error {
+	switch id {
+	case 1, 2, 3:
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
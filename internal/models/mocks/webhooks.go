@@ -0,0 +1,92 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/models"
+)
+
+// MockWebhookSecret is the secret of the mock webhook before rotation.
+const MockWebhookSecret = "whsec_mock"
+
+var mockWebhook = models.Webhook{
+	ID:      1,
+	UserID:  1,
+	URL:     "https://hooks.example.com/snippetbox",
+	Events:  []string{models.WebhookSnippetCreated},
+	Secret:  MockWebhookSecret,
+	Created: time.Now(),
+	Updated: time.Now(),
+}
+
+type WebhookModel struct{}
+
+func (m *WebhookModel) Insert(ctx context.Context, userID int, url string, events []string) (models.Webhook, error) {
+	return models.Webhook{
+		ID:      2,
+		UserID:  userID,
+		URL:     url,
+		Events:  events,
+		Secret:  "whsec_new",
+		Created: time.Now(),
+		Updated: time.Now(),
+	}, nil
+}
+
+func (m *WebhookModel) Get(ctx context.Context, userID, id int) (models.Webhook, error) {
+	if userID == mockWebhook.UserID && id == mockWebhook.ID {
+		return mockWebhook, nil
+	}
+
+	return models.Webhook{}, models.ErrNoRecord
+}
+
+func (m *WebhookModel) List(ctx context.Context, userID int) ([]models.Webhook, error) {
+	if userID == mockWebhook.UserID {
+		return []models.Webhook{mockWebhook}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *WebhookModel) Update(
+	ctx context.Context,
+	userID, id int,
+	url string,
+	events []string,
+) (models.Webhook, error) {
+	w, err := m.Get(ctx, userID, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	w.URL, w.Events = url, events
+
+	return w, nil
+}
+
+func (m *WebhookModel) RotateSecret(ctx context.Context, userID, id int) (models.Webhook, error) {
+	w, err := m.Get(ctx, userID, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	w.Secret = "whsec_rotated"
+
+	return w, nil
+}
+
+func (m *WebhookModel) Delete(ctx context.Context, userID, id int) error {
+	_, err := m.Get(ctx, userID, id)
+
+	return err
+}
+
+func (m *WebhookModel) Subscribed(ctx context.Context, userID int, event string) ([]models.Webhook, error) {
+	if userID == mockWebhook.UserID && mockWebhook.Subscribes(event) {
+		return []models.Webhook{mockWebhook}, nil
+	}
+
+	return nil, nil
+}
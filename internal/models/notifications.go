@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Notification kinds users can opt in to or out of.
+const (
+	NotifySnippetExpiring = "snippet_expiring"
+	NotifyTokenNewIP      = "token_new_ip"
+)
+
+type NotificationModelInterface interface {
+	Settings(ctx context.Context, userID int) (map[string]bool, error)
+	UpdateSettings(ctx context.Context, userID int, settings map[string]bool) error
+	Sent(ctx context.Context, userID int, kind, ref string) (bool, error)
+	MarkSent(ctx context.Context, userID int, kind, ref string) (bool, error)
+}
+
+type NotificationModel struct {
+	DB *pgxpool.Pool
+}
+
+// Settings returns the notification choices the user made explicitly, keyed
+// by kind. Kinds missing from the map use their default.
+func (m *NotificationModel) Settings(ctx context.Context, userID int) (map[string]bool, error) {
+	stmt := `SELECT kind, enabled FROM notification_settings WHERE user_id = $1`
+
+	rows, err := m.DB.Query(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying notification settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]bool)
+
+	for rows.Next() {
+		var (
+			kind    string
+			enabled bool
+		)
+
+		if err := rows.Scan(&kind, &enabled); err != nil {
+			return nil, fmt.Errorf("scanning notification setting: %w", err)
+		}
+
+		settings[kind] = enabled
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notification settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (m *NotificationModel) UpdateSettings(ctx context.Context, userID int, settings map[string]bool) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	stmt := `
+		INSERT INTO notification_settings (user_id, kind, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, kind) DO UPDATE SET enabled = EXCLUDED.enabled
+	`
+
+	for kind, enabled := range settings {
+		if _, err := tx.Exec(ctx, stmt, userID, kind, enabled); err != nil {
+			return fmt.Errorf("updating notification setting: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing notification settings: %w", err)
+	}
+
+	return nil
+}
+
+// Sent reports whether the notification of kind about ref was already sent
+// to the user.
+func (m *NotificationModel) Sent(ctx context.Context, userID int, kind, ref string) (bool, error) {
+	stmt := `
+		SELECT EXISTS (
+			SELECT 1 FROM notifications_sent
+			WHERE user_id = $1 AND kind = $2 AND ref = $3
+		)
+	`
+
+	var sent bool
+
+	err := m.DB.QueryRow(ctx, stmt, userID, kind, ref).Scan(&sent)
+	if err != nil {
+		return false, fmt.Errorf("checking sent notification: %w", err)
+	}
+
+	return sent, nil
+}
+
+// MarkSent records that the notification of kind about ref was sent to the
+// user. It reports false if it had already been recorded.
+func (m *NotificationModel) MarkSent(ctx context.Context, userID int, kind, ref string) (bool, error) {
+	stmt := `
+		INSERT INTO notifications_sent (user_id, kind, ref, created)
+		VALUES ($1, $2, $3, NOW() AT TIME ZONE 'UTC')
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := m.DB.Exec(ctx, stmt, userID, kind, ref)
+	if err != nil {
+		return false, fmt.Errorf("recording sent notification: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PasskeyModelInterface interface {
+	Insert(ctx context.Context, userID int, name string, credentialID, publicKey []byte, signCount uint32) error
+	List(ctx context.Context, userID int) ([]Passkey, error)
+	Get(ctx context.Context, credentialID []byte) (Passkey, error)
+	RecordUse(ctx context.Context, id int, signCount uint32) error
+	Delete(ctx context.Context, userID, id int) error
+}
+
+// Passkey is a WebAuthn credential a user can sign in with. PublicKey holds
+// the COSE-encoded key returned by the authenticator.
+type Passkey struct {
+	ID           int
+	UserID       int
+	Name         string
+	CredentialID []byte
+	PublicKey    []byte
+	SignCount    uint32
+	Created      time.Time
+	LastUsed     time.Time
+}
+
+type PasskeyModel struct {
+	DB *pgxpool.Pool
+}
+
+// Insert stores a newly registered credential, returning ErrDuplicatePasskey
+// if the credential is already registered.
+func (m *PasskeyModel) Insert(
+	ctx context.Context,
+	userID int,
+	name string,
+	credentialID, publicKey []byte,
+	signCount uint32,
+) error {
+	stmt := `
+		INSERT INTO passkeys (user_id, name, credential_id, public_key, sign_count, created)
+		VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'UTC')
+	`
+
+	_, err := m.DB.Exec(ctx, stmt, userID, name, credentialID, publicKey, int64(signCount))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicatePasskey
+		}
+
+		return fmt.Errorf("inserting passkey: %w", err)
+	}
+
+	return nil
+}
+
+func (m *PasskeyModel) List(ctx context.Context, userID int) ([]Passkey, error) {
+	stmt := `
+		SELECT id, user_id, name, credential_id, public_key, sign_count, created, last_used
+		FROM passkeys
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := m.DB.Query(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	var passkeys []Passkey
+
+	for rows.Next() {
+		p, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		passkeys = append(passkeys, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating passkeys: %w", err)
+	}
+
+	return passkeys, nil
+}
+
+// Get looks up a credential by the ID the authenticator assigned to it.
+func (m *PasskeyModel) Get(ctx context.Context, credentialID []byte) (Passkey, error) {
+	stmt := `
+		SELECT id, user_id, name, credential_id, public_key, sign_count, created, last_used
+		FROM passkeys
+		WHERE credential_id = $1
+	`
+
+	p, err := scanPasskey(m.DB.QueryRow(ctx, stmt, credentialID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Passkey{}, ErrNoRecord
+		}
+
+		return Passkey{}, err
+	}
+
+	return p, nil
+}
+
+func scanPasskey(row pgx.Row) (Passkey, error) {
+	var (
+		p         Passkey
+		signCount int64
+		lastUsed  *time.Time
+	)
+
+	err := row.Scan(&p.ID, &p.UserID, &p.Name, &p.CredentialID, &p.PublicKey, &signCount, &p.Created, &lastUsed)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("scanning passkey: %w", err)
+	}
+
+	p.SignCount = uint32(signCount) //nolint:gosec // only ever stored from a uint32
+
+	if lastUsed != nil {
+		p.LastUsed = *lastUsed
+	}
+
+	return p, nil
+}
+
+// RecordUse stores the signature counter reported by the latest sign-in.
+func (m *PasskeyModel) RecordUse(ctx context.Context, id int, signCount uint32) error {
+	stmt := `
+		UPDATE passkeys
+		SET sign_count = $2, last_used = NOW() AT TIME ZONE 'UTC'
+		WHERE id = $1
+	`
+
+	if _, err := m.DB.Exec(ctx, stmt, id, int64(signCount)); err != nil {
+		return fmt.Errorf("recording passkey use: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a passkey. Only the owning user can delete their passkeys.
+func (m *PasskeyModel) Delete(ctx context.Context, userID, id int) error {
+	stmt := `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`
+
+	tag, err := m.DB.Exec(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting passkey: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PreferencesModelInterface interface {
+	Get(ctx context.Context, userID int) (Preferences, error)
+	Update(ctx context.Context, userID int, p Preferences) error
+}
+
+// Preferences are a user's display and editing settings.
+type Preferences struct {
+	Theme           string `json:"theme"`
+	SnippetsPerPage int    `json:"snippets_per_page"`
+	DefaultExpiry   int    `json:"default_expiry"`
+	Language        string `json:"language"`
+}
+
+// DefaultPreferences apply to anonymous visitors and to users who never
+// saved their preferences. An empty Language lets the browser decide.
+var DefaultPreferences = Preferences{
+	Theme:           "light",
+	SnippetsPerPage: 10,
+	DefaultExpiry:   365,
+}
+
+type PreferencesModel struct {
+	DB *pgxpool.Pool
+}
+
+// Get returns the user's preferences, falling back to DefaultPreferences for
+// users without a saved row.
+func (m *PreferencesModel) Get(ctx context.Context, userID int) (Preferences, error) {
+	stmt := `
+		SELECT theme, snippets_per_page, default_expiry, language
+		FROM user_preferences
+		WHERE user_id = $1
+	`
+
+	var p Preferences
+
+	err := m.DB.QueryRow(ctx, stmt, userID).Scan(&p.Theme, &p.SnippetsPerPage, &p.DefaultExpiry, &p.Language)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DefaultPreferences, nil
+		}
+
+		return Preferences{}, fmt.Errorf("fetching preferences: %w", err)
+	}
+
+	return p, nil
+}
+
+func (m *PreferencesModel) Update(ctx context.Context, userID int, p Preferences) error {
+	stmt := `
+		INSERT INTO user_preferences (user_id, theme, snippets_per_page, default_expiry, language)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET theme = EXCLUDED.theme,
+		    snippets_per_page = EXCLUDED.snippets_per_page,
+		    default_expiry = EXCLUDED.default_expiry,
+		    language = EXCLUDED.language
+	`
+
+	_, err := m.DB.Exec(ctx, stmt, userID, p.Theme, p.SnippetsPerPage, p.DefaultExpiry, p.Language)
+	if err != nil {
+		return fmt.Errorf("updating preferences: %w", err)
+	}
+
+	return nil
+}
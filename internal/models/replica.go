@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaRetryAfter is how long reads stay on the primary after the
+// replica failed to answer one.
+const replicaRetryAfter = 30 * time.Second
+
+// Replica is a read-only replica of the database. Models that have one run
+// reads that may lag a moment behind writes on it, and go back to the
+// primary while it is unreachable. A nil *Replica sends every read to the
+// primary.
+type Replica struct {
+	DB *pgxpool.Pool
+	// Logger, if set, is told when the replica fails and reads fall back
+	// to the primary.
+	Logger *slog.Logger
+
+	// downUntil is when, in Unix nanoseconds, reads may try the replica
+	// again.
+	downUntil atomic.Int64
+}
+
+// up reports whether reads should try the replica.
+func (r *Replica) up() bool {
+	return r != nil && time.Now().UnixNano() >= r.downUntil.Load()
+}
+
+// fail sends reads to the primary for replicaRetryAfter.
+func (r *Replica) fail(err error) {
+	r.downUntil.Store(time.Now().Add(replicaRetryAfter).UnixNano())
+
+	if r.Logger != nil {
+		r.Logger.Warn("read replica failed; reading from the primary",
+			slog.String("err", err.Error()), slog.Duration("retry_after", replicaRetryAfter))
+	}
+}
+
+type primaryContextKey struct{}
+
+// Primary returns a context whose reads go to the primary even when the
+// model has a replica. Reads that must see a write just made, like the one
+// that returns a snippet after updating it, or whose result is kept, like a
+// cache fill, use it so that a lagging replica cannot serve an old row.
+func Primary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// useReplica reports whether a read with ctx should try the replica.
+func useReplica(ctx context.Context, replica *Replica) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+
+	return !primary && !inTx(ctx) && replica.up()
+}
+
+// readFrom runs read on the replica when it is up and on the primary
+// otherwise, or when the replica fails to answer. Inside a transaction it
+// runs on the transaction, so reads see the writes made in it, and with a
+// context from Primary on the primary.
+func readFrom[T any](ctx context.Context, primary *pgxpool.Pool, replica *Replica, read func(db dbtx) (T, error)) (T, error) {
+	if !useReplica(ctx, replica) {
+		return read(dbFor(ctx, primary))
+	}
+
+	v, err := read(replica.DB)
+	if err == nil || !replicaFailure(ctx, err) {
+		return v, err
+	}
+
+	replica.fail(err)
+
+	return read(primary)
+}
+
+// replicaFailure reports whether err means the replica could not answer,
+// rather than that it answered with an error the primary would give too.
+func replicaFailure(ctx context.Context, err error) bool {
+	var pgErr *pgconn.PgError
+
+	switch {
+	case ctx.Err() != nil, errors.Is(err, ErrNoRecord), errors.Is(err, pgx.ErrNoRows):
+		return false
+	case errors.As(err, &pgErr):
+		// A hot standby cancels queries that conflict with replaying the
+		// primary's changes, and refuses them while starting up or
+		// shutting down.
+		return pgErr.Code == "40001" || strings.HasPrefix(pgErr.Code, "57")
+	default:
+		return true
+	}
+}
+
+// inTx reports whether ctx carries a transaction begun by WithTx.
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(pgx.Tx)
+
+	return ok
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestReplicaFailure(t *testing.T) {
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"no record", t.Context(), ErrNoRecord, false},
+		{"no rows", t.Context(), fmt.Errorf("getting: %w", pgx.ErrNoRows), false},
+		{"syntax error", t.Context(), &pgconn.PgError{Code: "42601"}, false},
+		{"recovery conflict", t.Context(), &pgconn.PgError{Code: "40001"}, true},
+		{"shutting down", t.Context(), &pgconn.PgError{Code: "57P01"}, true},
+		{"unreachable", t.Context(), errors.New("dial tcp: connection refused"), true},
+		{"canceled request", canceled, context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, replicaFailure(tt.ctx, tt.err), tt.want)
+		})
+	}
+}
+
+func TestReplicaUp(t *testing.T) {
+	var none *Replica
+	assert.Equal(t, none.up(), false)
+
+	r := &Replica{}
+	assert.Equal(t, r.up(), true)
+
+	r.fail(errors.New("connection refused"))
+	assert.Equal(t, r.up(), false)
+}
+
+func TestUseReplica(t *testing.T) {
+	r := &Replica{}
+
+	assert.Equal(t, useReplica(t.Context(), r), true)
+	assert.Equal(t, useReplica(Primary(t.Context()), r), false)
+	assert.Equal(t, useReplica(t.Context(), nil), false)
+}
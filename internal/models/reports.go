@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReportModelInterface interface {
+	Insert(ctx context.Context, snippetID int, reason, contact string) (int, error)
+	Open(ctx context.Context, limit int) ([]Report, error)
+	Resolve(ctx context.Context, id int) error
+}
+
+// Report is an abuse report about a snippet, waiting for a moderator until
+// it is resolved.
+type Report struct {
+	ID        int
+	SnippetID int
+	Reason    string
+	// Contact is how the reporter can be reached, if they said.
+	Contact string
+	Created time.Time
+}
+
+type ReportModel struct {
+	DB *pgxpool.Pool
+}
+
+func scanReport(row pgx.CollectableRow) (Report, error) {
+	var r Report
+
+	err := row.Scan(&r.ID, &r.SnippetID, &r.Reason, &r.Contact, &r.Created)
+
+	return r, err //nolint:wrapcheck // the query helpers' callers wrap it
+}
+
+// Insert queues a report about the snippet for the moderators.
+func (m *ReportModel) Insert(ctx context.Context, snippetID int, reason, contact string) (int, error) {
+	stmt := `
+		INSERT INTO abuse_reports (snippet_id, reason, contact, created)
+		VALUES ($1, $2, $3, NOW() AT TIME ZONE 'UTC')
+		RETURNING id
+	`
+
+	var id int
+
+	if err := m.DB.QueryRow(ctx, stmt, snippetID, reason, contact).Scan(&id); err != nil {
+		return 0, fmt.Errorf("inserting abuse report: %w", err)
+	}
+
+	return id, nil
+}
+
+// Open returns the unresolved reports, oldest first.
+func (m *ReportModel) Open(ctx context.Context, limit int) ([]Report, error) {
+	stmt := `
+		SELECT id, snippet_id, reason, contact, created
+		FROM abuse_reports
+		WHERE resolved IS NULL
+		ORDER BY created, id
+		LIMIT $1
+	`
+
+	reports, err := queryAll(ctx, m.DB, scanReport, stmt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing abuse reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Resolve takes a report off the queue. It returns ErrNoRecord if there is
+// no such unresolved report.
+func (m *ReportModel) Resolve(ctx context.Context, id int) error {
+	stmt := `UPDATE abuse_reports SET resolved = NOW() AT TIME ZONE 'UTC' WHERE id = $1 AND resolved IS NULL`
+
+	tag, err := m.DB.Exec(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("resolving abuse report: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
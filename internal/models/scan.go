@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
+)
+
+// querier runs queries; a pool, a connection and a transaction all do.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// queryAll runs a query and scans every row it returns with scan. Models
+// pair it with a scan function per type, such as scanSnippet, so the list
+// of columns a type is read from is written once rather than in every
+// method. Database errors are wrapped as internal errors with the stack
+// of the model method that ran the query.
+func queryAll[T any](ctx context.Context, db querier, scan pgx.RowToFunc[T], stmt string, args ...any) ([]T, error) {
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, errorx.Wrap(err, errorx.Internal, "")
+	}
+
+	v, err := pgx.CollectRows(rows, scan)
+
+	return v, errorx.Wrap(err, errorx.Internal, "")
+}
+
+// queryOne runs a query for a single row and scans it with scan. It
+// returns ErrNoRecord if there is no such row.
+func queryOne[T any](ctx context.Context, db querier, scan pgx.RowToFunc[T], stmt string, args ...any) (T, error) {
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		var zero T
+
+		return zero, errorx.Wrap(err, errorx.Internal, "")
+	}
+
+	v, err := pgx.CollectOneRow(rows, scan)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return v, ErrNoRecord
+	}
+
+	return v, errorx.Wrap(err, errorx.Internal, "")
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// seedSQL inserts sample snippets for development.
+//
+//go:embed seed.sql
+var seedSQL string
+
+// Seed inserts the sample snippets that are not in the database yet.
+func Seed(ctx context.Context, db *pgxpool.Pool) error {
+	if _, err := db.Exec(ctx, seedSQL); err != nil {
+		return fmt.Errorf("seeding database: %w", err)
+	}
+
+	return nil
+}
+
+// requiredTables are the tables created by the migrations. A database missing
+// any of them has not been brought up to date with the running code.
+var requiredTables = []string{
+	"snippets",
+	"users",
+	"audit_events",
+	"snippet_view_rollups",
+	"api_tokens",
+	"api_token_ips",
+	"user_preferences",
+	"health_checks",
+	"status_incidents",
+	"settings",
+	"data_exports",
+	"email_queue",
+	"passkeys",
+	"notification_settings",
+	"notifications_sent",
+	"sessions",
+	"daily_stats",
+	"webhooks",
+}
+
+// MissingTables returns the required tables that do not exist in the
+// database, in the order the migrations create them.
+func MissingTables(ctx context.Context, db *pgxpool.Pool) ([]string, error) {
+	stmt := `
+		SELECT t.name
+		FROM unnest($1::text[]) WITH ORDINALITY AS t(name, n)
+		WHERE to_regclass(t.name) IS NULL
+		ORDER BY t.n
+	`
+
+	rows, err := db.Query(ctx, stmt, requiredTables)
+	if err != nil {
+		return nil, fmt.Errorf("checking schema: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning missing table: %w", err)
+		}
+		missing = append(missing, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating missing tables: %w", err)
+	}
+
+	return missing, nil
+}
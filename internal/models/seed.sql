@@ -0,0 +1,23 @@
+-- Sample snippets for development (matching the original MySQL data). Each
+-- one is only inserted if no snippet with the same title exists, so seeding
+-- twice does not duplicate them.
+INSERT INTO snippets (title, content, created, expires)
+SELECT 'An old silent pond',
+    'An old silent pond...' || E'\n' || 'A frog jumps into the pond,' || E'\n' || 'splash! Silence again.' || E'\n\n' || '– Matsuo Bashō',
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP + INTERVAL '365 days'
+WHERE NOT EXISTS (SELECT 1 FROM snippets WHERE title = 'An old silent pond');
+
+INSERT INTO snippets (title, content, created, expires)
+SELECT 'Over the wintry forest',
+    'Over the wintry' || E'\n' || 'forest, winds howl in rage' || E'\n' || 'with no leaves to blow.' || E'\n\n' || '– Natsume Soseki',
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP + INTERVAL '365 days'
+WHERE NOT EXISTS (SELECT 1 FROM snippets WHERE title = 'Over the wintry forest');
+
+INSERT INTO snippets (title, content, created, expires)
+SELECT 'First autumn morning',
+    'First autumn morning' || E'\n' || 'the mirror I stare into' || E'\n' || 'shows my father''s face.' || E'\n\n' || '– Murakami Kijo',
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP + INTERVAL '7 days'
+WHERE NOT EXISTS (SELECT 1 FROM snippets WHERE title = 'First autumn morning');
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Registration modes for Settings.RegistrationMode.
+const (
+	RegistrationOpen   = "open"
+	RegistrationClosed = "closed"
+)
+
+type SettingsModelInterface interface {
+	Get(ctx context.Context) (Settings, error)
+	Update(ctx context.Context, s Settings, updatedBy int) error
+}
+
+// Settings are the instance-wide options admins can change at runtime. Each
+// field is stored as its own row in the settings table, keyed by its JSON
+// name, so new settings start out at their default.
+type Settings struct {
+	RegistrationMode   string `json:"registration_mode"`
+	MaxSnippetLength   int    `json:"max_snippet_length"`
+	MaintenanceMode    bool   `json:"maintenance_mode"`
+	MaintenanceMessage string `json:"maintenance_message"`
+	Banner             string `json:"banner"`
+}
+
+// DefaultSettings apply to every setting that has never been changed.
+var DefaultSettings = Settings{
+	RegistrationMode:   RegistrationOpen,
+	MaxSnippetLength:   100000,
+	MaintenanceMessage: "Snippetbox is in maintenance mode and currently read-only.",
+}
+
+func (s Settings) values() (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("encoding settings: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("decoding settings: %w", err)
+	}
+
+	return values, nil
+}
+
+// Changes lists the settings that differ between s and other as
+// "key: old -> new", sorted by key.
+func (s Settings) Changes(other Settings) []string {
+	before, err := s.values()
+	if err != nil {
+		return nil
+	}
+
+	after, err := other.values()
+	if err != nil {
+		return nil
+	}
+
+	var changes []string
+
+	for _, key := range slices.Sorted(maps.Keys(after)) {
+		if string(before[key]) != string(after[key]) {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, before[key], after[key]))
+		}
+	}
+
+	return changes
+}
+
+type SettingsModel struct {
+	DB *pgxpool.Pool
+}
+
+func (m *SettingsModel) Get(ctx context.Context) (Settings, error) {
+	values, err := DefaultSettings.values()
+	if err != nil {
+		return Settings{}, err
+	}
+
+	rows, err := dbFor(ctx, m.DB).Query(ctx, `SELECT key, value FROM settings`)
+	if err != nil {
+		return Settings{}, fmt.Errorf("querying settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key   string
+			value []byte
+		)
+
+		if err := rows.Scan(&key, &value); err != nil {
+			return Settings{}, fmt.Errorf("scanning setting: %w", err)
+		}
+
+		values[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return Settings{}, fmt.Errorf("iterating settings: %w", err)
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return Settings{}, fmt.Errorf("encoding settings: %w", err)
+	}
+
+	var s Settings
+	if err := json.Unmarshal(b, &s); err != nil {
+		return Settings{}, fmt.Errorf("decoding settings: %w", err)
+	}
+
+	return s, nil
+}
+
+// Update stores every setting of s in a single transaction.
+func (m *SettingsModel) Update(ctx context.Context, s Settings, updatedBy int) error {
+	values, err := s.values()
+	if err != nil {
+		return err
+	}
+
+	tx, err := dbFor(ctx, m.DB).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	stmt := `
+		INSERT INTO settings (key, value, updated, updated_by)
+		VALUES ($1, $2, NOW() AT TIME ZONE 'UTC', NULLIF($3, 0))
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated = EXCLUDED.updated, updated_by = EXCLUDED.updated_by
+		WHERE settings.value IS DISTINCT FROM EXCLUDED.value
+	`
+
+	for key, value := range values {
+		if _, err := tx.Exec(ctx, stmt, key, []byte(value), updatedBy); err != nil {
+			return fmt.Errorf("updating setting %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing settings: %w", err)
+	}
+
+	return nil
+}
@@ -3,17 +3,29 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
 )
 
 type SnippetModelInterface interface {
-	Insert(ctx context.Context, title, content string, expires int) (int, error)
+	Insert(ctx context.Context, userID int, title, content string, expires int) (int, error)
+	InsertBatch(ctx context.Context, userID int, snippets []NewSnippet) ([]int, error)
 	Get(ctx context.Context, id int) (Snippet, error)
-	Latest(ctx context.Context) ([]Snippet, error)
+	Latest(ctx context.Context, limit int) ([]Snippet, error)
+	LatestPage(ctx context.Context, limit, offset int) ([]Snippet, error)
+	Count(ctx context.Context) (int, error)
+	Search(ctx context.Context, search SnippetSearch) ([]SnippetMatch, int, error)
+	ByUser(ctx context.Context, userID int) ([]Snippet, error)
+	Expiring(ctx context.Context, before time.Time) ([]Snippet, error)
+	Update(ctx context.Context, id int, version time.Time, title, content string, expires int) error
+	Delete(ctx context.Context, id int, version time.Time) error
 }
 
 type Snippet struct {
@@ -21,84 +33,392 @@ type Snippet struct {
 	Title   string
 	Content string
 	Created time.Time
+	// Updated is when the title, content or expiry last changed, which is
+	// Created for snippets that were never edited.
+	Updated time.Time
 	Expires time.Time
+	// UserID is the owner of the snippet, or 0 for anonymous snippets.
+	UserID int
+}
+
+// NewSnippet is a snippet to insert with InsertBatch. Expires is the number
+// of days it stays up.
+type NewSnippet struct {
+	Title   string
+	Content string
+	Expires int
+}
+
+// Orders of search results.
+const (
+	SearchRelevance = "relevance"
+	SearchNewest    = "newest"
+	SearchOldest    = "oldest"
+)
+
+// searchOrders maps the orders of search results to their ORDER BY clauses.
+var searchOrders = map[string]string{
+	SearchRelevance: "rank DESC, id DESC",
+	SearchNewest:    "id DESC",
+	SearchOldest:    "id",
+}
+
+// ValidSearchOrder reports whether order is one of the orders of search
+// results.
+func ValidSearchOrder(order string) bool {
+	_, ok := searchOrders[order]
+
+	return ok
+}
+
+// SnippetSearch is a full-text search of the live snippets. Query uses web
+// search syntax: quoted phrases, OR and -word.
+type SnippetSearch struct {
+	Query string
+	// AuthorID limits the results to one user's snippets unless it is 0.
+	AuthorID int
+	// Order is SearchRelevance, SearchNewest or SearchOldest.
+	Order  string
+	Limit  int
+	Offset int
+}
+
+// SnippetMatch is a snippet found by a search, with its relevance to the
+// query. Ranks only compare matches of the same query.
+type SnippetMatch struct {
+	Snippet
+	Rank float64
+	// Headline is up to two excerpts of the content around the matches,
+	// joined by " … ", with each matching word between HeadlineStart and
+	// HeadlineStop.
+	Headline string
+}
+
+// HeadlineStart and HeadlineStop mark the matching words of a headline.
+// They are private-use characters, which do not occur in ordinary text.
+const (
+	HeadlineStart = "\ue000"
+	HeadlineStop  = "\ue001"
+)
+
+// headlineOptions configure ts_headline for SnippetMatch.Headline.
+const headlineOptions = `StartSel="` + HeadlineStart + `", StopSel="` + HeadlineStop +
+	`", MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" … "`
+
+// ContentHash returns the hex-encoded SHA-256 digest of the snippet content.
+func (s Snippet) ContentHash() string {
+	sum := sha256.Sum256([]byte(s.Content))
+
+	return hex.EncodeToString(sum[:])
 }
 
 type SnippetModel struct {
 	DB *pgxpool.Pool
+	// Replica, if not nil, serves Get, Latest, LatestPage, Count and
+	// Search.
+	Replica *Replica
 }
 
-func (m *SnippetModel) Insert(ctx context.Context, title, content string, expires int) (int, error) {
-	stmt := `
-		INSERT INTO snippets (title, content, created, expires)
-		VALUES ($1, $2, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC' + $3 * INTERVAL '1 day')
-		RETURNING id
-	`
+const insertSnippetStmt = `
+	INSERT INTO snippets (title, content, created, updated, expires, user_id)
+	VALUES ($1, $2, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC' + $3 * INTERVAL '1 day', NULLIF($4, 0))
+	RETURNING id
+`
 
+func (m *SnippetModel) Insert(ctx context.Context, userID int, title, content string, expires int) (int, error) {
 	var id int
-	err := m.DB.QueryRow(ctx, stmt, title, content, expires).Scan(&id)
+	err := dbFor(ctx, m.DB).QueryRow(ctx, insertSnippetStmt, title, content, expires, userID).Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, errorx.Wrap(err, errorx.Internal, "inserting snippet")
 	}
 
 	return id, nil
 }
 
+// InsertBatch inserts snippets owned by userID in one round trip and returns
+// their IDs in order. It runs in a transaction, so either all of them are
+// inserted or none is.
+func (m *SnippetModel) InsertBatch(ctx context.Context, userID int, snippets []NewSnippet) ([]int, error) {
+	tx, err := dbFor(ctx, m.DB).Begin(ctx)
+	if err != nil {
+		return nil, errorx.Errorf(errorx.Internal, "beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	batch := &pgx.Batch{}
+	for _, s := range snippets {
+		batch.Queue(insertSnippetStmt, s.Title, s.Content, s.Expires, userID)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+
+	ids := make([]int, len(snippets))
+	for i := range ids {
+		if err := results.QueryRow().Scan(&ids[i]); err != nil {
+			results.Close()
+
+			return nil, errorx.Errorf(errorx.Internal, "inserting snippet %d of the batch: %w", i, err)
+		}
+	}
+
+	if err := results.Close(); err != nil {
+		return nil, errorx.Errorf(errorx.Internal, "closing batch results: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errorx.Errorf(errorx.Internal, "committing snippet batch: %w", err)
+	}
+
+	return ids, nil
+}
+
+// snippetColumns are the columns of a snippet, in the order scanSnippet
+// reads them.
+const snippetColumns = `id, title, content, created, updated, expires, COALESCE(user_id, 0)`
+
+// columns returns where each of snippetColumns is scanned to.
+func (s *Snippet) columns() []any {
+	return []any{&s.ID, &s.Title, &s.Content, &s.Created, &s.Updated, &s.Expires, &s.UserID}
+}
+
+// scanSnippet scans a row selecting snippetColumns.
+func scanSnippet(row pgx.CollectableRow) (Snippet, error) {
+	var s Snippet
+	err := row.Scan(s.columns()...)
+
+	return s, err //nolint:wrapcheck // the query helpers' callers wrap it
+}
+
 func (m *SnippetModel) Get(ctx context.Context, id int) (Snippet, error) {
-	stmt := `
-		SELECT id, title, content, created, expires
+	const stmt = `
+		SELECT ` + snippetColumns + `
 		FROM snippets
 		WHERE expires > NOW() AT TIME ZONE 'UTC' AND id = $1
 	`
 
-	row := m.DB.QueryRow(ctx, stmt, id)
+	get := func(db dbtx) (Snippet, error) {
+		return queryOne(ctx, db, scanSnippet, stmt, id)
+	}
+
+	replica := useReplica(ctx, m.Replica)
 
-	var s Snippet
-	err := row.Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires)
+	s, err := readFrom(ctx, m.DB, m.Replica, get)
+
+	// A snippet created a moment ago, like one whose creation redirected
+	// here, may not have reached the replica yet.
+	if errors.Is(err, ErrNoRecord) && replica && m.Replica.up() {
+		return get(m.DB)
+	}
+
+	return s, err
+}
+
+// Update replaces the title and content of a live snippet and sets it to
+// expire the given number of days from now. If version is not zero, the
+// snippet is only changed if it was last updated at version, and
+// ErrEditConflict is returned if it has been updated since.
+func (m *SnippetModel) Update(ctx context.Context, id int, version time.Time, title, content string, expires int) error {
+	stmt := `
+		UPDATE snippets
+		SET title = $3, content = $4, updated = NOW() AT TIME ZONE 'UTC',
+			expires = NOW() AT TIME ZONE 'UTC' + $5 * INTERVAL '1 day'
+		WHERE expires > NOW() AT TIME ZONE 'UTC' AND id = $1
+		  AND ($2::timestamp IS NULL OR updated = $2)
+	`
+
+	tag, err := dbFor(ctx, m.DB).Exec(ctx, stmt, id, versionArg(version), title, content, expires)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return Snippet{}, ErrNoRecord
-		}
-		return Snippet{}, err
+		return errorx.Errorf(errorx.Internal, "updating snippet: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return m.missing(ctx, id, version)
 	}
 
-	return s, nil
+	return nil
 }
 
-func (m *SnippetModel) Latest(ctx context.Context) ([]Snippet, error) {
+// Delete deletes a live snippet along with its view statistics. Like
+// Update, it returns ErrEditConflict if version is not zero and the snippet
+// has been updated since.
+func (m *SnippetModel) Delete(ctx context.Context, id int, version time.Time) error {
 	stmt := `
-		SELECT id, title, content, created, expires
+		DELETE FROM snippets
+		WHERE expires > NOW() AT TIME ZONE 'UTC' AND id = $1
+		  AND ($2::timestamp IS NULL OR updated = $2)
+	`
+
+	tag, err := dbFor(ctx, m.DB).Exec(ctx, stmt, id, versionArg(version))
+	if err != nil {
+		return errorx.Errorf(errorx.Internal, "deleting snippet: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return m.missing(ctx, id, version)
+	}
+
+	return nil
+}
+
+// versionArg returns the argument for a version condition, NULL for the
+// zero version so that any version matches.
+func versionArg(version time.Time) any {
+	if version.IsZero() {
+		return nil
+	}
+
+	return version
+}
+
+// missing explains why a change to the snippet id matched no row: either
+// there is no such live snippet, or it is no longer at version.
+func (m *SnippetModel) missing(ctx context.Context, id int, version time.Time) error {
+	if version.IsZero() {
+		return ErrNoRecord
+	}
+
+	stmt := `SELECT EXISTS (SELECT 1 FROM snippets WHERE expires > NOW() AT TIME ZONE 'UTC' AND id = $1)`
+
+	var exists bool
+	if err := dbFor(ctx, m.DB).QueryRow(ctx, stmt, id).Scan(&exists); err != nil {
+		return errorx.Errorf(errorx.Internal, "checking snippet: %w", err)
+	}
+
+	if exists {
+		return ErrEditConflict
+	}
+
+	return ErrNoRecord
+}
+
+func (m *SnippetModel) Latest(ctx context.Context, limit int) ([]Snippet, error) {
+	return m.LatestPage(ctx, limit, 0)
+}
+
+// LatestPage returns up to limit live snippets, newest first, skipping the
+// offset newest ones.
+func (m *SnippetModel) LatestPage(ctx context.Context, limit, offset int) ([]Snippet, error) {
+	const stmt = `
+		SELECT ` + snippetColumns + `
 		FROM snippets
 		WHERE expires > NOW() AT TIME ZONE 'UTC'
 		ORDER BY id DESC
-		LIMIT 10
+		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := m.DB.Query(ctx, stmt)
-	if err != nil {
-		return nil, err
+	return readFrom(ctx, m.DB, m.Replica, func(db dbtx) ([]Snippet, error) {
+		return queryAll(ctx, db, scanSnippet, stmt, limit, offset)
+	})
+}
+
+// Count returns the number of live snippets.
+func (m *SnippetModel) Count(ctx context.Context) (int, error) {
+	stmt := `SELECT COUNT(*) FROM snippets WHERE expires > NOW() AT TIME ZONE 'UTC'`
+
+	return readFrom(ctx, m.DB, m.Replica, func(db dbtx) (int, error) {
+		var n int
+		err := db.QueryRow(ctx, stmt).Scan(&n)
+
+		return n, err //nolint:wrapcheck // callers say what they were counting
+	})
+}
+
+// Search returns a page of the live snippets matching search, and how many
+// match in total.
+func (m *SnippetModel) Search(ctx context.Context, search SnippetSearch) ([]SnippetMatch, int, error) {
+	order, ok := searchOrders[search.Order]
+	if !ok {
+		return nil, 0, errorx.Errorf(errorx.Validation, "unknown search order %q", search.Order)
 	}
-	defer rows.Close()
 
-	var snippets []Snippet
+	const from = `
+		FROM snippets, websearch_to_tsquery('english', $1) AS query
+		WHERE search @@ query
+		  AND expires > NOW() AT TIME ZONE 'UTC'
+		  AND ($2 = 0 OR user_id = $2)
+	`
+
+	// Headlines are costly, so they are only made for the page of results,
+	// outside the subquery that picks it.
+	//
+	//nolint:gosec // order comes from searchOrders, not from the client
+	stmt := `
+		SELECT ` + snippetColumns + `, rank, ts_headline('english', content, query, $5)
+		FROM (
+			SELECT *, ts_rank(search, query) AS rank
+		` + from + `
+			ORDER BY ` + order + `
+			LIMIT $3 OFFSET $4
+		) AS page
+		ORDER BY ` + order
 
-	for rows.Next() {
-		var s Snippet
-		err := rows.Scan(
-			&s.ID,
-			&s.Title,
-			&s.Content,
-			&s.Created,
-			&s.Expires,
-		)
+	type results struct {
+		matches []SnippetMatch
+		total   int
+	}
+
+	// Both queries run on the same database, so the total counts the
+	// matches the page was taken from.
+	r, err := readFrom(ctx, m.DB, m.Replica, func(db dbtx) (results, error) {
+		var r results
+
+		if err := db.QueryRow(ctx, "SELECT COUNT(*)"+from, search.Query, search.AuthorID).Scan(&r.total); err != nil {
+			return r, errorx.Errorf(errorx.Internal, "counting search results: %w", err)
+		}
+
+		var err error
+
+		r.matches, err = queryAll(ctx, db, scanSnippetMatch, stmt,
+			search.Query, search.AuthorID, search.Limit, search.Offset, headlineOptions)
 		if err != nil {
-			return nil, err
+			return r, errorx.Errorf(errorx.Internal, "searching snippets: %w", err)
 		}
-		snippets = append(snippets, s)
+
+		return r, nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return r.matches, r.total, nil
+}
+
+// scanSnippetMatch scans a row selecting snippetColumns, the rank and the
+// headline.
+func scanSnippetMatch(row pgx.CollectableRow) (SnippetMatch, error) {
+	var s SnippetMatch
+	err := row.Scan(append(s.columns(), &s.Rank, &s.Headline)...)
+
+	return s, err //nolint:wrapcheck // the query helpers' callers wrap it
+}
+
+// ByUser returns every snippet owned by the user, including expired ones.
+func (m *SnippetModel) ByUser(ctx context.Context, userID int) ([]Snippet, error) {
+	const stmt = `
+		SELECT ` + snippetColumns + `
+		FROM snippets
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	return queryAll(ctx, dbFor(ctx, m.DB), scanSnippet, stmt, userID)
+}
+
+// Expiring returns the owned snippets that are still live but expire before
+// the given time, soonest first.
+func (m *SnippetModel) Expiring(ctx context.Context, before time.Time) ([]Snippet, error) {
+	const stmt = `
+		SELECT ` + snippetColumns + `
+		FROM snippets
+		WHERE user_id IS NOT NULL
+		  AND expires > NOW() AT TIME ZONE 'UTC'
+		  AND expires <= $1
+		ORDER BY expires
+	`
+
+	snippets, err := queryAll(ctx, dbFor(ctx, m.DB), scanSnippet, stmt, before.UTC())
+	if err != nil {
+		return nil, errorx.Errorf(errorx.Internal, "querying expiring snippets: %w", err)
 	}
 
 	return snippets, nil
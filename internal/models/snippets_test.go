@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+	"github.com/FABLOUSFALCON/snippetbox/internal/errorx"
+)
+
+func TestSnippetModel_Search(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	m := SnippetModel{DB: newTestDB(t)}
+
+	_, err := m.Insert(t.Context(), 1, "Haiku", "An old silent pond. A frog jumps into the pond, splash! Silence again.", 7)
+	assert.NilError(t, err)
+
+	_, err = m.Insert(t.Context(), 1, "Frogs", "Tree frogs climb.", 7)
+	assert.NilError(t, err)
+
+	matches, total, err := m.Search(t.Context(), SnippetSearch{Query: "pond -tree", Order: SearchRelevance, Limit: 10})
+	assert.NilError(t, err)
+	assert.Equal(t, total, 1)
+	assert.Equal(t, len(matches), 1)
+	assert.Equal(t, matches[0].Title, "Haiku")
+	assert.StringContains(t, matches[0].Headline, HeadlineStart+"pond"+HeadlineStop)
+}
+
+func TestSnippetModel_SearchUnknownOrder(t *testing.T) {
+	m := SnippetModel{}
+
+	_, _, err := m.Search(t.Context(), SnippetSearch{Query: "pond", Order: "popular", Limit: 10})
+	assert.Equal(t, errorx.KindOf(err), errorx.Validation)
+	assert.StringContains(t, errorx.Stack(err), "models.(*SnippetModel).Search")
+}
+
+func TestSnippetModel_UpdateVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	m := SnippetModel{DB: newTestDB(t)}
+
+	id, err := m.Insert(t.Context(), 1, "Haiku", "An old silent pond.", 7)
+	assert.NilError(t, err)
+
+	s, err := m.Get(t.Context(), id)
+	assert.NilError(t, err)
+
+	// Two clients edit the version they both read; only the first wins.
+	assert.NilError(t, m.Update(t.Context(), id, s.Updated, "First", "An old silent pond.", 7))
+	assert.Equal(t, errors.Is(m.Update(t.Context(), id, s.Updated, "Second", "A frog jumps.", 7), ErrEditConflict), true)
+	assert.Equal(t, errors.Is(m.Delete(t.Context(), id, s.Updated), ErrEditConflict), true)
+
+	s, err = m.Get(t.Context(), id)
+	assert.NilError(t, err)
+	assert.Equal(t, s.Title, "First")
+
+	assert.NilError(t, m.Delete(t.Context(), id, s.Updated))
+	assert.Equal(t, errors.Is(m.Delete(t.Context(), id, s.Updated), ErrNoRecord), true)
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatusModelInterface interface {
+	RecordCheck(ctx context.Context, hc HealthCheck) error
+	PruneChecks(ctx context.Context, before time.Time) error
+	Uptime(ctx context.Context, since time.Time) (Uptime, error)
+	Incidents(ctx context.Context, limit int) ([]Incident, error)
+	GetIncident(ctx context.Context, id int) (Incident, error)
+	InsertIncident(ctx context.Context, title, notes string) (int, error)
+	UpdateIncident(ctx context.Context, id int, notes string, resolved bool) error
+}
+
+// HealthCheck is the outcome of a single periodic health check.
+type HealthCheck struct {
+	Checked time.Time
+	OK      bool
+	Latency time.Duration
+	Error   string
+}
+
+// Uptime counts the health checks recorded in a time window.
+type Uptime struct {
+	Checks int
+	OK     int
+}
+
+// Percent returns the share of successful checks, or 100 when nothing has
+// been recorded yet.
+func (u Uptime) Percent() float64 {
+	if u.Checks == 0 {
+		return 100
+	}
+
+	return float64(u.OK) / float64(u.Checks) * 100
+}
+
+// Incident is an admin-maintained note about an outage or degradation.
+// Resolved is the zero time while the incident is ongoing.
+type Incident struct {
+	ID       int
+	Title    string
+	Notes    string
+	Started  time.Time
+	Resolved time.Time
+}
+
+func (i Incident) Ongoing() bool {
+	return i.Resolved.IsZero()
+}
+
+type StatusModel struct {
+	DB *pgxpool.Pool
+}
+
+func (m *StatusModel) RecordCheck(ctx context.Context, hc HealthCheck) error {
+	stmt := `
+		INSERT INTO health_checks (checked_at, ok, latency_ms, error)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := m.DB.Exec(ctx, stmt, hc.Checked.UTC(), hc.OK, hc.Latency.Milliseconds(), hc.Error)
+	if err != nil {
+		return fmt.Errorf("inserting health check: %w", err)
+	}
+
+	return nil
+}
+
+// PruneChecks deletes health checks recorded before the given time.
+func (m *StatusModel) PruneChecks(ctx context.Context, before time.Time) error {
+	stmt := `DELETE FROM health_checks WHERE checked_at < $1`
+
+	if _, err := m.DB.Exec(ctx, stmt, before.UTC()); err != nil {
+		return fmt.Errorf("pruning health checks: %w", err)
+	}
+
+	return nil
+}
+
+func (m *StatusModel) Uptime(ctx context.Context, since time.Time) (Uptime, error) {
+	stmt := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE ok)
+		FROM health_checks
+		WHERE checked_at >= $1
+	`
+
+	var u Uptime
+
+	if err := m.DB.QueryRow(ctx, stmt, since.UTC()).Scan(&u.Checks, &u.OK); err != nil {
+		return Uptime{}, fmt.Errorf("counting health checks: %w", err)
+	}
+
+	return u, nil
+}
+
+func (m *StatusModel) Incidents(ctx context.Context, limit int) ([]Incident, error) {
+	stmt := `
+		SELECT id, title, notes, started, resolved
+		FROM status_incidents
+		ORDER BY started DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := m.DB.Query(ctx, stmt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []Incident
+
+	for rows.Next() {
+		var (
+			i        Incident
+			resolved *time.Time
+		)
+
+		if err := rows.Scan(&i.ID, &i.Title, &i.Notes, &i.Started, &resolved); err != nil {
+			return nil, fmt.Errorf("scanning incident: %w", err)
+		}
+
+		if resolved != nil {
+			i.Resolved = *resolved
+		}
+
+		incidents = append(incidents, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating incidents: %w", err)
+	}
+
+	return incidents, nil
+}
+
+func (m *StatusModel) GetIncident(ctx context.Context, id int) (Incident, error) {
+	stmt := `
+		SELECT id, title, notes, started, resolved
+		FROM status_incidents
+		WHERE id = $1
+	`
+
+	var (
+		i        Incident
+		resolved *time.Time
+	)
+
+	err := m.DB.QueryRow(ctx, stmt, id).Scan(&i.ID, &i.Title, &i.Notes, &i.Started, &resolved)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Incident{}, ErrNoRecord
+		}
+
+		return Incident{}, fmt.Errorf("fetching incident: %w", err)
+	}
+
+	if resolved != nil {
+		i.Resolved = *resolved
+	}
+
+	return i, nil
+}
+
+func (m *StatusModel) InsertIncident(ctx context.Context, title, notes string) (int, error) {
+	stmt := `
+		INSERT INTO status_incidents (title, notes, started)
+		VALUES ($1, $2, NOW() AT TIME ZONE 'UTC')
+		RETURNING id
+	`
+
+	var id int
+
+	if err := m.DB.QueryRow(ctx, stmt, title, notes).Scan(&id); err != nil {
+		return 0, fmt.Errorf("inserting incident: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateIncident replaces the notes of an incident. Marking it resolved sets
+// the resolution time once; unmarking it reopens the incident.
+func (m *StatusModel) UpdateIncident(ctx context.Context, id int, notes string, resolved bool) error {
+	stmt := `
+		UPDATE status_incidents
+		SET notes = $2,
+		    resolved = CASE
+		        WHEN NOT $3 THEN NULL
+		        ELSE COALESCE(resolved, NOW() AT TIME ZONE 'UTC')
+		    END
+		WHERE id = $1
+	`
+
+	tag, err := m.DB.Exec(ctx, stmt, id, notes, resolved)
+	if err != nil {
+		return fmt.Errorf("updating incident: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
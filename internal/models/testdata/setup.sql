@@ -3,17 +3,30 @@ CREATE TABLE snippets (
     title VARCHAR(100) NOT NULL,
     content TEXT NOT NULL,
     created TIMESTAMP NOT NULL,
-    expires TIMESTAMP NOT NULL
+    expires TIMESTAMP NOT NULL,
+    user_id INTEGER,
+    updated TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    search tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', content), 'B')
+    ) STORED
 );
 
 CREATE INDEX idx_snippets_created ON snippets (created);
+CREATE INDEX idx_snippets_search ON snippets USING GIN (search);
 
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL,
     hashed_password CHAR(60) NOT NULL,
-    created TIMESTAMP NOT NULL
+    created TIMESTAMP NOT NULL,
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    suspended_until TIMESTAMP,
+    suspension_reason TEXT NOT NULL DEFAULT '',
+    tos_version INTEGER NOT NULL DEFAULT 0,
+    tos_accepted_at TIMESTAMP,
+    password_changed_at TIMESTAMP
 );
 
 ALTER TABLE users ADD CONSTRAINT users_uc_email UNIQUE (email);
@@ -24,3 +37,143 @@ INSERT INTO users (name, email, hashed_password, created) VALUES (
     '$2a$12$NuTjWXm3KKntReFwyBVHyuf/to.HEwTy.eS206TNfkGfr6HzGJSWG',
     '2022-01-01 09:18:24'
 );
+
+CREATE TABLE audit_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    event VARCHAR(50) NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL
+);
+
+CREATE TABLE snippet_view_rollups (
+    snippet_id INTEGER NOT NULL REFERENCES snippets (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    referrer VARCHAR(255) NOT NULL,
+    ua_family VARCHAR(50) NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (snippet_id, day, referrer, ua_family)
+);
+
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created TIMESTAMP NOT NULL,
+    last_used TIMESTAMP,
+    request_count BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE api_token_ips (
+    token_id INTEGER NOT NULL REFERENCES api_tokens (id) ON DELETE CASCADE,
+    ip_range VARCHAR(64) NOT NULL,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (token_id, ip_range)
+);
+
+CREATE TABLE user_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    theme VARCHAR(20) NOT NULL DEFAULT 'light',
+    snippets_per_page INTEGER NOT NULL DEFAULT 10,
+    default_expiry INTEGER NOT NULL DEFAULT 365,
+    language VARCHAR(10) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE health_checks (
+    id BIGSERIAL PRIMARY KEY,
+    checked_at TIMESTAMP NOT NULL,
+    ok BOOLEAN NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE status_incidents (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    started TIMESTAMP NOT NULL,
+    resolved TIMESTAMP
+);
+
+CREATE TABLE settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    updated_by INTEGER REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE data_exports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created TIMESTAMP NOT NULL,
+    completed TIMESTAMP,
+    expires TIMESTAMP NOT NULL,
+    archive BYTEA
+);
+
+CREATE TABLE email_queue (
+    id SERIAL PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    html TEXT NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL,
+    next_attempt TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent TIMESTAMP
+);
+
+CREATE TABLE passkeys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    created TIMESTAMP NOT NULL,
+    last_used TIMESTAMP
+);
+
+CREATE INDEX idx_passkeys_user_id ON passkeys (user_id);
+
+CREATE TABLE notification_settings (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, kind)
+);
+
+CREATE TABLE notifications_sent (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    ref VARCHAR(100) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, kind, ref)
+);
+
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE abuse_reports (
+    id SERIAL PRIMARY KEY,
+    snippet_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    contact VARCHAR(255) NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL,
+    resolved TIMESTAMP
+);
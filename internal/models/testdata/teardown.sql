@@ -1,2 +1,17 @@
+DROP TABLE IF EXISTS abuse_reports CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
+DROP TABLE IF EXISTS notifications_sent CASCADE;
+DROP TABLE IF EXISTS notification_settings CASCADE;
+DROP TABLE IF EXISTS passkeys CASCADE;
+DROP TABLE IF EXISTS email_queue CASCADE;
+DROP TABLE IF EXISTS data_exports CASCADE;
+DROP TABLE IF EXISTS settings CASCADE;
+DROP TABLE IF EXISTS status_incidents CASCADE;
+DROP TABLE IF EXISTS health_checks CASCADE;
+DROP TABLE IF EXISTS user_preferences CASCADE;
+DROP TABLE IF EXISTS api_token_ips CASCADE;
+DROP TABLE IF EXISTS api_tokens CASCADE;
+DROP TABLE IF EXISTS snippet_view_rollups CASCADE;
+DROP TABLE IF EXISTS audit_events CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
 DROP TABLE IF EXISTS snippets CASCADE;
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TokenPrefix marks personal access tokens so they are easy to recognise in
+// configuration files and secret scanners.
+const TokenPrefix = "sbx_"
+
+type TokenModelInterface interface {
+	Create(ctx context.Context, userID int, name string) (string, Token, error)
+	List(ctx context.Context, userID int) ([]Token, error)
+	Delete(ctx context.Context, userID, id int) error
+	Authenticate(ctx context.Context, plaintext string) (Token, error)
+	RecordUse(ctx context.Context, tokenID int, ipRange string) (bool, error)
+	IPs(ctx context.Context, tokenID int) ([]TokenIP, error)
+}
+
+// Token is a personal access token. The plaintext value is only available
+// once, when the token is created.
+type Token struct {
+	ID           int
+	UserID       int
+	Name         string
+	Created      time.Time
+	LastUsed     time.Time
+	RequestCount int64
+	IPs          []TokenIP
+}
+
+// TokenIP summarises the requests a token made from one network range.
+type TokenIP struct {
+	Range        string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	RequestCount int64
+}
+
+type TokenModel struct {
+	DB *pgxpool.Pool
+}
+
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Create generates a new random token for the user and returns its plaintext
+// value, which is not stored and cannot be recovered later.
+func (m *TokenModel) Create(ctx context.Context, userID int, name string) (string, Token, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", Token{}, fmt.Errorf("generating token: %w", err)
+	}
+
+	plaintext := TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	stmt := `
+		INSERT INTO api_tokens (user_id, name, token_hash, created)
+		VALUES ($1, $2, $3, NOW() AT TIME ZONE 'UTC')
+		RETURNING id, created
+	`
+
+	t := Token{UserID: userID, Name: name}
+
+	err := dbFor(ctx, m.DB).QueryRow(ctx, stmt, userID, name, hashToken(plaintext)).Scan(&t.ID, &t.Created)
+	if err != nil {
+		return "", Token{}, fmt.Errorf("inserting token: %w", err)
+	}
+
+	return plaintext, t, nil
+}
+
+func (m *TokenModel) List(ctx context.Context, userID int) ([]Token, error) {
+	stmt := `
+		SELECT id, user_id, name, created, last_used, request_count
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := dbFor(ctx, m.DB).Query(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []Token
+
+	for rows.Next() {
+		var (
+			t        Token
+			lastUsed *time.Time
+		)
+
+		err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Created, &lastUsed, &t.RequestCount)
+		if err != nil {
+			return nil, fmt.Errorf("scanning token: %w", err)
+		}
+
+		if lastUsed != nil {
+			t.LastUsed = *lastUsed
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Delete revokes a token. Only the owning user can delete their tokens.
+func (m *TokenModel) Delete(ctx context.Context, userID, id int) error {
+	stmt := `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`
+
+	tag, err := dbFor(ctx, m.DB).Exec(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting token: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// Authenticate looks up the token matching plaintext, returning
+// ErrInvalidCredentials if there is none.
+func (m *TokenModel) Authenticate(ctx context.Context, plaintext string) (Token, error) {
+	stmt := `
+		SELECT id, user_id, name, created
+		FROM api_tokens
+		WHERE token_hash = $1
+	`
+
+	var t Token
+
+	err := dbFor(ctx, m.DB).QueryRow(ctx, stmt, hashToken(plaintext)).Scan(&t.ID, &t.UserID, &t.Name, &t.Created)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Token{}, ErrInvalidCredentials
+		}
+
+		return Token{}, fmt.Errorf("authenticating token: %w", err)
+	}
+
+	return t, nil
+}
+
+// RecordUse bumps the usage counters of a token and of the network range the
+// request came from. It reports whether the range had never been seen before
+// while the token already had other ranges on record, i.e. whether the owner
+// should be alerted.
+func (m *TokenModel) RecordUse(ctx context.Context, tokenID int, ipRange string) (bool, error) {
+	tx, err := dbFor(ctx, m.DB).Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	stmt := `
+		UPDATE api_tokens
+		SET last_used = NOW() AT TIME ZONE 'UTC', request_count = request_count + 1
+		WHERE id = $1
+	`
+
+	if _, err := tx.Exec(ctx, stmt, tokenID); err != nil {
+		return false, fmt.Errorf("updating token usage: %w", err)
+	}
+
+	// xmax is 0 only for freshly inserted rows, which tells a new range apart
+	// from an update of an existing one.
+	stmt = `
+		INSERT INTO api_token_ips (token_id, ip_range, first_seen, last_seen, request_count)
+		VALUES ($1, $2, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC', 1)
+		ON CONFLICT (token_id, ip_range) DO UPDATE
+		SET last_seen = EXCLUDED.last_seen, request_count = api_token_ips.request_count + 1
+		RETURNING (xmax = 0)
+	`
+
+	var inserted bool
+	if err := tx.QueryRow(ctx, stmt, tokenID, ipRange).Scan(&inserted); err != nil {
+		return false, fmt.Errorf("updating token ip usage: %w", err)
+	}
+
+	var ranges int
+
+	stmt = `SELECT COUNT(*) FROM api_token_ips WHERE token_id = $1`
+	if err := tx.QueryRow(ctx, stmt, tokenID).Scan(&ranges); err != nil {
+		return false, fmt.Errorf("counting token ip ranges: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("committing token usage: %w", err)
+	}
+
+	return inserted && ranges > 1, nil
+}
+
+func (m *TokenModel) IPs(ctx context.Context, tokenID int) ([]TokenIP, error) {
+	stmt := `
+		SELECT ip_range, first_seen, last_seen, request_count
+		FROM api_token_ips
+		WHERE token_id = $1
+		ORDER BY last_seen DESC
+		LIMIT 20
+	`
+
+	rows, err := dbFor(ctx, m.DB).Query(ctx, stmt, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("querying token ips: %w", err)
+	}
+	defer rows.Close()
+
+	var ips []TokenIP
+
+	for rows.Next() {
+		var ip TokenIP
+		if err := rows.Scan(&ip.Range, &ip.FirstSeen, &ip.LastSeen, &ip.RequestCount); err != nil {
+			return nil, fmt.Errorf("scanning token ip: %w", err)
+		}
+		ips = append(ips, ip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating token ips: %w", err)
+	}
+
+	return ips, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TxRunner runs a function in a database transaction. Model methods called
+// with the context it passes to fn run in that transaction, so a handler
+// can make several of them, on any model, succeed or fail together.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// PoolTxRunner runs transactions on a connection pool.
+type PoolTxRunner struct {
+	DB *pgxpool.Pool
+}
+
+type txContextKey struct{}
+
+// WithTx begins a transaction, calls fn with a context carrying it and
+// commits if fn returns nil, rolling back otherwise. Called within another
+// transaction, it runs fn in a savepoint of that one, so fn failing only
+// undoes its own statements.
+func (r *PoolTxRunner) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := dbFor(ctx, r.DB).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// dbtx runs statements; both a pool and a transaction do.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// dbFor returns the transaction WithTx put in ctx, if any, and otherwise
+// the model's pool. Model methods run their statements on it so that they
+// take part in a caller's transaction.
+func dbFor(ctx context.Context, pool *pgxpool.Pool) dbtx {
+	if tx, ok := ctx.Value(txContextKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return pool
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestPoolTxRunner_WithTx(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	runner := &PoolTxRunner{DB: db}
+	audit := &AuditModel{DB: db}
+
+	count := func() int {
+		events, err := audit.List(t.Context(), AuditFilter{UserID: 1})
+		assert.NilError(t, err)
+
+		return len(events)
+	}
+
+	before := count()
+	errAbort := errors.New("abort")
+
+	err := runner.WithTx(t.Context(), func(ctx context.Context) error {
+		assert.NilError(t, audit.Insert(ctx, 1, AuditLogin, "127.0.0.1", ""))
+
+		return errAbort
+	})
+	assert.Equal(t, err, errAbort)
+	assert.Equal(t, count(), before)
+
+	err = runner.WithTx(t.Context(), func(ctx context.Context) error {
+		assert.NilError(t, audit.Insert(ctx, 1, AuditLogin, "127.0.0.1", ""))
+
+		// A nested transaction failing only undoes its own statements.
+		nested := runner.WithTx(ctx, func(ctx context.Context) error {
+			assert.NilError(t, audit.Insert(ctx, 1, AuditLogin, "127.0.0.1", ""))
+
+			return errAbort
+		})
+		assert.Equal(t, nested, errAbort)
+
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, count(), before+1)
+}
@@ -13,48 +13,72 @@ import (
 )
 
 type UserModelInterface interface {
-	Insert(name, email, password string) error
+	Insert(name, email, password string, tosVersion int) (int, error)
 	Authenticate(email, password string) (int, error)
 	Exists(id int) (bool, error)
 	Get(id int) (User, error)
 	PasswordUpdate(id int, currentPassword, newPassword string) error
+	Suspend(id int, until time.Time, reason string) error
+	Unsuspend(id int) error
+	AcceptTOS(id, version int) error
+	SetAdmin(email string, admin bool) (int, error)
 }
 
 type User struct {
-	ID             int
-	Name           string
-	Email          string
-	HashedPassword []byte
-	Created        time.Time
+	ID                int
+	Name              string
+	Email             string
+	HashedPassword    []byte
+	Created           time.Time
+	IsAdmin           bool
+	SuspendedUntil    time.Time
+	SuspensionReason  string
+	TOSVersion        int
+	PasswordChangedAt time.Time
+}
+
+// PasswordExpired reports whether the password is older than maxAge. A zero
+// maxAge disables the rotation policy.
+func (u User) PasswordExpired(maxAge time.Duration) bool {
+	return maxAge > 0 && time.Since(u.PasswordChangedAt) > maxAge
+}
+
+// Suspended reports whether the user's suspension is still in effect. A user
+// who has never been suspended has a zero SuspendedUntil.
+func (u User) Suspended() bool {
+	return u.SuspendedUntil.After(time.Now())
 }
 
 type UserModel struct {
 	DB *pgxpool.Pool
 }
 
-func (m *UserModel) Insert(name, email, password string) error {
+func (m *UserModel) Insert(name, email, password string, tosVersion int) (int, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
 	if err != nil {
-		return fmt.Errorf("hashing password: %w", err)
+		return 0, fmt.Errorf("hashing password: %w", err)
 	}
 
-	stmt := `INSERT INTO users (name, email, hashed_password, created)
-	         VALUES ($1, $2, $3, NOW() AT TIME ZONE 'UTC')`
+	stmt := `INSERT INTO users (name, email, hashed_password, created, tos_version, tos_accepted_at, password_changed_at)
+	         VALUES ($1, $2, $3, NOW() AT TIME ZONE 'UTC', $4, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC')
+	         RETURNING id`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = m.DB.Exec(ctx, stmt, name, email, hashedPassword)
+	var id int
+
+	err = m.DB.QueryRow(ctx, stmt, name, email, hashedPassword, tosVersion).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_uc_email" {
-			return ErrDuplicateEmail
+			return 0, ErrDuplicateEmail
 		}
 
-		return fmt.Errorf("inserting user: %w", err)
+		return 0, fmt.Errorf("inserting user: %w", err)
 	}
 
-	return nil
+	return id, nil
 }
 
 func (m *UserModel) Authenticate(email, password string) (int, error) {
@@ -100,19 +124,38 @@ func (m *UserModel) Exists(id int) (bool, error) {
 	return exists, nil
 }
 
-func (m *UserModel) Get(id int) (User, error) {
-	var user User
+// userColumns are the columns of a user, in the order scanUser reads them.
+// The password hash is left out; only Authenticate needs it.
+const userColumns = `id, name, email, created, is_admin, suspended_until, suspension_reason, tos_version,
+	COALESCE(password_changed_at, created)`
 
-	stmt := `SELECT id, name, email, created FROM users WHERE id = $1`
+// scanUser scans a row selecting userColumns.
+func scanUser(row pgx.CollectableRow) (User, error) {
+	var (
+		user           User
+		suspendedUntil *time.Time
+	)
+
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Created, &user.IsAdmin,
+		&suspendedUntil, &user.SuspensionReason, &user.TOSVersion, &user.PasswordChangedAt)
+
+	if suspendedUntil != nil {
+		user.SuspendedUntil = *suspendedUntil
+	}
+
+	return user, err //nolint:wrapcheck // the query helpers' callers wrap it
+}
+
+func (m *UserModel) Get(id int) (User, error) {
+	const stmt = `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRow(ctx, stmt, id).
-		Scan(&user.ID, &user.Name, &user.Email, &user.Created)
+	user, err := queryOne(ctx, m.DB, scanUser, stmt, id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return User{}, ErrNoRecord
+		if errors.Is(err, ErrNoRecord) {
+			return User{}, err
 		}
 		return User{}, fmt.Errorf("fetching user: %w", err)
 	}
@@ -144,7 +187,7 @@ func (m *UserModel) PasswordUpdate(id int, currentPassword, newPassword string)
 		return fmt.Errorf("hashing new password: %w", err)
 	}
 
-	stmt = `UPDATE users SET hashed_password = $1 WHERE id = $2`
+	stmt = `UPDATE users SET hashed_password = $1, password_changed_at = NOW() AT TIME ZONE 'UTC' WHERE id = $2`
 
 	_, err = m.DB.Exec(ctx, stmt, newHashedPassword, id)
 	if err != nil {
@@ -153,3 +196,81 @@ func (m *UserModel) PasswordUpdate(id int, currentPassword, newPassword string)
 
 	return nil
 }
+
+func (m *UserModel) Suspend(id int, until time.Time, reason string) error {
+	stmt := `UPDATE users SET suspended_until = $1, suspension_reason = $2 WHERE id = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tag, err := m.DB.Exec(ctx, stmt, until.UTC(), reason, id)
+	if err != nil {
+		return fmt.Errorf("suspending user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+func (m *UserModel) Unsuspend(id int) error {
+	stmt := `UPDATE users SET suspended_until = NULL, suspension_reason = '' WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tag, err := m.DB.Exec(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("unsuspending user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// SetAdmin grants or revokes admin rights for the user with the given email
+// address and returns their ID.
+func (m *UserModel) SetAdmin(email string, admin bool) (int, error) {
+	stmt := `UPDATE users SET is_admin = $2 WHERE email = $1 RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var id int
+
+	err := m.DB.QueryRow(ctx, stmt, email, admin).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+
+		return 0, fmt.Errorf("setting admin flag: %w", err)
+	}
+
+	return id, nil
+}
+
+// AcceptTOS records that the user accepted the given terms-of-service
+// version.
+func (m *UserModel) AcceptTOS(id, version int) error {
+	stmt := `UPDATE users SET tos_version = $1, tos_accepted_at = NOW() AT TIME ZONE 'UTC' WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tag, err := m.DB.Exec(ctx, stmt, version, id)
+	if err != nil {
+		return fmt.Errorf("recording terms acceptance: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
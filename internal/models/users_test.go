@@ -46,3 +46,21 @@ func TestUserModel_Exists(t *testing.T) {
 		})
 	}
 }
+
+func TestUserModel_Get(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	m := UserModel{newTestDB(t)}
+
+	user, err := m.Get(1)
+	assert.NilError(t, err)
+	assert.Equal(t, user.Name, "Alice Jones")
+	assert.Equal(t, user.Email, "alice@example.com")
+	assert.Equal(t, user.Suspended(), false)
+	assert.Equal(t, user.PasswordChangedAt, user.Created)
+
+	_, err = m.Get(2)
+	assert.Equal(t, err, ErrNoRecord)
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookSecretPrefix marks webhook signing secrets, like TokenPrefix marks
+// access tokens.
+const WebhookSecretPrefix = "whsec_"
+
+// Events a webhook can subscribe to.
+const (
+	WebhookSnippetCreated = "snippet.created"
+)
+
+// WebhookEvents are the events a webhook can subscribe to.
+var WebhookEvents = []string{WebhookSnippetCreated}
+
+type WebhookModelInterface interface {
+	Insert(ctx context.Context, userID int, url string, events []string) (Webhook, error)
+	Get(ctx context.Context, userID, id int) (Webhook, error)
+	List(ctx context.Context, userID int) ([]Webhook, error)
+	Update(ctx context.Context, userID, id int, url string, events []string) (Webhook, error)
+	RotateSecret(ctx context.Context, userID, id int) (Webhook, error)
+	Delete(ctx context.Context, userID, id int) error
+	Subscribed(ctx context.Context, userID int, event string) ([]Webhook, error)
+}
+
+// Webhook is a URL that is sent a signed POST request whenever one of its
+// events happens to its owner's snippets.
+type Webhook struct {
+	ID      int
+	UserID  int
+	URL     string
+	Events  []string
+	Secret  string
+	Created time.Time
+	Updated time.Time
+}
+
+// Subscribes reports whether the webhook is sent event.
+func (w Webhook) Subscribes(event string) bool {
+	return slices.Contains(w.Events, event)
+}
+
+type WebhookModel struct {
+	DB *pgxpool.Pool
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+const webhookColumns = `id, user_id, url, events, secret, created, updated`
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var w Webhook
+
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Events, &w.Secret, &w.Created, &w.Updated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Webhook{}, ErrNoRecord
+	}
+
+	return w, err
+}
+
+// Insert registers a webhook for the user with a new random secret.
+func (m *WebhookModel) Insert(ctx context.Context, userID int, url string, events []string) (Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	stmt := `
+		INSERT INTO webhooks (user_id, url, events, secret, created, updated)
+		VALUES ($1, $2, $3, $4, NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC')
+		RETURNING ` + webhookColumns
+
+	w, err := scanWebhook(m.DB.QueryRow(ctx, stmt, userID, url, events, secret))
+	if err != nil {
+		return Webhook{}, fmt.Errorf("inserting webhook: %w", err)
+	}
+
+	return w, nil
+}
+
+// Get returns one of the user's webhooks, or ErrNoRecord if the user has no
+// webhook with that ID.
+func (m *WebhookModel) Get(ctx context.Context, userID, id int) (Webhook, error) {
+	stmt := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	w, err := scanWebhook(m.DB.QueryRow(ctx, stmt, id, userID))
+	if err != nil && !errors.Is(err, ErrNoRecord) {
+		return Webhook{}, fmt.Errorf("getting webhook: %w", err)
+	}
+
+	return w, err
+}
+
+func (m *WebhookModel) List(ctx context.Context, userID int) ([]Webhook, error) {
+	stmt := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id`
+
+	return m.query(ctx, stmt, userID)
+}
+
+// Update replaces the URL and events of one of the user's webhooks.
+func (m *WebhookModel) Update(ctx context.Context, userID, id int, url string, events []string) (Webhook, error) {
+	stmt := `
+		UPDATE webhooks
+		SET url = $3, events = $4, updated = NOW() AT TIME ZONE 'UTC'
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + webhookColumns
+
+	w, err := scanWebhook(m.DB.QueryRow(ctx, stmt, id, userID, url, events))
+	if err != nil && !errors.Is(err, ErrNoRecord) {
+		return Webhook{}, fmt.Errorf("updating webhook: %w", err)
+	}
+
+	return w, err
+}
+
+// RotateSecret replaces the secret of one of the user's webhooks with a new
+// random one. Deliveries are signed with the new secret from then on.
+func (m *WebhookModel) RotateSecret(ctx context.Context, userID, id int) (Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	stmt := `
+		UPDATE webhooks
+		SET secret = $3, updated = NOW() AT TIME ZONE 'UTC'
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + webhookColumns
+
+	w, err := scanWebhook(m.DB.QueryRow(ctx, stmt, id, userID, secret))
+	if err != nil && !errors.Is(err, ErrNoRecord) {
+		return Webhook{}, fmt.Errorf("rotating webhook secret: %w", err)
+	}
+
+	return w, err
+}
+
+// Delete removes one of the user's webhooks.
+func (m *WebhookModel) Delete(ctx context.Context, userID, id int) error {
+	stmt := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	tag, err := m.DB.Exec(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// Subscribed returns the user's webhooks that subscribe to event.
+func (m *WebhookModel) Subscribed(ctx context.Context, userID int, event string) ([]Webhook, error) {
+	stmt := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 AND $2 = ANY (events) ORDER BY id`
+
+	return m.query(ctx, stmt, userID, event)
+}
+
+func (m *WebhookModel) query(ctx context.Context, stmt string, args ...any) ([]Webhook, error) {
+	rows, err := m.DB.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning webhook: %w", err)
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
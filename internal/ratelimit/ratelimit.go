@@ -0,0 +1,124 @@
+// Package ratelimit implements an in-memory token-bucket rate limiter keyed
+// by arbitrary strings such as client IPs or API token IDs.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter holds one token bucket per key. Each bucket refills at rate tokens
+// per second up to burst tokens, and every allowed request takes one token.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	rate    float64
+	burst   float64
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter allowing rate requests per second on average with
+// bursts of up to burst requests.
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns
+// false together with how long the caller should wait before retrying.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	res := l.Take(key)
+
+	return res.Allowed, res.RetryAfter
+}
+
+// Result describes the state of a bucket after Take, in the terms of the
+// X-RateLimit-* response headers.
+type Result struct {
+	Allowed    bool
+	Limit      int           // requests the full bucket allows
+	Remaining  int           // whole requests left in the bucket
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, if not Allowed
+}
+
+// Take is Allow, but also reports how much of key's bucket is left.
+func (l *Limiter) Take(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key)
+
+	res := Result{Allowed: b.tokens >= 1, Limit: int(l.burst)}
+
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((l.burst - b.tokens) / l.rate * float64(time.Second))
+
+	return res
+}
+
+// refill tops up key's bucket for the time elapsed since it was last used,
+// creating a full bucket for unseen keys. It must be called with mu held.
+func (l *Limiter) refill(key string) *bucket {
+	now := l.now()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+
+		return b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+	b.last = now
+
+	return b
+}
+
+// Cleanup forgets buckets that have not been used for longer than idle.
+// Forgotten keys start again with a full bucket, so idle should be at least
+// the time a bucket needs to refill completely.
+func (l *Limiter) Cleanup(idle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RefillTime is how long an empty bucket takes to fill up completely, the
+// shortest idle time that is safe to pass to Cleanup.
+func (l *Limiter) RefillTime() time.Duration {
+	return time.Duration(l.burst / l.rate * float64(time.Second))
+}
+
+// Len reports how many keys are currently tracked.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
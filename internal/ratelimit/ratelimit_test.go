@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	l := New(2, 3)
+	l.now = func() time.Time { return now }
+
+	for range 3 {
+		ok, _ := l.Allow("a")
+		assert.Equal(t, ok, true)
+	}
+
+	ok, wait := l.Allow("a")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, wait, 500*time.Millisecond)
+
+	// Other keys have their own bucket.
+	ok, _ = l.Allow("b")
+	assert.Equal(t, ok, true)
+
+	now = now.Add(500 * time.Millisecond)
+
+	ok, _ = l.Allow("a")
+	assert.Equal(t, ok, true)
+
+	ok, _ = l.Allow("a")
+	assert.Equal(t, ok, false)
+
+	// A long pause refills the bucket, but only up to burst.
+	now = now.Add(time.Hour)
+
+	for range 3 {
+		ok, _ = l.Allow("a")
+		assert.Equal(t, ok, true)
+	}
+
+	ok, _ = l.Allow("a")
+	assert.Equal(t, ok, false)
+}
+
+func TestLimiterTake(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	l := New(2, 3)
+	l.now = func() time.Time { return now }
+
+	res := l.Take("a")
+	assert.Equal(t, res, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 500 * time.Millisecond})
+
+	l.Take("a")
+	res = l.Take("a")
+	assert.Equal(t, res, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 1500 * time.Millisecond})
+
+	res = l.Take("a")
+	assert.Equal(t, res, Result{Limit: 3, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond})
+
+	// Half a token does not count as a remaining request.
+	now = now.Add(250 * time.Millisecond)
+
+	res = l.Take("a")
+	assert.Equal(t, res.Allowed, false)
+	assert.Equal(t, res.Remaining, 0)
+	assert.Equal(t, res.Reset, 1250*time.Millisecond)
+}
+
+func TestLimiterCleanup(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	l := New(1, 1)
+	l.now = func() time.Time { return now }
+
+	l.Allow("old")
+
+	now = now.Add(time.Minute)
+	l.Allow("new")
+
+	l.Cleanup(30 * time.Second)
+
+	assert.Equal(t, l.Len(), 1)
+}
+
+func TestLimiterRefillTime(t *testing.T) {
+	assert.Equal(t, New(2, 10).RefillTime(), 5*time.Second)
+	assert.Equal(t, New(10, 1).RefillTime(), 100*time.Millisecond)
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2024, 3, 13, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 13, 10, 18, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 13, 11, 0, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 3, 14, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 13, 10, 30, 0, 0, time.UTC)},
+		{"5/20 9-17 * * *", time.Date(2024, 3, 13, 10, 25, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"30 8 1 * *", time.Date(2024, 4, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted either one matches.
+		{"0 0 20 * 5", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			assert.NilError(t, err)
+			assert.Equal(t, s.Next(from), tt.want)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"} {
+		_, err := Parse(spec)
+		if err == nil {
+			t.Errorf("Parse(%q): want error", spec)
+		}
+	}
+}
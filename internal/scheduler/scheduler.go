@@ -0,0 +1,200 @@
+// Package scheduler runs jobs on cron schedules. A job that is still running
+// when it is due again is skipped rather than started a second time, and the
+// outcome of each job's last run is kept for display.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Status describes a job and its most recent run.
+type Status struct {
+	Name string
+	Spec string
+	// Next is when the job is due next, zero if it never is.
+	Next    time.Time
+	Running bool
+	// LastStart and LastDuration describe the last run, which failed with
+	// LastError unless it is empty.
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string
+	Runs         int
+	Failures     int
+	// Skipped counts the times the job was due while still running.
+	Skipped int
+}
+
+type job struct {
+	schedule Schedule
+	run      func(ctx context.Context) error
+	status   Status
+}
+
+// Scheduler runs registered jobs while Run is running. Create one with New.
+type Scheduler struct {
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu   sync.Mutex
+	jobs []*job
+}
+
+// New returns a Scheduler that logs job failures to logger. Schedules are
+// evaluated in UTC.
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Add registers run to be called whenever the cron expression spec is due.
+// It returns an error if spec does not parse or name is already taken.
+func (s *Scheduler) Add(name, spec string, run func(ctx context.Context) error) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.status.Name == name {
+			return fmt.Errorf("scheduler: job %q already added", name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &job{
+		schedule: schedule,
+		run:      run,
+		status:   Status{Name: name, Spec: spec, Next: schedule.Next(s.now())},
+	})
+
+	return nil
+}
+
+// Run starts the jobs as they become due until ctx is cancelled, then waits
+// for the running ones, which see ctx cancelled, to return. Jobs must be
+// added before Run is called.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		next := s.next()
+		if next.IsZero() {
+			<-ctx.Done()
+
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+			s.runDue(ctx, s.now(), &wg)
+		}
+	}
+}
+
+// next returns the earliest time a job is due, or the zero time if there
+// are no jobs.
+func (s *Scheduler) next() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+
+	for _, j := range s.jobs {
+		if !j.status.Next.IsZero() && (next.IsZero() || j.status.Next.Before(next)) {
+			next = j.status.Next
+		}
+	}
+
+	return next
+}
+
+// runDue starts every job that is due at now, skipping those still running
+// from an earlier time.
+func (s *Scheduler) runDue(ctx context.Context, now time.Time, wg *sync.WaitGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.status.Next.IsZero() || j.status.Next.After(now) {
+			continue
+		}
+
+		j.status.Next = j.schedule.Next(now)
+
+		if j.status.Running {
+			j.status.Skipped++
+			s.logger.Warn("skipping job that is still running", slog.String("job", j.status.Name))
+
+			continue
+		}
+
+		j.status.Running = true
+		j.status.LastStart = now
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.finish(j, s.call(ctx, j))
+		}()
+	}
+}
+
+// call runs the job, turning a panic into an error.
+func (s *Scheduler) call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.run(ctx)
+}
+
+func (s *Scheduler) finish(j *job, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j.status.Running = false
+	j.status.LastDuration = s.now().Sub(j.status.LastStart)
+	j.status.LastError = ""
+	j.status.Runs++
+
+	// A job cut short by shutdown did not fail.
+	if err != nil && !errors.Is(err, context.Canceled) {
+		j.status.LastError = err.Error()
+		j.status.Failures++
+		s.logger.Error("job failed", slog.String("job", j.status.Name), slog.String("err", err.Error()))
+	}
+}
+
+// Status returns the status of every job in the order they were added.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, len(s.jobs))
+	for i, j := range s.jobs {
+		statuses[i] = j.status
+	}
+
+	return statuses
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestSchedulerRunDue(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler))
+
+	now := time.Date(2024, 3, 13, 10, 59, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	assert.NilError(t, s.Add("rollup", "@hourly", func(context.Context) error {
+		started <- struct{}{}
+		<-release
+
+		return nil
+	}))
+	assert.NilError(t, s.Add("purge", "0 3 * * *", func(context.Context) error {
+		return errors.New("database unavailable")
+	}))
+	assert.Equal(t, s.Add("purge", "@daily", nil) != nil, true)
+	assert.Equal(t, s.Add("broken", "@often", nil) != nil, true)
+
+	var wg sync.WaitGroup
+
+	ctx := context.Background()
+
+	now = time.Date(2024, 3, 13, 11, 0, 0, 0, time.UTC)
+	s.runDue(ctx, now, &wg)
+	<-started
+
+	status := s.Status()
+	assert.Equal(t, status[0].Running, true)
+	assert.Equal(t, status[0].Next, time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, status[1].Next, time.Date(2024, 3, 14, 3, 0, 0, 0, time.UTC))
+
+	// The rollup is due again before its first run has finished.
+	now = time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	s.runDue(ctx, now, &wg)
+	assert.Equal(t, s.Status()[0].Skipped, 1)
+
+	close(release)
+	wg.Wait()
+
+	status = s.Status()
+	assert.Equal(t, status[0].Running, false)
+	assert.Equal(t, status[0].Runs, 1)
+	assert.Equal(t, status[0].LastDuration, time.Hour)
+	assert.Equal(t, status[0].LastError, "")
+
+	now = time.Date(2024, 3, 14, 3, 0, 0, 0, time.UTC)
+	s.runDue(ctx, now, &wg)
+	wg.Wait()
+
+	status = s.Status()
+	assert.Equal(t, status[0].Runs, 2)
+	assert.Equal(t, status[1].Runs, 1)
+	assert.Equal(t, status[1].Failures, 1)
+	assert.Equal(t, status[1].LastError, "database unavailable")
+}
+
+func TestSchedulerPanic(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler))
+
+	assert.NilError(t, s.Add("crash", "* * * * *", func(context.Context) error { panic("boom") }))
+
+	var wg sync.WaitGroup
+
+	s.runDue(context.Background(), time.Now().Add(time.Hour), &wg)
+	wg.Wait()
+
+	assert.Equal(t, s.Status()[0].LastError, "panic: boom")
+}
+
+func TestSchedulerRunStops(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler))
+
+	assert.NilError(t, s.Add("purge", "@daily", func(context.Context) error { return nil }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+}
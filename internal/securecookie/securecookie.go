@@ -0,0 +1,138 @@
+// Package securecookie encodes small values for cookies so that clients can
+// not change them undetected and, optionally, cannot read them. It suits
+// lightweight state such as a dismissed banner that is not worth a session.
+package securecookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalid is returned for values that were not produced by the codec,
+	// were altered or belong to a cookie of another name.
+	ErrInvalid = errors.New("securecookie: invalid value")
+	// ErrExpired is returned for values older than the maximum age.
+	ErrExpired = errors.New("securecookie: value expired")
+)
+
+// minHashKeyLen is the shortest signing key accepted, matching the output
+// size of SHA-256.
+const minHashKeyLen = 32
+
+// Codec signs cookie values with HMAC-SHA256 and, when it has an encryption
+// key, encrypts them with AES-GCM first.
+type Codec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	now     func() time.Time
+}
+
+// New returns a Codec signing with hashKey, which must be at least 32
+// bytes. A non-empty encryptKey of 16, 24 or 32 bytes also encrypts values
+// with AES-128, AES-192 or AES-256.
+func New(hashKey, encryptKey []byte) (*Codec, error) {
+	if len(hashKey) < minHashKeyLen {
+		return nil, fmt.Errorf("securecookie: hash key must be at least %d bytes", minHashKeyLen)
+	}
+
+	c := &Codec{hashKey: hashKey, now: time.Now}
+
+	if len(encryptKey) > 0 {
+		block, err := aes.NewCipher(encryptKey)
+		if err != nil {
+			return nil, fmt.Errorf("securecookie: encryption key: %w", err)
+		}
+
+		c.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("securecookie: encryption key: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// Encode returns value encoded for the cookie called name. The name is
+// bound into the signature, so a value cannot be replayed under another
+// cookie name.
+func (c *Codec) Encode(name string, value []byte) (string, error) {
+	payload := binary.BigEndian.AppendUint64(nil, uint64(c.now().Unix())) //nolint:gosec // Unix times are positive
+
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("securecookie: generating nonce: %w", err)
+		}
+
+		payload = c.aead.Seal(append(payload, nonce...), nonce, value, []byte(name))
+	} else {
+		payload = append(payload, value...)
+	}
+
+	enc := base64.RawURLEncoding
+
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.mac(name, payload)), nil
+}
+
+// Decode returns the value in encoded, which must have been produced by
+// Encode for the same name no longer than maxAge ago. A maxAge of zero
+// accepts values of any age.
+func (c *Codec) Decode(name, encoded string, maxAge time.Duration) ([]byte, error) {
+	enc := base64.RawURLEncoding
+
+	rawPayload, rawMAC, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil || len(payload) < 8 {
+		return nil, ErrInvalid
+	}
+
+	mac, err := enc.DecodeString(rawMAC)
+	if err != nil || !hmac.Equal(mac, c.mac(name, payload)) {
+		return nil, ErrInvalid
+	}
+
+	created := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0) //nolint:gosec // signed by us
+	if maxAge > 0 && c.now().Sub(created) > maxAge {
+		return nil, ErrExpired
+	}
+
+	value := payload[8:]
+
+	if c.aead != nil {
+		if len(value) < c.aead.NonceSize() {
+			return nil, ErrInvalid
+		}
+
+		nonce, ciphertext := value[:c.aead.NonceSize()], value[c.aead.NonceSize():]
+
+		value, err = c.aead.Open(nil, nonce, ciphertext, []byte(name))
+		if err != nil {
+			return nil, ErrInvalid
+		}
+	}
+
+	return value, nil
+}
+
+func (c *Codec) mac(name string, payload []byte) []byte {
+	h := hmac.New(sha256.New, c.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(payload)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,96 @@
+package securecookie
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+var (
+	hashKey    = bytes.Repeat([]byte("h"), 32)
+	encryptKey = bytes.Repeat([]byte("e"), 32)
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(hashKey[:16], nil)
+	assert.Equal(t, err != nil, true)
+
+	_, err = New(hashKey, []byte("short"))
+	assert.Equal(t, err != nil, true)
+
+	_, err = New(hashKey, encryptKey)
+	assert.NilError(t, err)
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, key := range [][]byte{nil, encryptKey} {
+		c, err := New(hashKey, key)
+		assert.NilError(t, err)
+
+		encoded, err := c.Encode("theme", []byte("dark"))
+		assert.NilError(t, err)
+
+		// Signed values are readable; encrypted ones are not.
+		payload, _, _ := strings.Cut(encoded, ".")
+		raw, err := base64.RawURLEncoding.DecodeString(payload)
+		assert.NilError(t, err)
+		assert.Equal(t, bytes.Contains(raw, []byte("dark")), key == nil)
+
+		value, err := c.Decode("theme", encoded, time.Hour)
+		assert.NilError(t, err)
+		assert.Equal(t, string(value), "dark")
+	}
+}
+
+func TestCodecDecodeRejects(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 0, 0, 0, time.UTC)
+
+	c, err := New(hashKey, encryptKey)
+	assert.NilError(t, err)
+
+	c.now = func() time.Time { return now }
+
+	encoded, err := c.Encode("banner", []byte("1"))
+	assert.NilError(t, err)
+
+	other, err := New(bytes.Repeat([]byte("x"), 32), encryptKey)
+	assert.NilError(t, err)
+
+	tampered := []byte(encoded)
+	tampered[3] ^= 1
+
+	tests := []struct {
+		name    string
+		codec   *Codec
+		cookie  string
+		value   string
+		maxAge  time.Duration
+		later   time.Duration
+		wantErr error
+	}{
+		{"Valid", c, "banner", encoded, time.Hour, 0, nil},
+		{"Any age", c, "banner", encoded, 0, 24 * time.Hour, nil},
+		{"Expired", c, "banner", encoded, time.Hour, 2 * time.Hour, ErrExpired},
+		{"Other name", c, "theme", encoded, time.Hour, 0, ErrInvalid},
+		{"Other key", other, "banner", encoded, time.Hour, 0, ErrInvalid},
+		{"Tampered", c, "banner", string(tampered), time.Hour, 0, ErrInvalid},
+		{"Garbage", c, "banner", "not-a-cookie", time.Hour, 0, ErrInvalid},
+		{"Empty", c, "banner", "", time.Hour, 0, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.codec.now = func() time.Time { return now.Add(tt.later) }
+
+			_, err := tt.codec.Decode(tt.cookie, tt.value, tt.maxAge)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v; want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errCBOR is returned for any malformed or unsupported CBOR input.
+var errCBOR = errors.New("webauthn: malformed CBOR")
+
+// maxCBORDepth bounds the nesting of decoded arrays and maps.
+const maxCBORDepth = 8
+
+// decodeCBOR decodes the first CBOR data item in b and returns it along with
+// the bytes that follow it. Only the subset used by WebAuthn is supported:
+// integers (as int64), byte and text strings, arrays, maps and the simple
+// values false, true and null. Indefinite lengths and floats are rejected.
+func decodeCBOR(b []byte) (any, []byte, error) {
+	return decodeItem(b, 0)
+}
+
+func decodeItem(b []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, fmt.Errorf("%w: nested too deeply", errCBOR)
+	}
+
+	major, n, b, err := decodeHead(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if n > 1<<63-1 {
+			return nil, nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+
+		return int64(n), b, nil
+	case 1:
+		if n > 1<<63-1 {
+			return nil, nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+
+		return -1 - int64(n), b, nil
+	case 2, 3:
+		if n > uint64(len(b)) {
+			return nil, nil, fmt.Errorf("%w: truncated string", errCBOR)
+		}
+
+		if major == 2 {
+			return b[:n:n], b[n:], nil
+		}
+
+		return string(b[:n]), b[n:], nil
+	case 4:
+		return decodeArray(b, n, depth)
+	case 5:
+		return decodeMap(b, n, depth)
+	case 7:
+		switch n {
+		case 20:
+			return false, b, nil
+		case 21:
+			return true, b, nil
+		case 22:
+			return nil, b, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: unsupported major type %d", errCBOR, major)
+}
+
+// decodeHead reads the initial byte and argument of a data item.
+func decodeHead(b []byte) (byte, uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, 0, nil, fmt.Errorf("%w: unexpected end of input", errCBOR)
+	}
+
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+
+	// Simple values keep their value in the additional information.
+	if major == 7 && info < 24 {
+		return major, uint64(info), b, nil
+	}
+
+	size := 0
+
+	switch {
+	case info < 24:
+		return major, uint64(info), b, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, nil, fmt.Errorf("%w: unsupported additional information %d", errCBOR, info)
+	}
+
+	if major == 7 || len(b) < size {
+		return 0, 0, nil, fmt.Errorf("%w: unsupported or truncated item", errCBOR)
+	}
+
+	var buf [8]byte
+	copy(buf[8-size:], b[:size])
+
+	return major, binary.BigEndian.Uint64(buf[:]), b[size:], nil
+}
+
+func decodeArray(b []byte, n uint64, depth int) (any, []byte, error) {
+	// Every item takes at least one byte.
+	if n > uint64(len(b)) {
+		return nil, nil, fmt.Errorf("%w: truncated array", errCBOR)
+	}
+
+	items := make([]any, 0, n)
+
+	for range n {
+		var (
+			item any
+			err  error
+		)
+
+		item, b, err = decodeItem(b, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, b, nil
+}
+
+func decodeMap(b []byte, n uint64, depth int) (any, []byte, error) {
+	if n > uint64(len(b))/2 {
+		return nil, nil, fmt.Errorf("%w: truncated map", errCBOR)
+	}
+
+	m := make(map[any]any, n)
+
+	for range n {
+		var (
+			key, value any
+			err        error
+		)
+
+		key, b, err = decodeItem(b, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch key.(type) {
+		case int64, string:
+		default:
+			return nil, nil, fmt.Errorf("%w: unsupported map key", errCBOR)
+		}
+
+		value, b, err = decodeItem(b, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m[key] = value
+	}
+
+	return m, b, nil
+}
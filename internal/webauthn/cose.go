@@ -0,0 +1,114 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers of the supported signature algorithms, in the
+// order they are offered to authenticators.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Algorithms lists the supported COSE algorithms.
+var Algorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters (RFC 9053).
+const (
+	coseKty = 1
+	coseAlg = 3
+	coseCrv = -1 // also the RSA modulus
+	coseX   = -2 // also the RSA exponent
+	coseY   = -3
+
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parsePublicKey(b []byte) (publicKey, error) {
+	v, _, err := decodeCBOR(b)
+	if err != nil {
+		return publicKey{}, fmt.Errorf("%w: invalid COSE key", ErrVerification)
+	}
+
+	m, _ := v.(map[any]any)
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+	crv, _ := m[int64(coseCrv)].(int64)
+	x, _ := m[int64(coseX)].([]byte)
+	y, _ := m[int64(coseY)].([]byte)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256 && crv == crvP256:
+		return parseP256(x, y)
+	case kty == ktyOKP && alg == AlgEdDSA && crv == crvEd25519 && len(x) == ed25519.PublicKeySize:
+		return publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseCrv)].([]byte)
+		e := new(big.Int).SetBytes(x)
+
+		if len(n) < 256 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return publicKey{}, fmt.Errorf("%w: unsupported RSA key", ErrVerification)
+		}
+
+		return publicKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(e.Int64())}}, nil
+	}
+
+	return publicKey{}, fmt.Errorf("%w: unsupported key type %d algorithm %d", ErrVerification, kty, alg)
+}
+
+func parseP256(x, y []byte) (publicKey, error) {
+	if len(x) != 32 || len(y) != 32 {
+		return publicKey{}, fmt.Errorf("%w: invalid P-256 key", ErrVerification)
+	}
+
+	// ecdh checks that the point is on the curve.
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return publicKey{}, fmt.Errorf("%w: invalid P-256 key", ErrVerification)
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	return publicKey{alg: AlgES256, key: key}, nil
+}
+
+func (k publicKey) verify(data, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(data)
+
+		return ecdsa.VerifyASN1(key, sum[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig) == nil
+	}
+
+	return false
+}
@@ -0,0 +1,278 @@
+// Package webauthn implements the server side of the WebAuthn registration
+// and authentication ceremonies used for passkey sign-in. It verifies the
+// responses produced by navigator.credentials.create() and .get() without
+// checking attestation statements, which is what "none" attestation asks
+// for.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ChallengeSize is the number of random bytes in a challenge.
+const ChallengeSize = 32
+
+// Authenticator data flags.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+var (
+	// ErrVerification is returned when a ceremony response does not check
+	// out, e.g. because of a wrong challenge, origin or signature.
+	ErrVerification = errors.New("webauthn: verification failed")
+	// ErrCloned is returned when the signature counter went backwards,
+	// which suggests the authenticator was cloned.
+	ErrCloned = errors.New("webauthn: signature counter did not increase")
+)
+
+// RelyingParty identifies the site credentials are scoped to.
+type RelyingParty struct {
+	// ID is the relying party ID, the host name of the site.
+	ID string
+	// Name is shown to the user by the authenticator.
+	Name string
+	// Origin is the scheme, host and port the ceremonies run on.
+	Origin string
+}
+
+// New returns the relying party for a site served at baseURL.
+func New(name, baseURL string) (*RelyingParty, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("webauthn: invalid base URL %q", baseURL)
+	}
+
+	return &RelyingParty{
+		ID:     u.Hostname(),
+		Name:   name,
+		Origin: u.Scheme + "://" + u.Host,
+	}, nil
+}
+
+// Credential is a registered public key credential.
+type Credential struct {
+	ID []byte
+	// PublicKey is the COSE-encoded public key.
+	PublicKey []byte
+	SignCount uint32
+}
+
+// Assertion is the response of an authenticator to a sign-in challenge.
+type Assertion struct {
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+}
+
+// NewChallenge returns a fresh random challenge.
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, ChallengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generating challenge: %w", err)
+	}
+
+	return b, nil
+}
+
+// Encode returns the unpadded base64url encoding WebAuthn uses for binary
+// values in JSON.
+func Encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode reverses Encode.
+func Decode(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64url: %w", err)
+	}
+
+	return b, nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, typ string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("%w: invalid client data", ErrVerification)
+	}
+
+	got, err := Decode(cd.Challenge)
+
+	switch {
+	case cd.Type != typ:
+		return fmt.Errorf("%w: unexpected ceremony type %q", ErrVerification, cd.Type)
+	case err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1:
+		return fmt.Errorf("%w: challenge mismatch", ErrVerification)
+	case cd.Origin != rp.Origin:
+		return fmt.Errorf("%w: unexpected origin %q", ErrVerification, cd.Origin)
+	}
+
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+func parseAuthenticatorData(b []byte) (authenticatorData, error) {
+	if len(b) < 37 {
+		return authenticatorData{}, fmt.Errorf("%w: authenticator data too short", ErrVerification)
+	}
+
+	ad := authenticatorData{
+		rpIDHash:  b[:32],
+		flags:     b[32],
+		signCount: binary.BigEndian.Uint32(b[33:37]),
+	}
+
+	if ad.flags&flagAttested == 0 {
+		return ad, nil
+	}
+
+	// Attested credential data: AAGUID, credential ID length and ID, then
+	// the COSE key.
+	rest := b[37:]
+	if len(rest) < 18 {
+		return authenticatorData{}, fmt.Errorf("%w: truncated credential data", ErrVerification)
+	}
+
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+
+	if idLen == 0 || len(rest) < idLen {
+		return authenticatorData{}, fmt.Errorf("%w: truncated credential ID", ErrVerification)
+	}
+
+	ad.credentialID = rest[:idLen]
+	rest = rest[idLen:]
+
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return authenticatorData{}, fmt.Errorf("%w: invalid credential public key", ErrVerification)
+	}
+
+	ad.publicKey = rest[:len(rest)-len(after)]
+
+	return ad, nil
+}
+
+func (rp *RelyingParty) checkAuthenticatorData(ad authenticatorData) error {
+	want := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(ad.rpIDHash, want[:]) {
+		return fmt.Errorf("%w: relying party ID mismatch", ErrVerification)
+	}
+
+	if ad.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user not present", ErrVerification)
+	}
+
+	// A passkey replaces the password, so presence alone is not enough:
+	// the authenticator must also have checked a PIN or biometric.
+	if ad.flags&flagUserVerified == 0 {
+		return fmt.Errorf("%w: user not verified", ErrVerification)
+	}
+
+	return nil
+}
+
+// VerifyRegistration checks the response to a registration challenge and
+// returns the new credential.
+func (rp *RelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte) (Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	obj, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w: invalid attestation object", ErrVerification)
+	}
+
+	m, _ := obj.(map[any]any)
+
+	raw, ok := m["authData"].([]byte)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: missing authenticator data", ErrVerification)
+	}
+
+	ad, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return Credential{}, err
+	}
+
+	if ad.credentialID == nil {
+		return Credential{}, fmt.Errorf("%w: no attested credential", ErrVerification)
+	}
+
+	// Make sure the key is one we can verify signatures with later.
+	if _, err := parsePublicKey(ad.publicKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:        bytes.Clone(ad.credentialID),
+		PublicKey: bytes.Clone(ad.publicKey),
+		SignCount: ad.signCount,
+	}, nil
+}
+
+// VerifyAssertion checks the response to a sign-in challenge against a
+// registered credential and returns the new signature counter to store.
+func (rp *RelyingParty) VerifyAssertion(cred Credential, challenge []byte, a Assertion) (uint32, error) {
+	if err := rp.verifyClientData(a.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	ad, err := parseAuthenticatorData(a.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(cred.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientHash := sha256.Sum256(a.ClientDataJSON)
+	signed := append(bytes.Clone(a.AuthenticatorData), clientHash[:]...)
+
+	if !key.verify(signed, a.Signature) {
+		return 0, fmt.Errorf("%w: bad signature", ErrVerification)
+	}
+
+	// Authenticators that do not implement a counter always report zero.
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return 0, ErrCloned
+	}
+
+	return ad.signCount, nil
+}
@@ -0,0 +1,254 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+// encodeCBOR is a minimal encoder for the values used in these tests.
+func encodeCBOR(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+
+			return b
+		}
+	}
+
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case map[any]any:
+		b := head(5, uint64(len(v)))
+		for k, val := range v {
+			b = append(b, encodeCBOR(k)...)
+			b = append(b, encodeCBOR(val)...)
+		}
+
+		return b
+	}
+
+	panic("unsupported type")
+}
+
+type testAuthenticator struct {
+	t         *testing.T
+	rp        *RelyingParty
+	id        []byte
+	ecKey     *ecdsa.PrivateKey
+	edKey     ed25519.PrivateKey
+	signCount uint32
+	// unverified makes assertions report user presence without user
+	// verification, like a security key without a PIN.
+	unverified bool
+}
+
+func newTestAuthenticator(t *testing.T, rp *RelyingParty, eddsa bool) *testAuthenticator {
+	t.Helper()
+
+	a := &testAuthenticator{t: t, rp: rp, id: []byte("credential-id")}
+
+	if eddsa {
+		_, a.edKey, _ = ed25519.GenerateKey(rand.Reader)
+	} else {
+		a.ecKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+
+	return a
+}
+
+func (a *testAuthenticator) coseKey() []byte {
+	if a.edKey != nil {
+		return encodeCBOR(map[any]any{
+			coseKty: ktyOKP, coseAlg: AlgEdDSA, coseCrv: crvEd25519,
+			coseX: []byte(a.edKey.Public().(ed25519.PublicKey)),
+		})
+	}
+
+	return encodeCBOR(map[any]any{
+		coseKty: ktyEC2, coseAlg: AlgES256, coseCrv: crvP256,
+		coseX: a.ecKey.X.FillBytes(make([]byte, 32)),
+		coseY: a.ecKey.Y.FillBytes(make([]byte, 32)),
+	})
+}
+
+func (a *testAuthenticator) authData(flags byte, attested bool) []byte {
+	rpHash := sha256.Sum256([]byte(a.rp.ID))
+
+	b := append([]byte{}, rpHash[:]...)
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, a.signCount)
+
+	if attested {
+		b = append(b, make([]byte, 16)...)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(a.id)))
+		b = append(b, a.id...)
+		b = append(b, a.coseKey()...)
+	}
+
+	return b
+}
+
+func clientDataJSON(typ string, challenge []byte, origin string) []byte {
+	b, _ := json.Marshal(clientData{Type: typ, Challenge: Encode(challenge), Origin: origin})
+
+	return b
+}
+
+func (a *testAuthenticator) register(challenge []byte) ([]byte, []byte) {
+	obj := encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(flagUserPresent|flagUserVerified|flagAttested, true),
+	})
+
+	return clientDataJSON("webauthn.create", challenge, a.rp.Origin), obj
+}
+
+func (a *testAuthenticator) assert(challenge []byte, origin string) Assertion {
+	a.signCount++
+
+	cd := clientDataJSON("webauthn.get", challenge, origin)
+	flags := byte(flagUserPresent | flagUserVerified)
+	if a.unverified {
+		flags = flagUserPresent
+	}
+
+	ad := a.authData(flags, false)
+
+	hash := sha256.Sum256(cd)
+	signed := append(bytes.Clone(ad), hash[:]...)
+
+	var sig []byte
+
+	if a.edKey != nil {
+		sig = ed25519.Sign(a.edKey, signed)
+	} else {
+		sum := sha256.Sum256(signed)
+
+		var err error
+
+		sig, err = ecdsa.SignASN1(rand.Reader, a.ecKey, sum[:])
+		assert.NilError(a.t, err)
+	}
+
+	return Assertion{ClientDataJSON: cd, AuthenticatorData: ad, Signature: sig}
+}
+
+func TestNew(t *testing.T) {
+	rp, err := New("Snippetbox", "https://snippets.example.com:8443/base")
+	assert.NilError(t, err)
+	assert.Equal(t, rp.ID, "snippets.example.com")
+	assert.Equal(t, rp.Origin, "https://snippets.example.com:8443")
+
+	_, err = New("Snippetbox", "not a url")
+	assert.Equal(t, err != nil, true)
+}
+
+func TestCeremonies(t *testing.T) {
+	rp, err := New("Snippetbox", "https://snippets.example.com")
+	assert.NilError(t, err)
+
+	for _, eddsa := range []bool{false, true} {
+		name := "ES256"
+		if eddsa {
+			name = "EdDSA"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			a := newTestAuthenticator(t, rp, eddsa)
+
+			challenge, err := NewChallenge()
+			assert.NilError(t, err)
+
+			cd, obj := a.register(challenge)
+
+			_, err = rp.VerifyRegistration([]byte("other challenge"), cd, obj)
+			assert.Equal(t, errors.Is(err, ErrVerification), true)
+
+			cred, err := rp.VerifyRegistration(challenge, cd, obj)
+			assert.NilError(t, err)
+			assert.Equal(t, string(cred.ID), "credential-id")
+
+			challenge, err = NewChallenge()
+			assert.NilError(t, err)
+
+			count, err := rp.VerifyAssertion(cred, challenge, a.assert(challenge, rp.Origin))
+			assert.NilError(t, err)
+			assert.Equal(t, count, 1)
+
+			cred.SignCount = count
+
+			// Wrong origin.
+			_, err = rp.VerifyAssertion(cred, challenge, a.assert(challenge, "https://evil.example.com"))
+			assert.Equal(t, errors.Is(err, ErrVerification), true)
+
+			// Tampered signature.
+			assertion := a.assert(challenge, rp.Origin)
+			assertion.Signature[len(assertion.Signature)-1] ^= 0xff
+
+			_, err = rp.VerifyAssertion(cred, challenge, assertion)
+			assert.Equal(t, errors.Is(err, ErrVerification), true)
+
+			// A touch without a PIN or biometric check.
+			a.unverified = true
+
+			_, err = rp.VerifyAssertion(cred, challenge, a.assert(challenge, rp.Origin))
+			assert.Equal(t, errors.Is(err, ErrVerification), true)
+
+			a.unverified = false
+
+			// A counter that does not move forward.
+			cred.SignCount = 100
+
+			_, err = rp.VerifyAssertion(cred, challenge, a.assert(challenge, rp.Origin))
+			assert.Equal(t, errors.Is(err, ErrCloned), true)
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	v, rest, err := decodeCBOR([]byte{0xa2, 0x01, 0x02, 0x61, 'a', 0x82, 0x20, 0xf5, 0xff})
+	assert.NilError(t, err)
+	assert.Equal(t, len(rest), 1)
+
+	m := v.(map[any]any)
+	assert.Equal(t, m[int64(1)], any(int64(2)))
+	assert.Equal(t, len(m["a"].([]any)), 2)
+
+	for _, b := range [][]byte{
+		{},
+		{0x5a, 0xff, 0xff, 0xff, 0xff}, // byte string longer than the input
+		{0x9f},                         // indefinite-length array
+		{0xfb, 0, 0, 0, 0, 0, 0, 0, 0}, // float
+		{0xa1, 0x80, 0x01},             // array as map key
+		bytes.Repeat([]byte{0x81}, 20), // nested too deeply
+	} {
+		_, _, err := decodeCBOR(b)
+		assert.Equal(t, errors.Is(err, errCBOR), true)
+	}
+}
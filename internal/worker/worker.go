@@ -0,0 +1,214 @@
+// Package worker runs background goroutines: named workers that live until
+// shutdown and one-off tasks started while serving requests. A panic in one
+// of them is logged instead of crashing the process, every worker keeps
+// counters that can be inspected while it runs, and shutdown can stop them
+// and wait for them to finish.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is returned by Stop when the goroutines did not finish in time.
+var ErrTimeout = errors.New("worker: timed out waiting for background work to finish")
+
+// Stats describe one named worker.
+type Stats struct {
+	Name    string
+	Running bool
+	Started time.Time
+	// Stopped is when the worker last returned, zero while it has not.
+	Stopped time.Time
+	// Panics counts the panics recovered from the worker, and LastPanic
+	// holds the value of the most recent one.
+	Panics    int
+	LastPanic string
+}
+
+// Group runs and tracks background goroutines. Create one with New.
+type Group struct {
+	logger *slog.Logger
+	// ctx is passed to the workers and cancelled by Stop.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	workers map[string]*Stats
+	// tasks counts the one-off tasks and tracked work in progress.
+	tasks int
+}
+
+// New returns a Group that logs recovered panics to logger.
+func New(logger *slog.Logger) *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Group{
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		workers: make(map[string]*Stats),
+	}
+}
+
+// Go starts the worker name, which runs run until ctx is cancelled by Stop.
+// A worker that returns early, or panics, is reported by Stopped. Starting
+// a worker under the name of one that is still running panics.
+func (g *Group) Go(name string, run func(ctx context.Context)) {
+	g.mu.Lock()
+
+	s, ok := g.workers[name]
+	if ok && s.Running {
+		g.mu.Unlock()
+		panic("worker: " + name + " is already running")
+	}
+
+	if !ok {
+		s = &Stats{Name: name}
+		g.workers[name] = s
+	}
+
+	s.Running = true
+	s.Started = time.Now()
+	s.Stopped = time.Time{}
+
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		defer func() {
+			r := recover()
+
+			g.mu.Lock()
+			defer g.mu.Unlock()
+
+			s.Running = false
+			s.Stopped = time.Now()
+
+			if r != nil {
+				s.Panics++
+				s.LastPanic = fmt.Sprint(r)
+				g.logPanic("worker "+name, r)
+			}
+		}()
+
+		run(g.ctx)
+	}()
+}
+
+// Background runs fn in a goroutine that Stop waits for. Unlike a worker it
+// is not cancelled by Stop, so it should bound its own running time.
+func (g *Group) Background(fn func()) {
+	done := g.Track()
+
+	go func() {
+		defer done()
+
+		defer func() {
+			if r := recover(); r != nil {
+				g.logPanic("background task", r)
+			}
+		}()
+
+		fn()
+	}()
+}
+
+// Track makes Stop wait for work the group did not start, such as a
+// connection hijacked from the HTTP server, until the returned function is
+// called.
+func (g *Group) Track() (done func()) {
+	g.wg.Add(1)
+	g.mu.Lock()
+	g.tasks++
+	g.mu.Unlock()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			g.tasks--
+			g.mu.Unlock()
+			g.wg.Done()
+		})
+	}
+}
+
+// Tasks returns the number of one-off tasks and tracked work in progress.
+func (g *Group) Tasks() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.tasks
+}
+
+// Stats returns the stats of every worker started so far, sorted by name.
+func (g *Group) Stats() []Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := make([]Stats, 0, len(g.workers))
+	for _, s := range g.workers {
+		stats = append(stats, *s)
+	}
+
+	slices.SortFunc(stats, func(a, b Stats) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return stats
+}
+
+// Stopped returns the sorted names of the workers that have returned.
+func (g *Group) Stopped() []string {
+	var names []string
+
+	for _, s := range g.Stats() {
+		if !s.Running {
+			names = append(names, s.Name)
+		}
+	}
+
+	return names
+}
+
+// Wait blocks until every goroutine of the group has finished, without
+// cancelling the workers.
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
+
+// Stop cancels the workers and waits for them and the background tasks to
+// finish, or for ctx to be done, in which case it returns ErrTimeout.
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ErrTimeout
+	}
+}
+
+func (g *Group) logPanic(what string, r any) {
+	g.logger.Error(fmt.Sprintf("%s panic: %v", what, r))
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/snippetbox/internal/assert"
+)
+
+func TestGroup(t *testing.T) {
+	g := New(slog.New(slog.DiscardHandler))
+
+	g.Go("ticker", func(ctx context.Context) { <-ctx.Done() })
+	g.Go("crasher", func(context.Context) { panic("boom") })
+
+	for len(g.Stopped()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A panicking worker stops alone; the others keep running.
+	assert.Equal(t, len(g.Stopped()), 1)
+	assert.Equal(t, g.Stopped()[0], "crasher")
+
+	stats := g.Stats()
+	assert.Equal(t, len(stats), 2)
+	assert.Equal(t, stats[0].Name, "crasher")
+	assert.Equal(t, stats[0].Panics, 1)
+	assert.Equal(t, stats[0].LastPanic, "boom")
+	assert.Equal(t, stats[1].Name, "ticker")
+	assert.Equal(t, stats[1].Running, true)
+	assert.Equal(t, stats[1].Stopped.IsZero(), true)
+
+	// A stopped worker can be started again under its name.
+	g.Go("crasher", func(ctx context.Context) { <-ctx.Done() })
+	assert.Equal(t, len(g.Stopped()), 0)
+
+	finished := make(chan struct{})
+
+	g.Background(func() {
+		time.Sleep(20 * time.Millisecond)
+		close(finished)
+	})
+	g.Background(func() { panic("task failed") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NilError(t, g.Stop(ctx))
+
+	// Stop waits for background tasks, which it does not cancel.
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Stop returned before the background task finished")
+	}
+
+	assert.Equal(t, g.Tasks(), 0)
+	assert.Equal(t, len(g.Stopped()), 2)
+}
+
+func TestGroupStopTimeout(t *testing.T) {
+	g := New(slog.New(slog.DiscardHandler))
+
+	done := g.Track()
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, g.Stop(ctx), ErrTimeout)
+	assert.Equal(t, g.Tasks(), 1)
+}
+
+func TestGroupDuplicateName(t *testing.T) {
+	g := New(slog.New(slog.DiscardHandler))
+
+	g.Go("mailer", func(ctx context.Context) { <-ctx.Done() })
+
+	defer func() {
+		assert.Equal(t, recover() != nil, true)
+		assert.NilError(t, g.Stop(context.Background()))
+	}()
+
+	g.Go("mailer", func(context.Context) {})
+}
@@ -0,0 +1,7 @@
+// Package snippetboxv1 is the gRPC API of Snippetbox: the SnippetService
+// definition in snippets.proto and the client and server code generated
+// from it. Regenerate the code with protoc-gen-go and protoc-gen-go-grpc
+// after changing the definition.
+package snippetboxv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative rpc/snippetbox/v1/snippets.proto
@@ -0,0 +1,616 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: rpc/snippetbox/v1/snippets.proto
+
+package snippetboxv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Snippet is a stored snippet.
+type Snippet struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title   string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Created *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created,proto3" json:"created,omitempty"`
+	Updated *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated,proto3" json:"updated,omitempty"`
+	Expires *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires,proto3" json:"expires,omitempty"`
+	// The owner of the snippet, or 0 for anonymous snippets.
+	UserId        int64 `protobuf:"varint,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snippet) Reset() {
+	*x = Snippet{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snippet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snippet) ProtoMessage() {}
+
+func (x *Snippet) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snippet.ProtoReflect.Descriptor instead.
+func (*Snippet) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Snippet) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Snippet) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Snippet) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Snippet) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *Snippet) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *Snippet) GetExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expires
+	}
+	return nil
+}
+
+func (x *Snippet) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type CreateSnippetRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Title   string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// Days until the snippet expires: 1, 7 or 365.
+	ExpiresDays int32 `protobuf:"varint,3,opt,name=expires_days,json=expiresDays,proto3" json:"expires_days,omitempty"`
+	// The owner of the new snippet, or 0 to create it anonymously.
+	UserId        int64 `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSnippetRequest) Reset() {
+	*x = CreateSnippetRequest{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSnippetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSnippetRequest) ProtoMessage() {}
+
+func (x *CreateSnippetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSnippetRequest.ProtoReflect.Descriptor instead.
+func (*CreateSnippetRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateSnippetRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateSnippetRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateSnippetRequest) GetExpiresDays() int32 {
+	if x != nil {
+		return x.ExpiresDays
+	}
+	return 0
+}
+
+func (x *CreateSnippetRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type CreateSnippetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSnippetResponse) Reset() {
+	*x = CreateSnippetResponse{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSnippetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSnippetResponse) ProtoMessage() {}
+
+func (x *CreateSnippetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSnippetResponse.ProtoReflect.Descriptor instead.
+func (*CreateSnippetResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateSnippetResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetSnippetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnippetRequest) Reset() {
+	*x = GetSnippetRequest{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnippetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnippetRequest) ProtoMessage() {}
+
+func (x *GetSnippetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnippetRequest.ProtoReflect.Descriptor instead.
+func (*GetSnippetRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSnippetRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetSnippetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snippet       *Snippet               `protobuf:"bytes,1,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnippetResponse) Reset() {
+	*x = GetSnippetResponse{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnippetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnippetResponse) ProtoMessage() {}
+
+func (x *GetSnippetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnippetResponse.ProtoReflect.Descriptor instead.
+func (*GetSnippetResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSnippetResponse) GetSnippet() *Snippet {
+	if x != nil {
+		return x.Snippet
+	}
+	return nil
+}
+
+type ListSnippetsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Page to return, counted from 1; 0 means the first.
+	Page int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	// Snippets per page, at most 100; 0 means 10.
+	PageSize      int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnippetsRequest) Reset() {
+	*x = ListSnippetsRequest{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnippetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnippetsRequest) ProtoMessage() {}
+
+func (x *ListSnippetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnippetsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnippetsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSnippetsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListSnippetsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListSnippetsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Snippets []*Snippet             `protobuf:"bytes,1,rep,name=snippets,proto3" json:"snippets,omitempty"`
+	// The number of live snippets across all pages.
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnippetsResponse) Reset() {
+	*x = ListSnippetsResponse{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnippetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnippetsResponse) ProtoMessage() {}
+
+func (x *ListSnippetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnippetsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnippetsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSnippetsResponse) GetSnippets() []*Snippet {
+	if x != nil {
+		return x.Snippets
+	}
+	return nil
+}
+
+func (x *ListSnippetsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type DeleteSnippetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSnippetRequest) Reset() {
+	*x = DeleteSnippetRequest{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSnippetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSnippetRequest) ProtoMessage() {}
+
+func (x *DeleteSnippetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSnippetRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSnippetRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteSnippetRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteSnippetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSnippetResponse) Reset() {
+	*x = DeleteSnippetResponse{}
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSnippetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSnippetResponse) ProtoMessage() {}
+
+func (x *DeleteSnippetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_snippetbox_v1_snippets_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSnippetResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSnippetResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP(), []int{8}
+}
+
+var File_rpc_snippetbox_v1_snippets_proto protoreflect.FileDescriptor
+
+const file_rpc_snippetbox_v1_snippets_proto_rawDesc = "" +
+	"\n" +
+	" rpc/snippetbox/v1/snippets.proto\x12\rsnippetbox.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x84\x02\n" +
+	"\aSnippet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x124\n" +
+	"\acreated\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x124\n" +
+	"\aupdated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\x124\n" +
+	"\aexpires\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aexpires\x12\x17\n" +
+	"\auser_id\x18\a \x01(\x03R\x06userId\"\x82\x01\n" +
+	"\x14CreateSnippetRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12!\n" +
+	"\fexpires_days\x18\x03 \x01(\x05R\vexpiresDays\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\"'\n" +
+	"\x15CreateSnippetResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"#\n" +
+	"\x11GetSnippetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"F\n" +
+	"\x12GetSnippetResponse\x120\n" +
+	"\asnippet\x18\x01 \x01(\v2\x16.snippetbox.v1.SnippetR\asnippet\"F\n" +
+	"\x13ListSnippetsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"`\n" +
+	"\x14ListSnippetsResponse\x122\n" +
+	"\bsnippets\x18\x01 \x03(\v2\x16.snippetbox.v1.SnippetR\bsnippets\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"&\n" +
+	"\x14DeleteSnippetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x17\n" +
+	"\x15DeleteSnippetResponse2\xf4\x02\n" +
+	"\x0eSnippetService\x12Z\n" +
+	"\rCreateSnippet\x12#.snippetbox.v1.CreateSnippetRequest\x1a$.snippetbox.v1.CreateSnippetResponse\x12Q\n" +
+	"\n" +
+	"GetSnippet\x12 .snippetbox.v1.GetSnippetRequest\x1a!.snippetbox.v1.GetSnippetResponse\x12W\n" +
+	"\fListSnippets\x12\".snippetbox.v1.ListSnippetsRequest\x1a#.snippetbox.v1.ListSnippetsResponse\x12Z\n" +
+	"\rDeleteSnippet\x12#.snippetbox.v1.DeleteSnippetRequest\x1a$.snippetbox.v1.DeleteSnippetResponseBDZBgithub.com/FABLOUSFALCON/snippetbox/rpc/snippetbox/v1;snippetboxv1b\x06proto3"
+
+var (
+	file_rpc_snippetbox_v1_snippets_proto_rawDescOnce sync.Once
+	file_rpc_snippetbox_v1_snippets_proto_rawDescData []byte
+)
+
+func file_rpc_snippetbox_v1_snippets_proto_rawDescGZIP() []byte {
+	file_rpc_snippetbox_v1_snippets_proto_rawDescOnce.Do(func() {
+		file_rpc_snippetbox_v1_snippets_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_snippetbox_v1_snippets_proto_rawDesc), len(file_rpc_snippetbox_v1_snippets_proto_rawDesc)))
+	})
+	return file_rpc_snippetbox_v1_snippets_proto_rawDescData
+}
+
+var file_rpc_snippetbox_v1_snippets_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_rpc_snippetbox_v1_snippets_proto_goTypes = []any{
+	(*Snippet)(nil),               // 0: snippetbox.v1.Snippet
+	(*CreateSnippetRequest)(nil),  // 1: snippetbox.v1.CreateSnippetRequest
+	(*CreateSnippetResponse)(nil), // 2: snippetbox.v1.CreateSnippetResponse
+	(*GetSnippetRequest)(nil),     // 3: snippetbox.v1.GetSnippetRequest
+	(*GetSnippetResponse)(nil),    // 4: snippetbox.v1.GetSnippetResponse
+	(*ListSnippetsRequest)(nil),   // 5: snippetbox.v1.ListSnippetsRequest
+	(*ListSnippetsResponse)(nil),  // 6: snippetbox.v1.ListSnippetsResponse
+	(*DeleteSnippetRequest)(nil),  // 7: snippetbox.v1.DeleteSnippetRequest
+	(*DeleteSnippetResponse)(nil), // 8: snippetbox.v1.DeleteSnippetResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_rpc_snippetbox_v1_snippets_proto_depIdxs = []int32{
+	9, // 0: snippetbox.v1.Snippet.created:type_name -> google.protobuf.Timestamp
+	9, // 1: snippetbox.v1.Snippet.updated:type_name -> google.protobuf.Timestamp
+	9, // 2: snippetbox.v1.Snippet.expires:type_name -> google.protobuf.Timestamp
+	0, // 3: snippetbox.v1.GetSnippetResponse.snippet:type_name -> snippetbox.v1.Snippet
+	0, // 4: snippetbox.v1.ListSnippetsResponse.snippets:type_name -> snippetbox.v1.Snippet
+	1, // 5: snippetbox.v1.SnippetService.CreateSnippet:input_type -> snippetbox.v1.CreateSnippetRequest
+	3, // 6: snippetbox.v1.SnippetService.GetSnippet:input_type -> snippetbox.v1.GetSnippetRequest
+	5, // 7: snippetbox.v1.SnippetService.ListSnippets:input_type -> snippetbox.v1.ListSnippetsRequest
+	7, // 8: snippetbox.v1.SnippetService.DeleteSnippet:input_type -> snippetbox.v1.DeleteSnippetRequest
+	2, // 9: snippetbox.v1.SnippetService.CreateSnippet:output_type -> snippetbox.v1.CreateSnippetResponse
+	4, // 10: snippetbox.v1.SnippetService.GetSnippet:output_type -> snippetbox.v1.GetSnippetResponse
+	6, // 11: snippetbox.v1.SnippetService.ListSnippets:output_type -> snippetbox.v1.ListSnippetsResponse
+	8, // 12: snippetbox.v1.SnippetService.DeleteSnippet:output_type -> snippetbox.v1.DeleteSnippetResponse
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_rpc_snippetbox_v1_snippets_proto_init() }
+func file_rpc_snippetbox_v1_snippets_proto_init() {
+	if File_rpc_snippetbox_v1_snippets_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_snippetbox_v1_snippets_proto_rawDesc), len(file_rpc_snippetbox_v1_snippets_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rpc_snippetbox_v1_snippets_proto_goTypes,
+		DependencyIndexes: file_rpc_snippetbox_v1_snippets_proto_depIdxs,
+		MessageInfos:      file_rpc_snippetbox_v1_snippets_proto_msgTypes,
+	}.Build()
+	File_rpc_snippetbox_v1_snippets_proto = out.File
+	file_rpc_snippetbox_v1_snippets_proto_goTypes = nil
+	file_rpc_snippetbox_v1_snippets_proto_depIdxs = nil
+}
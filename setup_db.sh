@@ -46,8 +46,10 @@ PGPASSWORD=$POSTGRES_PASSWORD psql -h $POSTGRES_HOST -p $POSTGRES_PORT -U $POSTG
     ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT ALL ON SEQUENCES TO web;
 EOSQL
 
-# Run schema as 'web' user
-PGPASSWORD=pass psql -h $POSTGRES_HOST -p $POSTGRES_PORT -U web -d snippetbox -f schema.sql
+# Create the tables and sample snippets as 'web' user
+DSN="postgres://web:pass@$POSTGRES_HOST:$POSTGRES_PORT/snippetbox?sslmode=disable"
+go run ./cmd/web migrate up -dsn="$DSN"
+go run ./cmd/web seed -dsn="$DSN"
 
 echo "🧪 Setting up test database..."
 
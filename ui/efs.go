@@ -1,7 +1,23 @@
-// Package ui just embed the static files
+//go:build !dev
+
+// Package ui holds the templates and static files of the web interface.
+// Release builds embed them, so the binary runs from any directory.
 package ui
 
-import "embed"
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:generate go run ../cmd/web assets -ui .
+
+//go:embed "html" "static" "email"
+var embedded embed.FS
+
+// Files is the root of the UI assets, containing the html, static and email
+// directories.
+var Files fs.FS = embedded
 
-//go:embed "html" "static"
-var Files embed.FS
+// Embedded reports whether Files is embedded in the binary rather than read
+// from the source tree.
+const Embedded = true
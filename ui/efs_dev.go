@@ -0,0 +1,29 @@
+//go:build dev
+
+package ui
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Files reads the UI assets straight from the source tree in development
+// builds, so edits to static files show up without rebuilding. The
+// directory is found relative to this file rather than the working
+// directory, so go run works from anywhere in the repository.
+var Files fs.FS = os.DirFS(sourceDir())
+
+// Embedded reports whether Files is embedded in the binary rather than read
+// from the source tree.
+const Embedded = false
+
+func sourceDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "ui"
+	}
+
+	return filepath.Dir(file)
+}
@@ -0,0 +1,107 @@
+var navLinks=document.querySelectorAll("nav a");
+for(var i=0;i<navLinks.length;i++){
+var link=navLinks[i]
+if(link.getAttribute('href')==window.location.pathname){
+link.classList.add("live");
+break;
+}
+}
+function base64urlToBuffer(s){
+var bin=atob(s.replace(/-/g,"+").replace(/_/g,"/"));
+var bytes=new Uint8Array(bin.length);
+for(var i=0;i<bin.length;i++){
+bytes[i]=bin.charCodeAt(i);
+}
+return bytes.buffer;
+}
+function bufferToBase64url(buf){
+var bytes=new Uint8Array(buf);
+var bin="";
+for(var i=0;i<bytes.length;i++){
+bin +=String.fromCharCode(bytes[i]);
+}
+return btoa(bin).replace(/\+/g,"-").replace(/\//g,"_").replace(/=+$/,"");
+}
+function passkeyFailed(form,err){
+var msg=form.querySelector(".passkey-error");
+msg.textContent=err&&err.name==="NotAllowedError"
+?"The passkey request was cancelled or timed out."
+:"Something went wrong with your passkey. Please try again.";
+msg.hidden=false;
+}
+function enablePasskeyForm(form,ceremony){
+if(!form||!window.PublicKeyCredential){
+return;
+}
+form.hidden=false;
+var unsupported=document.querySelector(".passkey-unsupported");
+if(unsupported){
+unsupported.hidden=true;
+}
+form.addEventListener("submit",function(e){
+e.preventDefault();
+ceremony(form).then(function(){
+form.submit();
+},function(err){
+passkeyFailed(form,err);
+});
+});
+}
+enablePasskeyForm(document.getElementById("passkey-register"),function(form){
+var d=form.dataset;
+var exclude=d.exclude?d.exclude.split(","):[];
+return navigator.credentials.create({publicKey:{
+challenge:base64urlToBuffer(d.challenge),
+rp:{id:d.rpId,name:d.rpName},
+user:{id:base64urlToBuffer(d.userId),name:d.userName,displayName:d.userDisplayName},
+pubKeyCredParams:d.algorithms.split(",").map(function(alg){
+return{type:"public-key",alg:Number(alg)};
+}),
+excludeCredentials:exclude.map(function(id){
+return{type:"public-key",id:base64urlToBuffer(id)};
+}),
+authenticatorSelection:{residentKey:"preferred",userVerification:"required"},
+attestation:"none"
+}}).then(function(cred){
+form.elements.client_data.value=bufferToBase64url(cred.response.clientDataJSON);
+form.elements.attestation_object.value=bufferToBase64url(cred.response.attestationObject);
+});
+});
+function passkeyAssertion(form){
+var d=form.dataset;
+return navigator.credentials.get({publicKey:{
+challenge:base64urlToBuffer(d.challenge),
+rpId:d.rpId,
+userVerification:"required"
+}}).then(function(cred){
+form.elements.credential_id.value=bufferToBase64url(cred.rawId);
+form.elements.client_data.value=bufferToBase64url(cred.response.clientDataJSON);
+form.elements.authenticator_data.value=bufferToBase64url(cred.response.authenticatorData);
+form.elements.signature.value=bufferToBase64url(cred.response.signature);
+if(cred.response.userHandle){
+form.elements.user_handle.value=bufferToBase64url(cred.response.userHandle);
+}
+});
+}
+enablePasskeyForm(document.getElementById("passkey-login"),passkeyAssertion);
+enablePasskeyForm(document.getElementById("passkey-reauth"),passkeyAssertion);
+var latest=document.getElementById("latest-snippets");
+if(latest&&window.EventSource){
+var source=new EventSource(latest.dataset.events);
+source.addEventListener("snippet",function(e){
+var s=JSON.parse(e.data);
+var row=latest.insertRow(1);
+var link=document.createElement("a");
+link.href=s.url;
+link.dir="auto";
+link.textContent=s.title;
+row.insertCell().appendChild(link);
+row.insertCell().textContent=s.date;
+row.insertCell().textContent="#" + s.id;
+latest.hidden=false;
+var empty=document.getElementById("no-snippets");
+if(empty){
+empty.remove();
+}
+});
+}
\ No newline at end of file